@@ -0,0 +1,203 @@
+package auth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrUnknownKey is given back for a token signed with a key the key set doesn't have
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// KeySet gives back the RSA public keys tokens are signed with by their key id. An empty kid asks for the only key
+// of a key set holding a single one.
+type KeySet interface {
+	Key(ctx context.Context, kid string) (*rsa.PublicKey, error)
+}
+
+// jwk is a JSON web key. Only RSA keys are read, the others are skipped.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type staticKeySet struct {
+	keys map[string]*rsa.PublicKey
+}
+
+// ParseKeySet parses a JWKS document, {"keys":[...]}, into a key set of its RSA signing keys
+func ParseKeySet(raw []byte) (KeySet, error) {
+	var document struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &document); err != nil {
+		return nil, fmt.Errorf("invalid jwks: %v", err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, key := range document.Keys {
+		if key.Kty != "RSA" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+		publicKey, err := rsaPublicKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jwks key %q: %v", key.Kid, err)
+		}
+		keys[key.Kid] = publicKey
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("jwks has no RSA signing keys")
+	}
+	return staticKeySet{keys: keys}, nil
+}
+
+// LoadKeySetFile reads a JWKS document from a local file
+func LoadKeySetFile(path string) (KeySet, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeySet(raw)
+}
+
+func (s staticKeySet) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, nil
+		}
+	}
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	return key, nil
+}
+
+// rsaPublicKey decodes the base64url modulus and exponent of a JSON web key
+func rsaPublicKey(key jwk) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(key.N)
+	if err != nil || len(n) == 0 {
+		return nil, errors.New("invalid modulus")
+	}
+	e, err := base64.RawURLEncoding.DecodeString(key.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, errors.New("invalid exponent")
+	}
+	exponent := 0
+	for _, b := range e {
+		exponent = exponent<<8 | int(b)
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: exponent}, nil
+}
+
+// DefaultKeySetRefreshInterval is how often a remote key set is fetched again at most, when a token names a key it
+// doesn't have
+const DefaultKeySetRefreshInterval = 5 * time.Minute
+
+type remoteKeySet struct {
+	url             string
+	client          *http.Client
+	refreshInterval time.Duration
+	now             func() time.Time
+
+	mu        sync.Mutex
+	keys      KeySet
+	fetchErr  error
+	fetchedAt time.Time
+	// fetching is closed when the fetch in flight is done, nil when there is none
+	fetching chan struct{}
+}
+
+// NewRemoteKeySet constructs and gives back a key set fetched from a JWKS url. It is fetched on the first token and
+// again when a token names a key it doesn't have, at most once every refreshInterval, so rotated keys are picked up.
+// A failed fetch isn't retried before refreshInterval either, so an unavailable identity provider isn't hammered.
+func NewRemoteKeySet(url string, client *http.Client, refreshInterval time.Duration) KeySet {
+	return &remoteKeySet{url: url, client: client, refreshInterval: refreshInterval, now: time.Now}
+}
+
+// Key gives back the key from the last fetched key set, fetching it again when it is due. Concurrent lookups wait on
+// a single fetch, made outside the lock so lookups of known keys don't wait behind it.
+func (r *remoteKeySet) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	r.mu.Lock()
+	due := r.fetchedAt.IsZero() || r.now().Sub(r.fetchedAt) >= r.refreshInterval
+	if r.keys != nil {
+		key, err := r.keys.Key(ctx, kid)
+		if err != ErrUnknownKey || !due {
+			r.mu.Unlock()
+			return key, err
+		}
+	} else if !due {
+		err := r.fetchErr
+		r.mu.Unlock()
+		return nil, err
+	}
+
+	done := r.fetching
+	if done == nil {
+		done = make(chan struct{})
+		r.fetching = done
+		go r.refresh(done)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	r.mu.Lock()
+	keys, err := r.keys, r.fetchErr
+	r.mu.Unlock()
+	if keys == nil {
+		return nil, err
+	}
+	return keys.Key(ctx, kid)
+}
+
+// refresh fetches the key set and records it, or the error of a failed fetch next to the key set fetched before.
+// It isn't tied to the context of the lookup that started it, whose caller may give up while the others wait.
+func (r *remoteKeySet) refresh(done chan struct{}) {
+	keys, err := r.fetch(context.Background())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err == nil {
+		r.keys = keys
+	}
+	r.fetchErr, r.fetchedAt, r.fetching = err, r.now(), nil
+	close(done)
+}
+
+// fetch gets and parses the JWKS document
+func (r *remoteKeySet) fetch(ctx context.Context) (KeySet, error) {
+	req, err := http.NewRequest(http.MethodGet, r.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch jwks: %v", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to fetch jwks: %s", res.Status)
+	}
+	raw, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeySet(raw)
+}
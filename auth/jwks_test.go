@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteKeySet(t *testing.T) {
+	key := newTestKey(t, "key-1")
+	rotated := newTestKey(t, "key-2")
+	served := key.jwks
+	fetches := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Write(served)
+	}))
+	defer ts.Close()
+
+	now := time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC)
+	keys := &remoteKeySet{url: ts.URL, client: ts.Client(), refreshInterval: time.Minute, now: func() time.Time { return now }}
+
+	_, err := keys.Key(context.Background(), "key-1")
+	assert.NoError(t, err)
+	_, err = keys.Key(context.Background(), "key-1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, fetches)
+
+	// a rotated key is picked up once the refresh interval is over
+	served = rotated.jwks
+	_, err = keys.Key(context.Background(), "key-2")
+	assert.Equal(t, ErrUnknownKey, err)
+	assert.Equal(t, 1, fetches)
+
+	now = now.Add(time.Minute)
+	publicKey, err := keys.Key(context.Background(), "key-2")
+	assert.NoError(t, err)
+	assert.Equal(t, rotated.key.PublicKey, *publicKey)
+	assert.Equal(t, 2, fetches)
+}
+
+func TestRemoteKeySetUnavailable(t *testing.T) {
+	key := newTestKey(t, "key-1")
+	var fetches int32
+	available := int32(0)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		if atomic.LoadInt32(&available) == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(key.jwks)
+	}))
+	defer ts.Close()
+
+	now := time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC)
+	keys := &remoteKeySet{url: ts.URL, client: ts.Client(), refreshInterval: time.Minute, now: func() time.Time { return now }}
+
+	_, err := keys.Key(context.Background(), "key-1")
+	assert.EqualError(t, err, "unable to fetch jwks: 503 Service Unavailable")
+
+	// the failed fetch isn't retried before the refresh interval is over
+	atomic.StoreInt32(&available, 1)
+	_, err = keys.Key(context.Background(), "key-1")
+	assert.EqualError(t, err, "unable to fetch jwks: 503 Service Unavailable")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+
+	now = now.Add(time.Minute)
+	_, err = keys.Key(context.Background(), "key-1")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+}
+
+func TestRemoteKeySetFetchesOnce(t *testing.T) {
+	key := newTestKey(t, "key-1")
+	rotated := newTestKey(t, "key-2")
+	var fetches int32
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&fetches, 1) == 1 {
+			w.Write(key.jwks)
+			return
+		}
+		<-release
+		w.Write(rotated.jwks)
+	}))
+	defer ts.Close()
+
+	keys := NewRemoteKeySet(ts.URL, ts.Client(), 0)
+	_, err := keys.Key(context.Background(), "key-1")
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 5)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = keys.Key(context.Background(), "key-2")
+		}(i)
+	}
+
+	// a known key doesn't wait on the fetch in flight, nor does a caller giving up
+	for atomic.LoadInt32(&fetches) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	_, err = keys.Key(context.Background(), "key-1")
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = keys.Key(ctx, "key-3")
+	assert.Equal(t, context.Canceled, err)
+
+	close(release)
+	wg.Wait()
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetches))
+}
@@ -0,0 +1,203 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// DefaultAudience is the aud claim a token has to carry to call the coverage service
+const DefaultAudience = "coverage"
+
+// AdminScope is the scope a token has to be granted to manage the api keys
+const AdminScope = "coverage:admin"
+
+// clockSkew is how far the exp and nbf claims are allowed to be off of the service's clock
+const clockSkew = 30 * time.Second
+
+var (
+	// ErrMalformedToken is given back for a token that isn't three base64url encoded parts with JSON header and claims
+	ErrMalformedToken = errors.New("malformed token")
+	// ErrUnsupportedAlgorithm is given back for a token not signed with RS256
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+	// ErrInvalidSignature is given back for a token whose signature doesn't verify with its key
+	ErrInvalidSignature = errors.New("invalid token signature")
+	// ErrExpiredToken is given back for a token without an exp claim, or past it
+	ErrExpiredToken = errors.New("token expired")
+	// ErrTokenNotYetValid is given back for a token before its nbf claim
+	ErrTokenNotYetValid = errors.New("token not yet valid")
+	// ErrInvalidAudience is given back for a token whose aud claim doesn't name the service
+	ErrInvalidAudience = errors.New("invalid token audience")
+)
+
+// Identity is the caller a verified token was issued to, with the scopes it was granted
+type Identity struct {
+	Subject   string
+	Issuer    string
+	ExpiresAt time.Time
+	Scopes    []string
+}
+
+// HasScope tells whether the token was granted the scope
+func (i Identity) HasScope(scope string) bool {
+	for _, s := range i.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Verifier verifies bearer tokens and gives back the identity of the caller
+type Verifier interface {
+	Verify(ctx context.Context, token string) (Identity, error)
+}
+
+type verifier struct {
+	keys     KeySet
+	audience string
+	now      func() time.Time
+}
+
+// NewVerifier constructs and gives back a verifier of RS256 tokens signed with a key of the key set and issued for
+// the audience
+func NewVerifier(keys KeySet, audience string) Verifier {
+	return verifier{keys: keys, audience: audience, now: time.Now}
+}
+
+// header is the JOSE header of a token
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// claims are the registered claims a token is checked with. Audience is a string or a list of them. The scopes are
+// granted in a space separated scope claim, or in an scp claim holding a list or a space separated string.
+type claims struct {
+	Subject   string          `json:"sub"`
+	Issuer    string          `json:"iss"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt *int64          `json:"exp"`
+	NotBefore *int64          `json:"nbf"`
+	Scope     string          `json:"scope"`
+	Scp       json.RawMessage `json:"scp"`
+}
+
+// Verify checks the token's RS256 signature, its exp and nbf claims and that its aud claim names the audience
+func (v verifier) Verify(ctx context.Context, token string) (Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Identity{}, ErrMalformedToken
+	}
+	var h header
+	if err := decodePart(parts[0], &h); err != nil {
+		return Identity{}, ErrMalformedToken
+	}
+	// the algorithm is pinned, a token can't pick none or a symmetric one
+	if h.Alg != "RS256" {
+		return Identity{}, ErrUnsupportedAlgorithm
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Identity{}, ErrMalformedToken
+	}
+
+	key, err := v.keys.Key(ctx, h.Kid)
+	if err != nil {
+		return Identity{}, err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return Identity{}, ErrInvalidSignature
+	}
+
+	var c claims
+	if err := decodePart(parts[1], &c); err != nil {
+		return Identity{}, ErrMalformedToken
+	}
+	now := v.now()
+	if c.ExpiresAt == nil || now.After(time.Unix(*c.ExpiresAt, 0).Add(clockSkew)) {
+		return Identity{}, ErrExpiredToken
+	}
+	if c.NotBefore != nil && now.Add(clockSkew).Before(time.Unix(*c.NotBefore, 0)) {
+		return Identity{}, ErrTokenNotYetValid
+	}
+	if !hasAudience(c.Audience, v.audience) {
+		return Identity{}, ErrInvalidAudience
+	}
+	return Identity{Subject: c.Subject, Issuer: c.Issuer, ExpiresAt: time.Unix(*c.ExpiresAt, 0).UTC(), Scopes: scopes(c)}, nil
+}
+
+// scopes gives back the scopes granted by the scope or scp claim, nil when there are none
+func scopes(c claims) []string {
+	if c.Scope != "" {
+		return strings.Fields(c.Scope)
+	}
+	var single string
+	if err := json.Unmarshal(c.Scp, &single); err == nil {
+		return strings.Fields(single)
+	}
+	var list []string
+	if err := json.Unmarshal(c.Scp, &list); err == nil && len(list) > 0 {
+		return list
+	}
+	return nil
+}
+
+// decodePart decodes a base64url encoded JSON part of a token
+func decodePart(part string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// hasAudience tells whether an aud claim, a string or a list of them, names the audience
+func hasAudience(raw json.RawMessage, audience string) bool {
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return single == audience
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		for _, aud := range list {
+			if aud == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type identityKey struct{}
+
+// WithIdentity gives back a copy of the context carrying the caller's identity
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFrom gives back the caller's identity carried by the context. ok is false for an unauthenticated request.
+func IdentityFrom(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+type clientIDKey struct{}
+
+// WithClientID gives back a copy of the context carrying the id of the api client the request's key belongs to
+func WithClientID(ctx context.Context, clientID string) context.Context {
+	return context.WithValue(ctx, clientIDKey{}, clientID)
+}
+
+// ClientIDFrom gives back the id of the api client carried by the context. ok is false for a request without a key.
+func ClientIDFrom(ctx context.Context) (string, bool) {
+	clientID, ok := ctx.Value(clientIDKey{}).(string)
+	return clientID, ok
+}
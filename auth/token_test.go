@@ -0,0 +1,242 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testKey is an RSA key generated for the tests along with its JWKS document
+type testKey struct {
+	kid  string
+	key  *rsa.PrivateKey
+	jwks []byte
+}
+
+func newTestKey(t *testing.T, kid string) testKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	jwks, _ := json.Marshal(map[string]interface{}{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"alg": "RS256",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	return testKey{kid: kid, key: key, jwks: jwks}
+}
+
+// sign gives back a token of the header and claims signed with the key
+func (k testKey) sign(t *testing.T, header map[string]interface{}, claims map[string]interface{}) string {
+	rawHeader, _ := json.Marshal(header)
+	rawClaims, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(rawHeader) + "." + base64.RawURLEncoding.EncodeToString(rawClaims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, k.key, crypto.SHA256, digest[:])
+	assert.NoError(t, err)
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestVerify(t *testing.T) {
+	key := newTestKey(t, "key-1")
+	other := newTestKey(t, "key-1")
+	keys, err := ParseKeySet(key.jwks)
+	assert.NoError(t, err)
+	now := time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC)
+	v := verifier{keys: keys, audience: "coverage", now: func() time.Time { return now }}
+
+	header := map[string]interface{}{"alg": "RS256", "kid": "key-1", "typ": "JWT"}
+	valid := map[string]interface{}{"sub": "postman", "iss": "credo", "aud": "coverage", "exp": now.Add(time.Hour).Unix()}
+
+	identity, err := v.Verify(context.Background(), key.sign(t, header, valid))
+	assert.NoError(t, err)
+	assert.Equal(t, Identity{Subject: "postman", Issuer: "credo", ExpiresAt: now.Add(time.Hour)}, identity)
+
+	testCases := []struct {
+		desc   string
+		token  string
+		expect error
+	}{
+		{
+			desc:   "Audience list",
+			token:  key.sign(t, header, map[string]interface{}{"aud": []string{"postman", "coverage"}, "exp": now.Add(time.Hour).Unix()}),
+			expect: nil,
+		},
+		{
+			desc:   "Other audience",
+			token:  key.sign(t, header, map[string]interface{}{"aud": "postman", "exp": now.Add(time.Hour).Unix()}),
+			expect: ErrInvalidAudience,
+		},
+		{
+			desc:   "Missing audience",
+			token:  key.sign(t, header, map[string]interface{}{"exp": now.Add(time.Hour).Unix()}),
+			expect: ErrInvalidAudience,
+		},
+		{
+			desc:   "Expired",
+			token:  key.sign(t, header, map[string]interface{}{"aud": "coverage", "exp": now.Add(-time.Minute).Unix()}),
+			expect: ErrExpiredToken,
+		},
+		{
+			desc:   "Expired within the clock skew",
+			token:  key.sign(t, header, map[string]interface{}{"aud": "coverage", "exp": now.Add(-10 * time.Second).Unix()}),
+			expect: nil,
+		},
+		{
+			desc:   "Without expiry",
+			token:  key.sign(t, header, map[string]interface{}{"aud": "coverage"}),
+			expect: ErrExpiredToken,
+		},
+		{
+			desc:   "Not yet valid",
+			token:  key.sign(t, header, map[string]interface{}{"aud": "coverage", "exp": now.Add(time.Hour).Unix(), "nbf": now.Add(time.Minute).Unix()}),
+			expect: ErrTokenNotYetValid,
+		},
+		{
+			desc:   "Signed with another key",
+			token:  other.sign(t, header, valid),
+			expect: ErrInvalidSignature,
+		},
+		{
+			desc:   "Unknown key id",
+			token:  key.sign(t, map[string]interface{}{"alg": "RS256", "kid": "key-2"}, valid),
+			expect: ErrUnknownKey,
+		},
+		{
+			desc:   "Symmetric algorithm",
+			token:  key.sign(t, map[string]interface{}{"alg": "HS256", "kid": "key-1"}, valid),
+			expect: ErrUnsupportedAlgorithm,
+		},
+		{
+			desc:   "Unsigned",
+			token:  key.sign(t, map[string]interface{}{"alg": "none"}, valid),
+			expect: ErrUnsupportedAlgorithm,
+		},
+		{
+			desc:   "Not a token",
+			token:  "not.a-token",
+			expect: ErrMalformedToken,
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			_, err := v.Verify(context.Background(), tC.token)
+			assert.Equal(t, tC.expect, err)
+		})
+	}
+}
+
+func TestVerifyScopes(t *testing.T) {
+	key := newTestKey(t, "key-1")
+	keys, err := ParseKeySet(key.jwks)
+	assert.NoError(t, err)
+	now := time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC)
+	v := verifier{keys: keys, audience: "coverage", now: func() time.Time { return now }}
+	header := map[string]interface{}{"alg": "RS256", "kid": "key-1"}
+
+	testCases := []struct {
+		desc   string
+		claims map[string]interface{}
+		expect []string
+	}{
+		{
+			desc:   "Scope claim",
+			claims: map[string]interface{}{"scope": "coverage:read coverage:admin"},
+			expect: []string{"coverage:read", "coverage:admin"},
+		},
+		{
+			desc:   "Scp list",
+			claims: map[string]interface{}{"scp": []string{"coverage:admin"}},
+			expect: []string{"coverage:admin"},
+		},
+		{
+			desc:   "Scp string",
+			claims: map[string]interface{}{"scp": "coverage:read"},
+			expect: []string{"coverage:read"},
+		},
+		{
+			desc:   "No scopes",
+			claims: map[string]interface{}{},
+			expect: nil,
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			tC.claims["aud"] = "coverage"
+			tC.claims["exp"] = now.Add(time.Hour).Unix()
+			identity, err := v.Verify(context.Background(), key.sign(t, header, tC.claims))
+			assert.NoError(t, err)
+			assert.Equal(t, tC.expect, identity.Scopes)
+		})
+	}
+
+	assert.True(t, Identity{Scopes: []string{"coverage:admin"}}.HasScope("coverage:admin"))
+	assert.False(t, Identity{Scopes: []string{"coverage:read"}}.HasScope("coverage:admin"))
+}
+
+func TestLoadKeySetFile(t *testing.T) {
+	key := newTestKey(t, "key-1")
+	dir, err := ioutil.TempDir("", "jwks")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "jwks.json")
+	assert.NoError(t, ioutil.WriteFile(path, key.jwks, 0600))
+
+	keys, err := LoadKeySetFile(path)
+	assert.NoError(t, err)
+	publicKey, err := keys.Key(context.Background(), "key-1")
+	assert.NoError(t, err)
+	assert.Equal(t, key.key.PublicKey, *publicKey)
+
+	// a single key is used for tokens without a kid
+	publicKey, err = keys.Key(context.Background(), "")
+	assert.NoError(t, err)
+	assert.Equal(t, key.key.PublicKey, *publicKey)
+
+	_, err = LoadKeySetFile(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestParseKeySet(t *testing.T) {
+	_, err := ParseKeySet([]byte(`{"keys":[{"kty":"EC","kid":"ec-1"}]}`))
+	assert.EqualError(t, err, "jwks has no RSA signing keys")
+
+	_, err = ParseKeySet([]byte(`{"keys":[{"kty":"RSA","kid":"bad","n":"","e":"AQAB"}]}`))
+	assert.Error(t, err)
+
+	_, err = ParseKeySet([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestIdentityContext(t *testing.T) {
+	_, ok := IdentityFrom(context.Background())
+	assert.False(t, ok)
+
+	identity, ok := IdentityFrom(WithIdentity(context.Background(), Identity{Subject: "postman"}))
+	assert.True(t, ok)
+	assert.Equal(t, "postman", identity.Subject)
+}
+
+func TestClientIDContext(t *testing.T) {
+	_, ok := ClientIDFrom(context.Background())
+	assert.False(t, ok)
+
+	clientID, ok := ClientIDFrom(WithClientID(context.Background(), "partner"))
+	assert.True(t, ok)
+	assert.Equal(t, "partner", clientID)
+}
@@ -0,0 +1,147 @@
+// Command datasets lists, promotes, rolls back, collects and compares the dataset versions of a carrier's coverage
+// data.
+//
+//	datasets -carrier 2 -arn arn:aws:dynamodb:us-east-2:123456789012:table/coverage list
+//	datasets -carrier 2 promote 2018-11-01
+//	datasets -carrier 2 rollback
+//	datasets -carrier 2 -keep 2 collect
+//	datasets -carrier 2 -format csv diff 2018-11-01 2018-12-01
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
+	"bitbucket.org/credomobile/coverage/services"
+	"github.com/rs/zerolog"
+)
+
+func main() {
+	var carrierIDs []string
+	for _, registered := range dbclient.RegisteredCarriers() {
+		carrierIDs = append(carrierIDs, fmt.Sprintf("%s (%s)", registered.ID, registered.Name))
+	}
+	carrier := flag.String("carrier", "", "carrier id of the dataset: "+strings.Join(carrierIDs, ", "))
+	dynamodbARN := flag.String("arn", os.Getenv("DYNAMODB_ARN"), "arn of the coverage table. Defaults to DYNAMODB_ARN")
+	region := flag.String("region", "", "aws region of the coverage table. Defaults to the region of the arn")
+	endpoint := flag.String("endpoint", "", "dynamodb endpoint override, e.g. http://localhost:8000")
+	keep := flag.Int("keep", 1, "number of versions besides the live and previous ones collect keeps")
+	swing := flag.Float64("swing", dbclient.DefaultSwingThreshold, "percentage points a technology has to move by for diff to report it")
+	format := flag.String("format", "json", "format of the diff report, json or csv")
+	rawRules := flag.String("rules", os.Getenv("COVERAGE_RULES"), "coverage rules json diff evaluates the versions with. Defaults to COVERAGE_RULES")
+	rulesFile := flag.String("rules-file", os.Getenv("COVERAGE_RULES_FILE"), "file of the coverage rules json, used in place of -rules. Defaults to COVERAGE_RULES_FILE")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: datasets [flags] list | promote <version> | rollback | collect | diff [<from>] <to>\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	logger := zerolog.New(os.Stderr).With().Timestamp().Logger()
+	ctx := logger.WithContext(context.Background())
+
+	command := flag.Arg(0)
+	if *carrier == "" || command == "" || ((command == "promote" || command == "diff") && flag.NArg() < 2) {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	connection, err := dbclient.NewConnection(dbclient.ConnectionConfig{
+		DynamoDBARN:    *dynamodbARN,
+		Region:         *region,
+		Endpoint:       *endpoint,
+		RequestTimeout: 30 * time.Second,
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msg("unable to create connection to dynamodb")
+	}
+
+	datasets := dbclient.NewDatasets(connection.TableName, connection.DynamoDB)
+	carrierID := entity.CarrierType(*carrier)
+	switch command {
+	case "list":
+		versions, err := datasets.Versions(ctx, carrierID)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("unable to list dataset versions")
+		}
+		printVersions(os.Stdout, versions)
+	case "promote":
+		if err := datasets.Promote(ctx, carrierID, flag.Arg(1)); err != nil {
+			logger.Fatal().Err(err).Msg("promotion failed")
+		}
+		fmt.Fprintf(os.Stdout, "promoted: %s\n", flag.Arg(1))
+	case "rollback":
+		version, err := datasets.Rollback(ctx, carrierID)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("rollback failed")
+		}
+		fmt.Fprintf(os.Stdout, "rolled back to: %s\n", version)
+	case "collect":
+		collected, err := datasets.Collect(ctx, carrierID, *keep)
+		for _, version := range collected {
+			fmt.Fprintf(os.Stdout, "collected: %s\n", version)
+		}
+		if err != nil {
+			logger.Fatal().Err(err).Msg("collection failed")
+		}
+	case "diff":
+		// with a single version, the live one is compared with it
+		from, to := "", flag.Arg(1)
+		if flag.NArg() > 2 {
+			from, to = flag.Arg(1), flag.Arg(2)
+		}
+		ruleSet, err := rules.Load(*rawRules, *rulesFile)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("unable to load coverage rules")
+		}
+		coverageDiffService, _ := services.NewCoverageDiff(dbclient.NewCoverageDiffer(datasets, ruleSet), &logger)
+		report, found, err := coverageDiffService.Diff(ctx, *carrier, from, to, *swing)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("diff failed")
+		}
+		if !found {
+			logger.Fatal().Err(dbclient.ErrUnknownVersion).Msg("diff failed")
+		}
+		if err := printDiff(os.Stdout, report, *format); err != nil {
+			logger.Fatal().Err(err).Msg("unable to write diff report")
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// printDiff writes a diff report as indented JSON or as CSV
+func printDiff(w io.Writer, report entity.CoverageDiffResponse, format string) error {
+	switch format {
+	case "csv":
+		return services.WriteCoverageDiffCSV(w, report)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	default:
+		return fmt.Errorf("unknown format %q, expected json or csv", format)
+	}
+}
+
+// printVersions writes a line per dataset version, marking the live and the previous one
+func printVersions(w io.Writer, versions []dbclient.DatasetVersion) {
+	for _, version := range versions {
+		marker := ""
+		if version.Live {
+			marker = " (live)"
+		} else if version.Previous {
+			marker = " (previous)"
+		}
+		fmt.Fprintf(w, "%s: %d rows%s\n", version.Version, version.Rows, marker)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintVersions(t *testing.T) {
+	var out bytes.Buffer
+	printVersions(&out, []dbclient.DatasetVersion{
+		{Version: "2018-12-01", Rows: 3},
+		{Version: "2018-11-01", Rows: 2, Live: true},
+		{Version: "2018-10-01", Rows: 1, Previous: true},
+	})
+
+	assert.Equal(t, "2018-12-01: 3 rows\n"+
+		"2018-11-01: 2 rows (live)\n"+
+		"2018-10-01: 1 rows (previous)\n", out.String())
+}
+
+func TestPrintDiff(t *testing.T) {
+	report := entity.CoverageDiffResponse{
+		CarrierID: "2",
+		Lost:      []entity.CoverageChange{{ZipCode: "94105", FromScore: 100, ToScore: 30}},
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, printDiff(&out, report, "csv"))
+	assert.Equal(t, "zipcode,change,technology,from,to\n94105,lost,,100,30\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, printDiff(&out, report, "json"))
+	assert.Contains(t, out.String(), "\"ZipCode\": \"94105\"")
+
+	assert.Error(t, printDiff(&out, report, "xml"))
+}
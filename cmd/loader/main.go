@@ -0,0 +1,155 @@
+// Command loader loads a carrier's raw coverage export into the coverage table as the dataset version of its load
+// date and makes it live, unless -promote=false leaves that to the datasets command. A load that rejected rows isn't
+// made live unless -allow-rejected is set, the rejected zipcodes would be missing from the live data.
+//
+//	loader -carrier 1 -file sprint.csv -arn arn:aws:dynamodb:us-east-2:123456789012:table/coverage
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
+	"github.com/rs/zerolog"
+)
+
+func main() {
+	var carrierIDs []string
+	for _, registered := range dbclient.RegisteredCarriers() {
+		carrierIDs = append(carrierIDs, fmt.Sprintf("%s (%s)", registered.ID, registered.Name))
+	}
+	carrier := flag.String("carrier", "", "carrier id of the export: "+strings.Join(carrierIDs, ", "))
+	file := flag.String("file", "", "path of the carrier's csv or json export")
+	format := flag.String("format", "", "format of the export, csv or json. Defaults to the file extension")
+	centroidsFile := flag.String("centroids", "", "csv, tsv or json file of zipcode centroids, such as the census ZCTA gazetteer, locating the zipcodes of an export without centroids")
+	dynamodbARN := flag.String("arn", os.Getenv("DYNAMODB_ARN"), "arn of the coverage table. Defaults to DYNAMODB_ARN")
+	region := flag.String("region", "", "aws region of the coverage table. Defaults to the region of the arn")
+	endpoint := flag.String("endpoint", "", "dynamodb endpoint override, e.g. http://localhost:8000")
+	loadDate := flag.String("load-date", time.Now().Format("2006-01-02"), "load date stamped on every item")
+	rawRules := flag.String("rules", os.Getenv("COVERAGE_RULES"), "coverage rules json the state and county summaries are computed with. Defaults to COVERAGE_RULES")
+	rulesFile := flag.String("rules-file", os.Getenv("COVERAGE_RULES_FILE"), "file of the coverage rules json, used in place of -rules. Defaults to COVERAGE_RULES_FILE")
+	promote := flag.Bool("promote", true, "make the loaded dataset version live once it is written")
+	allowRejected := flag.Bool("allow-rejected", false, "make the loaded dataset version live even though rows were rejected")
+	verbose := flag.Bool("v", false, "list every rejected row")
+	flag.Parse()
+
+	logger := zerolog.New(os.Stderr).With().Timestamp().Logger()
+	ctx := logger.WithContext(context.Background())
+
+	if *carrier == "" || *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+	}
+	f, err := os.Open(*file)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("unable to open carrier export")
+	}
+	defer f.Close()
+
+	rows, err := dbclient.ReadRows(f, *format)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("unable to read carrier export")
+	}
+
+	var centroids dbclient.ZipCodeCentroids
+	if *centroidsFile != "" {
+		centroids, err = readCentroids(*centroidsFile)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("unable to read zipcode centroids")
+		}
+	}
+
+	ruleSet, err := rules.Load(*rawRules, *rulesFile)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("unable to load coverage rules")
+	}
+
+	connection, err := dbclient.NewConnection(dbclient.ConnectionConfig{
+		DynamoDBARN:    *dynamodbARN,
+		Region:         *region,
+		Endpoint:       *endpoint,
+		RequestTimeout: 30 * time.Second,
+	})
+	if err != nil {
+		logger.Fatal().Err(err).Msg("unable to create connection to dynamodb")
+	}
+
+	loader := dbclient.NewCoverageLoader(connection.TableName, connection.DynamoDB, ruleSet, centroids)
+	summary, err := loader.Load(ctx, entity.CarrierType(*carrier), *loadDate, rows)
+	printSummary(os.Stdout, summary, *verbose)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("load failed")
+	}
+
+	if *promote {
+		if err := checkPromotion(summary, *allowRejected); err != nil {
+			logger.Fatal().Err(err).Msg("promotion refused, promote the version with the datasets command once it is checked")
+		}
+		err = dbclient.NewDatasets(connection.TableName, connection.DynamoDB).Promote(ctx, entity.CarrierType(*carrier), *loadDate)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("promotion failed")
+		}
+		fmt.Fprintf(os.Stdout, "promoted: %s\n", *loadDate)
+	}
+}
+
+// checkPromotion refuses to make a load that rejected rows live unless allowed, the rejected zipcodes would read as
+// unknown
+func checkPromotion(summary dbclient.LoadSummary, allowRejected bool) error {
+	if summary.Rejected > 0 && !allowRejected {
+		return fmt.Errorf("%d rows were rejected, run with -allow-rejected to promote anyway", summary.Rejected)
+	}
+	return nil
+}
+
+// readCentroids reads the zipcode centroids of a file in the format of its extension, tsv for the gazetteer's txt
+func readCentroids(path string) (dbclient.ZipCodeCentroids, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	if format == "txt" {
+		format = "tsv"
+	}
+	return dbclient.ReadCentroids(f, format)
+}
+
+// printSummary writes the counts of a load, the rejection reasons, the number of summaries and the ignored columns
+func printSummary(w io.Writer, summary dbclient.LoadSummary, verbose bool) {
+	fmt.Fprintf(w, "read: %d\nwritten: %d\nrejected: %d\n", summary.Read, summary.Written, summary.Rejected)
+
+	reasons := make([]string, 0, len(summary.Reasons))
+	for reason := range summary.Reasons {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		fmt.Fprintf(w, "  %s: %d\n", reason, summary.Reasons[reason])
+	}
+	fmt.Fprintf(w, "summaries: %d\n", summary.Summaries)
+
+	if len(summary.IgnoredColumns) > 0 {
+		fmt.Fprintf(w, "ignored columns: %s\n", strings.Join(summary.IgnoredColumns, ", "))
+	}
+
+	if verbose {
+		for _, rejection := range summary.Rejections {
+			fmt.Fprintf(w, "row %d (%s): %s\n", rejection.Row, rejection.ZipCode, rejection.Reason)
+		}
+	}
+}
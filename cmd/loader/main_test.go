@@ -0,0 +1,35 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckPromotion(t *testing.T) {
+	assert.NoError(t, checkPromotion(dbclient.LoadSummary{Read: 2, Written: 2}, false))
+	assert.EqualError(t, checkPromotion(dbclient.LoadSummary{Read: 3, Written: 1, Rejected: 2}, false),
+		"2 rows were rejected, run with -allow-rejected to promote anyway")
+	assert.NoError(t, checkPromotion(dbclient.LoadSummary{Read: 3, Written: 1, Rejected: 2}, true))
+}
+
+func TestPrintSummary(t *testing.T) {
+	var out bytes.Buffer
+	printSummary(&out, dbclient.LoadSummary{
+		Read:           3,
+		Written:        1,
+		Rejected:       2,
+		Reasons:        map[string]int{"missing zipcode": 1, "duplicate zipcode": 1},
+		Rejections:     []dbclient.Rejection{{Row: 2, ZipCode: "94105", Reason: "duplicate zipcode"}},
+		IgnoredColumns: []string{"Extra"},
+		Summaries:      2,
+	}, true)
+
+	assert.Equal(t, "read: 3\nwritten: 1\nrejected: 2\n"+
+		"  duplicate zipcode: 1\n  missing zipcode: 1\n"+
+		"summaries: 2\n"+
+		"ignored columns: Extra\n"+
+		"row 2 (94105): duplicate zipcode\n", out.String())
+}
@@ -0,0 +1,346 @@
+package dbclient
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/rs/zerolog"
+)
+
+// The items of the api keys table are keyed by an id prefixed with their kind: a client#<client id> item per client,
+// a key#<sha256 of the key> item per key and a usage#<client id>#<day> counter per client and day
+const (
+	clientIDPrefix = "client#"
+	keyIDPrefix    = "key#"
+	usageIDPrefix  = "usage#"
+)
+
+var (
+	// ErrClientExists is given back for issuing a key to a client id that is already taken
+	ErrClientExists = errors.New("api client already exists")
+	// ErrClientChanged is given back for replacing a client that was written again since it was read
+	ErrClientChanged = errors.New("api client changed since it was read")
+)
+
+// APIClient is a caller of the coverage API along with its limits. RateLimit is in requests per second and
+// DailyQuota in requests per UTC day, 0 leaves either unlimited. Version counts the writes of the client, it is 0 for
+// a client not written yet or written before clients were versioned.
+type APIClient struct {
+	ID         string
+	Name       string
+	RateLimit  float64
+	DailyQuota int
+	Keys       []APIKey
+	Version    int
+}
+
+// APIKey is a key of a client. Only the sha256 of the key is stored, along with its first characters so it can be
+// told apart. A key with an ExpiresAt, such as the one replaced by a rotation, stops working then.
+type APIKey struct {
+	Hash      string
+	Prefix    string
+	ExpiresAt time.Time
+}
+
+// Expired tells whether the key stopped working at the given time
+func (k APIKey) Expired(at time.Time) bool {
+	return !k.ExpiresAt.IsZero() && !at.Before(k.ExpiresAt)
+}
+
+// APIKeyStore keeps the api clients, the hashes of their keys and their usage counters. PutClient creates the
+// client when create is set, failing with ErrClientExists when it already exists, and replaces it otherwise, failing
+// with ErrClientChanged when the stored client's Version isn't the one it was read with. Either way the written client
+// is one Version on.
+// AddUsage adds to a client's counter of a day and gives back the new count.
+type APIKeyStore interface {
+	GetClient(ctx context.Context, clientID string) (APIClient, bool, error)
+	GetClientByKey(ctx context.Context, keyHash string) (APIClient, bool, error)
+	PutClient(ctx context.Context, client APIClient, create bool) error
+	DeleteKeys(ctx context.Context, keyHashes []string) error
+	AddUsage(ctx context.Context, clientID string, day string, requests int) (int, error)
+	GetUsage(ctx context.Context, clientID string, days []string) (map[string]int, error)
+}
+
+// clientItem gives back the item of a client. Its keys are kept as hash:prefix:expiry triples joined by commas.
+func clientItem(client APIClient) Item {
+	keys := make([]string, 0, len(client.Keys))
+	for _, key := range client.Keys {
+		expiresAt := ""
+		if !key.ExpiresAt.IsZero() {
+			expiresAt = strconv.FormatInt(key.ExpiresAt.Unix(), 10)
+		}
+		keys = append(keys, key.Hash+":"+key.Prefix+":"+expiresAt)
+	}
+	return Item{
+		"id":          clientIDPrefix + client.ID,
+		"client_id":   client.ID,
+		"name":        client.Name,
+		"rate_limit":  strconv.FormatFloat(client.RateLimit, 'f', -1, 64),
+		"daily_quota": strconv.Itoa(client.DailyQuota),
+		"keys":        strings.Join(keys, ","),
+		"version":     strconv.Itoa(client.Version),
+	}
+}
+
+// clientOf reads a client back from its item
+func clientOf(item Item) APIClient {
+	client := APIClient{ID: item["client_id"], Name: item["name"]}
+	client.RateLimit, _ = strconv.ParseFloat(item["rate_limit"], 64)
+	client.DailyQuota, _ = strconv.Atoi(item["daily_quota"])
+	client.Version, _ = strconv.Atoi(item["version"])
+	for _, raw := range strings.Split(item["keys"], ",") {
+		parts := strings.Split(raw, ":")
+		if len(parts) != 3 || parts[0] == "" {
+			continue
+		}
+		key := APIKey{Hash: parts[0], Prefix: parts[1]}
+		if seconds, err := strconv.ParseInt(parts[2], 10, 64); err == nil {
+			key.ExpiresAt = time.Unix(seconds, 0).UTC()
+		}
+		client.Keys = append(client.Keys, key)
+	}
+	return client
+}
+
+// keyItems gives back the lookup item of every key of a client
+func keyItems(client APIClient) []Item {
+	items := make([]Item, 0, len(client.Keys))
+	for _, key := range client.Keys {
+		items = append(items, Item{"id": keyIDPrefix + key.Hash, "client_id": client.ID})
+	}
+	return items
+}
+
+func usageID(clientID string, day string) string {
+	return usageIDPrefix + clientID + "#" + day
+}
+
+type dynamoAPIKeyStore struct {
+	tableName  *string
+	connection dynamodbiface.DynamoDBAPI
+}
+
+// NewAPIKeyStore constructs and gives back the api key store of the given DynamoDB table, whose hash key is id
+func NewAPIKeyStore(tableName *string, connection dynamodbiface.DynamoDBAPI) APIKeyStore {
+	return dynamoAPIKeyStore{tableName: tableName, connection: connection}
+}
+
+func (d dynamoAPIKeyStore) getItem(ctx context.Context, id string) (Item, error) {
+	result, err := d.connection.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: d.tableName,
+		Key:       map[string]*dynamodb.AttributeValue{"id": {S: aws.String(id)}},
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("failed to read from api keys dynamodb table")
+		return nil, err
+	}
+	if len(result.Item) == 0 {
+		return nil, nil
+	}
+	return itemOf(result.Item), nil
+}
+
+func (d dynamoAPIKeyStore) GetClient(ctx context.Context, clientID string) (APIClient, bool, error) {
+	item, err := d.getItem(ctx, clientIDPrefix+clientID)
+	if err != nil || item == nil {
+		return APIClient{}, false, err
+	}
+	return clientOf(item), true, nil
+}
+
+func (d dynamoAPIKeyStore) GetClientByKey(ctx context.Context, keyHash string) (APIClient, bool, error) {
+	item, err := d.getItem(ctx, keyIDPrefix+keyHash)
+	if err != nil || item == nil {
+		return APIClient{}, false, err
+	}
+	return d.GetClient(ctx, item["client_id"])
+}
+
+// PutClient writes the client item before the key lookup items, so a create that loses to an existing client never
+// leaves a key pointing at it. A lookup left behind by a failed write is ignored, the client item doesn't list it.
+// A replace is conditional on the version the client was read with, so of two concurrent changes one fails rather
+// than undoing the other.
+func (d dynamoAPIKeyStore) PutClient(ctx context.Context, client APIClient, create bool) error {
+	var condition expression.ConditionBuilder
+	switch {
+	case create:
+		condition = expression.AttributeNotExists(expression.Name("id"))
+	case client.Version == 0:
+		condition = expression.AttributeExists(expression.Name("id")).And(expression.AttributeNotExists(expression.Name("version")))
+	default:
+		condition = expression.Name("version").Equal(expression.Value(strconv.Itoa(client.Version)))
+	}
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return err
+	}
+	client.Version++
+	input := &dynamodb.PutItemInput{
+		TableName:                 d.tableName,
+		Item:                      attributeValues(clientItem(client)),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}
+	_, err = d.connection.PutItemWithContext(ctx, input)
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		if create {
+			return ErrClientExists
+		}
+		return ErrClientChanged
+	}
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msgf("failed to write api client %s", client.ID)
+		return err
+	}
+
+	for _, item := range keyItems(client) {
+		if _, err := d.connection.PutItemWithContext(ctx, &dynamodb.PutItemInput{TableName: d.tableName, Item: attributeValues(item)}); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msgf("failed to write key of api client %s", client.ID)
+			return err
+		}
+	}
+	return nil
+}
+
+func (d dynamoAPIKeyStore) DeleteKeys(ctx context.Context, keyHashes []string) error {
+	for _, keyHash := range keyHashes {
+		_, err := d.connection.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+			TableName: d.tableName,
+			Key:       map[string]*dynamodb.AttributeValue{"id": {S: aws.String(keyIDPrefix + keyHash)}},
+		})
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("failed to delete api key")
+			return err
+		}
+	}
+	return nil
+}
+
+// AddUsage adds to the counter atomically, so concurrent Lambda instances count every request
+func (d dynamoAPIKeyStore) AddUsage(ctx context.Context, clientID string, day string, requests int) (int, error) {
+	expr, err := expression.NewBuilder().
+		WithUpdate(expression.Add(expression.Name("requests"), expression.Value(requests)).
+			Set(expression.Name("client_id"), expression.Value(clientID)).
+			Set(expression.Name("day"), expression.Value(day))).
+		Build()
+	if err != nil {
+		return 0, err
+	}
+	result, err := d.connection.UpdateItemWithContext(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 d.tableName,
+		Key:                       map[string]*dynamodb.AttributeValue{"id": {S: aws.String(usageID(clientID, day))}},
+		UpdateExpression:          expr.Update(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ReturnValues:              aws.String(dynamodb.ReturnValueUpdatedNew),
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msgf("failed to count usage of api client %s", clientID)
+		return 0, err
+	}
+	count, _ := strconv.Atoi(itemOf(result.Attributes)["requests"])
+	return count, nil
+}
+
+func (d dynamoAPIKeyStore) GetUsage(ctx context.Context, clientID string, days []string) (map[string]int, error) {
+	usage := map[string]int{}
+	for _, day := range days {
+		item, err := d.getItem(ctx, usageID(clientID, day))
+		if err != nil {
+			return nil, err
+		}
+		if item != nil {
+			usage[day], _ = strconv.Atoi(item["requests"])
+		}
+	}
+	return usage, nil
+}
+
+// MemoryAPIKeyStore keeps the api clients and their usage in memory, for local development and tests
+type MemoryAPIKeyStore struct {
+	mu    sync.Mutex
+	items map[string]Item
+	usage map[string]int
+}
+
+// NewMemoryAPIKeyStore constructs and gives back an empty in-memory api key store
+func NewMemoryAPIKeyStore() *MemoryAPIKeyStore {
+	return &MemoryAPIKeyStore{items: map[string]Item{}, usage: map[string]int{}}
+}
+
+func (m *MemoryAPIKeyStore) GetClient(ctx context.Context, clientID string) (APIClient, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item, ok := m.items[clientIDPrefix+clientID]
+	if !ok {
+		return APIClient{}, false, nil
+	}
+	return clientOf(item), true, nil
+}
+
+func (m *MemoryAPIKeyStore) GetClientByKey(ctx context.Context, keyHash string) (APIClient, bool, error) {
+	m.mu.Lock()
+	item, ok := m.items[keyIDPrefix+keyHash]
+	m.mu.Unlock()
+	if !ok {
+		return APIClient{}, false, nil
+	}
+	return m.GetClient(ctx, item["client_id"])
+}
+
+func (m *MemoryAPIKeyStore) PutClient(ctx context.Context, client APIClient, create bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	current, ok := m.items[clientIDPrefix+client.ID]
+	if ok && create {
+		return ErrClientExists
+	}
+	if !create && (!ok || clientOf(current).Version != client.Version) {
+		return ErrClientChanged
+	}
+	client.Version++
+	item := clientItem(client)
+	for _, keyItem := range keyItems(client) {
+		m.items[keyItem["id"]] = keyItem
+	}
+	m.items[item["id"]] = item
+	return nil
+}
+
+func (m *MemoryAPIKeyStore) DeleteKeys(ctx context.Context, keyHashes []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, keyHash := range keyHashes {
+		delete(m.items, keyIDPrefix+keyHash)
+	}
+	return nil
+}
+
+func (m *MemoryAPIKeyStore) AddUsage(ctx context.Context, clientID string, day string, requests int) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usage[usageID(clientID, day)] += requests
+	return m.usage[usageID(clientID, day)], nil
+}
+
+func (m *MemoryAPIKeyStore) GetUsage(ctx context.Context, clientID string, days []string) (map[string]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	usage := map[string]int{}
+	for _, day := range days {
+		if requests, ok := m.usage[usageID(clientID, day)]; ok {
+			usage[day] = requests
+		}
+	}
+	return usage, nil
+}
@@ -0,0 +1,167 @@
+package dbclient
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+)
+
+func testAPIKeyStore(t *testing.T, store APIKeyStore) {
+	ctx := context.Background()
+	expiresAt := time.Date(2018, 11, 2, 0, 0, 0, 0, time.UTC)
+	client := APIClient{
+		ID:         "partner",
+		Name:       "Partner",
+		RateLimit:  2.5,
+		DailyQuota: 1000,
+		Keys:       []APIKey{{Hash: "aaa", Prefix: "ck_aaaaa", ExpiresAt: expiresAt}, {Hash: "bbb", Prefix: "ck_bbbbb"}},
+	}
+
+	_, found, err := store.GetClient(ctx, "partner")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	assert.NoError(t, store.PutClient(ctx, client, true))
+	client.Version = 1
+	assert.Equal(t, ErrClientExists, store.PutClient(ctx, APIClient{ID: "partner", Keys: []APIKey{{Hash: "ccc"}}}, true))
+	// the losing create doesn't leave its key pointing at the client
+	_, found, err = store.GetClientByKey(ctx, "ccc")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	stored, found, err := store.GetClient(ctx, "partner")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, client, stored)
+
+	byKey, found, err := store.GetClientByKey(ctx, "bbb")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, client, byKey)
+
+	client.Keys = client.Keys[1:]
+	assert.NoError(t, store.PutClient(ctx, client, false))
+	// a replace of the client as it was before that write is refused, it would undo it
+	assert.Equal(t, ErrClientChanged, store.PutClient(ctx, APIClient{ID: "partner", Version: 1, Keys: []APIKey{{Hash: "ccc"}}}, false))
+	stored, _, err = store.GetClient(ctx, "partner")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stored.Version)
+	assert.Equal(t, client.Keys, stored.Keys)
+	assert.Equal(t, ErrClientChanged, store.PutClient(ctx, APIClient{ID: "unknown", Keys: []APIKey{{Hash: "ddd"}}}, false))
+	assert.NoError(t, store.DeleteKeys(ctx, []string{"aaa"}))
+	_, found, err = store.GetClientByKey(ctx, "aaa")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	count, err := store.AddUsage(ctx, "partner", "2018-11-01", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	count, err = store.AddUsage(ctx, "partner", "2018-11-01", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	usage, err := store.GetUsage(ctx, "partner", []string{"2018-11-01", "2018-10-31"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"2018-11-01": 3}, usage)
+}
+
+func TestMemoryAPIKeyStore(t *testing.T) {
+	testAPIKeyStore(t, NewMemoryAPIKeyStore())
+}
+
+func TestDynamoAPIKeyStore(t *testing.T) {
+	testAPIKeyStore(t, NewAPIKeyStore(aws.String("coverage-apikeys"), &fakeAPIKeysDynamoDB{items: map[string]Item{}}))
+}
+
+func TestDynamoAPIKeyStoreUnversionedClient(t *testing.T) {
+	ctx := context.Background()
+	store := NewAPIKeyStore(aws.String("coverage-apikeys"), &fakeAPIKeysDynamoDB{items: map[string]Item{
+		"client#partner": {"id": "client#partner", "client_id": "partner", "keys": "aaa:ck_aaaaa:"},
+	}})
+
+	client, found, err := store.GetClient(ctx, "partner")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 0, client.Version)
+
+	client.Keys = nil
+	assert.NoError(t, store.PutClient(ctx, client, false))
+	assert.Equal(t, ErrClientChanged, store.PutClient(ctx, client, false))
+	client.Version = 1
+	assert.NoError(t, store.PutClient(ctx, client, false))
+}
+
+func TestAPIKeyExpired(t *testing.T) {
+	now := time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC)
+	assert.False(t, APIKey{}.Expired(now))
+	assert.False(t, APIKey{ExpiresAt: now.Add(time.Second)}.Expired(now))
+	assert.True(t, APIKey{ExpiresAt: now}.Expired(now))
+}
+
+// fakeAPIKeysDynamoDB keeps the items of an api keys table by id
+type fakeAPIKeysDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+	items map[string]Item
+}
+
+func (f *fakeAPIKeysDynamoDB) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	item, ok := f.items[aws.StringValue(input.Key["id"].S)]
+	if !ok {
+		return &dynamodb.GetItemOutput{}, nil
+	}
+	return &dynamodb.GetItemOutput{Item: attributeValues(item)}, nil
+}
+
+// PutItemWithContext only knows the conditions of PutClient: no item of the id for a create, and an item of the
+// version given, or of no version when none is, for a replace
+func (f *fakeAPIKeysDynamoDB) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error) {
+	item := itemOf(input.Item)
+	if input.ConditionExpression != nil {
+		current, found := f.items[item["id"]]
+		versioned := false
+		for _, name := range input.ExpressionAttributeNames {
+			versioned = versioned || aws.StringValue(name) == "version"
+		}
+		version := ""
+		for _, value := range input.ExpressionAttributeValues {
+			version = aws.StringValue(value.S)
+		}
+		if (!versioned && found) || (versioned && (!found || current["version"] != version)) {
+			return nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "The conditional request failed", nil)
+		}
+	}
+	f.items[item["id"]] = item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeAPIKeysDynamoDB) DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, opts ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	delete(f.items, aws.StringValue(input.Key["id"].S))
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+// UpdateItemWithContext only knows the ADD of the usage counters
+func (f *fakeAPIKeysDynamoDB) UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, opts ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	id := aws.StringValue(input.Key["id"].S)
+	item, ok := f.items[id]
+	if !ok {
+		item = Item{"id": id}
+		f.items[id] = item
+	}
+	requests, _ := strconv.Atoi(item["requests"])
+	for _, value := range input.ExpressionAttributeValues {
+		if value.N != nil {
+			added, _ := strconv.Atoi(*value.N)
+			requests += added
+		}
+	}
+	item["requests"] = strconv.Itoa(requests)
+	return &dynamodb.UpdateItemOutput{Attributes: map[string]*dynamodb.AttributeValue{"requests": {N: aws.String(item["requests"])}}}, nil
+}
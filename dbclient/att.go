@@ -0,0 +1,125 @@
+package dbclient
+
+import (
+	"context"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
+	"github.com/rs/zerolog"
+)
+
+type attDbClient struct {
+	logger *zerolog.Logger
+	store  Store
+	rule   rules.Rule
+}
+
+type attCoverageData struct {
+	ZipCode      string `json:"zipcode"`
+	CarrierType  string `json:"carriertype"`
+	City         string `json:"city"`
+	State        string `json:"state"`
+	County       string `json:"county"`
+	CmaName      string `json:"cma_name"`
+	NrPctCov     string `json:"nr_pct_cov"`
+	NrInd        string `json:"nr_ind"`
+	LtePctCov    string `json:"lte_pct_cov"`
+	LteInd       string `json:"lte_ind"`
+	HspaPctCov   string `json:"hspa_pct_cov"`
+	HspaInd      string `json:"hspa_ind"`
+	LoadDate     string `json:"load_date"`
+	ZipCenterLon string `json:"zip_center_lon"`
+	ZipCenterLat string `json:"zip_center_lat"`
+	Geohash      string `json:"geohash"`
+}
+
+// attTechnologies are the technologies reported in AT&T's coverage data
+var attTechnologies = []technology{
+	{name: "5G", percentageAttribute: "nr_pct_cov", indicatorAttribute: "nr_ind", weight: 0.2},
+	{name: "LTE", percentageAttribute: "lte_pct_cov", indicatorAttribute: "lte_ind", weight: 0.6},
+	{name: "HSPA", percentageAttribute: "hspa_pct_cov", indicatorAttribute: "hspa_ind", weight: 0.2},
+}
+
+func init() {
+	register(Carrier{
+		ID:      entity.ATT,
+		Name:    "AT&T",
+		SortKey: "att",
+		newData: func() interface{} { return &attCoverageData{} },
+		newClient: func(store Store, rule rules.Rule) CoverageCheckClient {
+			return NewATTClient(store, rule)
+		},
+		technologies: attTechnologies,
+		marketAreas: []marketAreaType{
+			{name: "CMA", nameAttribute: "cma_name"},
+		},
+		centroid: &centroid{latitudeAttribute: "zip_center_lat", longitudeAttribute: "zip_center_lon"},
+		region:   &region{stateAttribute: "state", countyAttribute: "county"},
+		aliases: map[string]string{
+			"zip":        "zipcode",
+			"5g_pct_cov": "nr_pct_cov",
+			"5g_ind":     "nr_ind",
+			"cma":        "cma_name",
+		},
+		numericAttributes: []string{"nr_pct_cov", "lte_pct_cov", "hspa_pct_cov", "zip_center_lon", "zip_center_lat"},
+	})
+}
+
+// NewATTClient constructs and returns AT&T's db client
+func NewATTClient(store Store, rule rules.Rule) attDbClient {
+	return attDbClient{store: store, rule: rule}
+}
+
+func (a attDbClient) VerifyCoverage(ctx context.Context, zipCode string) (Coverage, error) {
+	item, err := a.store.Get(ctx, zipCode, "att", a.attributes())
+	if err != nil {
+		return Coverage{}, err
+	}
+	if item == nil {
+		zerolog.Ctx(ctx).Debug().Msgf("Could not find coverage for zipcode: %s", zipCode)
+		return unknownCoverage(ctx, a.store, "att"), nil
+	}
+
+	data := attCoverageData{}
+	if err := decodeItem(item, &data); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("failed to decode AT&T coverage data")
+		return Coverage{}, err
+	}
+
+	return a.coverageOf(ctx, zipCode, data), nil
+}
+
+// BatchVerifyCoverage checks AT&T coverage for many zipcodes in batches. Zipcodes without coverage data are reported as not found.
+func (a attDbClient) BatchVerifyCoverage(ctx context.Context, zipCodes []string) (map[string]Coverage, error) {
+	items, err := a.store.BatchGet(ctx, "att", zipCodes, a.attributes())
+	if err != nil {
+		return nil, err
+	}
+
+	unknown := Coverage{}
+	if len(items) < len(zipCodes) {
+		unknown = unknownCoverage(ctx, a.store, "att")
+	}
+	coverage := make(map[string]Coverage, len(zipCodes))
+	for _, zipCode := range zipCodes {
+		coverage[zipCode] = unknown
+	}
+	for _, item := range items {
+		data := attCoverageData{}
+		if err := decodeItem(item, &data); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("failed to decode AT&T coverage data")
+			return nil, err
+		}
+		coverage[data.ZipCode] = a.coverageOf(ctx, data.ZipCode, data)
+	}
+	return coverage, nil
+}
+
+// attributes are the zipcode and the attributes read by the coverage rule and technologies
+func (a attDbClient) attributes() []string {
+	return coverageAttributes(a.rule, attTechnologies, "zipcode", "carriertype")
+}
+
+func (a attDbClient) coverageOf(ctx context.Context, zipCode string, data attCoverageData) Coverage {
+	return evaluateCoverage(ctx, a.rule, attTechnologies, zipCode, data)
+}
@@ -0,0 +1,94 @@
+package dbclient
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/rs/zerolog"
+)
+
+// batchGetItemLimit is the maximum number of keys DynamoDB accepts in a single BatchGetItem call
+const batchGetItemLimit = 100
+
+// maxBatchGetRetries is the number of times UnprocessedKeys are retried before giving up
+const maxBatchGetRetries = 5
+
+// batchRetryBaseDelay is the backoff before the first UnprocessedKeys retry, doubled on every attempt
+var batchRetryBaseDelay = 50 * time.Millisecond
+
+// coverageKey builds the primary key of a carrier's coverage item for a zipcode
+func coverageKey(zipCode string, carrierType string) map[string]*dynamodb.AttributeValue {
+	return map[string]*dynamodb.AttributeValue{
+		"zipcode": {
+			S: aws.String(zipCode),
+		},
+		"carriertype": {
+			S: aws.String(carrierType),
+		},
+	}
+}
+
+// batchGetItems retrieves the items for the given zipcodes of a carrier, splitting the keys into chunks of
+// batchGetItemLimit and retrying UnprocessedKeys with an exponential backoff.
+func batchGetItems(ctx context.Context, connection dynamodbiface.DynamoDBAPI, tableName *string, carrierType string, zipCodes []string, expr expression.Expression) ([]map[string]*dynamodb.AttributeValue, error) {
+	var items []map[string]*dynamodb.AttributeValue
+
+	for start := 0; start < len(zipCodes); start += batchGetItemLimit {
+		end := start + batchGetItemLimit
+		if end > len(zipCodes) {
+			end = len(zipCodes)
+		}
+
+		keys := make([]map[string]*dynamodb.AttributeValue, 0, end-start)
+		for _, zipCode := range zipCodes[start:end] {
+			keys = append(keys, coverageKey(zipCode, carrierType))
+		}
+
+		request := &dynamodb.KeysAndAttributes{
+			Keys:                     keys,
+			ExpressionAttributeNames: expr.Names(),
+			ProjectionExpression:     expr.Projection(),
+		}
+
+		delay := batchRetryBaseDelay
+		for attempt := 0; ; attempt++ {
+			if attempt > 0 {
+				if attempt > maxBatchGetRetries {
+					zerolog.Ctx(ctx).Error().Msgf("giving up on %d unprocessed %s keys after %d retries", len(request.Keys), carrierType, maxBatchGetRetries)
+					// keys stay unprocessed when the table is over its capacity
+					return nil, &Error{Kind: KindThrottled, Err: errors.New("unable to process all keys of batch get request")}
+				}
+				zerolog.Ctx(ctx).Debug().Msgf("retrying %d unprocessed %s keys in %s", len(request.Keys), carrierType, delay)
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-time.After(delay):
+				}
+				delay *= 2
+			}
+
+			input := &dynamodb.BatchGetItemInput{
+				RequestItems: map[string]*dynamodb.KeysAndAttributes{*tableName: request},
+			}
+			result, err := connection.BatchGetItemWithContext(ctx, input)
+			if err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Msg("failed to batch get items from coverage dynamodb table")
+				return nil, err
+			}
+			items = append(items, result.Responses[*tableName]...)
+
+			unprocessed, ok := result.UnprocessedKeys[*tableName]
+			if !ok || unprocessed == nil || len(unprocessed.Keys) == 0 {
+				break
+			}
+			request = unprocessed
+		}
+	}
+
+	return items, nil
+}
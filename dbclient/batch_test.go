@@ -0,0 +1,194 @@
+package dbclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/rules"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	batchRetryBaseDelay = 0
+}
+
+func TestSprintBatchVerifyCoverage(t *testing.T) {
+	tableName := aws.String("fakeCoverage")
+	fakeDb := &fakeBatchDynamoDB{
+		t:         t,
+		tableName: tableName,
+		items: map[string]map[string]string{
+			"94105": {"zipcode": "94105", "carriertype": "sprint", "cur_pct_cov": "100", "lte_4g_pctcov": "100"},
+			"94106": {"zipcode": "94106", "carriertype": "sprint", "cur_pct_cov": "50", "lte_4g_pctcov": "50"},
+			"_load": {"zipcode": "_load", "carriertype": "sprint", "load_date": "2026-10-01", "rows": "2"},
+		},
+	}
+
+	sprintdbClient := NewSprintClient(NewDynamoStore(tableName, fakeDb), rules.Default().Carriers["sprint"])
+	result, err := sprintdbClient.BatchVerifyCoverage(context.Background(), []string{"94105", "94106", "11111"})
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 3)
+	assert.True(t, result["94105"].IsCovered)
+	assert.Equal(t, 70.0, result["94105"].Detail.Score)
+	assert.False(t, result["94106"].IsCovered)
+	assert.True(t, result["94106"].Found)
+	assert.Equal(t, Coverage{LoadDate: "2026-10-01"}, result["11111"])
+	assert.Equal(t, 1, fakeDb.calls)
+	assert.Equal(t, "sprint", fakeDb.carrierType)
+}
+
+func TestVerizonBatchVerifyCoverage(t *testing.T) {
+	tableName := aws.String("fakeCoverage")
+	fakeDb := &fakeBatchDynamoDB{
+		t:         t,
+		tableName: tableName,
+		items: map[string]map[string]string{
+			"94105": {"zipcode": "94105", "carriertype": "verizon", "vzelte": "100", "vze_lte_ind": "Y", "state": "CA"},
+			"94106": {"zipcode": "94106", "carriertype": "verizon", "vzelte": "100", "vze_lte_ind": "N", "state": "CA"},
+		},
+	}
+
+	verizondbClient := NewVerizonClient(NewDynamoStore(tableName, fakeDb), rules.Default().Carriers["verizon"])
+	result, err := verizondbClient.BatchVerifyCoverage(context.Background(), []string{"94105", "94106", "11111"})
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 3)
+	assert.True(t, result["94105"].IsCovered)
+	assert.False(t, result["94106"].IsCovered)
+	assert.Equal(t, Coverage{}, result["11111"])
+	assert.Equal(t, "verizon", fakeDb.carrierType)
+}
+
+func TestBatchGetItems(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		zipCodeCount     int
+		unprocessedTimes int
+		causeError       bool
+		expectError      bool
+		expectCalls      int
+	}{
+		{
+			desc:         "single chunk",
+			zipCodeCount: 20,
+			expectCalls:  1,
+		},
+		{
+			desc:         "keys split into chunks of the BatchGetItem limit",
+			zipCodeCount: 250,
+			expectCalls:  3,
+		},
+		{
+			desc:             "unprocessed keys are retried",
+			zipCodeCount:     20,
+			unprocessedTimes: 2,
+			expectCalls:      3,
+		},
+		{
+			desc:             "gives up when keys stay unprocessed",
+			zipCodeCount:     20,
+			unprocessedTimes: maxBatchGetRetries + 1,
+			expectError:      true,
+			expectCalls:      maxBatchGetRetries + 1,
+		},
+		{
+			desc:         "dynamodb error",
+			zipCodeCount: 20,
+			causeError:   true,
+			expectError:  true,
+			expectCalls:  1,
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			tableName := aws.String("fakeCoverage")
+			fakeDb := &fakeBatchDynamoDB{
+				t:                t,
+				tableName:        tableName,
+				items:            map[string]map[string]string{},
+				unprocessedTimes: tC.unprocessedTimes,
+			}
+			if tC.causeError {
+				fakeDb.err = errors.New("fake DB error")
+			}
+
+			var zipCodes []string
+			for i := 0; i < tC.zipCodeCount; i++ {
+				zipCode := fmt.Sprintf("%05d", i)
+				zipCodes = append(zipCodes, zipCode)
+				fakeDb.items[zipCode] = map[string]string{"zipcode": zipCode, "carriertype": "sprint"}
+			}
+
+			client := NewSprintClient(NewDynamoStore(tableName, fakeDb), rules.Default().Carriers["sprint"])
+			expr, _ := projection(client.attributes())
+			items, err := batchGetItems(context.Background(), fakeDb, tableName, "sprint", zipCodes, expr)
+
+			if tC.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, items, tC.zipCodeCount)
+			}
+			assert.Equal(t, tC.expectCalls, fakeDb.calls)
+		})
+	}
+}
+
+type fakeBatchDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+	tableName        *string
+	carrierType      string
+	items            map[string]map[string]string // Store fake items by zipcode
+	unprocessedTimes int                          // Number of calls that leave all keys unprocessed
+	calls            int
+	err              error
+	t                *testing.T
+}
+
+func (fd *fakeBatchDynamoDB) BatchGetItemWithContext(ctx aws.Context, input *dynamodb.BatchGetItemInput, opts ...request.Option) (*dynamodb.BatchGetItemOutput, error) {
+	fd.calls++
+	request, ok := input.RequestItems[*fd.tableName]
+	assert.True(fd.t, ok, "incorrect table name")
+	assert.True(fd.t, len(request.Keys) <= batchGetItemLimit, "too many keys in a single batch")
+
+	if fd.err != nil {
+		return &dynamodb.BatchGetItemOutput{}, fd.err
+	}
+
+	if fd.calls <= fd.unprocessedTimes {
+		return &dynamodb.BatchGetItemOutput{UnprocessedKeys: input.RequestItems}, nil
+	}
+
+	var responses []map[string]*dynamodb.AttributeValue
+	for _, key := range request.Keys {
+		fd.carrierType = *key["carriertype"].S
+		payload, ok := fd.items[*key["zipcode"].S]
+		if !ok {
+			continue
+		}
+		item := map[string]*dynamodb.AttributeValue{}
+		for k, v := range payload {
+			item[k] = &dynamodb.AttributeValue{S: aws.String(v)}
+		}
+		responses = append(responses, item)
+	}
+	return &dynamodb.BatchGetItemOutput{Responses: map[string][]map[string]*dynamodb.AttributeValue{*fd.tableName: responses}}, nil
+}
+
+// GetItemWithContext serves the load meta item read for the zipcodes missing from a batch
+func (fd *fakeBatchDynamoDB) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	assert.Equal(fd.t, loadMetaZipCode, *input.Key["zipcode"].S, "unexpected single item lookup")
+	item := map[string]*dynamodb.AttributeValue{}
+	for k, v := range fd.items[loadMetaZipCode] {
+		item[k] = &dynamodb.AttributeValue{S: aws.String(v)}
+	}
+	return &dynamodb.GetItemOutput{Item: item}, nil
+}
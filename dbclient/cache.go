@@ -0,0 +1,323 @@
+package dbclient
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/rs/zerolog"
+)
+
+const (
+	defaultCacheSize             = 10000
+	defaultCacheTTL              = 15 * time.Minute
+	defaultCacheNegativeTTL      = 5 * time.Minute
+	defaultLoadDateCheckInterval = time.Minute
+)
+
+// CacheConfig configures the coverage cache. Zero values take the defaults.
+type CacheConfig struct {
+	// Size is the maximum number of lookups kept, least recently used ones are evicted first
+	Size int
+	// TTL is how long a lookup of a zipcode with coverage data is kept
+	TTL time.Duration
+	// NegativeTTL is how long a lookup of a zipcode without coverage data is kept
+	NegativeTTL time.Duration
+	// LoadDateCheckInterval is how often the load date of a carrier is re-read to notice a new load
+	LoadDateCheckInterval time.Duration
+}
+
+// CacheStats counts the lookups answered from the cache and the ones that had to read the store
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Size      int
+}
+
+// Cache is an in-process read-through cache of coverage and market area lookups. It outlives requests, so a warm
+// Lambda keeps answering from it. The lookups of a carrier are dropped once the loader records a new load
+// date for the carrier.
+type Cache struct {
+	config    CacheConfig
+	store     Store
+	now       func() time.Time
+	hits      uint64
+	misses    uint64
+	evictions uint64
+
+	mu      sync.Mutex
+	entries map[cacheKey]*list.Element
+	lru     *list.List
+
+	loadDatesMu sync.Mutex
+	loadDates   map[string]loadDateCheck
+}
+
+type cacheKey struct {
+	kind    string
+	sortKey string
+	zipCode string
+}
+
+type cacheEntry struct {
+	key      cacheKey
+	value    interface{}
+	loadDate string
+	expires  time.Time
+}
+
+// loadDateCheck is the last read of a carrier's load date, zero checked when it was never read. fetching is closed
+// once the read in flight is over.
+type loadDateCheck struct {
+	loadDate string
+	checked  time.Time
+	fetching chan struct{}
+}
+
+// NewCache constructs and gives back a cache reading carrier load dates from the store
+func NewCache(store Store, config CacheConfig) *Cache {
+	if config.Size <= 0 {
+		config.Size = defaultCacheSize
+	}
+	if config.TTL <= 0 {
+		config.TTL = defaultCacheTTL
+	}
+	if config.NegativeTTL <= 0 {
+		config.NegativeTTL = defaultCacheNegativeTTL
+	}
+	if config.LoadDateCheckInterval <= 0 {
+		config.LoadDateCheckInterval = defaultLoadDateCheckInterval
+	}
+	return &Cache{
+		config:    config,
+		store:     store,
+		now:       time.Now,
+		entries:   map[cacheKey]*list.Element{},
+		lru:       list.New(),
+		loadDates: map[string]loadDateCheck{},
+	}
+}
+
+// Stats gives back the hit, miss and eviction counts since the cache was constructed
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	size := c.lru.Len()
+	c.mu.Unlock()
+
+	return CacheStats{
+		Hits:      atomic.LoadUint64(&c.hits),
+		Misses:    atomic.LoadUint64(&c.misses),
+		Evictions: atomic.LoadUint64(&c.evictions),
+		Size:      size,
+	}
+}
+
+// CoverageClient wraps a carrier's db client so its lookups are answered from the cache
+func (c *Cache) CoverageClient(sortKey string, client CoverageCheckClient) CoverageCheckClient {
+	return cachingCoverageClient{cache: c, sortKey: sortKey, client: client}
+}
+
+// MarketAreaClient wraps a market area client so its lookups are answered from the cache
+func (c *Cache) MarketAreaClient(client MarketAreaClient) MarketAreaClient {
+	return cachingMarketAreaClient{cache: c, client: client}
+}
+
+// get gives back the value cached for the key, provided it hasn't expired and is from the carrier's current load
+func (c *Cache) get(key cacheKey, loadDate string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		atomic.AddUint64(&c.misses, 1)
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if entry.loadDate != loadDate || !c.now().Before(entry.expires) {
+		c.lru.Remove(element)
+		delete(c.entries, key)
+		atomic.AddUint64(&c.misses, 1)
+		return nil, false
+	}
+
+	c.lru.MoveToFront(element)
+	atomic.AddUint64(&c.hits, 1)
+	return entry.value, true
+}
+
+// put caches the value of the key, evicting the least recently used lookup when the cache is full
+func (c *Cache) put(key cacheKey, value interface{}, loadDate string, found bool) {
+	ttl := c.config.TTL
+	if !found {
+		ttl = c.config.NegativeTTL
+	}
+	entry := &cacheEntry{key: key, value: value, loadDate: loadDate, expires: c.now().Add(ttl)}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.lru.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.config.Size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+		atomic.AddUint64(&c.evictions, 1)
+	}
+}
+
+// loadDate gives back the load date the loader recorded for the carrier, re-reading it once the last read
+// is older than the check interval. One caller at a time reads it, outside the lock: the others keep the last known
+// load date meanwhile, or wait on the read when there is none. A failed read keeps the last known load date, and a
+// read its caller canceled is left for the next caller to make again.
+func (c *Cache) loadDate(ctx context.Context, sortKey string) string {
+	c.loadDatesMu.Lock()
+	check := c.loadDates[sortKey]
+	known := !check.checked.IsZero()
+	if known && c.now().Sub(check.checked) < c.config.LoadDateCheckInterval {
+		c.loadDatesMu.Unlock()
+		return check.loadDate
+	}
+	if done := check.fetching; done != nil {
+		c.loadDatesMu.Unlock()
+		if known {
+			return check.loadDate
+		}
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ""
+		}
+		c.loadDatesMu.Lock()
+		defer c.loadDatesMu.Unlock()
+		return c.loadDates[sortKey].loadDate
+	}
+	done := make(chan struct{})
+	check.fetching = done
+	c.loadDates[sortKey] = check
+	c.loadDatesMu.Unlock()
+
+	meta, _, err := GetLoadMeta(ctx, c.store, sortKey)
+
+	c.loadDatesMu.Lock()
+	defer c.loadDatesMu.Unlock()
+	defer close(done)
+	check.fetching = nil
+	if err != nil && KindOf(err) == KindCanceled {
+		c.loadDates[sortKey] = check
+		return check.loadDate
+	}
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msgf("failed to read the load date of %s, keeping %q", sortKey, check.loadDate)
+		meta.LoadDate = check.loadDate
+	}
+	if known && meta.LoadDate != check.loadDate {
+		zerolog.Ctx(ctx).Info().Msgf("%s load date changed from %q to %q, dropping its cached lookups", sortKey, check.loadDate, meta.LoadDate)
+	}
+	c.loadDates[sortKey] = loadDateCheck{loadDate: meta.LoadDate, checked: c.now()}
+	return meta.LoadDate
+}
+
+type cachingCoverageClient struct {
+	cache   *Cache
+	sortKey string
+	client  CoverageCheckClient
+}
+
+func (c cachingCoverageClient) VerifyCoverage(ctx context.Context, zipCode string) (Coverage, error) {
+	loadDate := c.cache.loadDate(ctx, c.sortKey)
+	key := cacheKey{kind: "coverage", sortKey: c.sortKey, zipCode: zipCode}
+	if value, ok := c.cache.get(key, loadDate); ok {
+		return value.(Coverage), nil
+	}
+
+	coverage, err := c.client.VerifyCoverage(ctx, zipCode)
+	if err != nil {
+		return Coverage{}, err
+	}
+	c.cache.put(key, coverage, loadDate, coverage.Found)
+	return coverage, nil
+}
+
+// BatchVerifyCoverage answers the cached zipcodes from the cache and looks the rest up in a single batch
+func (c cachingCoverageClient) BatchVerifyCoverage(ctx context.Context, zipCodes []string) (map[string]Coverage, error) {
+	loadDate := c.cache.loadDate(ctx, c.sortKey)
+	coverage := make(map[string]Coverage, len(zipCodes))
+	var missed []string
+	for _, zipCode := range zipCodes {
+		if value, ok := c.cache.get(cacheKey{kind: "coverage", sortKey: c.sortKey, zipCode: zipCode}, loadDate); ok {
+			coverage[zipCode] = value.(Coverage)
+			continue
+		}
+		missed = append(missed, zipCode)
+	}
+	if len(missed) == 0 {
+		return coverage, nil
+	}
+
+	looked, err := c.client.BatchVerifyCoverage(ctx, missed)
+	if err != nil {
+		return nil, err
+	}
+	for zipCode, zipCoverage := range looked {
+		coverage[zipCode] = zipCoverage
+		c.cache.put(cacheKey{kind: "coverage", sortKey: c.sortKey, zipCode: zipCode}, zipCoverage, loadDate, zipCoverage.Found)
+	}
+	return coverage, nil
+}
+
+type cachingMarketAreaClient struct {
+	cache  *Cache
+	client MarketAreaClient
+}
+
+func (c cachingMarketAreaClient) GetMarketAreas(ctx context.Context, carrier entity.CarrierType, zipCode string) (MarketAreas, error) {
+	sortKey := carrierSortKey(carrier)
+	if sortKey == "" {
+		return c.client.GetMarketAreas(ctx, carrier, zipCode)
+	}
+	loadDate := c.cache.loadDate(ctx, sortKey)
+	key := cacheKey{kind: "marketarea", sortKey: sortKey, zipCode: zipCode}
+	if value, ok := c.cache.get(key, loadDate); ok {
+		return value.(MarketAreas), nil
+	}
+
+	marketAreas, err := c.client.GetMarketAreas(ctx, carrier, zipCode)
+	if err != nil {
+		return MarketAreas{}, err
+	}
+	c.cache.put(key, marketAreas, loadDate, marketAreas.Found)
+	return marketAreas, nil
+}
+
+// carrierSortKey gives back the sort key of a registered carrier
+func carrierSortKey(id entity.CarrierType) string {
+	carrier, _ := LookupCarrier(id)
+	return carrier.SortKey
+}
+
+type cachingClientFactory struct {
+	ClientFactory
+	cache *Cache
+}
+
+// NewCachingDbClientFactory wraps the db clients the factory gives back so their lookups are answered from the cache
+func NewCachingDbClientFactory(factory ClientFactory, cache *Cache) ClientFactory {
+	return cachingClientFactory{ClientFactory: factory, cache: cache}
+}
+
+func (c cachingClientFactory) GetDbClient(t entity.CarrierType) (CoverageCheckClient, error) {
+	client, err := c.ClientFactory.GetDbClient(t)
+	if err != nil {
+		return nil, err
+	}
+	return c.cache.CoverageClient(carrierSortKey(t), client), nil
+}
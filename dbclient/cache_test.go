@@ -0,0 +1,303 @@
+package dbclient
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingClient answers coverage from a map and counts the zipcodes it is asked for
+type countingClient struct {
+	coverage map[string]Coverage
+	areas    map[string]MarketAreas
+	lookups  map[string]int
+	err      error
+}
+
+func newCountingClient() *countingClient {
+	return &countingClient{
+		coverage: map[string]Coverage{"94105": {IsCovered: true, Found: true}},
+		areas:    map[string]MarketAreas{"94105": {Found: true, Areas: []entity.MarketArea{{Type: "CSA", Code: "SFRSFR415"}}}},
+		lookups:  map[string]int{},
+	}
+}
+
+func (c *countingClient) VerifyCoverage(ctx context.Context, zipCode string) (Coverage, error) {
+	c.lookups[zipCode]++
+	return c.coverage[zipCode], c.err
+}
+
+func (c *countingClient) BatchVerifyCoverage(ctx context.Context, zipCodes []string) (map[string]Coverage, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	result := map[string]Coverage{}
+	for _, zipCode := range zipCodes {
+		c.lookups[zipCode]++
+		result[zipCode] = c.coverage[zipCode]
+	}
+	return result, nil
+}
+
+func (c *countingClient) GetMarketAreas(ctx context.Context, carrier entity.CarrierType, zipCode string) (MarketAreas, error) {
+	c.lookups[zipCode]++
+	return c.areas[zipCode], c.err
+}
+
+// fakeClock is a clock the tests move forward by hand
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+
+func newTestCache(store Store, config CacheConfig) (*Cache, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC)}
+	cache := NewCache(store, config)
+	cache.now = clock.Now
+	return cache, clock
+}
+
+func TestCacheVerifyCoverage(t *testing.T) {
+	cache, clock := newTestCache(NewMemoryStore(), CacheConfig{TTL: time.Minute, NegativeTTL: 10 * time.Second})
+	client := newCountingClient()
+	cached := cache.CoverageClient("sprint", client)
+
+	for i := 0; i < 3; i++ {
+		coverage, err := cached.VerifyCoverage(context.Background(), "94105")
+		assert.NoError(t, err)
+		assert.True(t, coverage.IsCovered)
+
+		coverage, err = cached.VerifyCoverage(context.Background(), "00000")
+		assert.NoError(t, err)
+		assert.False(t, coverage.Found)
+	}
+	assert.Equal(t, map[string]int{"94105": 1, "00000": 1}, client.lookups)
+	assert.Equal(t, CacheStats{Hits: 4, Misses: 2, Size: 2}, cache.Stats())
+
+	// the unknown zipcode expires first
+	clock.now = clock.now.Add(30 * time.Second)
+	cached.VerifyCoverage(context.Background(), "94105")
+	cached.VerifyCoverage(context.Background(), "00000")
+	assert.Equal(t, map[string]int{"94105": 1, "00000": 2}, client.lookups)
+
+	clock.now = clock.now.Add(time.Minute)
+	cached.VerifyCoverage(context.Background(), "94105")
+	assert.Equal(t, map[string]int{"94105": 2, "00000": 2}, client.lookups)
+}
+
+func TestCacheBatchVerifyCoverage(t *testing.T) {
+	cache, _ := newTestCache(NewMemoryStore(), CacheConfig{})
+	client := newCountingClient()
+	cached := cache.CoverageClient("sprint", client)
+
+	_, err := cached.VerifyCoverage(context.Background(), "94105")
+	assert.NoError(t, err)
+
+	result, err := cached.BatchVerifyCoverage(context.Background(), []string{"94105", "00000"})
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.True(t, result["94105"].IsCovered)
+	// only the zipcode that wasn't cached is looked up again
+	assert.Equal(t, map[string]int{"94105": 1, "00000": 1}, client.lookups)
+
+	result, err = cached.BatchVerifyCoverage(context.Background(), []string{"94105", "00000"})
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.Equal(t, map[string]int{"94105": 1, "00000": 1}, client.lookups)
+}
+
+func TestCacheDoesNotCacheErrors(t *testing.T) {
+	cache, _ := newTestCache(NewMemoryStore(), CacheConfig{})
+	client := newCountingClient()
+	client.err = errors.New("Fake error")
+	cached := cache.CoverageClient("sprint", client)
+
+	_, err := cached.VerifyCoverage(context.Background(), "94105")
+	assert.Error(t, err)
+	_, err = cached.BatchVerifyCoverage(context.Background(), []string{"94105"})
+	assert.Error(t, err)
+	_, err = cache.MarketAreaClient(client).GetMarketAreas(context.Background(), entity.Sprint, "94105")
+	assert.Error(t, err)
+
+	assert.Equal(t, 0, cache.Stats().Size)
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	cache, _ := newTestCache(NewMemoryStore(), CacheConfig{Size: 2})
+	client := newCountingClient()
+	cached := cache.CoverageClient("sprint", client)
+
+	cached.VerifyCoverage(context.Background(), "10001")
+	cached.VerifyCoverage(context.Background(), "10002")
+	cached.VerifyCoverage(context.Background(), "10001")
+	cached.VerifyCoverage(context.Background(), "10003")
+	cached.VerifyCoverage(context.Background(), "10001")
+	cached.VerifyCoverage(context.Background(), "10002")
+
+	assert.Equal(t, map[string]int{"10001": 1, "10002": 2, "10003": 1}, client.lookups)
+	assert.Equal(t, uint64(2), cache.Stats().Evictions)
+	assert.Equal(t, 2, cache.Stats().Size)
+}
+
+func TestCacheDropsLookupsOnNewLoadDate(t *testing.T) {
+	store := NewMemoryStore()
+	store.Put(loadMetaItem("sprint", "2018-11-01", 1), loadMetaItem("verizon", "2018-11-01", 1))
+	cache, clock := newTestCache(store, CacheConfig{LoadDateCheckInterval: time.Minute})
+	client := newCountingClient()
+	verizonClient := newCountingClient()
+	sprint := cache.CoverageClient("sprint", client)
+	verizon := cache.CoverageClient("verizon", verizonClient)
+
+	sprint.VerifyCoverage(context.Background(), "94105")
+	verizon.VerifyCoverage(context.Background(), "94105")
+	store.Put(loadMetaItem("sprint", "2018-12-01", 1))
+
+	// the new load date isn't read again until the check interval has passed
+	sprint.VerifyCoverage(context.Background(), "94105")
+	assert.Equal(t, 1, client.lookups["94105"])
+
+	clock.now = clock.now.Add(time.Minute)
+	sprint.VerifyCoverage(context.Background(), "94105")
+	assert.Equal(t, 2, client.lookups["94105"])
+	sprint.VerifyCoverage(context.Background(), "94105")
+	assert.Equal(t, 2, client.lookups["94105"])
+
+	// verizon wasn't reloaded
+	verizon.VerifyCoverage(context.Background(), "94105")
+	assert.Equal(t, 1, verizonClient.lookups["94105"])
+}
+
+// slowMetaStore holds the reads of the load meta items until released, failing them with err, and counts them
+type slowMetaStore struct {
+	Store
+	release chan struct{}
+	reads   int32
+	err     error
+}
+
+func (s *slowMetaStore) Get(ctx context.Context, zipCode string, sortKey string, attributes []string) (Item, error) {
+	if zipCode == loadMetaZipCode {
+		atomic.AddInt32(&s.reads, 1)
+		<-s.release
+		if s.err != nil {
+			return nil, s.err
+		}
+	}
+	return s.Store.Get(ctx, zipCode, sortKey, attributes)
+}
+
+func TestCacheReadsLoadDateOnce(t *testing.T) {
+	memoryStore := NewMemoryStore()
+	memoryStore.Put(loadMetaItem("sprint", "2018-11-01", 1), loadMetaItem("verizon", "2018-11-01", 1))
+	store := &slowMetaStore{Store: memoryStore, release: make(chan struct{})}
+	cache, clock := newTestCache(store, CacheConfig{LoadDateCheckInterval: time.Minute})
+	close(store.release)
+	assert.Equal(t, "2018-11-01", cache.loadDate(context.Background(), "sprint"))
+	store.release = make(chan struct{})
+	memoryStore.Put(loadMetaItem("sprint", "2018-12-01", 1))
+	clock.now = clock.now.Add(time.Minute)
+
+	// the first caller reads the load date again, the others keep the last one meanwhile
+	read := make(chan string)
+	go func() { read <- cache.loadDate(context.Background(), "sprint") }()
+	for atomic.LoadInt32(&store.reads) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, "2018-11-01", cache.loadDate(context.Background(), "sprint"))
+	}
+
+	// a carrier never read waits on its own read, which doesn't wait on the other carrier's
+	go func() { read <- cache.loadDate(context.Background(), "verizon") }()
+	for atomic.LoadInt32(&store.reads) < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, "", cache.loadDate(canceled, "verizon"))
+
+	close(store.release)
+	assert.ElementsMatch(t, []string{"2018-12-01", "2018-11-01"}, []string{<-read, <-read})
+	assert.Equal(t, int32(3), atomic.LoadInt32(&store.reads))
+	assert.Equal(t, "2018-12-01", cache.loadDate(context.Background(), "sprint"))
+}
+
+func TestCacheRereadsCanceledLoadDate(t *testing.T) {
+	memoryStore := NewMemoryStore()
+	memoryStore.Put(loadMetaItem("sprint", "2018-11-01", 1))
+	store := &slowMetaStore{Store: memoryStore, release: make(chan struct{})}
+	close(store.release)
+	cache, clock := newTestCache(store, CacheConfig{LoadDateCheckInterval: time.Minute})
+	assert.Equal(t, "2018-11-01", cache.loadDate(context.Background(), "sprint"))
+	memoryStore.Put(loadMetaItem("sprint", "2018-12-01", 1))
+	clock.now = clock.now.Add(time.Minute)
+
+	// a canceled read keeps the load date without counting as a check
+	store.err = awserr.New(request.CanceledErrorCode, "request context canceled", context.Canceled)
+	assert.Equal(t, "2018-11-01", cache.loadDate(context.Background(), "sprint"))
+	store.err = nil
+	assert.Equal(t, "2018-12-01", cache.loadDate(context.Background(), "sprint"))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&store.reads))
+
+	// any other failure does, so a failing table isn't read on every lookup
+	clock.now = clock.now.Add(time.Minute)
+	store.err = errors.New("Fake error")
+	assert.Equal(t, "2018-12-01", cache.loadDate(context.Background(), "sprint"))
+	assert.Equal(t, "2018-12-01", cache.loadDate(context.Background(), "sprint"))
+	assert.Equal(t, int32(4), atomic.LoadInt32(&store.reads))
+}
+
+func TestCacheGetMarketAreas(t *testing.T) {
+	cache, _ := newTestCache(NewMemoryStore(), CacheConfig{})
+	client := newCountingClient()
+	cached := cache.MarketAreaClient(client)
+
+	for i := 0; i < 2; i++ {
+		marketAreas, err := cached.GetMarketAreas(context.Background(), entity.Sprint, "94105")
+		assert.NoError(t, err)
+		assert.Equal(t, "SFRSFR415", marketAreas.Areas[0].Code)
+
+		marketAreas, err = cached.GetMarketAreas(context.Background(), entity.Sprint, "00000")
+		assert.NoError(t, err)
+		assert.False(t, marketAreas.Found)
+	}
+	assert.Equal(t, map[string]int{"94105": 1, "00000": 1}, client.lookups)
+
+	// each carrier's market areas are cached apart
+	_, err := cached.GetMarketAreas(context.Background(), entity.Verizon, "94105")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, client.lookups["94105"])
+
+	// market area and coverage lookups of the same zipcode don't collide
+	coverage, err := cache.CoverageClient("sprint", client).VerifyCoverage(context.Background(), "94105")
+	assert.NoError(t, err)
+	assert.True(t, coverage.IsCovered)
+}
+
+func TestCachingDbClientFactory(t *testing.T) {
+	store := NewMemoryStore()
+	factory, err := NewDbClientFactory(store, rules.Default(), nil)
+	assert.NoError(t, err)
+	cache := NewCache(store, CacheConfig{})
+	factory = NewCachingDbClientFactory(factory, cache)
+
+	client, err := factory.GetDbClient(entity.Verizon)
+	assert.NoError(t, err)
+	assert.IsType(t, cachingCoverageClient{}, client)
+	assert.Equal(t, "verizon", client.(cachingCoverageClient).sortKey)
+	assert.Len(t, factory.Carriers(), 4)
+
+	_, err = factory.GetDbClient(entity.CarrierType("9"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,74 @@
+package dbclient
+
+import (
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ZipCodeCentroid is the location of a zipcode's centroid
+type ZipCodeCentroid struct {
+	Lat float64
+	Lon float64
+}
+
+// ZipCodeCentroids are zipcode centroids from a carrier neutral source such as the census ZCTA gazetteer, keyed by
+// zipcode. The loader stamps them on the coverage data of carriers whose exports have no centroids of their own, so
+// lat/lon lookups find the zipcodes only those carriers cover.
+type ZipCodeCentroids map[string]ZipCodeCentroid
+
+// centroidColumns maps the lower cased columns of a centroid file onto the zipcode, latitude and longitude.
+// GEOID, INTPTLAT and INTPTLONG are the columns of the census ZCTA gazetteer.
+var centroidColumns = map[string]string{
+	"zip":       "zipcode",
+	"zipcode":   "zipcode",
+	"geoid":     "zipcode",
+	"lat":       "lat",
+	"latitude":  "lat",
+	"intptlat":  "lat",
+	"lon":       "lon",
+	"lng":       "lon",
+	"longitude": "lon",
+	"intptlong": "lon",
+}
+
+// ReadCentroids reads a csv, tsv or json file of zipcode centroids. Rows without a valid zipcode and location are
+// skipped.
+func ReadCentroids(r io.Reader, format string) (ZipCodeCentroids, error) {
+	rows, err := ReadRows(r, format)
+	if err != nil {
+		return nil, err
+	}
+
+	centroids := ZipCodeCentroids{}
+	for _, row := range rows {
+		values := map[string]string{}
+		for column, value := range row {
+			if name, ok := centroidColumns[strings.ToLower(strings.TrimSpace(column))]; ok {
+				values[name] = strings.TrimSpace(value)
+			}
+		}
+
+		zipCode := normalizeZipCode(values["zipcode"])
+		if !loaderZipCodeRegex.MatchString(zipCode) || geohashOf(values["lat"], values["lon"]) == "" {
+			continue
+		}
+		lat, _ := strconv.ParseFloat(values["lat"], 64)
+		lon, _ := strconv.ParseFloat(values["lon"], 64)
+		centroids[zipCode] = ZipCodeCentroid{Lat: lat, Lon: lon}
+	}
+	return centroids, nil
+}
+
+// stamp fills in the centroid of a mapped row from the centroids when the carrier's export left it out
+func (c ZipCodeCentroids) stamp(schema Carrier, attributes map[string]string) {
+	if schema.centroid == nil || attributes[schema.centroid.latitudeAttribute] != "" || attributes[schema.centroid.longitudeAttribute] != "" {
+		return
+	}
+	centroid, ok := c[attributes["zipcode"]]
+	if !ok {
+		return
+	}
+	attributes[schema.centroid.latitudeAttribute] = strconv.FormatFloat(centroid.Lat, 'f', -1, 64)
+	attributes[schema.centroid.longitudeAttribute] = strconv.FormatFloat(centroid.Lon, 'f', -1, 64)
+}
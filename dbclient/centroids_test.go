@@ -0,0 +1,32 @@
+package dbclient
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCentroids(t *testing.T) {
+	gazetteer := "GEOID\tALAND\tINTPTLAT\tINTPTLONG                 \n" +
+		"00501\t0\t40.8133\t-73.0476                 \n" +
+		"94105\t1\t37.7898\t-122.3942\n" +
+		"9410\t1\t37.7\t-122.3\n" +
+		"94106\t1\t\t-122.4\n"
+
+	centroids, err := ReadCentroids(strings.NewReader(gazetteer), "tsv")
+
+	assert.NoError(t, err)
+	assert.Equal(t, ZipCodeCentroids{
+		"00501": {Lat: 40.8133, Lon: -73.0476},
+		"94105": {Lat: 37.7898, Lon: -122.3942},
+		"09410": {Lat: 37.7, Lon: -122.3},
+	}, centroids)
+
+	centroids, err = ReadCentroids(strings.NewReader(`[{"zip":"94105","lat":37.7898,"lng":-122.3942}]`), "json")
+	assert.NoError(t, err)
+	assert.Equal(t, ZipCodeCentroids{"94105": {Lat: 37.7898, Lon: -122.3942}}, centroids)
+
+	_, err = ReadCentroids(strings.NewReader(""), "xml")
+	assert.Error(t, err)
+}
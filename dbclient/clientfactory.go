@@ -2,63 +2,63 @@ package dbclient
 
 import (
 	"context"
-	"errors"
-	"strings"
+	"fmt"
 
 	"bitbucket.org/credomobile/coverage/entity"
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/dynamodb"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"bitbucket.org/credomobile/coverage/rules"
 	"github.com/rs/zerolog"
 )
 
 type CoverageCheckClient interface {
-	VerifyCoverage(ctx context.Context, zipCode string) (bool, error)
+	VerifyCoverage(ctx context.Context, zipCode string) (Coverage, error)
+	BatchVerifyCoverage(ctx context.Context, zipCodes []string) (map[string]Coverage, error)
 }
 
 type ClientFactory interface {
 	GetDbClient(t entity.CarrierType) (CoverageCheckClient, error)
+	Carriers() []entity.CarrierType
+	RuleVersion() string
 }
 
 type clientFactoryImpl struct {
-	tableName  *string
-	connection dynamodbiface.DynamoDBAPI
+	store   Store
+	ruleSet rules.RuleSet
 }
 
 // NewDbClientFactory constructs and gives back a db client factory that can be used to retrieve carrier specfic db client.
-func NewDbClientFactory(dynamodbARN string, logger *zerolog.Logger) (ClientFactory, error) {
-	//awsSession, err := session.NewSession()
-	config := &aws.Config{
-		Region:   aws.String("us-east-2"),
-		Endpoint: aws.String("http://localhost:8000"),
-	}
-	awsSession, err := session.NewSession(config)
-
-	if err != nil {
-		logger.Fatal().Err(err).Msg("unable to create connection to dynamodb")
-		return nil, err
-	}
-	dynamo := dynamodb.New(awsSession)
-
-	if len(strings.Split(dynamodbARN, "/")) < 2 {
-		return nil, errors.New("Invalid dynamodbARN")
+// The rule set must define a coverage rule for every registered carrier.
+func NewDbClientFactory(store Store, ruleSet rules.RuleSet, logger *zerolog.Logger) (ClientFactory, error) {
+	for _, carrier := range RegisteredCarriers() {
+		if _, ok := ruleSet.Carriers[carrier.SortKey]; !ok {
+			return nil, fmt.Errorf("missing coverage rule for carrier %s (%s)", carrier.ID, carrier.SortKey)
+		}
 	}
 
 	return clientFactoryImpl{
-		tableName:  aws.String(strings.Split(dynamodbARN, "/")[1]),
-		connection: dynamodbiface.DynamoDBAPI(dynamo),
+		store:   store,
+		ruleSet: ruleSet,
 	}, nil
 }
 
 func (c clientFactoryImpl) GetDbClient(t entity.CarrierType) (CoverageCheckClient, error) {
-	switch t {
-	case entity.Sprint:
-		return NewSprintClient(c.tableName, c.connection), nil
-	case entity.Verizon:
-		return NewVerizonClient(c.tableName, c.connection), nil
-	default:
+	carrier, ok := LookupCarrier(t)
+	if !ok {
 		//if type is invalid, return an error
-		return nil, errors.New("Invalid Carrier Type")
+		return nil, ErrUnknownCarrier
 	}
+	return carrier.newClient(c.store, c.ruleSet.Carriers[carrier.SortKey]), nil
+}
+
+// Carriers lists every carrier GetDbClient can give back a db client for, i.e. every registered carrier
+func (c clientFactoryImpl) Carriers() []entity.CarrierType {
+	var carriers []entity.CarrierType
+	for _, carrier := range RegisteredCarriers() {
+		carriers = append(carriers, carrier.ID)
+	}
+	return carriers
+}
+
+// RuleVersion is the version of the coverage rules the db clients evaluate
+func (c clientFactoryImpl) RuleVersion() string {
+	return c.ruleSet.Version
 }
@@ -5,7 +5,7 @@ import (
 	"testing"
 
 	"bitbucket.org/credomobile/coverage/entity"
-	"github.com/aws/aws-sdk-go/aws"
+	"bitbucket.org/credomobile/coverage/rules"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
@@ -13,21 +13,23 @@ import (
 
 func TestNewDbClientFactory(t *testing.T) {
 	logger := zerolog.New(os.Stdout).With().Logger()
-	dbClientFactory, err := NewDbClientFactory("abc/fakeDyanamoDbArn", &logger)
+	dbClientFactory, err := NewDbClientFactory(NewMemoryStore(), rules.Default(), &logger)
 
 	assert.NoError(t, err)
 	assert.Implements(t, (*ClientFactory)(nil), dbClientFactory)
 }
 
-func TestNewDbClientFactoryForInvalidDynamodbArn(t *testing.T) {
+func TestNewDbClientFactoryWithMissingCarrierRule(t *testing.T) {
 	logger := zerolog.New(os.Stdout).With().Logger()
-	_, err := NewDbClientFactory("fakeDyanamoDbArn", &logger)
+	ruleSet := rules.Default()
+	delete(ruleSet.Carriers, "verizon")
+	_, err := NewDbClientFactory(NewMemoryStore(), ruleSet, &logger)
 
 	assert.Error(t, err)
 }
 
 func TestGetDbClientForSprint(t *testing.T) {
-	dbClientFactory := clientFactoryImpl{tableName: aws.String("fakeCoverage"), connection: &fakeDynamoDB{}}
+	dbClientFactory := clientFactoryImpl{store: NewMemoryStore(), ruleSet: rules.Default()}
 
 	//Sprint Db Client
 	dbClient, err := dbClientFactory.GetDbClient(entity.CarrierType("1"))
@@ -36,7 +38,7 @@ func TestGetDbClientForSprint(t *testing.T) {
 }
 
 func TestGetDbClientForVerizon(t *testing.T) {
-	dbClientFactory := clientFactoryImpl{tableName: aws.String("fakeCoverage"), connection: &fakeDynamoDB{}}
+	dbClientFactory := clientFactoryImpl{store: NewMemoryStore(), ruleSet: rules.Default()}
 
 	//Verizon Db Client
 	dbClient, err := dbClientFactory.GetDbClient(entity.CarrierType("2"))
@@ -44,8 +46,20 @@ func TestGetDbClientForVerizon(t *testing.T) {
 	assert.IsType(t, verizonDbClient{}, dbClient)
 }
 
+func TestGetDbClientForTMobileAndATT(t *testing.T) {
+	dbClientFactory := clientFactoryImpl{store: NewMemoryStore(), ruleSet: rules.Default()}
+
+	dbClient, err := dbClientFactory.GetDbClient(entity.CarrierType("3"))
+	assert.NoError(t, err)
+	assert.IsType(t, tmobileDbClient{}, dbClient)
+
+	dbClient, err = dbClientFactory.GetDbClient(entity.CarrierType("4"))
+	assert.NoError(t, err)
+	assert.IsType(t, attDbClient{}, dbClient)
+}
+
 func TestGetDbClientForInvalidCarrierID(t *testing.T) {
-	dbClientFactory := clientFactoryImpl{tableName: aws.String("fakeCoverage"), connection: &fakeDynamoDB{}}
+	dbClientFactory := clientFactoryImpl{store: NewMemoryStore(), ruleSet: rules.Default()}
 
 	//Verizon Db Client
 	dbClient, err := dbClientFactory.GetDbClient(entity.CarrierType("5"))
@@ -60,3 +74,20 @@ type fakeDynamoDB struct {
 	err             error
 	t               *testing.T
 }
+
+func TestCarriers(t *testing.T) {
+	dbClientFactory := clientFactoryImpl{store: NewMemoryStore(), ruleSet: rules.Default()}
+
+	for _, carrier := range dbClientFactory.Carriers() {
+		dbClient, err := dbClientFactory.GetDbClient(carrier)
+		assert.NoError(t, err)
+		assert.NotNil(t, dbClient)
+	}
+	assert.Equal(t, []entity.CarrierType{entity.Sprint, entity.Verizon, entity.TMobile, entity.ATT}, dbClientFactory.Carriers())
+}
+
+func TestRuleVersion(t *testing.T) {
+	dbClientFactory := clientFactoryImpl{store: NewMemoryStore(), ruleSet: rules.Default()}
+
+	assert.Equal(t, "default-1", dbClientFactory.RuleVersion())
+}
@@ -0,0 +1,109 @@
+package dbclient
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+)
+
+const (
+	defaultConnectTimeout = 2 * time.Second
+	defaultRequestTimeout = 5 * time.Second
+)
+
+// ConnectionConfig configures the connection to the coverage table. The region defaults to the one in the
+// table ARN and credentials come from the default AWS credential chain.
+type ConnectionConfig struct {
+	// DynamoDBARN is the table ARN, e.g. arn:aws:dynamodb:us-east-2:674346455231:table/coverage
+	DynamoDBARN string
+	// Region overrides the region of the table ARN
+	Region string
+	// Endpoint overrides the DynamoDB endpoint, e.g. http://localhost:8000 for DynamoDB local
+	Endpoint string
+	// ConnectTimeout bounds dialing DynamoDB, RequestTimeout bounds each request including reading the response
+	ConnectTimeout time.Duration
+	RequestTimeout time.Duration
+}
+
+// Connection is a DynamoDB connection to the coverage table, shared by the db clients and services
+type Connection struct {
+	TableName *string
+	DynamoDB  dynamodbiface.DynamoDBAPI
+}
+
+// NewConnection builds the aws session and DynamoDB connection described by the config
+func NewConnection(config ConnectionConfig) (Connection, error) {
+	region, tableName, err := ParseTableARN(config.DynamoDBARN)
+	if err != nil {
+		return Connection{}, err
+	}
+	if config.Region != "" {
+		region = config.Region
+	}
+
+	connectTimeout := config.ConnectTimeout
+	if connectTimeout <= 0 {
+		connectTimeout = defaultConnectTimeout
+	}
+	requestTimeout := config.RequestTimeout
+	if requestTimeout <= 0 {
+		requestTimeout = defaultRequestTimeout
+	}
+
+	awsConfig := &aws.Config{
+		HTTPClient: &http.Client{
+			Timeout: requestTimeout,
+			Transport: &http.Transport{
+				Proxy:               http.ProxyFromEnvironment,
+				DialContext:         (&net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}).DialContext,
+				TLSHandshakeTimeout: connectTimeout,
+				MaxIdleConnsPerHost: 16,
+				IdleConnTimeout:     90 * time.Second,
+			},
+		},
+	}
+	if region != "" {
+		awsConfig.Region = aws.String(region)
+	}
+	if config.Endpoint != "" {
+		awsConfig.Endpoint = aws.String(config.Endpoint)
+	}
+
+	awsSession, err := session.NewSession(awsConfig)
+	if err != nil {
+		return Connection{}, err
+	}
+
+	client := dynamodb.New(awsSession)
+	client.Handlers.Validate.PushFrontNamed(request.NamedHandler{Name: "coverage.tracing.start", Fn: startDynamoDBSpan})
+	client.Handlers.Complete.PushBackNamed(request.NamedHandler{Name: "coverage.metrics", Fn: observeDynamoDB})
+	client.Handlers.Complete.PushBackNamed(request.NamedHandler{Name: "coverage.tracing.end", Fn: endDynamoDBSpan})
+
+	return Connection{
+		TableName: aws.String(tableName),
+		DynamoDB:  dynamodbiface.DynamoDBAPI(client),
+	}, nil
+}
+
+// ParseTableARN gives back the region and table name of a DynamoDB table ARN such as
+// arn:aws:dynamodb:us-east-2:674346455231:table/coverage. The region is empty when the ARN doesn't carry one.
+func ParseTableARN(dynamodbARN string) (string, string, error) {
+	resource := strings.Split(dynamodbARN, "/")
+	if len(resource) < 2 || resource[1] == "" {
+		return "", "", errors.New("Invalid dynamodbARN")
+	}
+
+	region := ""
+	if parts := strings.Split(resource[0], ":"); len(parts) == 6 && parts[0] == "arn" && parts[2] == "dynamodb" {
+		region = parts[3]
+	}
+	return region, resource[1], nil
+}
@@ -0,0 +1,79 @@
+package dbclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTableARN(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		arn            string
+		expectedRegion string
+		expectedTable  string
+		expectError    bool
+	}{
+		{
+			desc:           "full table arn",
+			arn:            "arn:aws:dynamodb:us-west-2:674346455231:table/coverage",
+			expectedRegion: "us-west-2",
+			expectedTable:  "coverage",
+		},
+		{
+			desc:          "table arn without a region",
+			arn:           "abc/coverage",
+			expectedTable: "coverage",
+		},
+		{
+			desc:        "arn without a table",
+			arn:         "arn:aws:dynamodb:us-west-2:674346455231:table/",
+			expectError: true,
+		},
+		{
+			desc:        "not an arn",
+			arn:         "coverage",
+			expectError: true,
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			region, table, err := ParseTableARN(tC.arn)
+
+			if tC.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tC.expectedRegion, region)
+			assert.Equal(t, tC.expectedTable, table)
+		})
+	}
+}
+
+func TestNewConnection(t *testing.T) {
+	connection, err := NewConnection(ConnectionConfig{
+		DynamoDBARN:    "arn:aws:dynamodb:us-west-2:674346455231:table/coverage",
+		Endpoint:       "http://localhost:8000",
+		RequestTimeout: time.Second,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "coverage", *connection.TableName)
+	client := connection.DynamoDB.(*dynamodb.DynamoDB)
+	assert.Equal(t, "us-west-2", *client.Config.Region)
+	assert.Equal(t, "http://localhost:8000", client.Endpoint)
+	assert.Equal(t, time.Second, client.Config.HTTPClient.Timeout)
+
+	connection, err = NewConnection(ConnectionConfig{DynamoDBARN: "arn:aws:dynamodb:us-west-2:674346455231:table/coverage", Region: "us-east-1"})
+	assert.NoError(t, err)
+	client = connection.DynamoDB.(*dynamodb.DynamoDB)
+	assert.Equal(t, "us-east-1", *client.Config.Region)
+	assert.Equal(t, defaultRequestTimeout, client.Config.HTTPClient.Timeout)
+
+	_, err = NewConnection(ConnectionConfig{DynamoDBARN: "fakeDyanamoDbArn"})
+	assert.Error(t, err)
+}
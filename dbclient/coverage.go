@@ -0,0 +1,117 @@
+package dbclient
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strconv"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
+	"github.com/rs/zerolog"
+)
+
+// Coverage is a carrier's coverage of a zipcode along with its breakdown per technology.
+// Found is false when the carrier has no coverage data for the zipcode. LoadDate is the load date of the dataset
+// the answer came from, the one the zipcode is missing from when it isn't found.
+type Coverage struct {
+	IsCovered bool
+	Found     bool
+	LoadDate  string
+	Detail    entity.CoverageDetail
+}
+
+// technology is a radio technology reported in a carrier's coverage data. The coverage score is the
+// weighted average of the technologies' percentages.
+type technology struct {
+	name                string
+	percentageAttribute string
+	indicatorAttribute  string
+	weight              float64
+}
+
+// coverageAttributes lists the given attributes followed by the ones the coverage rule and technologies read and
+// the load date
+func coverageAttributes(rule rules.Rule, technologies []technology, attributes ...string) []string {
+	attributes = append(attributes, rule.Attributes()...)
+	for _, t := range technologies {
+		attributes = append(attributes, t.percentageAttribute)
+		if t.indicatorAttribute != "" {
+			attributes = append(attributes, t.indicatorAttribute)
+		}
+	}
+	attributes = append(attributes, "load_date")
+
+	seen := map[string]bool{}
+	var names []string
+	for _, attribute := range attributes {
+		if seen[attribute] {
+			continue
+		}
+		seen[attribute] = true
+		names = append(names, attribute)
+	}
+	return names
+}
+
+// evaluateCoverage applies a carrier's coverage rule to its coverage data and breaks it down per technology.
+// Data that can't be evaluated, such as a non numeric percentage, is logged and treated as not covered.
+func evaluateCoverage(ctx context.Context, rule rules.Rule, technologies []technology, zipCode string, data interface{}) Coverage {
+	attributes := attributesOf(data)
+	coverage := Coverage{Found: true, LoadDate: attributes["load_date"], Detail: coverageDetail(technologies, attributes)}
+
+	covered, err := rule.Evaluate(attributes)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msgf("Illegal coverage data for zipcode: %s", zipCode)
+		return coverage
+	}
+	if !covered {
+		zerolog.Ctx(ctx).Debug().Msgf("zipcode: %s not covered by coverage rule", zipCode)
+	}
+	coverage.IsCovered = covered
+	return coverage
+}
+
+// unknownCoverage gives back the coverage of a zipcode the carrier's coverage data doesn't have, dated with the load
+// of the live dataset it is missing from. The load date is left out when it can't be read, the answer stands without.
+func unknownCoverage(ctx context.Context, store Store, sortKey string) Coverage {
+	meta, _, err := GetLoadMeta(ctx, store, sortKey)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msgf("failed to read the load date of %s", sortKey)
+	}
+	return Coverage{LoadDate: meta.LoadDate}
+}
+
+// coverageDetail reports the percentage and indicator of every technology and a 0-100 coverage score.
+// A missing, non numeric or non finite percentage counts as no coverage in the score.
+func coverageDetail(technologies []technology, attributes map[string]string) entity.CoverageDetail {
+	detail := entity.CoverageDetail{}
+	var weightedSum, totalWeight float64
+	for _, t := range technologies {
+		technologyCoverage := entity.TechnologyCoverage{Technology: t.name, Indicator: attributes[t.indicatorAttribute]}
+		percentage, err := strconv.ParseFloat(attributes[t.percentageAttribute], 64)
+		if err == nil && !math.IsNaN(percentage) && !math.IsInf(percentage, 0) {
+			percentage = math.Max(0, math.Min(100, percentage))
+			technologyCoverage.Percentage = &percentage
+			weightedSum += percentage * t.weight
+		}
+		totalWeight += t.weight
+		detail.Technologies = append(detail.Technologies, technologyCoverage)
+	}
+
+	if totalWeight > 0 {
+		detail.Score = math.Round(weightedSum/totalWeight*10) / 10
+	}
+	return detail
+}
+
+// attributesOf flattens a carrier's coverage data struct into its dynamodb attributes
+func attributesOf(data interface{}) map[string]string {
+	attributes := map[string]string{}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return attributes
+	}
+	json.Unmarshal(raw, &attributes)
+	return attributes
+}
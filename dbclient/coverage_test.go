@@ -0,0 +1,95 @@
+package dbclient
+
+import (
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoverageDetail(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		attributes     map[string]string
+		expectedDetail entity.CoverageDetail
+	}{
+		{
+			desc: "every technology reported",
+			attributes: map[string]string{
+				"vzelte": "78", "vze_lte_ind": "Y",
+				"vzwevdo": "92", "vzw_evdo_ind": "Y",
+				"vzwvoiceor1x": "100", "vzw_voice_or_1x_ind": "Y",
+			},
+			expectedDetail: entity.CoverageDetail{
+				Score: 85.2,
+				Technologies: []entity.TechnologyCoverage{
+					{Technology: "LTE", Percentage: percentage(78), Indicator: "Y"},
+					{Technology: "EVDO", Percentage: percentage(92), Indicator: "Y"},
+					{Technology: "Voice/1X", Percentage: percentage(100), Indicator: "Y"},
+				},
+			},
+		},
+		{
+			desc: "missing and illegal percentages count as no coverage",
+			attributes: map[string]string{
+				"vzelte": "100", "vze_lte_ind": "Y",
+				"vzwevdo": "abc", "vzw_evdo_ind": "N",
+			},
+			expectedDetail: entity.CoverageDetail{
+				Score: 60,
+				Technologies: []entity.TechnologyCoverage{
+					{Technology: "LTE", Percentage: percentage(100), Indicator: "Y"},
+					{Technology: "EVDO", Indicator: "N"},
+					{Technology: "Voice/1X"},
+				},
+			},
+		},
+		{
+			desc: "non finite percentages count as no coverage",
+			attributes: map[string]string{
+				"vzelte": "NaN", "vzwevdo": "Inf", "vzwvoiceor1x": "100",
+			},
+			expectedDetail: entity.CoverageDetail{
+				Score: 20,
+				Technologies: []entity.TechnologyCoverage{
+					{Technology: "LTE"},
+					{Technology: "EVDO"},
+					{Technology: "Voice/1X", Percentage: percentage(100)},
+				},
+			},
+		},
+		{
+			desc: "percentages are clamped to 0-100",
+			attributes: map[string]string{
+				"vzelte": "100.0001", "vzwevdo": "-3", "vzwvoiceor1x": "100",
+			},
+			expectedDetail: entity.CoverageDetail{
+				Score: 80,
+				Technologies: []entity.TechnologyCoverage{
+					{Technology: "LTE", Percentage: percentage(100)},
+					{Technology: "EVDO", Percentage: percentage(0)},
+					{Technology: "Voice/1X", Percentage: percentage(100)},
+				},
+			},
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Equal(t, tC.expectedDetail, coverageDetail(verizonTechnologies, tC.attributes))
+		})
+	}
+}
+
+func TestAttributesOf(t *testing.T) {
+	data := sprintCoverageData{ZipCode: "94105", CsaLeaf: "SFRSFR415", Lte2500PctCov: "12.5"}
+	attributes := attributesOf(data)
+
+	assert.Equal(t, "94105", attributes["zipcode"])
+	assert.Equal(t, "SFRSFR415", attributes["csa_leaf"])
+	assert.Equal(t, "12.5", attributes["lte_2500_PctCov"])
+}
+
+func percentage(value float64) *float64 {
+	return &value
+}
@@ -0,0 +1,65 @@
+package dbclient
+
+import (
+	"context"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
+	"github.com/rs/zerolog"
+)
+
+// csaIndex is the secondary index of the coverage table keyed by Sprint's csa_leaf and zipcode. Only Sprint's
+// coverage items have a csa_leaf, so the index holds nothing else.
+const csaIndex = "csa_leaf-zipcode-index"
+
+// CsaZipCodes is a page of the zipcodes of a CSA. NextToken continues the listing and is empty on the last page.
+type CsaZipCodes struct {
+	ZipCodes  []entity.CsaZipCode
+	NextToken string
+}
+
+// CsaZipCodeClient lists the zipcodes of a Sprint CSA
+type CsaZipCodeClient interface {
+	ListZipCodes(ctx context.Context, csa string, limit int, token string) (CsaZipCodes, error)
+}
+
+type csaZipCodeClient struct {
+	store Store
+}
+
+// NewCsaZipCodeClient constructs and gives back a client listing the zipcodes of a CSA through the csa index
+func NewCsaZipCodeClient(store Store) CsaZipCodeClient {
+	return csaZipCodeClient{store: store}
+}
+
+// ListZipCodes gives back a page of at most limit zipcodes of the CSA, ordered by zipcode, along with their state,
+// city and coverage percentages. ErrInvalidToken is given back for a token that isn't from a listing of the CSA.
+func (c csaZipCodeClient) ListZipCodes(ctx context.Context, csa string, limit int, token string) (CsaZipCodes, error) {
+	zerolog.Ctx(ctx).Info().Msgf("*** IN CSA ZIPCODE CLIENT ListZipCodes() for csa %s ***", csa)
+
+	// listing zipcodes doesn't evaluate coverage, so it doesn't need a coverage rule
+	page, err := c.store.Query(ctx, IndexQuery{
+		Index:      csaIndex,
+		HashKey:    "csa_leaf",
+		Value:      csa,
+		Attributes: coverageAttributes(rules.Rule{}, sprintTechnologies, "zipcode", "carriertype", "csa_leaf", "state", "zip_postal_city"),
+		Limit:      limit,
+		Token:      token,
+	})
+	if err != nil {
+		return CsaZipCodes{}, err
+	}
+
+	zipCodes := CsaZipCodes{ZipCodes: make([]entity.CsaZipCode, 0, len(page.Items)), NextToken: page.NextToken}
+	for _, item := range page.Items {
+		detail := coverageDetail(sprintTechnologies, item)
+		zipCodes.ZipCodes = append(zipCodes.ZipCodes, entity.CsaZipCode{
+			ZipCode:      item["zipcode"],
+			State:        item["state"],
+			City:         item["zip_postal_city"],
+			Score:        detail.Score,
+			Technologies: detail.Technologies,
+		})
+	}
+	return zipCodes, nil
+}
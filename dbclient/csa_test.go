@@ -0,0 +1,85 @@
+package dbclient
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListCsaZipCodes(t *testing.T) {
+	store := NewMemoryStore()
+	for i := 5; i > 0; i-- {
+		store.Put(Item{
+			"zipcode":         fmt.Sprintf("8500%d", i),
+			"carriertype":     "sprint",
+			"csa_leaf":        "PHXTUC520",
+			"state":           "AZ",
+			"zip_postal_city": "Phoenix",
+			"cur_pct_cov":     "100",
+			"lte_4g_pctcov":   "80",
+		})
+	}
+	store.Put(
+		Item{"zipcode": "94105", "carriertype": "sprint", "csa_leaf": "SFRSFR415"},
+		Item{"zipcode": "85001", "carriertype": "verizon", "state": "AZ"},
+	)
+	client := NewCsaZipCodeClient(store)
+
+	page, err := client.ListZipCodes(context.Background(), "PHXTUC520", 2, "")
+	assert.NoError(t, err)
+	assert.Len(t, page.ZipCodes, 2)
+	assert.Equal(t, "85001", page.ZipCodes[0].ZipCode)
+	assert.Equal(t, "AZ", page.ZipCodes[0].State)
+	assert.Equal(t, "Phoenix", page.ZipCodes[0].City)
+	assert.Equal(t, 60.0, page.ZipCodes[0].Score)
+	assert.Equal(t, "LTE", page.ZipCodes[0].Technologies[0].Technology)
+	assert.Equal(t, 80.0, *page.ZipCodes[0].Technologies[0].Percentage)
+	assert.NotEmpty(t, page.NextToken)
+
+	var zipCodes []string
+	for _, zipCode := range page.ZipCodes {
+		zipCodes = append(zipCodes, zipCode.ZipCode)
+	}
+	for page.NextToken != "" {
+		page, err = client.ListZipCodes(context.Background(), "PHXTUC520", 2, page.NextToken)
+		assert.NoError(t, err)
+		for _, zipCode := range page.ZipCodes {
+			zipCodes = append(zipCodes, zipCode.ZipCode)
+		}
+	}
+	assert.Equal(t, []string{"85001", "85002", "85003", "85004", "85005"}, zipCodes)
+
+	page, err = client.ListZipCodes(context.Background(), "NOSUCHCSA", 2, "")
+	assert.NoError(t, err)
+	assert.Equal(t, CsaZipCodes{ZipCodes: []entity.CsaZipCode{}}, page)
+}
+
+func TestListCsaZipCodesWithInvalidToken(t *testing.T) {
+	store := NewMemoryStore()
+	store.Put(
+		Item{"zipcode": "85001", "carriertype": "sprint", "csa_leaf": "PHXTUC520"},
+		Item{"zipcode": "85002", "carriertype": "sprint", "csa_leaf": "PHXTUC520"},
+	)
+	client := NewCsaZipCodeClient(store)
+
+	page, err := client.ListZipCodes(context.Background(), "PHXTUC520", 1, "")
+	assert.NoError(t, err)
+
+	for _, tC := range []struct {
+		desc  string
+		csa   string
+		token string
+	}{
+		{desc: "not base64", csa: "PHXTUC520", token: "%%%"},
+		{desc: "not json", csa: "PHXTUC520", token: "bm90IGpzb24"},
+		{desc: "token of another csa", csa: "SFRSFR415", token: page.NextToken},
+	} {
+		t.Run(tC.desc, func(t *testing.T) {
+			_, err := client.ListZipCodes(context.Background(), tC.csa, 1, tC.token)
+			assert.Equal(t, ErrInvalidToken, err)
+		})
+	}
+}
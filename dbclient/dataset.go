@@ -0,0 +1,449 @@
+package dbclient
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/rs/zerolog"
+)
+
+// livePointerZipCode is the zipcode of the pointer item naming the live dataset version of every carrier. It can't
+// clash with a real zipcode, which is always 5 digits.
+const livePointerZipCode = "_live"
+
+// versionSeparator joins a carrier's sort key and a dataset version into the sort key of the version's items,
+// e.g. verizon#2018-11-01
+const versionSeparator = "#"
+
+var (
+	// ErrUnknownVersion is given back for a dataset version that was never completely loaded or was collected
+	ErrUnknownVersion = errors.New("unknown dataset version")
+	// ErrNoPreviousVersion is given back when rolling back a carrier without a previous dataset version
+	ErrNoPreviousVersion = errors.New("no previous dataset version to roll back to")
+	// ErrLiveVersion is given back for loading into the live dataset version, which would change it in place
+	ErrLiveVersion = errors.New("dataset version is live")
+	// ErrVersionLoaded is given back for loading into a dataset version that was already loaded, whose rows the
+	// load would only partly replace
+	ErrVersionLoaded = errors.New("dataset version is already loaded")
+	// ErrConcurrentPromotion is given back when the live version changed while it was being switched
+	ErrConcurrentPromotion = errors.New("live dataset version changed concurrently")
+)
+
+// versionedSortKey gives back the sort key of the items of a carrier's dataset version
+func versionedSortKey(sortKey string, version string) string {
+	return sortKey + versionSeparator + version
+}
+
+// DatasetPointer names a carrier's live dataset version and the version that was live before it, which is empty
+// when there was none
+type DatasetPointer struct {
+	Version  string
+	Previous string
+}
+
+// GetDatasetPointer reads a carrier's live dataset pointer. found is false when no version was ever promoted, in
+// which case its coverage data is read unversioned.
+func GetDatasetPointer(ctx context.Context, store Store, sortKey string) (DatasetPointer, bool, error) {
+	return getDatasetPointer(ctx, store, sortKey)
+}
+
+// itemGetter reads an item by its key, the part of a Store the dataset pointer is read through
+type itemGetter interface {
+	Get(ctx context.Context, zipCode string, sortKey string, attributes []string) (Item, error)
+}
+
+func getDatasetPointer(ctx context.Context, getter itemGetter, sortKey string) (DatasetPointer, bool, error) {
+	item, err := getter.Get(ctx, livePointerZipCode, sortKey, []string{"zipcode", "version", "previous"})
+	if err != nil || item == nil {
+		return DatasetPointer{}, false, err
+	}
+	return DatasetPointer{Version: item["version"], Previous: item["previous"]}, true, nil
+}
+
+// DatasetVersion is a completely loaded dataset version of a carrier
+type DatasetVersion struct {
+	Version  string
+	Rows     int
+	Live     bool
+	Previous bool
+}
+
+// Datasets manages the dataset versions of the carriers' coverage data. A load writes a new version, Promote
+// switches the live pointer onto it in one conditional write, Rollback switches it back onto the previous version and
+// Collect deletes the versions no longer needed. Items reads every coverage item of a version, e.g. to compare it
+// with the live one before promoting it.
+type Datasets interface {
+	Versions(ctx context.Context, carrier entity.CarrierType) ([]DatasetVersion, error)
+	Promote(ctx context.Context, carrier entity.CarrierType, version string) error
+	Rollback(ctx context.Context, carrier entity.CarrierType) (string, error)
+	Collect(ctx context.Context, carrier entity.CarrierType, keep int) ([]string, error)
+	Items(ctx context.Context, carrier entity.CarrierType, version string) ([]Item, error)
+}
+
+// datasetStorage is what the dataset versions are managed through. putPointer only writes the pointer when the
+// live version is still current, or when there is no pointer yet for an empty current.
+type datasetStorage interface {
+	itemGetter
+	loadMetaItems(ctx context.Context, sortKey string) ([]Item, error)
+	putPointer(ctx context.Context, pointer Item, current string) error
+	deleteVersion(ctx context.Context, sortKey string) (int, error)
+	versionItems(ctx context.Context, sortKey string) ([]Item, error)
+}
+
+type datasets struct {
+	storage datasetStorage
+}
+
+// NewDatasets constructs and gives back the dataset versions of the given DynamoDB coverage table
+func NewDatasets(tableName *string, connection dynamodbiface.DynamoDBAPI) Datasets {
+	return datasets{storage: dynamoDatasetStorage{dynamoStore: dynamoStore{tableName: tableName, connection: connection}}}
+}
+
+// Versions lists a carrier's completely loaded dataset versions, newest first
+func (d datasets) Versions(ctx context.Context, carrierID entity.CarrierType) ([]DatasetVersion, error) {
+	carrier, ok := LookupCarrier(carrierID)
+	if !ok {
+		return nil, ErrUnknownCarrier
+	}
+	pointer, _, err := d.pointer(ctx, carrier.SortKey)
+	if err != nil {
+		return nil, err
+	}
+	items, err := d.storage.loadMetaItems(ctx, carrier.SortKey)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]DatasetVersion, 0, len(items))
+	for _, item := range items {
+		version := strings.TrimPrefix(item["carriertype"], carrier.SortKey+versionSeparator)
+		rows, _ := strconv.Atoi(item["rows"])
+		versions = append(versions, DatasetVersion{
+			Version:  version,
+			Rows:     rows,
+			Live:     version == pointer.Version,
+			Previous: version == pointer.Previous,
+		})
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version > versions[j].Version })
+	return versions, nil
+}
+
+// Promote makes a completely loaded dataset version live, keeping the version it replaces as the previous one
+func (d datasets) Promote(ctx context.Context, carrierID entity.CarrierType, version string) error {
+	carrier, ok := LookupCarrier(carrierID)
+	if !ok {
+		return ErrUnknownCarrier
+	}
+	if err := d.checkVersion(ctx, carrier.SortKey, version); err != nil {
+		return err
+	}
+	pointer, _, err := d.pointer(ctx, carrier.SortKey)
+	if err != nil {
+		return err
+	}
+	if pointer.Version == version {
+		return nil
+	}
+
+	zerolog.Ctx(ctx).Info().Msgf("promoting %s dataset version %s over %q", carrier.SortKey, version, pointer.Version)
+	return d.storage.putPointer(ctx, pointerItem(carrier.SortKey, version, pointer.Version), pointer.Version)
+}
+
+// Rollback makes the previous dataset version live again and gives it back. The version rolled back from becomes
+// the previous one, so a rollback can be undone with another.
+func (d datasets) Rollback(ctx context.Context, carrierID entity.CarrierType) (string, error) {
+	carrier, ok := LookupCarrier(carrierID)
+	if !ok {
+		return "", ErrUnknownCarrier
+	}
+	pointer, _, err := d.pointer(ctx, carrier.SortKey)
+	if err != nil {
+		return "", err
+	}
+	if pointer.Previous == "" {
+		return "", ErrNoPreviousVersion
+	}
+	if err := d.checkVersion(ctx, carrier.SortKey, pointer.Previous); err != nil {
+		return "", err
+	}
+
+	zerolog.Ctx(ctx).Info().Msgf("rolling %s dataset back from version %s to %s", carrier.SortKey, pointer.Version, pointer.Previous)
+	if err := d.storage.putPointer(ctx, pointerItem(carrier.SortKey, pointer.Previous, pointer.Version), pointer.Version); err != nil {
+		return "", err
+	}
+	return pointer.Previous, nil
+}
+
+// Collect deletes a carrier's dataset versions other than the live one, the previous one and the keep newest of the
+// rest, and gives back the versions deleted. A version's meta item goes first, so it can't be promoted half deleted.
+func (d datasets) Collect(ctx context.Context, carrierID entity.CarrierType, keep int) ([]string, error) {
+	carrier, ok := LookupCarrier(carrierID)
+	if !ok {
+		return nil, ErrUnknownCarrier
+	}
+	versions, err := d.Versions(ctx, carrierID)
+	if err != nil {
+		return nil, err
+	}
+
+	var collected []string
+	kept := 0
+	for _, version := range versions {
+		if version.Live || version.Previous {
+			continue
+		}
+		if kept < keep {
+			kept++
+			continue
+		}
+
+		deleted, err := d.storage.deleteVersion(ctx, versionedSortKey(carrier.SortKey, version.Version))
+		if err != nil {
+			return collected, err
+		}
+		zerolog.Ctx(ctx).Info().Msgf("collected %s dataset version %s, deleted %d items", carrier.SortKey, version.Version, deleted)
+		collected = append(collected, version.Version)
+	}
+	return collected, nil
+}
+
+// Items gives back the coverage items of a completely loaded dataset version, leaving out its meta and summary items.
+// Their carriertype is the carrier's sort key.
+func (d datasets) Items(ctx context.Context, carrierID entity.CarrierType, version string) ([]Item, error) {
+	carrier, ok := LookupCarrier(carrierID)
+	if !ok {
+		return nil, ErrUnknownCarrier
+	}
+	if err := d.checkVersion(ctx, carrier.SortKey, version); err != nil {
+		return nil, err
+	}
+	items, err := d.storage.versionItems(ctx, versionedSortKey(carrier.SortKey, version))
+	if err != nil {
+		return nil, err
+	}
+
+	coverageItems := make([]Item, 0, len(items))
+	for _, item := range items {
+		// the meta, summary and pointer items have zipcodes starting with an underscore
+		if strings.HasPrefix(item["zipcode"], "_") {
+			continue
+		}
+		coverageItems = append(coverageItems, unversioned(item, carrier.SortKey))
+	}
+	sort.Slice(coverageItems, func(i, j int) bool { return coverageItems[i]["zipcode"] < coverageItems[j]["zipcode"] })
+	return coverageItems, nil
+}
+
+// pointer reads a carrier's live dataset pointer from the storage
+func (d datasets) pointer(ctx context.Context, sortKey string) (DatasetPointer, bool, error) {
+	return getDatasetPointer(ctx, d.storage, sortKey)
+}
+
+// checkVersion gives back ErrUnknownVersion unless the version's load finished by writing its meta item
+func (d datasets) checkVersion(ctx context.Context, sortKey string, version string) error {
+	item, err := d.storage.Get(ctx, loadMetaZipCode, versionedSortKey(sortKey, version), []string{"zipcode"})
+	if err != nil {
+		return err
+	}
+	if item == nil {
+		return ErrUnknownVersion
+	}
+	return nil
+}
+
+// pointerItem gives back the pointer item making a carrier's version live
+func pointerItem(sortKey string, version string, previous string) Item {
+	return Item{
+		"zipcode":     livePointerZipCode,
+		"carriertype": sortKey,
+		"version":     version,
+		"previous":    previous,
+		"promoted_at": time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// dynamoDatasetStorage manages the dataset versions of the DynamoDB coverage table
+type dynamoDatasetStorage struct {
+	dynamoStore
+}
+
+// loadMetaItems queries the meta items of every version of a carrier, which share the _load zipcode
+func (d dynamoDatasetStorage) loadMetaItems(ctx context.Context, sortKey string) ([]Item, error) {
+	keyCondition := expression.Key("zipcode").Equal(expression.Value(loadMetaZipCode)).
+		And(expression.Key("carriertype").BeginsWith(sortKey + versionSeparator))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msgf("failed to build expression to query %s dataset versions", sortKey)
+		return nil, err
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 d.tableName,
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}
+	var items []Item
+	err = d.connection.QueryPagesWithContext(ctx, input, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+		for _, attributeValues := range page.Items {
+			items = append(items, itemOf(attributeValues))
+		}
+		return true
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msgf("failed to query %s dataset versions of coverage dynamodb table", sortKey)
+		return nil, err
+	}
+	return items, nil
+}
+
+// putPointer writes the pointer item on condition the live version is still current
+func (d dynamoDatasetStorage) putPointer(ctx context.Context, pointer Item, current string) error {
+	condition := expression.AttributeNotExists(expression.Name("zipcode"))
+	if current != "" {
+		condition = expression.Name("version").Equal(expression.Value(current))
+	}
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("failed to build condition expression of dataset pointer")
+		return err
+	}
+
+	_, err = d.connection.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName:                 d.tableName,
+		Item:                      attributeValues(pointer),
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+		return ErrConcurrentPromotion
+	}
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("failed to write dataset pointer to coverage dynamodb table")
+	}
+	return err
+}
+
+// deleteVersion deletes the meta item of a version and then scans for the rest of its items and deletes them in
+// batches. It gives back how many items were deleted.
+func (d dynamoDatasetStorage) deleteVersion(ctx context.Context, sortKey string) (int, error) {
+	_, err := d.connection.DeleteItemWithContext(ctx, &dynamodb.DeleteItemInput{
+		TableName: d.tableName,
+		Key:       coverageKey(loadMetaZipCode, sortKey),
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msgf("failed to delete %s meta item from coverage dynamodb table", sortKey)
+		return 0, err
+	}
+
+	expr, err := expression.NewBuilder().
+		WithFilter(expression.Name("carriertype").Equal(expression.Value(sortKey))).
+		WithProjection(expression.NamesList(expression.Name("zipcode"), expression.Name("carriertype"))).
+		Build()
+	if err != nil {
+		return 0, err
+	}
+	input := &dynamodb.ScanInput{
+		TableName:                 d.tableName,
+		FilterExpression:          expr.Filter(),
+		ProjectionExpression:      expr.Projection(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}
+
+	deleted := 0
+	var deleteErr error
+	err = d.connection.ScanPagesWithContext(ctx, input, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		for start := 0; start < len(page.Items); start += batchWriteItemLimit {
+			end := start + batchWriteItemLimit
+			if end > len(page.Items) {
+				end = len(page.Items)
+			}
+			if deleteErr = d.batchDelete(ctx, page.Items[start:end]); deleteErr != nil {
+				return false
+			}
+			deleted += end - start
+		}
+		return true
+	})
+	if err == nil {
+		err = deleteErr
+	}
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msgf("failed to delete %s items from coverage dynamodb table", sortKey)
+	}
+	return deleted, err
+}
+
+// versionItems scans for every item of a version. It reads the whole table, so it is meant for reports rather than
+// lookups.
+func (d dynamoDatasetStorage) versionItems(ctx context.Context, sortKey string) ([]Item, error) {
+	expr, err := expression.NewBuilder().
+		WithFilter(expression.Name("carriertype").Equal(expression.Value(sortKey))).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+	input := &dynamodb.ScanInput{
+		TableName:                 d.tableName,
+		FilterExpression:          expr.Filter(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}
+
+	var items []Item
+	err = d.connection.ScanPagesWithContext(ctx, input, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		for _, attributeValues := range page.Items {
+			items = append(items, itemOf(attributeValues))
+		}
+		return true
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msgf("failed to scan %s items of coverage dynamodb table", sortKey)
+		return nil, err
+	}
+	return items, nil
+}
+
+// batchDelete deletes the keys, retrying UnprocessedItems with an exponential backoff
+func (d dynamoDatasetStorage) batchDelete(ctx context.Context, keys []map[string]*dynamodb.AttributeValue) error {
+	requests := make([]*dynamodb.WriteRequest, 0, len(keys))
+	for _, key := range keys {
+		requests = append(requests, &dynamodb.WriteRequest{DeleteRequest: &dynamodb.DeleteRequest{Key: key}})
+	}
+
+	delay := batchRetryBaseDelay
+	for attempt := 0; len(requests) > 0; attempt++ {
+		if attempt > 0 {
+			if attempt > maxBatchWriteRetries {
+				return errors.New("items unprocessed after retries")
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		result, err := d.connection.BatchWriteItemWithContext(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]*dynamodb.WriteRequest{*d.tableName: requests},
+		})
+		if err != nil {
+			return err
+		}
+		requests = result.UnprocessedItems[*d.tableName]
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package dbclient
+
+import (
+	"context"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+)
+
+func loadVersions(t *testing.T, store *MemoryStore, versions ...string) {
+	for _, version := range versions {
+		_, err := store.Load(context.Background(), entity.Sprint, version, []map[string]string{
+			{"ZIP": "94105", "Cur_Pct_Cov": "100"},
+		})
+		assert.NoError(t, err)
+	}
+}
+
+func TestPromoteAndRollback(t *testing.T) {
+	store := NewMemoryStore()
+	loadVersions(t, store, "2018-10-01", "2018-11-01")
+	datasets := store.Datasets()
+
+	_, found, err := GetDatasetPointer(context.Background(), store, "sprint")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	assert.NoError(t, datasets.Promote(context.Background(), entity.Sprint, "2018-10-01"))
+	assert.NoError(t, datasets.Promote(context.Background(), entity.Sprint, "2018-11-01"))
+	assert.NoError(t, datasets.Promote(context.Background(), entity.Sprint, "2018-11-01"))
+	pointer, found, err := GetDatasetPointer(context.Background(), store, "sprint")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, DatasetPointer{Version: "2018-11-01", Previous: "2018-10-01"}, pointer)
+
+	version, err := datasets.Rollback(context.Background(), entity.Sprint)
+	assert.NoError(t, err)
+	assert.Equal(t, "2018-10-01", version)
+	pointer, _, _ = GetDatasetPointer(context.Background(), store, "sprint")
+	assert.Equal(t, DatasetPointer{Version: "2018-10-01", Previous: "2018-11-01"}, pointer)
+
+	// a second rollback undoes the first
+	version, err = datasets.Rollback(context.Background(), entity.Sprint)
+	assert.NoError(t, err)
+	assert.Equal(t, "2018-11-01", version)
+
+	assert.Equal(t, ErrUnknownVersion, datasets.Promote(context.Background(), entity.Sprint, "2018-12-01"))
+	assert.EqualError(t, datasets.Promote(context.Background(), entity.CarrierType("9"), "2018-11-01"), "Invalid Carrier Type")
+}
+
+func TestRollbackWithoutPreviousVersion(t *testing.T) {
+	store := NewMemoryStore()
+	loadVersions(t, store, "2018-11-01")
+	datasets := store.Datasets()
+
+	_, err := datasets.Rollback(context.Background(), entity.Sprint)
+	assert.Equal(t, ErrNoPreviousVersion, err)
+
+	assert.NoError(t, datasets.Promote(context.Background(), entity.Sprint, "2018-11-01"))
+	_, err = datasets.Rollback(context.Background(), entity.Sprint)
+	assert.Equal(t, ErrNoPreviousVersion, err)
+}
+
+func TestVersionsAndCollect(t *testing.T) {
+	store := NewMemoryStore()
+	loadVersions(t, store, "2018-08-01", "2018-09-01", "2018-10-01", "2018-11-01", "2018-12-01")
+	datasets := store.Datasets()
+	assert.NoError(t, datasets.Promote(context.Background(), entity.Sprint, "2018-09-01"))
+	assert.NoError(t, datasets.Promote(context.Background(), entity.Sprint, "2018-11-01"))
+
+	versions, err := datasets.Versions(context.Background(), entity.Sprint)
+	assert.NoError(t, err)
+	assert.Equal(t, []DatasetVersion{
+		{Version: "2018-12-01", Rows: 1},
+		{Version: "2018-11-01", Rows: 1, Live: true},
+		{Version: "2018-10-01", Rows: 1},
+		{Version: "2018-09-01", Rows: 1, Previous: true},
+		{Version: "2018-08-01", Rows: 1},
+	}, versions)
+
+	collected, err := datasets.Collect(context.Background(), entity.Sprint, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2018-10-01", "2018-08-01"}, collected)
+
+	item, err := store.Get(context.Background(), "94105", "sprint#2018-10-01", []string{"zipcode"})
+	assert.NoError(t, err)
+	assert.Nil(t, item)
+	assert.Equal(t, ErrUnknownVersion, datasets.Promote(context.Background(), entity.Sprint, "2018-10-01"))
+
+	versions, err = datasets.Versions(context.Background(), entity.Sprint)
+	assert.NoError(t, err)
+	assert.Len(t, versions, 3)
+
+	collected, err = datasets.Collect(context.Background(), entity.Sprint, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2018-12-01"}, collected)
+}
+
+func TestDatasetItems(t *testing.T) {
+	store := NewMemoryStore()
+	_, err := store.Load(context.Background(), entity.Verizon, "2018-11-01", []map[string]string{
+		{"zip": "94106", "State": "CA", "VZW_LTE": "90"},
+		{"zip": "94105", "State": "CA", "VZW_LTE": "100"},
+	})
+	assert.NoError(t, err)
+
+	items, err := store.Datasets().Items(context.Background(), entity.Verizon, "2018-11-01")
+	assert.NoError(t, err)
+	assert.Equal(t, []Item{
+		{"zipcode": "94105", "carriertype": "verizon", "state": "CA", "vzelte": "100", "load_date": "2018-11-01"},
+		{"zipcode": "94106", "carriertype": "verizon", "state": "CA", "vzelte": "90", "load_date": "2018-11-01"},
+	}, items)
+
+	_, err = store.Datasets().Items(context.Background(), entity.Verizon, "2018-12-01")
+	assert.Equal(t, ErrUnknownVersion, err)
+}
+
+func TestLoadRejectsVersion(t *testing.T) {
+	store := NewMemoryStore()
+	loadVersions(t, store, "2018-11-01")
+	assert.NoError(t, store.Datasets().Promote(context.Background(), entity.Sprint, "2018-11-01"))
+
+	_, err := store.Load(context.Background(), entity.Sprint, "2018-11-01", nil)
+	assert.Equal(t, ErrLiveVersion, err)
+	_, err = store.Load(context.Background(), entity.Sprint, "", nil)
+	assert.Error(t, err)
+	_, err = store.Load(context.Background(), entity.Sprint, "2018#11", nil)
+	assert.Error(t, err)
+
+	// nor can a version that was loaded before, live or not
+	loadVersions(t, store, "2018-12-01")
+	_, err = store.Load(context.Background(), entity.Sprint, "2018-12-01", nil)
+	assert.Equal(t, ErrVersionLoaded, err)
+}
+
+// fakeDatasetDynamoDB keeps the items of a coverage table by zipcode and carriertype for the dataset storage
+type fakeDatasetDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+	tableName *string
+	items     map[string]map[string]string
+	deletes   int
+}
+
+func (f *fakeDatasetDynamoDB) QueryPagesWithContext(ctx aws.Context, input *dynamodb.QueryInput, fn func(*dynamodb.QueryOutput, bool) bool, opts ...request.Option) error {
+	output := &dynamodb.QueryOutput{}
+	for _, item := range f.items {
+		if item["zipcode"] == loadMetaZipCode {
+			output.Items = append(output.Items, attributeValues(Item(item)))
+		}
+	}
+	fn(output, true)
+	return nil
+}
+
+func (f *fakeDatasetDynamoDB) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error) {
+	item := itemOf(input.Item)
+	key := item["zipcode"] + "/" + item["carriertype"]
+	existing, found := f.items[key]
+	// the condition compares the live version unless it asks for there to be no pointer yet
+	current, compare := input.ExpressionAttributeValues[":0"]
+	if (!compare && found) || (compare && existing["version"] != aws.StringValue(current.S)) {
+		return nil, awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "The conditional request failed", nil)
+	}
+	f.items[key] = item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeDatasetDynamoDB) DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, opts ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	delete(f.items, aws.StringValue(input.Key["zipcode"].S)+"/"+aws.StringValue(input.Key["carriertype"].S))
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+func (f *fakeDatasetDynamoDB) ScanPagesWithContext(ctx aws.Context, input *dynamodb.ScanInput, fn func(*dynamodb.ScanOutput, bool) bool, opts ...request.Option) error {
+	sortKey := aws.StringValue(input.ExpressionAttributeValues[":0"].S)
+	output := &dynamodb.ScanOutput{}
+	for _, item := range f.items {
+		if item["carriertype"] == sortKey {
+			output.Items = append(output.Items, coverageKey(item["zipcode"], item["carriertype"]))
+		}
+	}
+	fn(output, true)
+	return nil
+}
+
+func (f *fakeDatasetDynamoDB) BatchWriteItemWithContext(ctx aws.Context, input *dynamodb.BatchWriteItemInput, opts ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
+	for _, writeRequest := range input.RequestItems[*f.tableName] {
+		key := writeRequest.DeleteRequest.Key
+		delete(f.items, aws.StringValue(key["zipcode"].S)+"/"+aws.StringValue(key["carriertype"].S))
+		f.deletes++
+	}
+	return &dynamodb.BatchWriteItemOutput{}, nil
+}
+
+func (f *fakeDatasetDynamoDB) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	item, ok := f.items[aws.StringValue(input.Key["zipcode"].S)+"/"+aws.StringValue(input.Key["carriertype"].S)]
+	if !ok {
+		return &dynamodb.GetItemOutput{}, nil
+	}
+	return &dynamodb.GetItemOutput{Item: attributeValues(Item(item))}, nil
+}
+
+func TestDynamoDatasets(t *testing.T) {
+	tableName := aws.String("fakeCoverage")
+	fakeDb := &fakeDatasetDynamoDB{tableName: tableName, items: map[string]map[string]string{
+		"_load/sprint#2018-10-01": {"zipcode": "_load", "carriertype": "sprint#2018-10-01", "load_date": "2018-10-01", "rows": "2"},
+		"_load/sprint#2018-11-01": {"zipcode": "_load", "carriertype": "sprint#2018-11-01", "load_date": "2018-11-01", "rows": "1"},
+		"_load/sprint#2018-12-01": {"zipcode": "_load", "carriertype": "sprint#2018-12-01", "load_date": "2018-12-01", "rows": "1"},
+		"94105/sprint#2018-10-01": {"zipcode": "94105", "carriertype": "sprint#2018-10-01"},
+		"94106/sprint#2018-10-01": {"zipcode": "94106", "carriertype": "sprint#2018-10-01"},
+		"94105/sprint#2018-11-01": {"zipcode": "94105", "carriertype": "sprint#2018-11-01"},
+	}}
+	datasets := NewDatasets(tableName, fakeDb)
+
+	assert.NoError(t, datasets.Promote(context.Background(), entity.Sprint, "2018-11-01"))
+	assert.NoError(t, datasets.Promote(context.Background(), entity.Sprint, "2018-12-01"))
+	assert.Equal(t, "2018-12-01", fakeDb.items["_live/sprint"]["version"])
+	assert.Equal(t, "2018-11-01", fakeDb.items["_live/sprint"]["previous"])
+
+	versions, err := datasets.Versions(context.Background(), entity.Sprint)
+	assert.NoError(t, err)
+	assert.Equal(t, []DatasetVersion{
+		{Version: "2018-12-01", Rows: 1, Live: true},
+		{Version: "2018-11-01", Rows: 1, Previous: true},
+		{Version: "2018-10-01", Rows: 2},
+	}, versions)
+
+	collected, err := datasets.Collect(context.Background(), entity.Sprint, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2018-10-01"}, collected)
+	assert.Equal(t, 2, fakeDb.deletes)
+	assert.NotContains(t, fakeDb.items, "_load/sprint#2018-10-01")
+	assert.NotContains(t, fakeDb.items, "94106/sprint#2018-10-01")
+	assert.Contains(t, fakeDb.items, "94105/sprint#2018-11-01")
+}
+
+func TestDynamoDatasetsConcurrentPromotion(t *testing.T) {
+	tableName := aws.String("fakeCoverage")
+	fakeDb := &fakeDatasetDynamoDB{tableName: tableName, items: map[string]map[string]string{
+		"_live/sprint": {"zipcode": "_live", "carriertype": "sprint", "version": "2018-11-01"},
+	}}
+	storage := dynamoDatasetStorage{dynamoStore: dynamoStore{tableName: tableName, connection: fakeDb}}
+
+	err := storage.putPointer(context.Background(), pointerItem("sprint", "2018-12-01", "2018-10-01"), "2018-10-01")
+	assert.Equal(t, ErrConcurrentPromotion, err)
+	err = storage.putPointer(context.Background(), pointerItem("sprint", "2018-12-01", ""), "")
+	assert.Equal(t, ErrConcurrentPromotion, err)
+
+	assert.NoError(t, storage.putPointer(context.Background(), pointerItem("sprint", "2018-12-01", "2018-11-01"), "2018-11-01"))
+	assert.Equal(t, "2018-12-01", fakeDb.items["_live/sprint"]["version"])
+}
+
+func TestMemoryConcurrentPromotion(t *testing.T) {
+	store := NewMemoryStore()
+	assert.NoError(t, store.putPointer(context.Background(), pointerItem("sprint", "2018-11-01", ""), ""))
+	assert.Equal(t, ErrConcurrentPromotion, store.putPointer(context.Background(), pointerItem("sprint", "2018-12-01", ""), ""))
+	assert.Equal(t, ErrConcurrentPromotion, store.putPointer(context.Background(), pointerItem("sprint", "2018-12-01", "2018-10-01"), "2018-10-01"))
+}
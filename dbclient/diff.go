@@ -0,0 +1,158 @@
+package dbclient
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
+	"github.com/rs/zerolog"
+)
+
+// DefaultSwingThreshold is the number of percentage points a technology has to move by to be reported as a swing
+const DefaultSwingThreshold = 20.0
+
+// CoverageDiff is what changed in a carrier's coverage data between two dataset versions
+type CoverageDiff struct {
+	From        string
+	To          string
+	RuleVersion string
+	ZipCodes    int
+	Gained      []entity.CoverageChange
+	Lost        []entity.CoverageChange
+	Swings      []entity.CoverageSwing
+	Added       []string
+	Removed     []string
+}
+
+// CoverageDiffer compares the dataset versions of a carrier's coverage data
+type CoverageDiffer interface {
+	Diff(ctx context.Context, carrier entity.CarrierType, from string, to string, swingThreshold float64) (CoverageDiff, error)
+}
+
+type coverageDiffer struct {
+	datasets Datasets
+	ruleSet  rules.RuleSet
+}
+
+// NewCoverageDiffer constructs and gives back a differ reading the dataset versions and evaluating them with the
+// coverage rules the service checks coverage with
+func NewCoverageDiffer(datasets Datasets, ruleSet rules.RuleSet) CoverageDiffer {
+	return coverageDiffer{datasets: datasets, ruleSet: ruleSet}
+}
+
+// Diff compares the from version of a carrier's coverage data with the to version, or the live version with it when
+// from is empty. ErrUnknownVersion is given back when either wasn't completely loaded, or when from is empty and no
+// version is live.
+func (d coverageDiffer) Diff(ctx context.Context, carrierID entity.CarrierType, from string, to string, swingThreshold float64) (CoverageDiff, error) {
+	carrier, ok := LookupCarrier(carrierID)
+	if !ok {
+		return CoverageDiff{}, ErrUnknownCarrier
+	}
+	rule, ok := d.ruleSet.Carriers[carrier.SortKey]
+	if !ok {
+		return CoverageDiff{}, errors.New("no coverage rule for " + carrier.SortKey)
+	}
+
+	if from == "" {
+		versions, err := d.datasets.Versions(ctx, carrierID)
+		if err != nil {
+			return CoverageDiff{}, err
+		}
+		for _, version := range versions {
+			if version.Live {
+				from = version.Version
+			}
+		}
+		if from == "" {
+			return CoverageDiff{}, ErrUnknownVersion
+		}
+	}
+
+	fromItems, err := d.datasets.Items(ctx, carrierID, from)
+	if err != nil {
+		return CoverageDiff{}, err
+	}
+	toItems, err := d.datasets.Items(ctx, carrierID, to)
+	if err != nil {
+		return CoverageDiff{}, err
+	}
+
+	diff := CoverageDiff{From: from, To: to, RuleVersion: d.ruleSet.Version}
+	fromByZipCode := make(map[string]Item, len(fromItems))
+	for _, item := range fromItems {
+		fromByZipCode[item["zipcode"]] = item
+	}
+	seen := make(map[string]bool, len(toItems))
+	for _, toItem := range toItems {
+		zipCode := toItem["zipcode"]
+		seen[zipCode] = true
+		fromItem, ok := fromByZipCode[zipCode]
+		if !ok {
+			diff.Added = append(diff.Added, zipCode)
+			continue
+		}
+		diff.ZipCodes++
+
+		fromCoverage := d.evaluate(ctx, rule, carrier, fromItem)
+		toCoverage := d.evaluate(ctx, rule, carrier, toItem)
+		change := entity.CoverageChange{ZipCode: zipCode, FromScore: fromCoverage.Detail.Score, ToScore: toCoverage.Detail.Score}
+		switch {
+		case !fromCoverage.IsCovered && toCoverage.IsCovered:
+			diff.Gained = append(diff.Gained, change)
+		case fromCoverage.IsCovered && !toCoverage.IsCovered:
+			diff.Lost = append(diff.Lost, change)
+		}
+		diff.Swings = append(diff.Swings, swings(zipCode, fromCoverage.Detail, toCoverage.Detail, swingThreshold)...)
+	}
+	for _, item := range fromItems {
+		if !seen[item["zipcode"]] {
+			diff.Removed = append(diff.Removed, item["zipcode"])
+		}
+	}
+
+	zerolog.Ctx(ctx).Info().Msgf("compared %s dataset versions %s and %s: %d gained, %d lost, %d swings", carrier.SortKey, from, to, len(diff.Gained), len(diff.Lost), len(diff.Swings))
+	return diff, nil
+}
+
+// evaluate applies the coverage rule to an item the way the carrier's db client does
+func (d coverageDiffer) evaluate(ctx context.Context, rule rules.Rule, carrier Carrier, item Item) Coverage {
+	coverage := Coverage{Found: true, Detail: coverageDetail(carrier.technologies, item)}
+	covered, err := rule.Evaluate(item)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msgf("Illegal coverage data for zipcode: %s", item["zipcode"])
+		return coverage
+	}
+	coverage.IsCovered = covered
+	return coverage
+}
+
+// swings gives back the technologies whose percentage moved by at least the threshold
+func swings(zipCode string, from entity.CoverageDetail, to entity.CoverageDetail, threshold float64) []entity.CoverageSwing {
+	var result []entity.CoverageSwing
+	for i, toTechnology := range to.Technologies {
+		fromPercentage := percentageOf(from.Technologies[i])
+		toPercentage := percentageOf(toTechnology)
+		change := toPercentage - fromPercentage
+		if change == 0 || math.Abs(change) < threshold {
+			continue
+		}
+		result = append(result, entity.CoverageSwing{
+			ZipCode:    zipCode,
+			Technology: toTechnology.Technology,
+			From:       fromPercentage,
+			To:         toPercentage,
+			Change:     math.Round(change*10) / 10,
+		})
+	}
+	return result
+}
+
+// percentageOf gives back a technology's percentage, 0 when it has none
+func percentageOf(technologyCoverage entity.TechnologyCoverage) float64 {
+	if technologyCoverage.Percentage == nil {
+		return 0
+	}
+	return *technologyCoverage.Percentage
+}
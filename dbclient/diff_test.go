@@ -0,0 +1,57 @@
+package dbclient
+
+import (
+	"context"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoverageDiff(t *testing.T) {
+	store := NewMemoryStore()
+	loadLive(t, store, entity.Verizon, "2018-11-01", []map[string]string{
+		{"zip": "94105", "State": "CA", "VZW_LTE": "100", "VZW_LTE_IND": "Y"},
+		{"zip": "94106", "State": "CA", "VZW_LTE": "40", "VZW_LTE_IND": "Y"},
+		{"zip": "94107", "State": "CA", "VZW_LTE": "90", "VZW_LTE_IND": "Y", "VZW_EVDO": "80"},
+		{"zip": "94108", "State": "CA", "VZW_LTE": "90", "VZW_LTE_IND": "Y"},
+	})
+	_, err := store.Load(context.Background(), entity.Verizon, "2018-12-01", []map[string]string{
+		{"zip": "94105", "State": "CA", "VZW_LTE": "30", "VZW_LTE_IND": "Y"},
+		{"zip": "94106", "State": "CA", "VZW_LTE": "60", "VZW_LTE_IND": "Y"},
+		{"zip": "94107", "State": "CA", "VZW_LTE": "85", "VZW_LTE_IND": "Y", "VZW_EVDO": "50"},
+		{"zip": "94109", "State": "CA", "VZW_LTE": "90", "VZW_LTE_IND": "Y"},
+	})
+	assert.NoError(t, err)
+	differ := NewCoverageDiffer(store.Datasets(), rules.Default())
+
+	diff, err := differ.Diff(context.Background(), entity.Verizon, "", "2018-12-01", DefaultSwingThreshold)
+	assert.NoError(t, err)
+	assert.Equal(t, "2018-11-01", diff.From)
+	assert.Equal(t, "2018-12-01", diff.To)
+	assert.Equal(t, rules.Default().Version, diff.RuleVersion)
+	assert.Equal(t, 3, diff.ZipCodes)
+	assert.Equal(t, []string{"94109"}, diff.Added)
+	assert.Equal(t, []string{"94108"}, diff.Removed)
+	assert.Len(t, diff.Lost, 1)
+	assert.Equal(t, "94105", diff.Lost[0].ZipCode)
+	assert.Len(t, diff.Gained, 1)
+	assert.Equal(t, "94106", diff.Gained[0].ZipCode)
+	assert.Equal(t, []entity.CoverageSwing{
+		{ZipCode: "94105", Technology: "LTE", From: 100, To: 30, Change: -70},
+		{ZipCode: "94106", Technology: "LTE", From: 40, To: 60, Change: 20},
+		{ZipCode: "94107", Technology: "EVDO", From: 80, To: 50, Change: -30},
+	}, diff.Swings)
+
+	diff, err = differ.Diff(context.Background(), entity.Verizon, "2018-11-01", "2018-12-01", 50)
+	assert.NoError(t, err)
+	assert.Len(t, diff.Swings, 1)
+
+	_, err = differ.Diff(context.Background(), entity.Verizon, "2018-11-01", "2019-01-01", DefaultSwingThreshold)
+	assert.Equal(t, ErrUnknownVersion, err)
+	_, err = differ.Diff(context.Background(), entity.Sprint, "", "2018-12-01", DefaultSwingThreshold)
+	assert.Equal(t, ErrUnknownVersion, err)
+	_, err = differ.Diff(context.Background(), entity.CarrierType("9"), "", "2018-12-01", DefaultSwingThreshold)
+	assert.EqualError(t, err, "Invalid Carrier Type")
+}
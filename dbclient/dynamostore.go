@@ -0,0 +1,200 @@
+package dbclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"github.com/rs/zerolog"
+)
+
+type dynamoStore struct {
+	tableName  *string
+	connection dynamodbiface.DynamoDBAPI
+}
+
+// NewDynamoStore constructs and gives back a store reading the given DynamoDB coverage table
+func NewDynamoStore(tableName *string, connection dynamodbiface.DynamoDBAPI) Store {
+	return dynamoStore{tableName: tableName, connection: connection}
+}
+
+func (d dynamoStore) Get(ctx context.Context, zipCode string, sortKey string, attributes []string) (Item, error) {
+	expr, err := projection(attributes)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msgf("failed to build projection expression to query dynamodb table for %s coverage", sortKey)
+		return nil, err
+	}
+
+	input := &dynamodb.GetItemInput{
+		TableName:                d.tableName,
+		Key:                      coverageKey(zipCode, sortKey),
+		ExpressionAttributeNames: expr.Names(),
+		ProjectionExpression:     expr.Projection(),
+	}
+
+	result, err := d.connection.GetItemWithContext(ctx, input)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("failed to query coverage dynamodb table")
+		return nil, err
+	}
+
+	item := itemOf(result.Item)
+	if item["zipcode"] == "" {
+		return nil, nil
+	}
+	return item, nil
+}
+
+func (d dynamoStore) BatchGet(ctx context.Context, sortKey string, zipCodes []string, attributes []string) ([]Item, error) {
+	expr, err := projection(attributes)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msgf("failed to build projection expression to query dynamodb table for %s coverage", sortKey)
+		return nil, err
+	}
+
+	result, err := batchGetItems(ctx, d.connection, d.tableName, sortKey, zipCodes, expr)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0, len(result))
+	for _, attributeValues := range result {
+		if item := itemOf(attributeValues); item["zipcode"] != "" {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// Query reads a page of a secondary index with a Query on its hash key. The continuation token wraps the
+// LastEvaluatedKey of the page.
+func (d dynamoStore) Query(ctx context.Context, query IndexQuery) (Page, error) {
+	keyCondition := expression.Key(query.HashKey).Equal(expression.Value(query.Value))
+	names := projectedNames(query.Attributes)
+	builder := expression.NewBuilder().WithKeyCondition(keyCondition)
+	if len(names) > 0 {
+		builder = builder.WithProjection(expression.NamesList(names[0], names[1:]...))
+	}
+	expr, err := builder.Build()
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msgf("failed to build expression to query %s", query.Index)
+		return Page{}, err
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 d.tableName,
+		IndexName:                 aws.String(query.Index),
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ProjectionExpression:      expr.Projection(),
+	}
+	if query.Limit > 0 {
+		input.Limit = aws.Int64(int64(query.Limit))
+	}
+	if query.Token != "" {
+		key, err := decodeToken(query)
+		if err != nil {
+			return Page{}, err
+		}
+		input.ExclusiveStartKey = attributeValues(key)
+	}
+
+	result, err := d.connection.QueryWithContext(ctx, input)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msgf("failed to query %s of coverage dynamodb table", query.Index)
+		return Page{}, err
+	}
+
+	page := Page{Items: make([]Item, 0, len(result.Items))}
+	for _, attributeValues := range result.Items {
+		page.Items = append(page.Items, itemOf(attributeValues))
+	}
+	if len(result.LastEvaluatedKey) > 0 {
+		page.NextToken = encodeToken(itemOf(result.LastEvaluatedKey))
+	}
+	return page, nil
+}
+
+// healthProbeSortKey is the sort key of the item Check probes for. No carrier uses it, so the probe reads nothing.
+const healthProbeSortKey = "health"
+
+// Check describes the coverage table and probes it with a GetItem, so a missing or inactive table is told
+// apart from missing read permissions
+func (d dynamoStore) Check(ctx context.Context) error {
+	result, err := d.connection.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{TableName: d.tableName})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("failed to describe coverage dynamodb table")
+		return err
+	}
+	if status := aws.StringValue(result.Table.TableStatus); status != dynamodb.TableStatusActive {
+		return fmt.Errorf("coverage table is %s", status)
+	}
+
+	_, err = d.connection.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: d.tableName,
+		Key:       coverageKey(loadMetaZipCode, healthProbeSortKey),
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("failed to probe coverage dynamodb table")
+		return err
+	}
+	return nil
+}
+
+// projection builds the projection expression of the attributes
+func projection(attributes []string) (expression.Expression, error) {
+	names := projectedNames(attributes)
+	if len(names) == 0 {
+		return expression.Expression{}, errors.New("no attributes to project")
+	}
+	return expression.NewBuilder().WithProjection(expression.NamesList(names[0], names[1:]...)).Build()
+}
+
+// projectedNames names each of the attributes once. DynamoDB rejects a projection with overlapping paths, such as the
+// carriertype the versioned store adds to attributes that may already have it.
+func projectedNames(attributes []string) []expression.NameBuilder {
+	names := make([]expression.NameBuilder, 0, len(attributes))
+	seen := map[string]bool{}
+	for _, attribute := range attributes {
+		if seen[attribute] {
+			continue
+		}
+		seen[attribute] = true
+		names = append(names, expression.Name(attribute))
+	}
+	return names
+}
+
+// itemOf gives back the string, number and boolean attributes of a dynamodb item
+func itemOf(attributeValues map[string]*dynamodb.AttributeValue) Item {
+	item := Item{}
+	for attribute, value := range attributeValues {
+		switch {
+		case value == nil:
+		case value.S != nil:
+			item[attribute] = *value.S
+		case value.N != nil:
+			item[attribute] = *value.N
+		case value.BOOL != nil:
+			item[attribute] = strconv.FormatBool(*value.BOOL)
+		}
+	}
+	return item
+}
+
+// attributeValues gives back the dynamodb item of an item, leaving out empty attributes
+func attributeValues(item Item) map[string]*dynamodb.AttributeValue {
+	values := map[string]*dynamodb.AttributeValue{}
+	for attribute, value := range item {
+		if value != "" {
+			values[attribute] = &dynamodb.AttributeValue{S: aws.String(value)}
+		}
+	}
+	return values
+}
@@ -0,0 +1,124 @@
+package dbclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeCheckDynamoDB describes a table with the given status and fails the probe GetItem with getErr
+type fakeCheckDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+	status      string
+	describeErr error
+	getErr      error
+	probed      map[string]*dynamodb.AttributeValue
+}
+
+func (f *fakeCheckDynamoDB) DescribeTableWithContext(ctx aws.Context, input *dynamodb.DescribeTableInput, opts ...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	if f.describeErr != nil {
+		return nil, f.describeErr
+	}
+	return &dynamodb.DescribeTableOutput{Table: &dynamodb.TableDescription{TableName: input.TableName, TableStatus: aws.String(f.status)}}, nil
+}
+
+func (f *fakeCheckDynamoDB) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	f.probed = input.Key
+	if f.getErr != nil {
+		return nil, f.getErr
+	}
+	return &dynamodb.GetItemOutput{}, nil
+}
+
+func TestDynamoStoreCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		fakeDb  *fakeCheckDynamoDB
+		wantErr string
+	}{
+		{name: "active table", fakeDb: &fakeCheckDynamoDB{status: dynamodb.TableStatusActive}},
+		{name: "updating table", fakeDb: &fakeCheckDynamoDB{status: dynamodb.TableStatusUpdating}, wantErr: "coverage table is UPDATING"},
+		{name: "missing table", fakeDb: &fakeCheckDynamoDB{describeErr: errors.New("ResourceNotFoundException")}, wantErr: "ResourceNotFoundException"},
+		{name: "no read permission", fakeDb: &fakeCheckDynamoDB{status: dynamodb.TableStatusActive, getErr: errors.New("AccessDeniedException")}, wantErr: "AccessDeniedException"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewDynamoStore(aws.String("fakeCoverage"), tt.fakeDb).Check(context.Background())
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				assert.Equal(t, coverageKey(loadMetaZipCode, healthProbeSortKey), tt.fakeDb.probed)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+// fakeQueryDynamoDB gives back the items of a single page, with a LastEvaluatedKey when lastKey is set
+type fakeQueryDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+	items   []map[string]string
+	lastKey map[string]string
+	input   *dynamodb.QueryInput
+}
+
+func (f *fakeQueryDynamoDB) QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, opts ...request.Option) (*dynamodb.QueryOutput, error) {
+	f.input = input
+	output := &dynamodb.QueryOutput{}
+	for _, item := range f.items {
+		output.Items = append(output.Items, attributeValues(item))
+	}
+	if f.lastKey != nil {
+		output.LastEvaluatedKey = attributeValues(f.lastKey)
+	}
+	return output, nil
+}
+
+func TestDynamoStoreQuery(t *testing.T) {
+	fakeDb := &fakeQueryDynamoDB{
+		items:   []map[string]string{{"zipcode": "85001", "state": "AZ"}},
+		lastKey: map[string]string{"csa_leaf": "PHXTUC520", "zipcode": "85001", "carriertype": "sprint"},
+	}
+	store := NewDynamoStore(aws.String("fakeCoverage"), fakeDb)
+	query := IndexQuery{Index: csaIndex, HashKey: "csa_leaf", Value: "PHXTUC520", Attributes: []string{"zipcode", "state"}, Limit: 1}
+
+	page, err := store.Query(context.Background(), query)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Item{{"zipcode": "85001", "state": "AZ"}}, page.Items)
+	assert.Equal(t, csaIndex, aws.StringValue(fakeDb.input.IndexName))
+	assert.Equal(t, int64(1), aws.Int64Value(fakeDb.input.Limit))
+	assert.Nil(t, fakeDb.input.ExclusiveStartKey)
+
+	// the token hands the LastEvaluatedKey back as the ExclusiveStartKey
+	fakeDb.lastKey = nil
+	query.Token = page.NextToken
+	page, err = store.Query(context.Background(), query)
+
+	assert.NoError(t, err)
+	assert.Empty(t, page.NextToken)
+	assert.Equal(t, "85001", aws.StringValue(fakeDb.input.ExclusiveStartKey["zipcode"].S))
+	assert.Equal(t, "PHXTUC520", aws.StringValue(fakeDb.input.ExclusiveStartKey["csa_leaf"].S))
+
+	query.Value = "SFRSFR415"
+	_, err = store.Query(context.Background(), query)
+	assert.Equal(t, ErrInvalidToken, err)
+}
+
+func TestDynamoStoreQueryProjectsEachAttributeOnce(t *testing.T) {
+	fakeDb := &fakeQueryDynamoDB{}
+	store := NewDynamoStore(aws.String("fakeCoverage"), fakeDb)
+
+	_, err := store.Query(context.Background(), IndexQuery{Index: geohashIndex, HashKey: "geohash", Value: "9q8y", Attributes: []string{"carriertype", "zipcode", "carriertype"}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "#1, #2", aws.StringValue(fakeDb.input.ProjectionExpression))
+	assert.Equal(t, map[string]*string{"#0": aws.String("geohash"), "#1": aws.String("carriertype"), "#2": aws.String("zipcode")}, fakeDb.input.ExpressionAttributeNames)
+}
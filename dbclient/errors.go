@@ -0,0 +1,135 @@
+package dbclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// ErrorKind classifies a failure of the coverage data, so the handlers can tell callers whether it is worth retrying
+type ErrorKind int
+
+const (
+	// KindInternal is any failure not classified below, a bug or a misconfiguration
+	KindInternal ErrorKind = iota
+	// KindNotFound is a lookup of something that doesn't exist, such as an unknown carrier
+	KindNotFound
+	// KindThrottled is DynamoDB rejecting calls over the table's capacity
+	KindThrottled
+	// KindUnavailable is DynamoDB timing out, unreachable or failing on its side
+	KindUnavailable
+	// KindBadData is an item that doesn't decode into its carrier's coverage data
+	KindBadData
+	// KindCanceled is the caller giving up on the request, nothing failed on DynamoDB's side
+	KindCanceled
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindNotFound:
+		return "not found"
+	case KindThrottled:
+		return "throttled"
+	case KindUnavailable:
+		return "unavailable"
+	case KindBadData:
+		return "bad data"
+	case KindCanceled:
+		return "canceled"
+	default:
+		return "internal"
+	}
+}
+
+// ThrottledRetryAfter and UnavailableRetryAfter are how long callers are told to wait before retrying a throttled or
+// unavailable lookup
+const (
+	ThrottledRetryAfter   = time.Second
+	UnavailableRetryAfter = 5 * time.Second
+)
+
+// Error is a failure of a kind. The kind of a DynamoDB error is read off its code instead, see KindOf.
+type Error struct {
+	Kind ErrorKind
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrUnknownCarrier is given back for a carrier id no carrier is registered under
+var ErrUnknownCarrier error = &Error{Kind: KindNotFound, Err: errors.New("Invalid Carrier Type")}
+
+// KindOf gives back the kind of an error: the kind of an Error, or of a DynamoDB error by its code. Errors wrapping
+// another one with an Unwrap method are looked through.
+func KindOf(err error) ErrorKind {
+	for err != nil {
+		switch err {
+		case context.DeadlineExceeded:
+			return KindUnavailable
+		case context.Canceled:
+			return KindCanceled
+		}
+		switch e := err.(type) {
+		case *Error:
+			return e.Kind
+		case awserr.Error:
+			// a canceled request timed out when its context's deadline passed, the caller gave up otherwise
+			if e.Code() == request.CanceledErrorCode {
+				if KindOf(e.OrigErr()) == KindUnavailable {
+					return KindUnavailable
+				}
+				return KindCanceled
+			}
+			return kindOfCode(e.Code())
+		case net.Error:
+			if e.Timeout() {
+				return KindUnavailable
+			}
+		}
+		wrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		err = wrapper.Unwrap()
+	}
+	return KindInternal
+}
+
+// kindOfCode gives back the kind of a DynamoDB error code. A missing table is unavailable, as it is while being
+// created or restored.
+func kindOfCode(code string) ErrorKind {
+	switch code {
+	case dynamodb.ErrCodeProvisionedThroughputExceededException, dynamodb.ErrCodeLimitExceededException,
+		"RequestLimitExceeded", "ThrottlingException":
+		return KindThrottled
+	case dynamodb.ErrCodeInternalServerError, dynamodb.ErrCodeResourceNotFoundException, "ServiceUnavailable",
+		request.ErrCodeRequestError, request.ErrCodeResponseTimeout:
+		return KindUnavailable
+	default:
+		return KindInternal
+	}
+}
+
+// RetryAfter gives back how long to wait before retrying after the error, zero when retrying won't help or the
+// caller canceled the request
+func RetryAfter(err error) time.Duration {
+	switch KindOf(err) {
+	case KindThrottled:
+		return ThrottledRetryAfter
+	case KindUnavailable:
+		return UnavailableRetryAfter
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,75 @@
+package dbclient
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKindOf(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		err        error
+		kind       ErrorKind
+		retryAfter time.Duration
+	}{
+		{desc: "Unknown carrier", err: ErrUnknownCarrier, kind: KindNotFound},
+		{desc: "Provisioned throughput exceeded", err: awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "", nil), kind: KindThrottled, retryAfter: ThrottledRetryAfter},
+		{desc: "Request limit exceeded", err: awserr.New("RequestLimitExceeded", "", nil), kind: KindThrottled, retryAfter: ThrottledRetryAfter},
+		{desc: "Unprocessed keys", err: &Error{Kind: KindThrottled, Err: errors.New("unable to process all keys of batch get request")}, kind: KindThrottled, retryAfter: ThrottledRetryAfter},
+		{desc: "Internal server error", err: awserr.New(dynamodb.ErrCodeInternalServerError, "", nil), kind: KindUnavailable, retryAfter: UnavailableRetryAfter},
+		{desc: "Missing table", err: awserr.New(dynamodb.ErrCodeResourceNotFoundException, "", nil), kind: KindUnavailable, retryAfter: UnavailableRetryAfter},
+		{desc: "Request timeout", err: awserr.New("RequestCanceled", "", context.DeadlineExceeded), kind: KindUnavailable, retryAfter: UnavailableRetryAfter},
+		{desc: "Deadline exceeded", err: context.DeadlineExceeded, kind: KindUnavailable, retryAfter: UnavailableRetryAfter},
+		{desc: "Request canceled", err: awserr.New("RequestCanceled", "", context.Canceled), kind: KindCanceled},
+		{desc: "Context canceled", err: context.Canceled, kind: KindCanceled},
+		{desc: "Request error", err: awserr.New("RequestError", "", nil), kind: KindUnavailable, retryAfter: UnavailableRetryAfter},
+		{desc: "Response timeout", err: awserr.New("ResponseTimeout", "", nil), kind: KindUnavailable, retryAfter: UnavailableRetryAfter},
+		{desc: "Service unavailable", err: awserr.New("ServiceUnavailable", "", nil), kind: KindUnavailable, retryAfter: UnavailableRetryAfter},
+		{desc: "Wrapped throttle", err: wrappedError{"querying cell 9q8yy", awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "", nil)}, kind: KindThrottled, retryAfter: ThrottledRetryAfter},
+		{desc: "Wrapped deadline exceeded", err: wrappedError{"querying cell 9q8yy", context.DeadlineExceeded}, kind: KindUnavailable, retryAfter: UnavailableRetryAfter},
+		{desc: "Wrapped network timeout", err: wrappedError{"querying cell 9q8yy", &net.OpError{Op: "dial", Err: timeoutError{}}}, kind: KindUnavailable, retryAfter: UnavailableRetryAfter},
+		{desc: "Wrapped kind", err: wrappedError{"decoding item", &Error{Kind: KindBadData, Err: errors.New("malformed coverage item")}}, kind: KindBadData},
+		{desc: "Network timeout", err: &net.OpError{Op: "dial", Err: timeoutError{}}, kind: KindUnavailable, retryAfter: UnavailableRetryAfter},
+		{desc: "Validation", err: awserr.New("ValidationException", "", nil), kind: KindInternal},
+		{desc: "Corrupt item", err: &Error{Kind: KindBadData, Err: errors.New("malformed coverage item")}, kind: KindBadData},
+		{desc: "Plain", err: errors.New("Fake error"), kind: KindInternal},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert.Equal(t, tC.kind, KindOf(tC.err))
+			assert.Equal(t, tC.retryAfter, RetryAfter(tC.err))
+		})
+	}
+}
+
+func TestDecodeItemBadData(t *testing.T) {
+	data := struct {
+		Score float64 `json:"score"`
+	}{}
+	err := decodeItem(Item{"zipcode": "94105", "score": "high"}, &data)
+
+	assert.Error(t, err)
+	assert.Equal(t, KindBadData, KindOf(err))
+}
+
+// wrappedError wraps an error the way fmt.Errorf's %w does, which isn't available in every supported Go version
+type wrappedError struct {
+	message string
+	err     error
+}
+
+func (e wrappedError) Error() string { return e.message + ": " + e.err.Error() }
+func (e wrappedError) Unwrap() error { return e.err }
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
@@ -0,0 +1,55 @@
+package dbclient
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/rs/zerolog"
+)
+
+// LoadFixtures loads the carrier exports listed in fixtures, a comma separated list of carrierid:path such as
+// 1:sprint_coverage_batch_data.json,2:verizon_coverage_batch_data.json, and promotes them. The format of a file is
+// taken from its extension.
+func LoadFixtures(ctx context.Context, loader CoverageLoader, datasets Datasets, fixtures string, loadDate string) error {
+	for _, fixture := range strings.Split(fixtures, ",") {
+		fixture = strings.TrimSpace(fixture)
+		if fixture == "" {
+			continue
+		}
+
+		parts := strings.SplitN(fixture, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("invalid fixture %q, expected carrierid:path", fixture)
+		}
+
+		summary, err := loadFixture(ctx, loader, entity.CarrierType(parts[0]), parts[1], loadDate)
+		if err != nil {
+			return fmt.Errorf("unable to load fixture %s: %v", parts[1], err)
+		}
+		for _, rejection := range summary.Rejections {
+			zerolog.Ctx(ctx).Warn().Msgf("fixture %s row %d (%s) rejected: %s", parts[1], rejection.Row, rejection.ZipCode, rejection.Reason)
+		}
+		if err := datasets.Promote(ctx, entity.CarrierType(parts[0]), loadDate); err != nil {
+			return fmt.Errorf("unable to promote fixture %s: %v", parts[1], err)
+		}
+	}
+	return nil
+}
+
+func loadFixture(ctx context.Context, loader CoverageLoader, carrier entity.CarrierType, path string, loadDate string) (LoadSummary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return LoadSummary{}, err
+	}
+	defer f.Close()
+
+	rows, err := ReadRows(f, strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), "."))
+	if err != nil {
+		return LoadSummary{}, err
+	}
+	return loader.Load(ctx, carrier, loadDate, rows)
+}
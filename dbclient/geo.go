@@ -0,0 +1,303 @@
+package dbclient
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// geohashIndex is the secondary index of the coverage table keyed by the geohash of a zipcode's centroid and the
+// zipcode. Only items with a centroid, from the carrier's export or the loader's centroid file, have a geohash.
+const geohashIndex = "geohash-zipcode-index"
+
+// geohashPrecision is the length of the geohash stamped on coverage items. A cell is about 39km by 20km.
+const geohashPrecision = 4
+
+// maxGeohashRing is how many rings of cells around a location are searched for its nearest zipcode
+const maxGeohashRing = 2
+
+// maxWithinRings bounds the rings of cells searched for the zipcodes within a radius, which near the poles would
+// otherwise grow without bound as cells narrow
+const maxWithinRings = 10
+
+// maxCellQueries bounds the geohash index queries of a lookup running at once. A 25 mile radius search reads 49 cells.
+const maxCellQueries = 8
+
+const geohashBase32 = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+const earthRadiusKm = 6371.0
+
+// geohash encodes a location into a geohash of the given length
+func geohash(lat float64, lon float64, precision int) string {
+	latRange := [2]float64{-90, 90}
+	lonRange := [2]float64{-180, 180}
+	hash := make([]byte, 0, precision)
+	bit, ch, even := 0, 0, true
+	for len(hash) < precision {
+		value, valueRange := lat, &latRange
+		if even {
+			value, valueRange = lon, &lonRange
+		}
+		mid := (valueRange[0] + valueRange[1]) / 2
+		ch <<= 1
+		if value >= mid {
+			ch |= 1
+			valueRange[0] = mid
+		} else {
+			valueRange[1] = mid
+		}
+		even = !even
+		if bit++; bit == 5 {
+			hash = append(hash, geohashBase32[ch])
+			bit, ch = 0, 0
+		}
+	}
+	return string(hash)
+}
+
+// geohashCellSize gives back the height and width in degrees of a geohash cell of the given length
+func geohashCellSize(precision int) (float64, float64) {
+	bits := precision * 5
+	lonBits := (bits + 1) / 2
+	latBits := bits / 2
+	return 180 / math.Exp2(float64(latBits)), 360 / math.Exp2(float64(lonBits))
+}
+
+// geohashCellsAround gives back the geohash cell of a location and the rings of cells around it
+func geohashCellsAround(lat float64, lon float64, precision int, rings int) []string {
+	height, width := geohashCellSize(precision)
+	seen := map[string]bool{}
+	var cells []string
+	for dLat := -rings; dLat <= rings; dLat++ {
+		cellLat := lat + float64(dLat)*height
+		if cellLat > 90 || cellLat < -90 {
+			continue
+		}
+		for dLon := -rings; dLon <= rings; dLon++ {
+			cellLon := math.Mod(lon+float64(dLon)*width+540, 360) - 180
+			cell := geohash(cellLat, cellLon, precision)
+			if !seen[cell] {
+				seen[cell] = true
+				cells = append(cells, cell)
+			}
+		}
+	}
+	return cells
+}
+
+// geohashRingKm is the distance every location within the given rings of cells around a location is guaranteed
+// to be found within, i.e. the rings times the shorter side of a cell at the location's latitude
+func geohashRingKm(lat float64, precision int, rings int) float64 {
+	height, width := geohashCellSize(precision)
+	heightKm := height * math.Pi / 180 * earthRadiusKm
+	widthKm := width * math.Pi / 180 * earthRadiusKm * math.Cos(lat*math.Pi/180)
+	return float64(rings) * math.Min(heightKm, widthKm)
+}
+
+// distanceKm is the great circle distance between two locations
+func distanceKm(lat1 float64, lon1 float64, lat2 float64, lon2 float64) float64 {
+	toRadians := math.Pi / 180
+	dLat := (lat2 - lat1) * toRadians
+	dLon := (lon2 - lon1) * toRadians
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1*toRadians)*math.Cos(lat2*toRadians)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// geohashOf gives back the geohash of a centroid, or an empty string when it isn't a valid location
+func geohashOf(latitude string, longitude string) string {
+	lat, err := strconv.ParseFloat(latitude, 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return ""
+	}
+	lon, err := strconv.ParseFloat(longitude, 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return ""
+	}
+	return geohash(lat, lon, geohashPrecision)
+}
+
+// NearbyZipCode is a zipcode whose centroid is DistanceKm away from a location
+type NearbyZipCode struct {
+	ZipCode    string
+	DistanceKm float64
+}
+
+// ZipCodeLocator finds zipcodes by the location of their centroids
+type ZipCodeLocator interface {
+	Nearest(ctx context.Context, lat float64, lon float64) (NearbyZipCode, bool, error)
+	Within(ctx context.Context, lat float64, lon float64, radiusKm float64) ([]NearbyZipCode, error)
+	Centroid(ctx context.Context, zipCode string) (float64, float64, bool, error)
+}
+
+type zipCodeLocator struct {
+	store Store
+}
+
+// NewZipCodeLocator constructs and gives back a locator reading zipcode centroids through the geohash index
+func NewZipCodeLocator(store Store) ZipCodeLocator {
+	return zipCodeLocator{store: store}
+}
+
+// Nearest gives back the zipcode whose centroid is nearest the location. The cell of the location and the ring of
+// cells around it are searched first, widening to maxGeohashRing rings while a nearer zipcode could be in the next
+// ring. found is false when no zipcode is that close.
+func (z zipCodeLocator) Nearest(ctx context.Context, lat float64, lon float64) (NearbyZipCode, bool, error) {
+	for rings := 1; rings <= maxGeohashRing; rings++ {
+		nearby, err := z.within(ctx, lat, lon, geohashCellsAround(lat, lon, geohashPrecision, rings))
+		if err != nil {
+			return NearbyZipCode{}, false, err
+		}
+		if len(nearby) > 0 && (nearby[0].DistanceKm <= geohashRingKm(lat, geohashPrecision, rings) || rings == maxGeohashRing) {
+			return nearby[0], true, nil
+		}
+	}
+	return NearbyZipCode{}, false, nil
+}
+
+// Within gives back the zipcodes whose centroid is within the radius of the location, nearest first
+func (z zipCodeLocator) Within(ctx context.Context, lat float64, lon float64, radiusKm float64) ([]NearbyZipCode, error) {
+	nearby, err := z.within(ctx, lat, lon, cellsWithin(lat, lon, radiusKm))
+	if err != nil {
+		return nil, err
+	}
+	var inRadius []NearbyZipCode
+	for _, zipCode := range nearby {
+		if zipCode.DistanceKm <= radiusKm {
+			inRadius = append(inRadius, zipCode)
+		}
+	}
+	return inRadius, nil
+}
+
+// Centroid gives back the centroid of a zipcode from the coverage data of the first carrier that has one.
+// found is false when no carrier has a centroid for the zipcode.
+func (z zipCodeLocator) Centroid(ctx context.Context, zipCode string) (float64, float64, bool, error) {
+	for _, carrier := range RegisteredCarriers() {
+		if carrier.centroid == nil {
+			continue
+		}
+		item, err := z.store.Get(ctx, zipCode, carrier.SortKey, []string{"zipcode", "carriertype", carrier.centroid.latitudeAttribute, carrier.centroid.longitudeAttribute})
+		if err != nil {
+			return 0, 0, false, err
+		}
+		if item == nil {
+			continue
+		}
+		item["carriertype"] = carrier.SortKey
+		if lat, lon, ok := centroidOf(item); ok {
+			return lat, lon, true, nil
+		}
+	}
+	return 0, 0, false, nil
+}
+
+// cellsWithin gives back the cells covering the radius of the location
+func cellsWithin(lat float64, lon float64, radiusKm float64) []string {
+	rings := int(math.Ceil(radiusKm / geohashRingKm(lat, geohashPrecision, 1)))
+	if rings < 1 {
+		rings = 1
+	}
+	if rings > maxWithinRings {
+		rings = maxWithinRings
+	}
+	return geohashCellsAround(lat, lon, geohashPrecision, rings)
+}
+
+// within gives back the zipcodes with a centroid in the cells, nearest first. The cells are queried in parallel,
+// at most maxCellQueries at once.
+func (z zipCodeLocator) within(ctx context.Context, lat float64, lon float64, cells []string) ([]NearbyZipCode, error) {
+	itemsByCell := make([][]Item, len(cells))
+	errs := make([]error, len(cells))
+	queries := make(chan struct{}, maxCellQueries)
+	var wg sync.WaitGroup
+	for i, cell := range cells {
+		wg.Add(1)
+		go func(i int, cell string) {
+			defer wg.Done()
+			queries <- struct{}{}
+			defer func() { <-queries }()
+
+			itemsByCell[i], errs[i] = queryAll(ctx, z.store, IndexQuery{Index: geohashIndex, HashKey: "geohash", Value: cell, Attributes: centroidAttributes()})
+		}(i, cell)
+	}
+	wg.Wait()
+
+	seen := map[string]bool{}
+	var nearby []NearbyZipCode
+	for i, items := range itemsByCell {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		for _, item := range items {
+			zipLat, zipLon, ok := centroidOf(item)
+			if !ok || seen[item["zipcode"]] {
+				continue
+			}
+			seen[item["zipcode"]] = true
+			nearby = append(nearby, NearbyZipCode{ZipCode: item["zipcode"], DistanceKm: distanceKm(lat, lon, zipLat, zipLon)})
+		}
+	}
+	sort.Slice(nearby, func(i, j int) bool {
+		if nearby[i].DistanceKm != nearby[j].DistanceKm {
+			return nearby[i].DistanceKm < nearby[j].DistanceKm
+		}
+		return nearby[i].ZipCode < nearby[j].ZipCode
+	})
+	return nearby, nil
+}
+
+// centroidAttributes are the zipcode and the centroid attributes of every carrier whose coverage data has centroids,
+// each listed once since a projection can't name an attribute twice
+func centroidAttributes() []string {
+	attributes := []string{"zipcode", "carriertype"}
+	seen := map[string]bool{}
+	for _, carrier := range RegisteredCarriers() {
+		if carrier.centroid == nil {
+			continue
+		}
+		for _, attribute := range []string{carrier.centroid.latitudeAttribute, carrier.centroid.longitudeAttribute} {
+			if !seen[attribute] {
+				seen[attribute] = true
+				attributes = append(attributes, attribute)
+			}
+		}
+	}
+	return attributes
+}
+
+// centroidOf gives back the centroid of an item of the geohash index
+func centroidOf(item Item) (float64, float64, bool) {
+	for _, carrier := range RegisteredCarriers() {
+		if carrier.centroid == nil || carrier.SortKey != item["carriertype"] {
+			continue
+		}
+		lat, err := strconv.ParseFloat(item[carrier.centroid.latitudeAttribute], 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		lon, err := strconv.ParseFloat(item[carrier.centroid.longitudeAttribute], 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		return lat, lon, true
+	}
+	return 0, 0, false
+}
+
+// queryAll reads every page of an index query
+func queryAll(ctx context.Context, store Store, query IndexQuery) ([]Item, error) {
+	var items []Item
+	for {
+		page, err := store.Query(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page.Items...)
+		if page.NextToken == "" {
+			return items, nil
+		}
+		query.Token = page.NextToken
+	}
+}
@@ -0,0 +1,184 @@
+package dbclient
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeohash(t *testing.T) {
+	assert.Equal(t, "u4pruydqqvj", geohash(57.64911, 10.40744, 11))
+	assert.Equal(t, "9q8y", geohash(37.7749, -122.4194, geohashPrecision))
+	assert.Equal(t, "9q8y", geohashOf("37.7749", "-122.4194"))
+	assert.Equal(t, "", geohashOf("", "-122.4194"))
+	assert.Equal(t, "", geohashOf("91", "-122.4194"))
+}
+
+func TestGeohashCellsAround(t *testing.T) {
+	cells := geohashCellsAround(37.7749, -122.4194, geohashPrecision, 1)
+	assert.Len(t, cells, 9)
+	assert.Equal(t, "9q8y", cells[4])
+	assert.Len(t, geohashCellsAround(37.7749, -122.4194, geohashPrecision, 2), 25)
+
+	// the cells wrap around the antimeridian
+	assert.Len(t, geohashCellsAround(0, 179.99, geohashPrecision, 1), 9)
+	assert.Contains(t, geohashCellsAround(0, 179.99, geohashPrecision, 1), geohash(0, -179.99, geohashPrecision))
+}
+
+func TestDistanceKm(t *testing.T) {
+	assert.InDelta(t, 559, distanceKm(37.7749, -122.4194, 34.0522, -118.2437), 1)
+	assert.Equal(t, 0.0, distanceKm(37.7749, -122.4194, 37.7749, -122.4194))
+}
+
+func TestNearestZipCode(t *testing.T) {
+	store := loadLive(t, NewMemoryStore(), entity.Sprint, "2018-11-01", []map[string]string{
+		{"ZIP": "94105", "ZIP_CENTER_LAT": "37.7898", "ZIP_CENTER_LON": "-122.3942"},
+		{"ZIP": "94110", "ZIP_CENTER_LAT": "37.7486", "ZIP_CENTER_LON": "-122.4184"},
+		{"ZIP": "90012", "ZIP_CENTER_LAT": "34.0614", "ZIP_CENTER_LON": "-118.2385"},
+		{"ZIP": "00016"},
+	})
+	item, err := store.Get(context.Background(), "94105", "sprint", []string{"geohash"})
+	assert.NoError(t, err)
+	assert.Equal(t, Item{"geohash": "9q8y"}, item)
+
+	locator := NewZipCodeLocator(store)
+
+	nearest, found, err := locator.Nearest(context.Background(), 37.7879, -122.4075)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "94105", nearest.ZipCode)
+	assert.InDelta(t, 1.2, nearest.DistanceKm, 0.1)
+
+	nearest, found, err = locator.Nearest(context.Background(), 37.75, -122.42)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "94110", nearest.ZipCode)
+
+	_, found, err = locator.Nearest(context.Background(), 40.7128, -74.0060)
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestZipCodesWithin(t *testing.T) {
+	store := loadLive(t, NewMemoryStore(), entity.Sprint, "2018-11-01", []map[string]string{
+		{"ZIP": "94105", "ZIP_CENTER_LAT": "37.7898", "ZIP_CENTER_LON": "-122.3942"},
+		{"ZIP": "94110", "ZIP_CENTER_LAT": "37.7486", "ZIP_CENTER_LON": "-122.4184"},
+		{"ZIP": "94301", "ZIP_CENTER_LAT": "37.4443", "ZIP_CENTER_LON": "-122.1500"},
+		{"ZIP": "90012", "ZIP_CENTER_LAT": "34.0614", "ZIP_CENTER_LON": "-118.2385"},
+	})
+	locator := NewZipCodeLocator(store)
+
+	nearby, err := locator.Within(context.Background(), 37.7879, -122.4075, 10)
+	assert.NoError(t, err)
+	assert.Len(t, nearby, 2)
+	assert.Equal(t, "94105", nearby[0].ZipCode)
+	assert.Equal(t, "94110", nearby[1].ZipCode)
+
+	// palo alto is about 45km away, in the second ring of cells
+	nearby, err = locator.Within(context.Background(), 37.7879, -122.4075, 50)
+	assert.NoError(t, err)
+	assert.Len(t, nearby, 3)
+	assert.Equal(t, "94301", nearby[2].ZipCode)
+
+	nearby, err = locator.Within(context.Background(), 40.7128, -74.0060, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, nearby)
+}
+
+func TestZipCodeCentroid(t *testing.T) {
+	store := NewMemoryStore()
+	store.Put(
+		Item{"zipcode": "94105", "carriertype": "sprint", "zip_center_lat": "37.7898", "zip_center_lon": "-122.3942"},
+		Item{"zipcode": "94106", "carriertype": "sprint"},
+	)
+	locator := NewZipCodeLocator(store)
+
+	lat, lon, found, err := locator.Centroid(context.Background(), "94105")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 37.7898, lat)
+	assert.Equal(t, -122.3942, lon)
+
+	_, _, found, err = locator.Centroid(context.Background(), "94106")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	_, _, found, err = locator.Centroid(context.Background(), "00000")
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestZipCodesWithinAcrossCarriers(t *testing.T) {
+	store := NewMemoryStore()
+	loadLive(t, store, entity.Sprint, "2018-11-01", []map[string]string{
+		{"ZIP": "94105", "ZIP_CENTER_LAT": "37.7898", "ZIP_CENTER_LON": "-122.3942"},
+	})
+	// the centroids the loader stamps from its centroid file, as verizon exports have none
+	live := loadLive(t, store, entity.Verizon, "2018-11-02", []map[string]string{
+		{"zip": "94105", "zip_center_lat": "37.7898", "zip_center_lon": "-122.3942"},
+		{"zip": "94110", "zip_center_lat": "37.7486", "zip_center_lon": "-122.4184"},
+	})
+	locator := NewZipCodeLocator(live)
+
+	nearby, err := locator.Within(context.Background(), 37.7879, -122.4075, 10)
+	assert.NoError(t, err)
+	assert.Len(t, nearby, 2)
+	assert.Equal(t, "94105", nearby[0].ZipCode)
+	assert.Equal(t, "94110", nearby[1].ZipCode)
+
+	lat, lon, found, err := locator.Centroid(context.Background(), "94110")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 37.7486, lat)
+	assert.Equal(t, -122.4184, lon)
+}
+
+// concurrencyStore is a store recording how many queries run at once
+type concurrencyStore struct {
+	Store
+	mu       sync.Mutex
+	queries  int
+	running  int
+	mostSeen int
+}
+
+func (c *concurrencyStore) Query(ctx context.Context, query IndexQuery) (Page, error) {
+	c.mu.Lock()
+	c.queries++
+	c.running++
+	if c.running > c.mostSeen {
+		c.mostSeen = c.running
+	}
+	c.mu.Unlock()
+
+	time.Sleep(time.Millisecond)
+	defer func() {
+		c.mu.Lock()
+		c.running--
+		c.mu.Unlock()
+	}()
+	return c.Store.Query(ctx, query)
+}
+
+func TestZipCodesWithinMaxRadius(t *testing.T) {
+	// validators.MaxRadiusMiles, 25 miles
+	radiusKm := 25 * 1.609344
+	assert.Len(t, cellsWithin(37.7879, -122.4075, radiusKm), 49)
+	// cells narrow towards the poles, up to maxWithinRings rings
+	assert.Len(t, cellsWithin(71.29, -156.79, radiusKm), 81)
+	assert.Len(t, cellsWithin(89.9, 0, radiusKm), (2*maxWithinRings+1)*(maxWithinRings+1))
+
+	store := &concurrencyStore{Store: loadLive(t, NewMemoryStore(), entity.Sprint, "2018-11-01", []map[string]string{
+		{"ZIP": "94105", "ZIP_CENTER_LAT": "37.7898", "ZIP_CENTER_LON": "-122.3942"},
+	})}
+	nearby, err := NewZipCodeLocator(store).Within(context.Background(), 37.7879, -122.4075, radiusKm)
+
+	assert.NoError(t, err)
+	assert.Len(t, nearby, 1)
+	assert.Equal(t, 49, store.queries)
+	assert.True(t, store.mostSeen <= maxCellQueries, "%d queries at once", store.mostSeen)
+}
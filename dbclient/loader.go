@@ -0,0 +1,339 @@
+package dbclient
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/rs/zerolog"
+)
+
+// batchWriteItemLimit is the maximum number of items DynamoDB accepts in a single BatchWriteItem call
+const batchWriteItemLimit = 25
+
+// maxBatchWriteRetries is the number of times UnprocessedItems are retried before the rows are rejected
+const maxBatchWriteRetries = 5
+
+var loaderZipCodeRegex = regexp.MustCompile(`^\d{5}$`)
+
+// CoverageLoader writes rows of a carrier's raw coverage export into the coverage table
+type CoverageLoader interface {
+	Load(ctx context.Context, carrier entity.CarrierType, loadDate string, rows []map[string]string) (LoadSummary, error)
+}
+
+// LoadSummary reports what happened to the rows of a load
+type LoadSummary struct {
+	Read           int
+	Written        int
+	Rejected       int
+	Reasons        map[string]int
+	Rejections     []Rejection
+	IgnoredColumns []string
+	// Summaries is the number of state and county coverage summaries written
+	Summaries int
+}
+
+// Rejection is a row that was not written and why. Row is 1 based.
+type Rejection struct {
+	Row     int
+	ZipCode string
+	Reason  string
+}
+
+type coverageLoader struct {
+	tableName  *string
+	connection dynamodbiface.DynamoDBAPI
+	ruleSet    rules.RuleSet
+	centroids  ZipCodeCentroids
+}
+
+// NewCoverageLoader constructs and gives back a loader writing into the given coverage table. The rule set decides
+// which zipcodes the state and county coverage summaries count as covered. The centroids, which may be nil, locate
+// the zipcodes of exports without centroids.
+func NewCoverageLoader(tableName *string, connection dynamodbiface.DynamoDBAPI, ruleSet rules.RuleSet, centroids ZipCodeCentroids) CoverageLoader {
+	return coverageLoader{tableName: tableName, connection: connection, ruleSet: ruleSet, centroids: centroids}
+}
+
+// Load maps the rows of a carrier export onto the carrier's coverage data, stamps them with the
+// carriertype sort key of the dataset version of the load date, the load date and the geohash of their centroid and writes them in batches. Rows that can't be mapped or
+// written are rejected with a reason rather than failing the load. The summaries of the states and counties of the
+// written rows are written after them. The version isn't live until it is promoted.
+func (l coverageLoader) Load(ctx context.Context, carrier entity.CarrierType, loadDate string, rows []map[string]string) (LoadSummary, error) {
+	schema, ok := LookupCarrier(carrier)
+	if !ok {
+		return LoadSummary{}, ErrUnknownCarrier
+	}
+
+	if err := checkLoadVersion(ctx, dynamoStore{tableName: l.tableName, connection: l.connection}, schema.SortKey, loadDate); err != nil {
+		return LoadSummary{}, err
+	}
+
+	pending, summary := mapRows(schema, loadDate, rows, l.centroids)
+	unprocessed, err := l.writeAll(ctx, pending)
+	if err != nil {
+		return summary, err
+	}
+	rejected := map[int]bool{}
+	for _, p := range unprocessed {
+		summary.reject(p.row, p.zipCode, "unprocessed after retries")
+		rejected[p.row] = true
+	}
+	var written []Item
+	for _, p := range pending {
+		if !rejected[p.row] {
+			written = append(written, p.item)
+		}
+	}
+	summary.Written = len(written)
+
+	// the summaries are written before the meta item, so readers never see a load's date without its summaries
+	var summaries []pendingItem
+	for _, item := range summaryItems(ctx, schema, l.ruleSet, loadDate, written) {
+		summaries = append(summaries, pendingItem{zipCode: item["zipcode"], item: item})
+	}
+	unprocessed, err = l.writeAll(ctx, summaries)
+	if err != nil {
+		return summary, err
+	}
+	if len(unprocessed) > 0 {
+		return summary, fmt.Errorf("%d coverage summaries unprocessed after retries", len(unprocessed))
+	}
+	summary.Summaries = len(summaries)
+
+	// the meta item tells readers such as the cache which load the carrier's coverage data is from
+	_, err = l.connection.PutItemWithContext(ctx, &dynamodb.PutItemInput{
+		TableName: l.tableName,
+		Item:      attributeValues(loadMetaItem(versionedSortKey(schema.SortKey, loadDate), loadDate, summary.Written)),
+	})
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("failed to write load meta item to coverage dynamodb table")
+		return summary, err
+	}
+
+	zerolog.Ctx(ctx).Info().Msgf("loaded %d of %d %s rows, rejected %d, summarized %d states and counties", summary.Written, summary.Read, schema.SortKey, summary.Rejected, summary.Summaries)
+	return summary, nil
+}
+
+// mapRows maps the rows of a carrier export onto coverage items stamped with the carriertype sort key of the load
+// date's dataset version, the geohash and the load date. Rows without a centroid get theirs from the centroids.
+// The summary counts the rows read and rejected.
+func mapRows(schema Carrier, loadDate string, rows []map[string]string, centroids ZipCodeCentroids) ([]pendingItem, LoadSummary) {
+	summary := LoadSummary{Read: len(rows), Reasons: map[string]int{}}
+	columns := schemaColumns(schema)
+	ignored := map[string]bool{}
+	seen := map[string]bool{}
+	var pending []pendingItem
+	for i, row := range rows {
+		attributes := map[string]string{}
+		for column, value := range row {
+			attribute, ok := columns[strings.ToLower(strings.TrimSpace(column))]
+			if !ok {
+				ignored[column] = true
+				continue
+			}
+			attributes[attribute] = strings.TrimSpace(value)
+		}
+
+		zipCode := normalizeZipCode(attributes["zipcode"])
+		attributes["zipcode"] = zipCode
+		if reason := validateRow(schema, attributes, seen); reason != "" {
+			summary.reject(i+1, zipCode, reason)
+			continue
+		}
+		seen[zipCode] = true
+
+		attributes["carriertype"] = versionedSortKey(schema.SortKey, loadDate)
+		attributes["load_date"] = loadDate
+		centroids.stamp(schema, attributes)
+		if schema.centroid != nil {
+			attributes["geohash"] = geohashOf(attributes[schema.centroid.latitudeAttribute], attributes[schema.centroid.longitudeAttribute])
+		}
+		item, err := coverageItem(schema, attributes)
+		if err != nil {
+			summary.reject(i+1, zipCode, "unable to map row onto coverage data")
+			continue
+		}
+		pending = append(pending, pendingItem{row: i + 1, zipCode: zipCode, item: item})
+	}
+
+	for column := range ignored {
+		summary.IgnoredColumns = append(summary.IgnoredColumns, column)
+	}
+	sort.Strings(summary.IgnoredColumns)
+	return pending, summary
+}
+
+// checkLoadVersion gives back why the load date can't be loaded as a dataset version: it must be a valid version that
+// isn't live, since loading it would change the live coverage data in place, and wasn't loaded before, since the
+// rows of the earlier load missing from this one would be left in place
+func checkLoadVersion(ctx context.Context, getter itemGetter, sortKey string, loadDate string) error {
+	if loadDate == "" || strings.Contains(loadDate, versionSeparator) {
+		return fmt.Errorf("invalid load date %q", loadDate)
+	}
+	pointer, found, err := getDatasetPointer(ctx, getter, sortKey)
+	if err != nil {
+		return err
+	}
+	if found && pointer.Version == loadDate {
+		return ErrLiveVersion
+	}
+
+	meta, err := getter.Get(ctx, loadMetaZipCode, versionedSortKey(sortKey, loadDate), []string{"zipcode"})
+	if err != nil {
+		return err
+	}
+	if meta != nil {
+		return ErrVersionLoaded
+	}
+	return nil
+}
+
+// reject counts a row that was not written
+func (s *LoadSummary) reject(row int, zipCode string, reason string) {
+	s.Rejected++
+	s.Reasons[reason]++
+	s.Rejections = append(s.Rejections, Rejection{Row: row, ZipCode: zipCode, Reason: reason})
+}
+
+// pendingItem is a mapped row waiting to be written
+type pendingItem struct {
+	row     int
+	zipCode string
+	item    Item
+}
+
+// writeAll puts the items in batches and gives back the items that were still unprocessed when the retries ran out
+func (l coverageLoader) writeAll(ctx context.Context, items []pendingItem) ([]pendingItem, error) {
+	var unprocessed []pendingItem
+	for start := 0; start < len(items); start += batchWriteItemLimit {
+		end := start + batchWriteItemLimit
+		if end > len(items) {
+			end = len(items)
+		}
+
+		batchUnprocessed, err := l.batchWrite(ctx, items[start:end])
+		if err != nil {
+			return nil, err
+		}
+		unprocessed = append(unprocessed, batchUnprocessed...)
+	}
+	return unprocessed, nil
+}
+
+// batchWrite puts the items, retrying UnprocessedItems with an exponential backoff, and gives back
+// the items that were still unprocessed when the retries ran out
+func (l coverageLoader) batchWrite(ctx context.Context, items []pendingItem) ([]pendingItem, error) {
+	byKey := map[string]pendingItem{}
+	requests := make([]*dynamodb.WriteRequest, 0, len(items))
+	for _, p := range items {
+		byKey[p.zipCode] = p
+		requests = append(requests, &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: attributeValues(p.item)}})
+	}
+
+	delay := batchRetryBaseDelay
+	for attempt := 0; len(requests) > 0; attempt++ {
+		if attempt > 0 {
+			if attempt > maxBatchWriteRetries {
+				break
+			}
+			zerolog.Ctx(ctx).Debug().Msgf("retrying %d unprocessed items in %s", len(requests), delay)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		result, err := l.connection.BatchWriteItemWithContext(ctx, &dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]*dynamodb.WriteRequest{*l.tableName: requests},
+		})
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("failed to batch write items to coverage dynamodb table")
+			return nil, err
+		}
+		requests = result.UnprocessedItems[*l.tableName]
+	}
+
+	var unprocessed []pendingItem
+	for _, request := range requests {
+		unprocessed = append(unprocessed, byKey[aws.StringValue(request.PutRequest.Item["zipcode"].S)])
+	}
+	return unprocessed, nil
+}
+
+// schemaColumns maps the lower cased columns a carrier export may use onto the attributes of its coverage data
+func schemaColumns(schema Carrier) map[string]string {
+	columns := map[string]string{}
+	dataType := reflect.TypeOf(schema.newData()).Elem()
+	for i := 0; i < dataType.NumField(); i++ {
+		attribute := strings.Split(dataType.Field(i).Tag.Get("json"), ",")[0]
+		if attribute == "" || attribute == "carriertype" || attribute == "load_date" || attribute == "geohash" {
+			continue
+		}
+		columns[strings.ToLower(attribute)] = attribute
+	}
+	for column, attribute := range schema.aliases {
+		columns[column] = attribute
+	}
+	return columns
+}
+
+// normalizeZipCode restores the leading zeros spreadsheet exports drop from zipcodes such as 00501
+func normalizeZipCode(zipCode string) string {
+	if _, err := strconv.Atoi(zipCode); err == nil && len(zipCode) >= 3 && len(zipCode) < 5 {
+		return strings.Repeat("0", 5-len(zipCode)) + zipCode
+	}
+	return zipCode
+}
+
+// validateRow gives back why a row must be rejected, or an empty string when it can be written
+func validateRow(schema Carrier, attributes map[string]string, seen map[string]bool) string {
+	zipCode := attributes["zipcode"]
+	if zipCode == "" {
+		return "missing zipcode"
+	}
+	if !loaderZipCodeRegex.MatchString(zipCode) {
+		return "invalid zipcode"
+	}
+	if seen[zipCode] {
+		return "duplicate zipcode"
+	}
+	for _, attribute := range schema.numericAttributes {
+		if value := attributes[attribute]; value != "" {
+			// ParseFloat reads NaN and Inf, which are no coverage percentage either
+			if v, err := strconv.ParseFloat(value, 64); err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+				return fmt.Sprintf("non numeric %s", attribute)
+			}
+		}
+	}
+	return ""
+}
+
+// coverageItem maps the attributes onto the carrier's coverage data struct and gives back its item.
+// Empty attributes are left out of the item.
+func coverageItem(schema Carrier, attributes map[string]string) (Item, error) {
+	data := schema.newData()
+	if err := decodeItem(attributes, data); err != nil {
+		return nil, err
+	}
+
+	item := Item{}
+	for attribute, value := range attributesOf(data) {
+		if value != "" {
+			item[attribute] = value
+		}
+	}
+	return item, nil
+}
@@ -0,0 +1,249 @@
+package dbclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeWriteDynamoDB keeps the items it is asked to put. unprocessed items are handed back
+// as UnprocessedItems on their first put.
+type fakeWriteDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+	t           *testing.T
+	tableName   *string
+	items       map[string]map[string]string
+	unprocessed map[string]int
+	calls       int
+	err         error
+	putErr      error
+}
+
+func (f *fakeWriteDynamoDB) BatchWriteItemWithContext(ctx aws.Context, input *dynamodb.BatchWriteItemInput, opts ...request.Option) (*dynamodb.BatchWriteItemOutput, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	requests := input.RequestItems[*f.tableName]
+	assert.True(f.t, len(requests) <= batchWriteItemLimit, "too many items in a single batch write")
+
+	output := &dynamodb.BatchWriteItemOutput{UnprocessedItems: map[string][]*dynamodb.WriteRequest{}}
+	for _, writeRequest := range requests {
+		zipCode := aws.StringValue(writeRequest.PutRequest.Item["zipcode"].S)
+		if f.unprocessed[zipCode] > 0 {
+			f.unprocessed[zipCode]--
+			output.UnprocessedItems[*f.tableName] = append(output.UnprocessedItems[*f.tableName], writeRequest)
+			continue
+		}
+		item := map[string]string{}
+		for attribute, value := range writeRequest.PutRequest.Item {
+			item[attribute] = aws.StringValue(value.S)
+		}
+		f.items[zipCode] = item
+	}
+	return output, nil
+}
+
+func (f *fakeWriteDynamoDB) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error) {
+	if f.putErr != nil {
+		return nil, f.putErr
+	}
+	item := map[string]string{}
+	for attribute, value := range input.Item {
+		item[attribute] = aws.StringValue(value.S)
+	}
+	f.items[item["zipcode"]+"/"+item["carriertype"]] = item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+// GetItemWithContext reads the items put with PutItemWithContext, such as the dataset pointer
+func (f *fakeWriteDynamoDB) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	key := aws.StringValue(input.Key["zipcode"].S) + "/" + aws.StringValue(input.Key["carriertype"].S)
+	return &dynamodb.GetItemOutput{Item: attributeValues(f.items[key])}, nil
+}
+
+func TestLoadSprintRows(t *testing.T) {
+	tableName := aws.String("fakeCoverage")
+	fakeDb := &fakeWriteDynamoDB{t: t, tableName: tableName, items: map[string]map[string]string{}}
+
+	rows := []map[string]string{
+		{"ZIP": "94105", "CSA_Leaf": "SFRSFR415", "Cur_Pct_Cov": "100", "LTE_4G_PctCov": "99.5", "STATE": "CA", "Extra": "x"},
+		{"ZIP": "501", "Cur_Pct_Cov": "0", "LTE_2500_PctCov": ""},
+		{"ZIP": "94105", "Cur_Pct_Cov": "100"},
+		{"ZIP": "941055", "Cur_Pct_Cov": "100"},
+		{"ZIP": "ABCDE", "Cur_Pct_Cov": "100"},
+		{"Cur_Pct_Cov": "100"},
+		{"ZIP": "94106", "Cur_Pct_Cov": "lots"},
+		{"ZIP": "94107", "Cur_Pct_Cov": "NaN"},
+		{"ZIP": "94108", "Cur_Pct_Cov": "Inf"},
+		{"ZIP": "94109", "Cur_Pct_Cov": "-Inf"},
+	}
+
+	summary, err := NewCoverageLoader(tableName, fakeDb, rules.Default(), nil).Load(context.Background(), entity.Sprint, "2018-11-01", rows)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 10, summary.Read)
+	assert.Equal(t, 2, summary.Written)
+	assert.Equal(t, 8, summary.Rejected)
+	assert.Equal(t, map[string]int{
+		"duplicate zipcode":       1,
+		"invalid zipcode":         2,
+		"missing zipcode":         1,
+		"non numeric cur_pct_cov": 4,
+	}, summary.Reasons)
+	assert.Equal(t, Rejection{Row: 3, ZipCode: "94105", Reason: "duplicate zipcode"}, summary.Rejections[0])
+	assert.Equal(t, []string{"Extra"}, summary.IgnoredColumns)
+	assert.Equal(t, map[string]string{
+		"zipcode":       "94105",
+		"carriertype":   "sprint#2018-11-01",
+		"csa_leaf":      "SFRSFR415",
+		"cur_pct_cov":   "100",
+		"lte_4g_pctcov": "99.5",
+		"state":         "CA",
+		"load_date":     "2018-11-01",
+	}, fakeDb.items["94105"])
+	assert.Equal(t, map[string]string{
+		"zipcode":     "00501",
+		"carriertype": "sprint#2018-11-01",
+		"cur_pct_cov": "0",
+		"load_date":   "2018-11-01",
+	}, fakeDb.items["00501"])
+	assert.Equal(t, map[string]string{
+		"zipcode":     loadMetaZipCode,
+		"carriertype": "sprint#2018-11-01",
+		"load_date":   "2018-11-01",
+		"rows":        "2",
+	}, fakeDb.items[loadMetaZipCode+"/sprint#2018-11-01"])
+	assert.Equal(t, 1, summary.Summaries)
+	assert.Equal(t, "1", fakeDb.items["_summary#CA"]["zipcodes"])
+	assert.Equal(t, "1", fakeDb.items["_summary#CA"]["covered"])
+	assert.Equal(t, "2018-11-01", fakeDb.items["_summary#CA"]["load_date"])
+}
+
+func TestLoadVerizonRowsWithAliases(t *testing.T) {
+	tableName := aws.String("fakeCoverage")
+	fakeDb := &fakeWriteDynamoDB{t: t, tableName: tableName, items: map[string]map[string]string{}}
+
+	rows := []map[string]string{
+		{"zip": "94105", "VZW_LTE": "100", "VZW_LTE_IND": "Y", "State": "CA", "ALL_LTE_IND": "Y"},
+	}
+
+	summary, err := NewCoverageLoader(tableName, fakeDb, rules.Default(), nil).Load(context.Background(), entity.Verizon, "2018-11-01", rows)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summary.Written)
+	assert.Equal(t, map[string]string{
+		"zipcode":     "94105",
+		"carriertype": "verizon#2018-11-01",
+		"vzelte":      "100",
+		"vze_lte_ind": "Y",
+		"all_tle_ind": "Y",
+		"state":       "CA",
+		"load_date":   "2018-11-01",
+	}, fakeDb.items["94105"])
+}
+
+func TestLoadVerizonRowsWithCentroids(t *testing.T) {
+	tableName := aws.String("fakeCoverage")
+	fakeDb := &fakeWriteDynamoDB{t: t, tableName: tableName, items: map[string]map[string]string{}}
+	centroids := ZipCodeCentroids{"94105": {Lat: 37.7898, Lon: -122.3942}}
+
+	rows := []map[string]string{
+		{"zip": "94105", "VZW_LTE": "100", "State": "CA"},
+		{"zip": "94106", "VZW_LTE": "100", "State": "CA"},
+		{"zip": "94107", "VZW_LTE": "100", "State": "CA", "zip_center_lat": "37.7621", "zip_center_lon": "-122.3971"},
+	}
+
+	summary, err := NewCoverageLoader(tableName, fakeDb, rules.Default(), centroids).Load(context.Background(), entity.Verizon, "2018-11-01", rows)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, summary.Written)
+	assert.Equal(t, "37.7898", fakeDb.items["94105"]["zip_center_lat"])
+	assert.Equal(t, "-122.3942", fakeDb.items["94105"]["zip_center_lon"])
+	assert.Equal(t, geohash(37.7898, -122.3942, geohashPrecision), fakeDb.items["94105"]["geohash"])
+	assert.NotContains(t, fakeDb.items["94106"], "geohash")
+	assert.Equal(t, "37.7621", fakeDb.items["94107"]["zip_center_lat"])
+	assert.Equal(t, geohash(37.7621, -122.3971, geohashPrecision), fakeDb.items["94107"]["geohash"])
+}
+
+func TestLoadBatchesAndRetries(t *testing.T) {
+	tableName := aws.String("fakeCoverage")
+	fakeDb := &fakeWriteDynamoDB{
+		t:           t,
+		tableName:   tableName,
+		items:       map[string]map[string]string{},
+		unprocessed: map[string]int{"10001": 2, "10002": maxBatchWriteRetries + 1},
+	}
+
+	var rows []map[string]string
+	for i := 0; i < 60; i++ {
+		rows = append(rows, map[string]string{"zipcode": fmt.Sprintf("%05d", 10000+i)})
+	}
+
+	summary, err := NewCoverageLoader(tableName, fakeDb, rules.Default(), nil).Load(context.Background(), entity.Sprint, "2018-11-01", rows)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 59, summary.Written)
+	assert.Equal(t, []Rejection{{Row: 3, ZipCode: "10002", Reason: "unprocessed after retries"}}, summary.Rejections)
+	assert.Contains(t, fakeDb.items, "10001")
+	assert.NotContains(t, fakeDb.items, "10002")
+	// 3 batches, the first one retried until its retries ran out
+	assert.Equal(t, 3+maxBatchWriteRetries, fakeDb.calls)
+}
+
+func TestLoadSadPath(t *testing.T) {
+	tableName := aws.String("fakeCoverage")
+	fakeDb := &fakeWriteDynamoDB{t: t, tableName: tableName, err: errors.New("Fake error")}
+
+	_, err := NewCoverageLoader(tableName, fakeDb, rules.Default(), nil).Load(context.Background(), entity.Sprint, "2018-11-01", []map[string]string{{"zipcode": "94105"}})
+	assert.Error(t, err)
+
+	_, err = NewCoverageLoader(tableName, fakeDb, rules.Default(), nil).Load(context.Background(), entity.CarrierType("9"), "2018-11-01", nil)
+	assert.EqualError(t, err, "Invalid Carrier Type")
+}
+
+func TestLoadRejectsLoadedVersion(t *testing.T) {
+	tableName := aws.String("fakeCoverage")
+	fakeDb := &fakeWriteDynamoDB{t: t, tableName: tableName, items: map[string]map[string]string{}}
+	loader := NewCoverageLoader(tableName, fakeDb, rules.Default(), nil)
+
+	_, err := loader.Load(context.Background(), entity.Sprint, "2018-11-01", []map[string]string{
+		{"ZIP": "94105", "Cur_Pct_Cov": "100", "STATE": "CA"},
+		{"ZIP": "94106", "Cur_Pct_Cov": "100", "STATE": "CA"},
+	})
+	assert.NoError(t, err)
+	calls := fakeDb.calls
+
+	// a smaller reload would leave 94106 in the version and its summaries counting both
+	summary, err := loader.Load(context.Background(), entity.Sprint, "2018-11-01", []map[string]string{
+		{"ZIP": "94105", "Cur_Pct_Cov": "0", "STATE": "CA"},
+	})
+
+	assert.Equal(t, ErrVersionLoaded, err)
+	assert.Equal(t, LoadSummary{}, summary)
+	assert.Equal(t, calls, fakeDb.calls)
+	assert.Equal(t, "100", fakeDb.items["94105"]["cur_pct_cov"])
+	assert.Equal(t, "2", fakeDb.items[loadMetaZipCode+"/sprint#2018-11-01"]["rows"])
+
+	_, err = loader.Load(context.Background(), entity.Sprint, "2018-11-02", []map[string]string{{"ZIP": "94105", "Cur_Pct_Cov": "0"}})
+	assert.NoError(t, err)
+}
+
+func TestLoadMetaItemSadPath(t *testing.T) {
+	tableName := aws.String("fakeCoverage")
+	fakeDb := &fakeWriteDynamoDB{t: t, tableName: tableName, items: map[string]map[string]string{}, putErr: errors.New("Fake error")}
+
+	summary, err := NewCoverageLoader(tableName, fakeDb, rules.Default(), nil).Load(context.Background(), entity.Sprint, "2018-11-01", []map[string]string{{"zipcode": "94105"}})
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, summary.Written)
+}
@@ -0,0 +1,83 @@
+package dbclient
+
+import (
+	"context"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
+	"github.com/rs/zerolog"
+)
+
+// marketAreaType is a kind of market area in a carrier's coverage data and the attributes holding its code and name.
+// Either attribute may be empty when the carrier only codes or only names the market area.
+type marketAreaType struct {
+	name          string
+	codeAttribute string
+	nameAttribute string
+}
+
+// MarketAreas are the market areas a carrier places a zipcode in. Found is false when the carrier's coverage
+// data has none for the zipcode. Coverage is the carrier's coverage of the zipcode, read off the same item.
+type MarketAreas struct {
+	Found    bool
+	Areas    []entity.MarketArea
+	Coverage Coverage
+}
+
+// MarketAreaClient looks up the market areas of a zipcode for any registered carrier
+type MarketAreaClient interface {
+	GetMarketAreas(ctx context.Context, carrier entity.CarrierType, zipCode string) (MarketAreas, error)
+}
+
+type marketAreaClient struct {
+	store   Store
+	ruleSet rules.RuleSet
+}
+
+// NewMarketAreaClient constructs and gives back a market area client reading the store, evaluating the coverage
+// of the zipcodes with the rule set
+func NewMarketAreaClient(store Store, ruleSet rules.RuleSet) MarketAreaClient {
+	return marketAreaClient{store: store, ruleSet: ruleSet}
+}
+
+func (m marketAreaClient) GetMarketAreas(ctx context.Context, carrierID entity.CarrierType, zipCode string) (MarketAreas, error) {
+	carrier, ok := LookupCarrier(carrierID)
+	if !ok {
+		return MarketAreas{}, ErrUnknownCarrier
+	}
+	rule := m.ruleSet.Carriers[carrier.SortKey]
+
+	attributes := coverageAttributes(rule, carrier.technologies, marketAreaAttributes(carrier.marketAreas)...)
+	item, err := m.store.Get(ctx, zipCode, carrier.SortKey, attributes)
+	if err != nil {
+		return MarketAreas{}, err
+	}
+	if item == nil {
+		zerolog.Ctx(ctx).Debug().Msgf("Could not find %s market areas for zipcode: %s", carrier.Name, zipCode)
+		return MarketAreas{Coverage: unknownCoverage(ctx, m.store, carrier.SortKey)}, nil
+	}
+
+	var areas []entity.MarketArea
+	for _, areaType := range carrier.marketAreas {
+		area := entity.MarketArea{Type: areaType.name, Code: item[areaType.codeAttribute], Name: item[areaType.nameAttribute]}
+		if area.Code == "" && area.Name == "" {
+			continue
+		}
+		areas = append(areas, area)
+	}
+	coverage := evaluateCoverage(ctx, rule, carrier.technologies, zipCode, item)
+	return MarketAreas{Found: len(areas) > 0, Areas: areas, Coverage: coverage}, nil
+}
+
+// marketAreaAttributes are the zipcode and the code and name attributes of the market area types
+func marketAreaAttributes(areaTypes []marketAreaType) []string {
+	attributes := []string{"zipcode"}
+	for _, areaType := range areaTypes {
+		for _, attribute := range []string{areaType.codeAttribute, areaType.nameAttribute} {
+			if attribute != "" {
+				attributes = append(attributes, attribute)
+			}
+		}
+	}
+	return attributes
+}
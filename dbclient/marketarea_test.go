@@ -0,0 +1,110 @@
+package dbclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingStore is a store whose reads fail
+type failingStore struct {
+	*MemoryStore
+}
+
+func (f failingStore) Get(ctx context.Context, zipCode string, sortKey string, attributes []string) (Item, error) {
+	return nil, errors.New("Fake error")
+}
+
+func TestGetMarketAreas(t *testing.T) {
+	store := NewMemoryStore()
+	store.Put(
+		Item{"zipcode": "94105", "carriertype": "sprint", "csa_leaf": "SFRSFR415", "mkt_name": "San Francisco", "cur_pct_cov": "100", "lte_4g_pctcov": "100", "load_date": "2018-11-01"},
+		Item{"zipcode": "94106", "carriertype": "sprint", "cur_pct_cov": "100", "lte_4g_pctcov": "100", "load_date": "2018-11-01"},
+		Item{"zipcode": "94105", "carriertype": "verizon", "mtacode": "M024", "mtaname": "San Francisco-Oakland-San Jose", "btacode": "B404", "btaname": "San Francisco-Oakland-San Jose", "msarsacode": "MSA7360", "load_date": "2018-11-02"},
+		Item{"zipcode": "94105", "carriertype": "att", "cma_name": "San Francisco-Oakland, CA"},
+		loadMetaItem("tmobile", "2018-11-03", 1),
+	)
+
+	testCases := []struct {
+		desc     string
+		carrier  entity.CarrierType
+		zipCode  string
+		expected MarketAreas
+		found    bool
+		covered  bool
+		loadDate string
+	}{
+		{
+			desc:    "Sprint csa and market",
+			carrier: entity.Sprint,
+			zipCode: "94105",
+			expected: MarketAreas{Found: true, Areas: []entity.MarketArea{
+				{Type: "CSA", Code: "SFRSFR415"},
+				{Type: "Market", Name: "San Francisco"},
+			}},
+			found:    true,
+			covered:  true,
+			loadDate: "2018-11-01",
+		},
+		{
+			desc:     "Sprint zipcode without market areas",
+			carrier:  entity.Sprint,
+			zipCode:  "94106",
+			expected: MarketAreas{},
+			found:    true,
+			covered:  true,
+			loadDate: "2018-11-01",
+		},
+		{
+			desc:    "Verizon mta, bta and msa",
+			carrier: entity.Verizon,
+			zipCode: "94105",
+			expected: MarketAreas{Found: true, Areas: []entity.MarketArea{
+				{Type: "MTA", Code: "M024", Name: "San Francisco-Oakland-San Jose"},
+				{Type: "BTA", Code: "B404", Name: "San Francisco-Oakland-San Jose"},
+				{Type: "MSA/RSA", Code: "MSA7360"},
+			}},
+			found:    true,
+			loadDate: "2018-11-02",
+		},
+		{
+			desc:     "AT&T cma",
+			carrier:  entity.ATT,
+			zipCode:  "94105",
+			expected: MarketAreas{Found: true, Areas: []entity.MarketArea{{Type: "CMA", Name: "San Francisco-Oakland, CA"}}},
+			found:    true,
+		},
+		{
+			desc:     "T-Mobile zipcode without coverage data",
+			carrier:  entity.TMobile,
+			zipCode:  "94105",
+			expected: MarketAreas{},
+			loadDate: "2018-11-03",
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			result, err := NewMarketAreaClient(store, rules.Default()).GetMarketAreas(context.Background(), tC.carrier, tC.zipCode)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tC.expected.Found, result.Found)
+			assert.Equal(t, tC.expected.Areas, result.Areas)
+			assert.Equal(t, tC.found, result.Coverage.Found)
+			assert.Equal(t, tC.covered, result.Coverage.IsCovered)
+			assert.Equal(t, tC.loadDate, result.Coverage.LoadDate)
+		})
+	}
+}
+
+func TestGetMarketAreasSadPath(t *testing.T) {
+	_, err := NewMarketAreaClient(NewMemoryStore(), rules.Default()).GetMarketAreas(context.Background(), entity.CarrierType("9"), "94105")
+	assert.EqualError(t, err, "Invalid Carrier Type")
+
+	_, err = NewMarketAreaClient(failingStore{NewMemoryStore()}, rules.Default()).GetMarketAreas(context.Background(), entity.Sprint, "94105")
+	assert.Error(t, err)
+}
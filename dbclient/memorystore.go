@@ -0,0 +1,235 @@
+package dbclient
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
+	"github.com/rs/zerolog"
+)
+
+// MemoryStore is a Store kept in memory, for tests and running without DynamoDB. It is loaded from carrier
+// exports the same way as the coverage table, so it is a CoverageLoader as well.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	items   map[memoryKey]Item
+	ruleSet rules.RuleSet
+}
+
+type memoryKey struct {
+	zipCode string
+	sortKey string
+}
+
+// NewMemoryStore constructs and gives back an empty memory store. Its loads summarize coverage with the default
+// coverage rules until SetRuleSet is called.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{items: map[memoryKey]Item{}, ruleSet: rules.Default()}
+}
+
+// SetRuleSet sets the rule set later loads summarize coverage with
+func (m *MemoryStore) SetRuleSet(ruleSet rules.RuleSet) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.ruleSet = ruleSet
+}
+
+// Put adds or replaces items. Every item must carry its zipcode and carriertype.
+func (m *MemoryStore) Put(items ...Item) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, item := range items {
+		stored := Item{}
+		for attribute, value := range item {
+			stored[attribute] = value
+		}
+		m.items[memoryKey{zipCode: item["zipcode"], sortKey: item["carriertype"]}] = stored
+	}
+}
+
+func (m *MemoryStore) Get(ctx context.Context, zipCode string, sortKey string, attributes []string) (Item, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	item, ok := m.items[memoryKey{zipCode: zipCode, sortKey: sortKey}]
+	if !ok {
+		return nil, nil
+	}
+	return project(item, attributes), nil
+}
+
+func (m *MemoryStore) BatchGet(ctx context.Context, sortKey string, zipCodes []string, attributes []string) ([]Item, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var items []Item
+	for _, zipCode := range zipCodes {
+		if item, ok := m.items[memoryKey{zipCode: zipCode, sortKey: sortKey}]; ok {
+			items = append(items, project(item, attributes))
+		}
+	}
+	return items, nil
+}
+
+// Query reads a page of the items whose hash key attribute has the value, the way a sparse secondary index would.
+// Items are ordered by zipcode and then carriertype.
+func (m *MemoryStore) Query(ctx context.Context, query IndexQuery) (Page, error) {
+	var after Item
+	if query.Token != "" {
+		key, err := decodeToken(query)
+		if err != nil {
+			return Page{}, err
+		}
+		after = key
+	}
+
+	m.mu.RLock()
+	var matches []Item
+	for _, item := range m.items {
+		if item[query.HashKey] == query.Value {
+			matches = append(matches, item)
+		}
+	}
+	m.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool { return itemBefore(matches[i], matches[j]) })
+	page := Page{Items: []Item{}}
+	var last Item
+	for _, item := range matches {
+		if after != nil && !itemBefore(after, item) {
+			continue
+		}
+		if query.Limit > 0 && len(page.Items) == query.Limit {
+			page.NextToken = encodeToken(Item{query.HashKey: query.Value, "zipcode": last["zipcode"], "carriertype": last["carriertype"]})
+			break
+		}
+		last = item
+		if len(query.Attributes) == 0 {
+			page.Items = append(page.Items, project(item, keysOf(item)))
+			continue
+		}
+		page.Items = append(page.Items, project(item, query.Attributes))
+	}
+	return page, nil
+}
+
+// keysOf gives back the attribute names of an item
+func keysOf(item Item) []string {
+	attributes := make([]string, 0, len(item))
+	for attribute := range item {
+		attributes = append(attributes, attribute)
+	}
+	return attributes
+}
+
+// itemBefore orders items by zipcode and then carriertype
+func itemBefore(a Item, b Item) bool {
+	if a["zipcode"] != b["zipcode"] {
+		return a["zipcode"] < b["zipcode"]
+	}
+	return a["carriertype"] < b["carriertype"]
+}
+
+// Check always succeeds, an in-memory store can always be read
+func (m *MemoryStore) Check(ctx context.Context) error {
+	return nil
+}
+
+// Load maps the rows of a carrier export onto coverage items the same way the coverage table loader does and puts them
+// under the load date's dataset version. The version isn't live until it is promoted.
+func (m *MemoryStore) Load(ctx context.Context, carrier entity.CarrierType, loadDate string, rows []map[string]string) (LoadSummary, error) {
+	schema, ok := LookupCarrier(carrier)
+	if !ok {
+		return LoadSummary{}, ErrUnknownCarrier
+	}
+
+	if err := checkLoadVersion(ctx, m, schema.SortKey, loadDate); err != nil {
+		return LoadSummary{}, err
+	}
+
+	pending, summary := mapRows(schema, loadDate, rows, nil)
+	written := make([]Item, 0, len(pending))
+	for _, p := range pending {
+		m.Put(p.item)
+		written = append(written, p.item)
+	}
+	summary.Written = len(pending)
+
+	m.mu.RLock()
+	ruleSet := m.ruleSet
+	m.mu.RUnlock()
+	summaries := summaryItems(ctx, schema, ruleSet, loadDate, written)
+	m.Put(summaries...)
+	summary.Summaries = len(summaries)
+	m.Put(loadMetaItem(versionedSortKey(schema.SortKey, loadDate), loadDate, summary.Written))
+
+	zerolog.Ctx(ctx).Info().Msgf("loaded %d of %d %s rows into memory, rejected %d, summarized %d states and counties", summary.Written, summary.Read, schema.SortKey, summary.Rejected, summary.Summaries)
+	return summary, nil
+}
+
+// Datasets gives back the dataset versions of the memory store
+func (m *MemoryStore) Datasets() Datasets {
+	return datasets{storage: m}
+}
+
+// loadMetaItems gives back the meta items of every version of a carrier
+func (m *MemoryStore) loadMetaItems(ctx context.Context, sortKey string) ([]Item, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var items []Item
+	for key, item := range m.items {
+		if key.zipCode == loadMetaZipCode && strings.HasPrefix(key.sortKey, sortKey+versionSeparator) {
+			items = append(items, project(item, keysOf(item)))
+		}
+	}
+	return items, nil
+}
+
+// putPointer puts the pointer item if the live version is still current
+func (m *MemoryStore) putPointer(ctx context.Context, pointer Item, current string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := memoryKey{zipCode: livePointerZipCode, sortKey: pointer["carriertype"]}
+	existing, found := m.items[key]
+	if (current == "" && found) || (current != "" && existing["version"] != current) {
+		return ErrConcurrentPromotion
+	}
+	m.items[key] = project(pointer, keysOf(pointer))
+	return nil
+}
+
+// deleteVersion deletes every item of a version and gives back how many there were
+func (m *MemoryStore) deleteVersion(ctx context.Context, sortKey string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := 0
+	for key := range m.items {
+		if key.sortKey == sortKey {
+			delete(m.items, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// versionItems gives back every item of a version
+func (m *MemoryStore) versionItems(ctx context.Context, sortKey string) ([]Item, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var items []Item
+	for key, item := range m.items {
+		if key.sortKey == sortKey {
+			items = append(items, project(item, keysOf(item)))
+		}
+	}
+	return items, nil
+}
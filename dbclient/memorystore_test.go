@@ -0,0 +1,108 @@
+package dbclient
+
+import (
+	"context"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	store.Put(
+		Item{"zipcode": "94105", "carriertype": "sprint", "cur_pct_cov": "100", "lte_4g_pctcov": "100", "csa_leaf": "SFRSFR415"},
+		Item{"zipcode": "94105", "carriertype": "verizon", "vzelte": "100"},
+	)
+
+	item, err := store.Get(context.Background(), "94105", "sprint", []string{"zipcode", "csa_leaf", "state"})
+	assert.NoError(t, err)
+	assert.Equal(t, Item{"zipcode": "94105", "csa_leaf": "SFRSFR415"}, item)
+
+	item, err = store.Get(context.Background(), "94106", "sprint", []string{"zipcode"})
+	assert.NoError(t, err)
+	assert.Nil(t, item)
+
+	items, err := store.BatchGet(context.Background(), "verizon", []string{"94105", "94106"}, []string{"zipcode", "vzelte"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Item{{"zipcode": "94105", "vzelte": "100"}}, items)
+}
+
+// loadLive loads the rows into the memory store, promotes the load and gives back a store reading the live versions
+func loadLive(t *testing.T, store *MemoryStore, carrier entity.CarrierType, loadDate string, rows []map[string]string) Store {
+	_, err := store.Load(context.Background(), carrier, loadDate, rows)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Datasets().Promote(context.Background(), carrier, loadDate))
+	return NewVersionedStore(store, 0)
+}
+
+func TestMemoryStoreLoad(t *testing.T) {
+	store := NewMemoryStore()
+	summary, err := store.Load(context.Background(), entity.Sprint, "2018-11-01", []map[string]string{
+		{"ZIP": "94105", "Cur_Pct_Cov": "100", "LTE_4G_PctCov": "100"},
+		{"ZIP": "abc"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summary.Written)
+	assert.Equal(t, 1, summary.Rejected)
+
+	item, err := store.Get(context.Background(), "94105", "sprint#2018-11-01", []string{"zipcode", "carriertype"})
+	assert.NoError(t, err)
+	assert.Equal(t, Item{"zipcode": "94105", "carriertype": "sprint#2018-11-01"}, item)
+
+	live := NewVersionedStore(store, 0)
+	_, found, err := GetLoadMeta(context.Background(), live, "sprint")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	assert.NoError(t, store.Datasets().Promote(context.Background(), entity.Sprint, "2018-11-01"))
+	sprintClient := NewSprintClient(live, rules.Default().Carriers["sprint"])
+	coverage, err := sprintClient.VerifyCoverage(context.Background(), "94105")
+	assert.NoError(t, err)
+	assert.True(t, coverage.IsCovered)
+	assert.Equal(t, "2018-11-01", coverage.LoadDate)
+
+	coverage, err = sprintClient.VerifyCoverage(context.Background(), "11111")
+	assert.NoError(t, err)
+	assert.Equal(t, Coverage{LoadDate: "2018-11-01"}, coverage)
+
+	meta, found, err := GetLoadMeta(context.Background(), live, "sprint")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, LoadMeta{LoadDate: "2018-11-01", Rows: 1}, meta)
+
+	_, err = store.Load(context.Background(), entity.Sprint, "2018-11-01", nil)
+	assert.Equal(t, ErrLiveVersion, err)
+
+	_, found, err = GetLoadMeta(context.Background(), live, "verizon")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	_, err = store.Load(context.Background(), entity.CarrierType("9"), "2018-11-01", nil)
+	assert.Error(t, err)
+}
+
+func TestLoadFixtures(t *testing.T) {
+	store := NewMemoryStore()
+	err := LoadFixtures(context.Background(), store, store.Datasets(), "1:../sprint_coverage_batch_data.json, 2:../verizon_coverage_batch_data.json", "2018-11-01")
+	assert.NoError(t, err)
+
+	live := NewVersionedStore(store, 0)
+	sprintClient := NewSprintClient(live, rules.Default().Carriers["sprint"])
+	coverage, err := sprintClient.VerifyCoverage(context.Background(), "94105")
+	assert.NoError(t, err)
+	assert.True(t, coverage.IsCovered)
+
+	marketAreas, err := NewMarketAreaClient(live, rules.Default()).GetMarketAreas(context.Background(), entity.Sprint, "00015")
+	assert.NoError(t, err)
+	assert.Equal(t, entity.MarketArea{Type: "CSA", Code: "PHXTUC520"}, marketAreas.Areas[0])
+
+	item, err := live.Get(context.Background(), "94106", "verizon", []string{"zipcode", "load_date"})
+	assert.NoError(t, err)
+	assert.Equal(t, Item{"zipcode": "94106", "load_date": "2018-11-01"}, item)
+
+	assert.Error(t, LoadFixtures(context.Background(), store, store.Datasets(), "sprint.json", "2018-11-01"))
+	assert.Error(t, LoadFixtures(context.Background(), store, store.Datasets(), "1:does-not-exist.json", "2018-11-01"))
+}
@@ -0,0 +1,38 @@
+package dbclient
+
+import (
+	"context"
+	"strconv"
+)
+
+// loadMetaZipCode is the zipcode of the item the loader writes for every carrier it loads. It can't clash
+// with a real zipcode, which is always 5 digits.
+const loadMetaZipCode = "_load"
+
+// LoadMeta describes the last load of a carrier's coverage data
+type LoadMeta struct {
+	LoadDate string
+	Rows     int
+}
+
+// loadMetaItem gives back the meta item recording a carrier's load
+func loadMetaItem(sortKey string, loadDate string, rows int) Item {
+	return Item{
+		"zipcode":     loadMetaZipCode,
+		"carriertype": sortKey,
+		"load_date":   loadDate,
+		"rows":        strconv.Itoa(rows),
+	}
+}
+
+// GetLoadMeta reads what the loader recorded about the last load of a carrier. found is false when the
+// carrier's coverage data wasn't written by the loader.
+func GetLoadMeta(ctx context.Context, store Store, sortKey string) (LoadMeta, bool, error) {
+	item, err := store.Get(ctx, loadMetaZipCode, sortKey, []string{"zipcode", "load_date", "rows"})
+	if err != nil || item == nil {
+		return LoadMeta{}, false, err
+	}
+
+	rows, _ := strconv.Atoi(item["rows"])
+	return LoadMeta{LoadDate: item["load_date"], Rows: rows}, true, nil
+}
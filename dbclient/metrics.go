@@ -0,0 +1,23 @@
+package dbclient
+
+import (
+	"time"
+
+	"bitbucket.org/credomobile/coverage/metrics"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+var dynamoDBDuration = metrics.Default.NewHistogram("coverage_dynamodb_request_duration_seconds",
+	"Time of the DynamoDB calls, retries included, by operation and ok or the error code", metrics.DefaultBuckets, "operation", "status")
+
+// observeDynamoDB is a Complete handler of the DynamoDB client timing every call, each page of a paginated one
+func observeDynamoDB(r *request.Request) {
+	status := "ok"
+	if aerr, ok := r.Error.(awserr.Error); ok {
+		status = aerr.Code()
+	} else if r.Error != nil {
+		status = "error"
+	}
+	dynamoDBDuration.Observe(time.Since(r.Time).Seconds(), r.Operation.Name, status)
+}
@@ -0,0 +1,45 @@
+package dbclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObserveDynamoDB(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		if r.Header.Get("X-Amz-Target") == "DynamoDB_20120810.DescribeTable" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"Requested resource not found"}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+	os.Setenv("AWS_ACCESS_KEY_ID", "test")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	defer os.Unsetenv("AWS_ACCESS_KEY_ID")
+	defer os.Unsetenv("AWS_SECRET_ACCESS_KEY")
+
+	connection, err := NewConnection(ConnectionConfig{DynamoDBARN: "arn:aws:dynamodb:us-east-2:674346455231:table/coverage", Endpoint: ts.URL})
+	assert.NoError(t, err)
+	ok := dynamoDBDuration.Count("GetItem", "ok")
+	notFound := dynamoDBDuration.Count("DescribeTable", "ResourceNotFoundException")
+
+	_, err = connection.DynamoDB.GetItem(&dynamodb.GetItemInput{
+		TableName: connection.TableName,
+		Key:       map[string]*dynamodb.AttributeValue{"zipcode": {S: aws.String("94105")}, "carriertype": {S: aws.String("sprint")}},
+	})
+	assert.NoError(t, err)
+	_, err = connection.DynamoDB.DescribeTable(&dynamodb.DescribeTableInput{TableName: connection.TableName})
+	assert.Error(t, err)
+
+	assert.Equal(t, ok+1, dynamoDBDuration.Count("GetItem", "ok"))
+	assert.Equal(t, notFound+1, dynamoDBDuration.Count("DescribeTable", "ResourceNotFoundException"))
+}
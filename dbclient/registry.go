@@ -0,0 +1,97 @@
+package dbclient
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
+)
+
+// Carrier is a carrier registered with the db client package. Each carrier registers itself from the init of
+// its own file, so adding a carrier doesn't need the factory, the loader or the validators to change.
+type Carrier struct {
+	ID   entity.CarrierType
+	Name string
+	// SortKey is the carriertype sort key of the carrier's coverage items and the key of its coverage rule
+	SortKey string
+	// newData gives back a pointer to an empty coverage data struct of the carrier
+	newData func() interface{}
+	// newClient gives back a db client evaluating the carrier's coverage data with the rule
+	newClient    func(store Store, rule rules.Rule) CoverageCheckClient
+	technologies []technology
+	// marketAreas are the market areas the carrier's coverage data places a zipcode in
+	marketAreas []marketAreaType
+	// centroid are the attributes of the zipcode centroid in the carrier's coverage data, nil when it has none
+	centroid *centroid
+	// region are the state and county attributes the loader summarizes the carrier's coverage data by, nil when it has none
+	region *region
+	// aliases maps lower cased export columns onto attributes whose names differ
+	aliases map[string]string
+	// numericAttributes must parse as numbers when loaded
+	numericAttributes []string
+}
+
+// centroid names the latitude and longitude attributes of a zipcode's centroid
+type centroid struct {
+	latitudeAttribute  string
+	longitudeAttribute string
+}
+
+// Technologies lists the names of the technologies reported in the carrier's coverage data
+func (c Carrier) Technologies() []string {
+	names := make([]string, 0, len(c.technologies))
+	for _, t := range c.technologies {
+		names = append(names, t.name)
+	}
+	return names
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[entity.CarrierType]Carrier{}
+)
+
+// register adds a carrier to the registry. It panics if the carrier is incomplete or its id or sort key is taken.
+func register(carrier Carrier) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if carrier.ID == "" || carrier.ID == entity.AllCarriers || carrier.SortKey == "" || carrier.newData == nil || carrier.newClient == nil {
+		panic(fmt.Sprintf("dbclient: incomplete carrier registration %q", carrier.ID))
+	}
+	for _, registered := range registry {
+		if registered.ID == carrier.ID || registered.SortKey == carrier.SortKey {
+			panic(fmt.Sprintf("dbclient: carrier %q (%s) registered twice", carrier.ID, carrier.SortKey))
+		}
+	}
+	registry[carrier.ID] = carrier
+}
+
+// LookupCarrier gives back the registered carrier with the id
+func LookupCarrier(id entity.CarrierType) (Carrier, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	carrier, ok := registry[id]
+	return carrier, ok
+}
+
+// RegisteredCarriers gives back every registered carrier ordered by id, so "2" comes before "10"
+func RegisteredCarriers() []Carrier {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	carriers := make([]Carrier, 0, len(registry))
+	for _, carrier := range registry {
+		carriers = append(carriers, carrier)
+	}
+	sort.Slice(carriers, func(i, j int) bool {
+		if len(carriers[i].ID) != len(carriers[j].ID) {
+			return len(carriers[i].ID) < len(carriers[j].ID)
+		}
+		return carriers[i].ID < carriers[j].ID
+	})
+	return carriers
+}
@@ -0,0 +1,47 @@
+package dbclient
+
+import (
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupCarrier(t *testing.T) {
+	carrier, ok := LookupCarrier(entity.Verizon)
+	assert.True(t, ok)
+	assert.Equal(t, "Verizon", carrier.Name)
+	assert.Equal(t, "verizon", carrier.SortKey)
+	assert.Equal(t, []string{"LTE", "EVDO", "Voice/1X"}, carrier.Technologies())
+
+	_, ok = LookupCarrier(entity.AllCarriers)
+	assert.False(t, ok)
+}
+
+func TestRegisteredCarriers(t *testing.T) {
+	var ids []entity.CarrierType
+	for _, carrier := range RegisteredCarriers() {
+		ids = append(ids, carrier.ID)
+		_, ok := rules.Default().Carriers[carrier.SortKey]
+		assert.True(t, ok, "missing default rule for carrier %s", carrier.ID)
+	}
+	assert.Equal(t, []entity.CarrierType{entity.Sprint, entity.Verizon, entity.TMobile, entity.ATT}, ids)
+}
+
+func TestRegisterRejectsDuplicates(t *testing.T) {
+	newClient := func(store Store, rule rules.Rule) CoverageCheckClient { return nil }
+	newData := func() interface{} { return &sprintCoverageData{} }
+
+	assert.Panics(t, func() {
+		register(Carrier{ID: entity.Sprint, SortKey: "another", newData: newData, newClient: newClient})
+	})
+	assert.Panics(t, func() {
+		register(Carrier{ID: "99", SortKey: "sprint", newData: newData, newClient: newClient})
+	})
+	assert.Panics(t, func() {
+		register(Carrier{ID: "99", SortKey: "incomplete"})
+	})
+	_, ok := LookupCarrier("99")
+	assert.False(t, ok)
+}
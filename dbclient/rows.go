@@ -0,0 +1,140 @@
+package dbclient
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// ReadRows reads the rows of a carrier export such as the ones the loader and the memory store load. format is csv or json.
+func ReadRows(r io.Reader, format string) ([]map[string]string, error) {
+	switch format {
+	case "csv":
+		return readCsvRows(r, ',')
+	case "tsv":
+		return readCsvRows(r, '\t')
+	case "json":
+		return readJSONRows(r)
+	default:
+		return nil, fmt.Errorf("unsupported format %s", format)
+	}
+}
+
+// readCsvRows reads a csv export, or a tsv one for a tab delimiter, whose first record holds the column names
+func readCsvRows(r io.Reader, delimiter rune) ([]map[string]string, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("missing csv header")
+	}
+
+	header := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := map[string]string{}
+		for i, value := range record {
+			if i < len(header) {
+				row[header[i]] = value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// readJSONRows reads either an array of objects or a batch-write-item request such as
+// sprint_coverage_batch_data.json, where the attributes of each PutRequest are flattened and
+// a JSON_DATA attribute is expanded into its own columns
+func readJSONRows(r io.Reader) ([]map[string]string, error) {
+	raw, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if strings.HasPrefix(strings.TrimSpace(string(raw)), "[") {
+		var objects []map[string]interface{}
+		if err := decoder.Decode(&objects); err != nil {
+			return nil, err
+		}
+		rows := make([]map[string]string, 0, len(objects))
+		for _, object := range objects {
+			rows = append(rows, flatten(object))
+		}
+		return rows, nil
+	}
+
+	var requests map[string][]struct {
+		PutRequest struct {
+			Item map[string]map[string]interface{}
+		}
+	}
+	if err := decoder.Decode(&requests); err != nil {
+		return nil, err
+	}
+	var rows []map[string]string
+	for _, table := range requests {
+		for _, request := range table {
+			row := map[string]string{}
+			for attribute, value := range request.PutRequest.Item {
+				row[attribute] = stringOf(typedValue(value))
+			}
+			if data, ok := row["JSON_DATA"]; ok {
+				delete(row, "JSON_DATA")
+				var object map[string]interface{}
+				dataDecoder := json.NewDecoder(strings.NewReader(data))
+				dataDecoder.UseNumber()
+				if err := dataDecoder.Decode(&object); err != nil {
+					return nil, fmt.Errorf("malformed JSON_DATA: %v", err)
+				}
+				for column, value := range flatten(object) {
+					row[column] = value
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// typedValue gives back the value of a dynamodb attribute value such as {"S": "94105"}
+func typedValue(value map[string]interface{}) interface{} {
+	for _, dataType := range []string{"S", "N", "BOOL"} {
+		if v, ok := value[dataType]; ok {
+			return v
+		}
+	}
+	return nil
+}
+
+func flatten(object map[string]interface{}) map[string]string {
+	row := map[string]string{}
+	for column, value := range object {
+		row[column] = stringOf(value)
+	}
+	return row
+}
+
+func stringOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,43 @@
+package dbclient
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadCsvRows(t *testing.T) {
+	rows, err := ReadRows(strings.NewReader("ZIP,Cur_Pct_Cov\n94105,100\n00501\n"), "csv")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]string{
+		{"ZIP": "94105", "Cur_Pct_Cov": "100"},
+		{"ZIP": "00501"},
+	}, rows)
+}
+
+func TestReadJSONRows(t *testing.T) {
+	rows, err := ReadRows(strings.NewReader(`[{"zip":"94105","vzw_lte":99.99999,"vzw_lte_ind":"Y","county":null}]`), "json")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]string{{"zip": "94105", "vzw_lte": "99.99999", "vzw_lte_ind": "Y", "county": ""}}, rows)
+}
+
+func TestReadBatchWriteJSONRows(t *testing.T) {
+	payload := `{"sprint_coverage":[{"PutRequest":{"Item":{
+		"ZIP":{"S":"00015"},
+		"JSON_DATA":{"S":"{\"CSA_Leaf\":\"PHXTUC520\",\"Cur_Pct_Cov\":\"24.6\",\"STATE\":\"AZ\"}"}
+	}}}]}`
+
+	rows, err := ReadRows(strings.NewReader(payload), "json")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]string{{"ZIP": "00015", "CSA_Leaf": "PHXTUC520", "Cur_Pct_Cov": "24.6", "STATE": "AZ"}}, rows)
+
+	_, err = ReadRows(strings.NewReader(`{"coverage":[{"PutRequest":{"Item":{"JSON_DATA":{"S":"{"}}}}]}`), "json")
+	assert.Error(t, err)
+
+	_, err = ReadRows(strings.NewReader(""), "xml")
+	assert.Error(t, err)
+}
@@ -2,25 +2,16 @@ package dbclient
 
 import (
 	"context"
-	"fmt"
-	"strconv"
-
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/dynamodb"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
-	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
 	"github.com/rs/zerolog"
 )
 
-type SprintCsaDbClient interface {
-	GetCsa(ctx context.Context, zipCode string) (string, error)
-}
-
 type sprintDbClient struct {
-	logger     *zerolog.Logger
-	tableName  *string
-	connection dynamodbiface.DynamoDBAPI
+	logger *zerolog.Logger
+	store  Store
+	rule   rules.Rule
 }
 
 type sprintCoverageData struct {
@@ -40,103 +31,110 @@ type sprintCoverageData struct {
 	Lte2500PctCov  string `json:"lte_2500_PctCov"`
 	ZipCenterLon   string `json:"zip_center_lon"`
 	ZipCenterLat   string `json:"zip_center_lat"`
+	LoadDate       string `json:"load_date"`
+	Geohash        string `json:"geohash"`
+}
+
+// sprintTechnologies are the technologies reported in Sprint's coverage data
+var sprintTechnologies = []technology{
+	{name: "LTE", percentageAttribute: "lte_4g_pctcov", weight: 0.5},
+	{name: "LTE 2500", percentageAttribute: "lte_2500_PctCov", weight: 0.1},
+	{name: "EVDO", percentageAttribute: "cur_evdo_pct_cov", weight: 0.2},
+	{name: "CDMA", percentageAttribute: "cur_pct_cov", weight: 0.2},
+}
+
+func init() {
+	register(Carrier{
+		ID:      entity.Sprint,
+		Name:    "Sprint",
+		SortKey: "sprint",
+		newData: func() interface{} { return &sprintCoverageData{} },
+		newClient: func(store Store, rule rules.Rule) CoverageCheckClient {
+			return NewSprintClient(store, rule)
+		},
+		technologies: sprintTechnologies,
+		marketAreas: []marketAreaType{
+			{name: "CSA", codeAttribute: "csa_leaf"},
+			{name: "Market", nameAttribute: "mkt_name"},
+		},
+		centroid: &centroid{latitudeAttribute: "zip_center_lat", longitudeAttribute: "zip_center_lon"},
+		region:   &region{stateAttribute: "state", countyAttribute: "zipcode_area"},
+		aliases: map[string]string{
+			"zip": "zipcode",
+		},
+		numericAttributes: []string{"cur_pct_cov", "cur_evdo_pct_cov", "roam1x_pct_cov", "evdoroam_pct_cov", "cdmaroam_pct_cov", "lte_4g_pctcov", "lte_2500_PctCov", "zip_center_lon", "zip_center_lat"},
+	})
 }
 
 //NewSprintClient construts and returns Sprint's db client
-func NewSprintClient(tableName *string, connection dynamodbiface.DynamoDBAPI) sprintDbClient {
-	return sprintDbClient{tableName: tableName, connection: connection}
+func NewSprintClient(store Store, rule rules.Rule) sprintDbClient {
+	return sprintDbClient{store: store, rule: rule}
 }
 
-func (s sprintDbClient) VerifyCoverage(ctx context.Context, zipCode string) (bool, error) {
+func (s sprintDbClient) VerifyCoverage(ctx context.Context, zipCode string) (Coverage, error) {
 	zerolog.Ctx(ctx).Info().Msgf("*** IN SPRINT DB CLIENT VerifyCoverage() ***")
 
 	data, err := s.getData(ctx, zipCode)
 	if err != nil {
-		return false, err
+		return Coverage{}, err
 	}
-	fmt.Println("CsaLeaf: ", data.CsaLeaf)
-	fmt.Println("CurPctCov: ", data.CurPctCov)
-	fmt.Println("LTE4GPctCov: ", data.Lte4GPctCov)
-
-	covered := s.isZipCovered(ctx, zipCode, data)
-	return covered, nil
+	if data.ZipCode == "" {
+		return unknownCoverage(ctx, s.store, "sprint"), nil
+	}
+	return s.coverageOf(ctx, zipCode, data), nil
 }
 
-func (s sprintDbClient) GetCsa(ctx context.Context, zipCode string) (string, error) {
-	zerolog.Ctx(ctx).Info().Msgf("*** IN SPRINT DB CLIENT GetCsa() for zipcode %s***", zipCode)
+// BatchVerifyCoverage checks Sprint coverage for many zipcodes in batches. Zipcodes without coverage data are reported as not found.
+func (s sprintDbClient) BatchVerifyCoverage(ctx context.Context, zipCodes []string) (map[string]Coverage, error) {
+	zerolog.Ctx(ctx).Info().Msgf("*** IN SPRINT DB CLIENT BatchVerifyCoverage() for %d zipcodes ***", len(zipCodes))
 
-	data, err := s.getData(ctx, zipCode)
+	items, err := s.store.BatchGet(ctx, "sprint", zipCodes, s.attributes())
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return data.CsaLeaf, nil
-}
 
-func (s sprintDbClient) getData(ctx context.Context, zipCode string) (sprintCoverageData, error) {
-	// Get cur_pct_cov and lte_4g_pctcov attributes
-	proj := expression.NamesList(expression.Name("zipcode"), expression.Name("carriertype"), expression.Name("csa_leaf"), expression.Name("cur_pct_cov"), expression.Name("lte_4g_pctcov"))
-	expr, err := expression.NewBuilder().WithProjection(proj).Build()
-	if err != nil {
-		zerolog.Ctx(ctx).Error().Err(err).Msg("failed to build projection expression to query dynamodb table for Sprint coverage")
-		return sprintCoverageData{}, err
+	unknown := Coverage{}
+	if len(items) < len(zipCodes) {
+		unknown = unknownCoverage(ctx, s.store, "sprint")
 	}
-
-	input := &dynamodb.GetItemInput{
-		TableName: s.tableName,
-		Key: map[string]*dynamodb.AttributeValue{
-			"zipcode": {
-				S: aws.String(zipCode),
-			},
-			"carriertype": {
-				S: aws.String("sprint"),
-			},
-		},
-		ExpressionAttributeNames: expr.Names(),
-		ProjectionExpression:     expr.Projection(),
+	coverage := make(map[string]Coverage, len(zipCodes))
+	for _, zipCode := range zipCodes {
+		coverage[zipCode] = unknown
 	}
-
-	result, err := s.connection.GetItemWithContext(ctx, input)
-	if err != nil {
-		zerolog.Ctx(ctx).Error().Err(err).Msg("failed to query coverage dynamodb table")
-		return sprintCoverageData{}, err
+	for _, item := range items {
+		data := sprintCoverageData{}
+		if err := decodeItem(item, &data); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("failed to decode Sprint coverage data")
+			return nil, err
+		}
+		coverage[data.ZipCode] = s.coverageOf(ctx, data.ZipCode, data)
 	}
+	return coverage, nil
+}
 
-	item := sprintCoverageData{}
-	err = dynamodbattribute.UnmarshalMap(result.Item, &item)
+// attributes are the zipcode, csa_leaf and the attributes read by the coverage rule and technologies
+func (s sprintDbClient) attributes() []string {
+	return coverageAttributes(s.rule, sprintTechnologies, "zipcode", "carriertype", "csa_leaf")
+}
+
+func (s sprintDbClient) getData(ctx context.Context, zipCode string) (sprintCoverageData, error) {
+	item, err := s.store.Get(ctx, zipCode, "sprint", s.attributes())
 	if err != nil {
-		zerolog.Ctx(ctx).Error().Err(err).Msg("failed to UnmarshalMap Sprint coverage data from dynamodb")
 		return sprintCoverageData{}, err
 	}
-
-	if item.ZipCode == "" {
+	if item == nil {
 		zerolog.Ctx(ctx).Debug().Msgf("Could not find coverage data for zipcode: %s", zipCode)
 		return sprintCoverageData{}, nil
 	}
 
-	// var data sprintCoverageData
-	// json.Unmarshal([]byte(item.JsonData), &data)
-	return item, nil
-}
-func (s sprintDbClient) isZipCovered(ctx context.Context, zipCode string, data sprintCoverageData) bool {
-	if len(data.CurPctCov) == 0 || len(data.Lte4GPctCov) == 0 {
-		zerolog.Ctx(ctx).Debug().Msgf("zipcode: %s not covered as either Cur_Pct_Cov, LTE_4G_PctCov fields are empty", zipCode)
-		return false
-	}
-
-	curPctCov, err := strconv.ParseFloat(data.CurPctCov, 64)
-	if err != nil {
-		zerolog.Ctx(ctx).Error().Err(err).Msg("Illegal value in Cur_Pct_Cov")
-		return false
-	}
-
-	lTE4GPctCov, err := strconv.ParseFloat(data.Lte4GPctCov, 64)
-	if err != nil {
-		zerolog.Ctx(ctx).Error().Err(err).Msg("Illegal value in LTE_4G_PctCov")
-		return false
+	data := sprintCoverageData{}
+	if err := decodeItem(item, &data); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("failed to decode Sprint coverage data")
+		return sprintCoverageData{}, err
 	}
+	return data, nil
+}
 
-	if lTE4GPctCov > 50 && curPctCov > 50 {
-		return true
-	}
-	return false
+func (s sprintDbClient) coverageOf(ctx context.Context, zipCode string, data sprintCoverageData) Coverage {
+	return evaluateCoverage(ctx, s.rule, sprintTechnologies, zipCode, data)
 }
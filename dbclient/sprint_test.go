@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"testing"
 
+	"bitbucket.org/credomobile/coverage/rules"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -133,98 +134,14 @@ func TestSprintVerifyCoverage(t *testing.T) {
 			}
 		}
 
-		sprintdbClient := NewSprintClient(tableName, fakeDb)
+		sprintdbClient := NewSprintClient(NewDynamoStore(tableName, fakeDb), rules.Default().Carriers["sprint"])
 		result, err := sprintdbClient.VerifyCoverage(context.Background(), tC.zipCode)
 
 		if tC.causeDynamoDbError {
 			assert.NotNil(t, err)
 		} else {
 			assert.NoError(t, err)
-			assert.Equal(t, tC.expectZipCodeCovered, result)
-		}
-
-		assert.Equal(t, tC.zipCode, fakeDb.Keys["zipcode"])
-		assert.Equal(t, "sprint", fakeDb.Keys["carriertype"])
-	}
-}
-
-func TestGetCsa(t *testing.T) {
-	testCases := []struct {
-		desc                  string
-		zipCode               string
-		expectCsa             bool
-		causeDynamoDbError    bool
-		zipCodeExistInDb      bool
-		dynamodbReturnPayload map[string]string
-	}{
-		{
-			desc:               "happy path with Zip code that has Csa",
-			zipCode:            "94105",
-			expectCsa:          true,
-			causeDynamoDbError: false,
-			dynamodbReturnPayload: map[string]string{
-				"zipcode":       "94105",
-				"carriertype":   "sprint",
-				"csa_leaf":      "fakeCsa",
-				"cur_pct_cov":   "100",
-				"lte_4g_pctcov": "100",
-			},
-		},
-		{
-			desc:               "happy path with Zip code that has no Csa",
-			zipCode:            "94105",
-			expectCsa:          true,
-			causeDynamoDbError: false,
-			dynamodbReturnPayload: map[string]string{
-				"zipcode":       "94105",
-				"carriertype":   "sprint",
-				"csa_leaf":      "",
-				"cur_pct_cov":   "100",
-				"lte_4g_pctcov": "100",
-			},
-		},
-		{
-			desc:               "Sad path when there is a failure to retrieve data from database",
-			zipCode:            "94105",
-			expectCsa:          true,
-			causeDynamoDbError: true,
-			dynamodbReturnPayload: map[string]string{
-				"zipcode":       "94105",
-				"carriertype":   "sprint",
-				"csa_leaf":      "",
-				"cur_pct_cov":   "100",
-				"lte_4g_pctcov": "100",
-			},
-		},
-	}
-	for _, tC := range testCases {
-		tableName := aws.String("fakeCoverage")
-
-		fakeDb := &fakeSprintDynamoDB{}
-		if tC.causeDynamoDbError {
-			fakeDb = &fakeSprintDynamoDB{
-				t:               t,
-				tableName:       tableName,
-				payloadToReturn: tC.dynamodbReturnPayload,
-				err:             errors.New("fake DB error"),
-			}
-		} else {
-			fakeDb = &fakeSprintDynamoDB{
-				t:               t,
-				tableName:       tableName,
-				payloadToReturn: tC.dynamodbReturnPayload,
-				err:             nil,
-			}
-		}
-
-		sprintdbClient := NewSprintClient(aws.String("fakeCoverage"), fakeDb)
-		result, err := sprintdbClient.GetCsa(context.Background(), tC.zipCode)
-
-		if tC.causeDynamoDbError {
-			assert.NotNil(t, err)
-		} else {
-			assert.NoError(t, err)
-			assert.Equal(t, tC.dynamodbReturnPayload["csa_leaf"], result)
+			assert.Equal(t, tC.expectZipCodeCovered, result.IsCovered)
 		}
 
 		assert.Equal(t, tC.zipCode, fakeDb.Keys["zipcode"])
@@ -243,6 +160,9 @@ type fakeSprintDynamoDB struct {
 
 func (fd *fakeSprintDynamoDB) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
 	assert.Equal(fd.t, *fd.tableName, *input.TableName, "incorrect table name")
+	if *input.Key["zipcode"].S == loadMetaZipCode {
+		return &dynamodb.GetItemOutput{}, nil
+	}
 
 	expectedAttributes := map[string]*string{
 		"#0": aws.String("zipcode"),
@@ -250,6 +170,9 @@ func (fd *fakeSprintDynamoDB) GetItemWithContext(ctx aws.Context, input *dynamod
 		"#2": aws.String("csa_leaf"),
 		"#3": aws.String("cur_pct_cov"),
 		"#4": aws.String("lte_4g_pctcov"),
+		"#5": aws.String("lte_2500_PctCov"),
+		"#6": aws.String("cur_evdo_pct_cov"),
+		"#7": aws.String("load_date"),
 	}
 
 	actual := input.ExpressionAttributeNames
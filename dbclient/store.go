@@ -0,0 +1,86 @@
+package dbclient
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Item is a coverage item of the coverage table, its attributes keyed by name
+type Item map[string]string
+
+// Store reads the coverage items of the coverage table, keyed by zipcode and carriertype sort key.
+// Only the given attributes are read. Get gives back a nil item for a zipcode without coverage data
+// and BatchGet leaves such zipcodes out. Query reads a page of the items of a secondary index. Check gives
+// back why the store can't be read, if it can't.
+type Store interface {
+	Get(ctx context.Context, zipCode string, sortKey string, attributes []string) (Item, error)
+	BatchGet(ctx context.Context, sortKey string, zipCodes []string, attributes []string) ([]Item, error)
+	Query(ctx context.Context, query IndexQuery) (Page, error)
+	Check(ctx context.Context) error
+}
+
+// IndexQuery asks for the items of a secondary index whose hash key attribute has the value, ordered by zipcode.
+// Token continues a previous query and is empty for the first page.
+type IndexQuery struct {
+	Index      string
+	HashKey    string
+	Value      string
+	Attributes []string
+	Limit      int
+	Token      string
+}
+
+// Page is a page of the items of an index query. NextToken continues the query and is empty on the last page.
+type Page struct {
+	Items     []Item
+	NextToken string
+}
+
+// ErrInvalidToken is given back for a continuation token the store didn't hand out for the query
+var ErrInvalidToken = errors.New("invalid continuation token")
+
+// encodeToken turns the key of the last item of a page into an opaque continuation token
+func encodeToken(key Item) string {
+	raw, _ := json.Marshal(key)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeToken gives back the key a continuation token of the query was made from
+func decodeToken(query IndexQuery) (Item, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(query.Token)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	key := Item{}
+	if err := json.Unmarshal(raw, &key); err != nil || key[query.HashKey] != query.Value || key["zipcode"] == "" {
+		return nil, ErrInvalidToken
+	}
+	return key, nil
+}
+
+// decodeItem maps an item onto a carrier's coverage data struct through its json tags. An item that doesn't map is
+// bad data.
+func decodeItem(item Item, data interface{}) error {
+	raw, err := json.Marshal(item)
+	if err != nil {
+		return &Error{Kind: KindBadData, Err: err}
+	}
+	if err := json.Unmarshal(raw, data); err != nil {
+		return &Error{Kind: KindBadData, Err: fmt.Errorf("malformed coverage item %v: %v", item["zipcode"], err)}
+	}
+	return nil
+}
+
+// project gives back the given attributes of an item
+func project(item Item, attributes []string) Item {
+	projected := Item{}
+	for _, attribute := range attributes {
+		if value, ok := item[attribute]; ok {
+			projected[attribute] = value
+		}
+	}
+	return projected
+}
@@ -0,0 +1,228 @@
+package dbclient
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
+	"github.com/rs/zerolog"
+)
+
+// summaryZipCodePrefix starts the zipcode of the summary items the loader writes for every state and county of a
+// carrier's coverage data, e.g. _summary#CA and _summary#CA#ALAMEDA. It can't clash with a real zipcode.
+const summaryZipCodePrefix = "_summary#"
+
+// summaryPrefix starts the attributes holding the average percentage of a technology in a summary item
+const summaryPrefix = "avg_"
+
+// region names the state and county attributes of a carrier's coverage data. Sprint has no county and reports
+// the zipcode's area in its place.
+type region struct {
+	stateAttribute  string
+	countyAttribute string
+}
+
+// CoverageSummary is what a carrier's coverage data says about the zipcodes of a state or county: how many there are,
+// how many the coverage rule finds covered and the average score and percentage of each technology. A technology
+// without any percentage in the area has no average.
+type CoverageSummary struct {
+	State        string
+	County       string
+	ZipCodes     int
+	Covered      int
+	Score        float64
+	Technologies []entity.TechnologyCoverage
+	LoadDate     string
+	RuleVersion  string
+}
+
+// CoverageSummaryClient reads the coverage summaries the loader writes
+type CoverageSummaryClient interface {
+	GetSummary(ctx context.Context, carrier entity.CarrierType, state string, county string) (CoverageSummary, bool, error)
+}
+
+type coverageSummaryClient struct {
+	store Store
+}
+
+// NewCoverageSummaryClient constructs and gives back a coverage summary client reading the store
+func NewCoverageSummaryClient(store Store) CoverageSummaryClient {
+	return coverageSummaryClient{store: store}
+}
+
+// GetSummary reads a carrier's summary of a state, or of a county of the state when county isn't empty. found is
+// false when the carrier has no zipcodes there, or when the summary is left over from an earlier load.
+func (c coverageSummaryClient) GetSummary(ctx context.Context, carrierID entity.CarrierType, state string, county string) (CoverageSummary, bool, error) {
+	carrier, ok := LookupCarrier(carrierID)
+	if !ok {
+		return CoverageSummary{}, false, ErrUnknownCarrier
+	}
+	if carrier.region == nil {
+		return CoverageSummary{}, false, nil
+	}
+
+	attributes := []string{"zipcode", "load_date", "rule_version", "state", "county", "zipcodes", "covered", "score"}
+	for _, t := range carrier.technologies {
+		attributes = append(attributes, summaryPrefix+t.percentageAttribute)
+	}
+	item, err := c.store.Get(ctx, summaryZipCode(state, county), carrier.SortKey, attributes)
+	if err != nil || item == nil {
+		return CoverageSummary{}, false, err
+	}
+
+	// a county that vanished from the carrier's latest export keeps its summary of the earlier load
+	meta, found, err := GetLoadMeta(ctx, c.store, carrier.SortKey)
+	if err != nil {
+		return CoverageSummary{}, false, err
+	}
+	if !found || meta.LoadDate != item["load_date"] {
+		zerolog.Ctx(ctx).Debug().Msgf("Ignoring %s summary of %s from load %s", carrier.Name, item["zipcode"], item["load_date"])
+		return CoverageSummary{}, false, nil
+	}
+
+	summary := CoverageSummary{
+		State:       item["state"],
+		County:      item["county"],
+		LoadDate:    item["load_date"],
+		RuleVersion: item["rule_version"],
+	}
+	summary.ZipCodes, _ = strconv.Atoi(item["zipcodes"])
+	summary.Covered, _ = strconv.Atoi(item["covered"])
+	summary.Score, _ = strconv.ParseFloat(item["score"], 64)
+	for _, t := range carrier.technologies {
+		technologyCoverage := entity.TechnologyCoverage{Technology: t.name}
+		if percentage, err := strconv.ParseFloat(item[summaryPrefix+t.percentageAttribute], 64); err == nil {
+			technologyCoverage.Percentage = &percentage
+		}
+		summary.Technologies = append(summary.Technologies, technologyCoverage)
+	}
+	return summary, true, nil
+}
+
+// summaryZipCode gives back the zipcode of the summary item of a state, or of a county of the state. Both are upper
+// cased so the lookup doesn't depend on how the carrier spells them.
+func summaryZipCode(state string, county string) string {
+	zipCode := summaryZipCodePrefix + strings.ToUpper(strings.TrimSpace(state))
+	if county = strings.TrimSpace(county); county != "" {
+		zipCode += "#" + strings.ToUpper(county)
+	}
+	return zipCode
+}
+
+// regionTotals adds up the coverage of the zipcodes of a state or county
+type regionTotals struct {
+	state        string
+	county       string
+	zipCodes     int
+	covered      int
+	scoreSum     float64
+	technologies map[string]*averageTotals
+}
+
+// averageTotals adds up the percentages of a technology over the zipcodes that report one
+type averageTotals struct {
+	sum   float64
+	count int
+}
+
+// summaryItems summarizes the coverage items of a carrier's load per state and per county, evaluating the carrier's
+// coverage rule the same way its db client does. Items without a state are left out.
+func summaryItems(ctx context.Context, schema Carrier, ruleSet rules.RuleSet, loadDate string, items []Item) []Item {
+	if schema.region == nil {
+		return nil
+	}
+	rule, ok := ruleSet.Carriers[schema.SortKey]
+	if !ok {
+		zerolog.Ctx(ctx).Warn().Msgf("no coverage rule for %s, skipping its coverage summaries", schema.SortKey)
+		return nil
+	}
+
+	totals := map[string]*regionTotals{}
+	for _, item := range items {
+		state := strings.TrimSpace(item[schema.region.stateAttribute])
+		if state == "" {
+			continue
+		}
+		detail := coverageDetail(schema.technologies, item)
+		covered, err := rule.Evaluate(item)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msgf("Illegal coverage data for zipcode: %s", item["zipcode"])
+		}
+
+		keys := []string{summaryZipCode(state, "")}
+		if county := strings.TrimSpace(item[schema.region.countyAttribute]); county != "" {
+			keys = append(keys, summaryZipCode(state, county))
+		}
+		for i, key := range keys {
+			total, ok := totals[key]
+			if !ok {
+				total = &regionTotals{state: strings.ToUpper(state), technologies: map[string]*averageTotals{}}
+				if i > 0 {
+					total.county = strings.TrimSpace(item[schema.region.countyAttribute])
+				}
+				totals[key] = total
+			}
+			total.add(detail, covered)
+		}
+	}
+
+	keys := make([]string, 0, len(totals))
+	for key := range totals {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	summaries := make([]Item, 0, len(keys))
+	for _, key := range keys {
+		total := totals[key]
+		item := Item{
+			"zipcode":      key,
+			"carriertype":  versionedSortKey(schema.SortKey, loadDate),
+			"load_date":    loadDate,
+			"rule_version": ruleSet.Version,
+			"state":        total.state,
+			"zipcodes":     strconv.Itoa(total.zipCodes),
+			"covered":      strconv.Itoa(total.covered),
+			"score":        formatAverage(total.scoreSum, total.zipCodes),
+		}
+		if total.county != "" {
+			item["county"] = total.county
+		}
+		for _, t := range schema.technologies {
+			if average, ok := total.technologies[t.name]; ok {
+				item[summaryPrefix+t.percentageAttribute] = formatAverage(average.sum, average.count)
+			}
+		}
+		summaries = append(summaries, item)
+	}
+	return summaries
+}
+
+// add counts a zipcode's coverage
+func (r *regionTotals) add(detail entity.CoverageDetail, covered bool) {
+	r.zipCodes++
+	if covered {
+		r.covered++
+	}
+	r.scoreSum += detail.Score
+	for _, technologyCoverage := range detail.Technologies {
+		if technologyCoverage.Percentage == nil {
+			continue
+		}
+		average, ok := r.technologies[technologyCoverage.Technology]
+		if !ok {
+			average = &averageTotals{}
+			r.technologies[technologyCoverage.Technology] = average
+		}
+		average.sum += *technologyCoverage.Percentage
+		average.count++
+	}
+}
+
+// formatAverage gives back the average rounded to one decimal, the precision of the coverage score
+func formatAverage(sum float64, count int) string {
+	return strconv.FormatFloat(math.Round(sum/float64(count)*10)/10, 'f', -1, 64)
+}
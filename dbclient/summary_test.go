@@ -0,0 +1,103 @@
+package dbclient
+
+import (
+	"context"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSummary(t *testing.T) {
+	store := NewMemoryStore()
+	summary, err := store.Load(context.Background(), entity.Verizon, "2018-11-01", []map[string]string{
+		{"zip": "94501", "State": "CA", "County": "Alameda", "VZW_LTE": "100", "VZW_LTE_IND": "Y", "VZW_EVDO": "80"},
+		{"zip": "94502", "State": "CA", "County": "ALAMEDA", "VZW_LTE": "40", "VZW_LTE_IND": "Y"},
+		{"zip": "94105", "State": "ca", "County": "San Francisco", "VZW_LTE": "90", "VZW_LTE_IND": "N"},
+		{"zip": "10001", "VZW_LTE": "100", "VZW_LTE_IND": "Y"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, summary.Summaries)
+	assert.NoError(t, store.Datasets().Promote(context.Background(), entity.Verizon, "2018-11-01"))
+	client := NewCoverageSummaryClient(NewVersionedStore(store, 0))
+
+	state, found, err := client.GetSummary(context.Background(), entity.Verizon, "CA", "")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "CA", state.State)
+	assert.Equal(t, 3, state.ZipCodes)
+	assert.Equal(t, 1, state.Covered)
+	assert.Equal(t, "2018-11-01", state.LoadDate)
+	assert.Equal(t, rules.Default().Version, state.RuleVersion)
+	assert.Equal(t, "LTE", state.Technologies[0].Technology)
+	assert.Equal(t, 76.7, *state.Technologies[0].Percentage)
+	assert.Equal(t, 80.0, *state.Technologies[1].Percentage)
+	assert.Nil(t, state.Technologies[2].Percentage)
+
+	county, found, err := client.GetSummary(context.Background(), entity.Verizon, "ca", "alameda")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "Alameda", county.County)
+	assert.Equal(t, 2, county.ZipCodes)
+	assert.Equal(t, 1, county.Covered)
+	assert.Equal(t, 50.0, county.Score)
+
+	_, found, err = client.GetSummary(context.Background(), entity.Verizon, "NY", "")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	_, _, err = client.GetSummary(context.Background(), entity.CarrierType("9"), "CA", "")
+	assert.EqualError(t, err, "Invalid Carrier Type")
+}
+
+func TestGetSummaryAfterReload(t *testing.T) {
+	store := NewMemoryStore()
+	loadLive(t, store, entity.Verizon, "2018-11-01", []map[string]string{
+		{"zip": "94501", "State": "CA", "County": "Alameda", "VZW_LTE": "100", "VZW_LTE_IND": "Y"},
+		{"zip": "94105", "State": "CA", "County": "San Francisco", "VZW_LTE": "100", "VZW_LTE_IND": "Y"},
+	})
+	client := NewCoverageSummaryClient(loadLive(t, store, entity.Verizon, "2018-12-01", []map[string]string{
+		{"zip": "94501", "State": "CA", "County": "Alameda", "VZW_LTE": "10", "VZW_LTE_IND": "Y"},
+	}))
+
+	state, found, err := client.GetSummary(context.Background(), entity.Verizon, "CA", "")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, 1, state.ZipCodes)
+	assert.Equal(t, 0, state.Covered)
+	assert.Equal(t, "2018-12-01", state.LoadDate)
+
+	// san francisco isn't in the reload, its summary is in the earlier version only
+	_, found, err = client.GetSummary(context.Background(), entity.Verizon, "CA", "San Francisco")
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	_, err = store.Datasets().Rollback(context.Background(), entity.Verizon)
+	assert.NoError(t, err)
+	county, found, err := client.GetSummary(context.Background(), entity.Verizon, "CA", "San Francisco")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "2018-11-01", county.LoadDate)
+}
+
+func TestSummaryItemsWithRuleSet(t *testing.T) {
+	carrier, _ := LookupCarrier(entity.Sprint)
+	items := []Item{
+		{"zipcode": "85001", "state": "AZ", "zipcode_area": "Phoenix", "cur_pct_cov": "60", "lte_4g_pctcov": "60"},
+		{"zipcode": "85002", "state": "AZ", "cur_pct_cov": "10", "lte_4g_pctcov": "10"},
+	}
+
+	summaries := summaryItems(context.Background(), carrier, rules.RuleSet{Version: "lenient-1", Carriers: map[string]rules.Rule{
+		"sprint": {Attribute: "cur_pct_cov", Operator: rules.Present},
+	}}, "2018-11-01", items)
+
+	assert.Equal(t, []Item{
+		{"zipcode": "_summary#AZ", "carriertype": "sprint#2018-11-01", "load_date": "2018-11-01", "rule_version": "lenient-1", "state": "AZ",
+			"zipcodes": "2", "covered": "2", "score": "24.5", "avg_lte_4g_pctcov": "35", "avg_cur_pct_cov": "35"},
+		{"zipcode": "_summary#AZ#PHOENIX", "carriertype": "sprint#2018-11-01", "load_date": "2018-11-01", "rule_version": "lenient-1", "state": "AZ",
+			"county": "Phoenix", "zipcodes": "1", "covered": "1", "score": "42", "avg_lte_4g_pctcov": "60", "avg_cur_pct_cov": "60"},
+	}, summaries)
+
+	assert.Empty(t, summaryItems(context.Background(), carrier, rules.RuleSet{Version: "empty-1"}, "2018-11-01", items))
+}
@@ -0,0 +1,124 @@
+package dbclient
+
+import (
+	"context"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
+	"github.com/rs/zerolog"
+)
+
+type tmobileDbClient struct {
+	logger *zerolog.Logger
+	store  Store
+	rule   rules.Rule
+}
+
+type tmobileCoverageData struct {
+	ZipCode      string `json:"zipcode"`
+	CarrierType  string `json:"carriertype"`
+	City         string `json:"city"`
+	State        string `json:"state"`
+	County       string `json:"county"`
+	MarketName   string `json:"market_name"`
+	NrPctCov     string `json:"nr_pct_cov"`
+	LtePctCov    string `json:"lte_pct_cov"`
+	VoltePctCov  string `json:"volte_pct_cov"`
+	UmtsPctCov   string `json:"umts_pct_cov"`
+	GsmPctCov    string `json:"gsm_pct_cov"`
+	LoadDate     string `json:"load_date"`
+	ZipCenterLon string `json:"zip_center_lon"`
+	ZipCenterLat string `json:"zip_center_lat"`
+	Geohash      string `json:"geohash"`
+}
+
+// tmobileTechnologies are the technologies reported in T-Mobile's coverage data
+var tmobileTechnologies = []technology{
+	{name: "5G", percentageAttribute: "nr_pct_cov", weight: 0.2},
+	{name: "LTE", percentageAttribute: "lte_pct_cov", weight: 0.5},
+	{name: "UMTS", percentageAttribute: "umts_pct_cov", weight: 0.2},
+	{name: "GSM", percentageAttribute: "gsm_pct_cov", weight: 0.1},
+}
+
+func init() {
+	register(Carrier{
+		ID:      entity.TMobile,
+		Name:    "T-Mobile",
+		SortKey: "tmobile",
+		newData: func() interface{} { return &tmobileCoverageData{} },
+		newClient: func(store Store, rule rules.Rule) CoverageCheckClient {
+			return NewTMobileClient(store, rule)
+		},
+		technologies: tmobileTechnologies,
+		marketAreas: []marketAreaType{
+			{name: "Market", nameAttribute: "market_name"},
+		},
+		centroid: &centroid{latitudeAttribute: "zip_center_lat", longitudeAttribute: "zip_center_lon"},
+		region:   &region{stateAttribute: "state", countyAttribute: "county"},
+		aliases: map[string]string{
+			"zip":        "zipcode",
+			"5g_pct_cov": "nr_pct_cov",
+			"market":     "market_name",
+		},
+		numericAttributes: []string{"nr_pct_cov", "lte_pct_cov", "volte_pct_cov", "umts_pct_cov", "gsm_pct_cov", "zip_center_lon", "zip_center_lat"},
+	})
+}
+
+// NewTMobileClient constructs and returns T-Mobile's db client
+func NewTMobileClient(store Store, rule rules.Rule) tmobileDbClient {
+	return tmobileDbClient{store: store, rule: rule}
+}
+
+func (t tmobileDbClient) VerifyCoverage(ctx context.Context, zipCode string) (Coverage, error) {
+	item, err := t.store.Get(ctx, zipCode, "tmobile", t.attributes())
+	if err != nil {
+		return Coverage{}, err
+	}
+	if item == nil {
+		zerolog.Ctx(ctx).Debug().Msgf("Could not find coverage for zipcode: %s", zipCode)
+		return unknownCoverage(ctx, t.store, "tmobile"), nil
+	}
+
+	data := tmobileCoverageData{}
+	if err := decodeItem(item, &data); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("failed to decode T-Mobile coverage data")
+		return Coverage{}, err
+	}
+
+	return t.coverageOf(ctx, zipCode, data), nil
+}
+
+// BatchVerifyCoverage checks T-Mobile coverage for many zipcodes in batches. Zipcodes without coverage data are reported as not found.
+func (t tmobileDbClient) BatchVerifyCoverage(ctx context.Context, zipCodes []string) (map[string]Coverage, error) {
+	items, err := t.store.BatchGet(ctx, "tmobile", zipCodes, t.attributes())
+	if err != nil {
+		return nil, err
+	}
+
+	unknown := Coverage{}
+	if len(items) < len(zipCodes) {
+		unknown = unknownCoverage(ctx, t.store, "tmobile")
+	}
+	coverage := make(map[string]Coverage, len(zipCodes))
+	for _, zipCode := range zipCodes {
+		coverage[zipCode] = unknown
+	}
+	for _, item := range items {
+		data := tmobileCoverageData{}
+		if err := decodeItem(item, &data); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("failed to decode T-Mobile coverage data")
+			return nil, err
+		}
+		coverage[data.ZipCode] = t.coverageOf(ctx, data.ZipCode, data)
+	}
+	return coverage, nil
+}
+
+// attributes are the zipcode and the attributes read by the coverage rule and technologies
+func (t tmobileDbClient) attributes() []string {
+	return coverageAttributes(t.rule, tmobileTechnologies, "zipcode", "carriertype")
+}
+
+func (t tmobileDbClient) coverageOf(ctx context.Context, zipCode string, data tmobileCoverageData) Coverage {
+	return evaluateCoverage(ctx, t.rule, tmobileTechnologies, zipCode, data)
+}
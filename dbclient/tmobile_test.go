@@ -0,0 +1,180 @@
+package dbclient
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/rules"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTMobileVerifyCoverage(t *testing.T) {
+	testCases := []struct {
+		desc                  string
+		zipCode               string
+		expectZipCodeCovered  bool
+		expectedScore         float64
+		causeDynamoDbError    bool
+		dynamodbReturnPayload map[string]string
+	}{
+		{
+			desc:                 "happy path with Zip code that has coverage",
+			zipCode:              "94105",
+			expectZipCodeCovered: true,
+			expectedScore:        88,
+			causeDynamoDbError:   false,
+			dynamodbReturnPayload: map[string]string{
+				"zipcode":      "94105",
+				"carriertype":  "tmobile",
+				"state":        "CA",
+				"nr_pct_cov":   "40",
+				"lte_pct_cov":  "100",
+				"umts_pct_cov": "100",
+				"gsm_pct_cov":  "100",
+			},
+		},
+		{
+			desc:                 "happy path with Zip code that has No coverage when lte_pct_cov attribute at the threshold",
+			zipCode:              "94105",
+			expectZipCodeCovered: false,
+			expectedScore:        25,
+			causeDynamoDbError:   false,
+			dynamodbReturnPayload: map[string]string{
+				"zipcode":     "94105",
+				"carriertype": "tmobile",
+				"state":       "CA",
+				"lte_pct_cov": "50",
+			},
+		},
+		{
+			desc:                 "happy path with Zip code that has No coverage without a state",
+			zipCode:              "94105",
+			expectZipCodeCovered: false,
+			expectedScore:        50,
+			causeDynamoDbError:   false,
+			dynamodbReturnPayload: map[string]string{
+				"zipcode":     "94105",
+				"carriertype": "tmobile",
+				"lte_pct_cov": "100",
+			},
+		},
+		{
+			desc:                 "no coverage for a zip code with invalid lte_pct_cov attribute in database",
+			zipCode:              "94107",
+			expectZipCodeCovered: false,
+			expectedScore:        0,
+			causeDynamoDbError:   false,
+			dynamodbReturnPayload: map[string]string{
+				"zipcode":     "94107",
+				"carriertype": "tmobile",
+				"state":       "CA",
+				"lte_pct_cov": "abc",
+			},
+		},
+		{
+			desc:                  "no coverage for a zip code that does not exist in the database",
+			zipCode:               "11111",
+			expectZipCodeCovered:  false,
+			expectedScore:         0,
+			causeDynamoDbError:    false,
+			dynamodbReturnPayload: map[string]string{},
+		},
+		{
+			desc:                 "Sad path with Zip code that has coverage but results in dynamodb error",
+			zipCode:              "94105",
+			expectZipCodeCovered: false,
+			expectedScore:        0,
+			causeDynamoDbError:   true,
+			dynamodbReturnPayload: map[string]string{
+				"zipcode":     "94105",
+				"carriertype": "tmobile",
+				"state":       "CA",
+				"lte_pct_cov": "100",
+			},
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			tableName := aws.String("fakeCoverage")
+
+			fakeDb := &fakeTMobileDynamoDB{
+				t:               t,
+				tableName:       tableName,
+				payloadToReturn: tC.dynamodbReturnPayload,
+			}
+			if tC.causeDynamoDbError {
+				fakeDb.err = errors.New("fake DB error")
+			}
+
+			dbClient := NewTMobileClient(NewDynamoStore(tableName, fakeDb), rules.Default().Carriers["tmobile"])
+			result, err := dbClient.VerifyCoverage(context.Background(), tC.zipCode)
+
+			if tC.causeDynamoDbError {
+				assert.NotNil(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tC.expectZipCodeCovered, result.IsCovered)
+				assert.Equal(t, tC.expectedScore, result.Detail.Score)
+			}
+
+			assert.Equal(t, tC.zipCode, fakeDb.Keys["zipcode"])
+			assert.Equal(t, "tmobile", fakeDb.Keys["carriertype"])
+		})
+	}
+}
+
+type fakeTMobileDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+	tableName       *string
+	Keys            map[string]string
+	payloadToReturn map[string]string // Store fake return values
+	err             error
+	t               *testing.T
+}
+
+func (fd *fakeTMobileDynamoDB) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	assert.Equal(fd.t, *fd.tableName, *input.TableName, "incorrect table name")
+	if *input.Key["zipcode"].S == loadMetaZipCode {
+		return &dynamodb.GetItemOutput{}, nil
+	}
+
+	expectedAttributes := map[string]*string{
+		"#0": aws.String("zipcode"),
+		"#1": aws.String("carriertype"),
+		"#2": aws.String("lte_pct_cov"),
+		"#3": aws.String("state"),
+		"#4": aws.String("nr_pct_cov"),
+		"#5": aws.String("umts_pct_cov"),
+		"#6": aws.String("gsm_pct_cov"),
+		"#7": aws.String("load_date"),
+	}
+
+	actual := input.ExpressionAttributeNames
+	if e, a := expectedAttributes, actual; !reflect.DeepEqual(a, e) {
+		fd.t.Errorf("expect %v, got %v", e, a)
+	}
+
+	fd.Keys = make(map[string]string)
+	for k, v := range input.Key {
+		if v != nil && v.S != nil {
+			fd.Keys[k] = *v.S
+		}
+	}
+
+	if fd.err != nil {
+		return &dynamodb.GetItemOutput{}, errors.New("Fake dynamodb error")
+	}
+
+	item := map[string]*dynamodb.AttributeValue{}
+	for attribute, value := range fd.payloadToReturn {
+		item[attribute] = &dynamodb.AttributeValue{S: aws.String(value)}
+	}
+	return &dynamodb.GetItemOutput{Item: item}, nil
+}
@@ -0,0 +1,65 @@
+package dbclient
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+
+	"bitbucket.org/credomobile/coverage/tracing"
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+// dynamoDBSpanKey keys the span of a DynamoDB call in the context of its request
+type dynamoDBSpanKey struct{}
+
+// startDynamoDBSpan is a Validate handler of the DynamoDB client starting a client span per call, each page of a
+// paginated one, as a child of the span in the context the call was made with
+func startDynamoDBSpan(r *request.Request) {
+	ctx, span := tracing.Start(r.Context(), "DynamoDB."+r.Operation.Name, tracing.KindClient)
+	if span == nil {
+		return
+	}
+	span.SetAttribute("db.system", "dynamodb")
+	span.SetAttribute("db.operation", r.Operation.Name)
+	if tableName := tableNameOf(r.Params); tableName != "" {
+		span.SetAttribute("aws.dynamodb.table_names", tableName)
+	}
+	r.SetContext(context.WithValue(ctx, dynamoDBSpanKey{}, span))
+}
+
+// endDynamoDBSpan is a Complete handler of the DynamoDB client ending the span of the call, retries included
+func endDynamoDBSpan(r *request.Request) {
+	span, ok := r.Context().Value(dynamoDBSpanKey{}).(*tracing.Span)
+	if !ok {
+		return
+	}
+	span.SetAttribute("aws.request_id", r.RequestID)
+	span.SetAttribute("aws.retry_count", strconv.Itoa(r.RetryCount))
+	if r.HTTPResponse != nil {
+		span.SetAttribute("http.status_code", strconv.Itoa(r.HTTPResponse.StatusCode))
+	}
+	// a call the caller canceled didn't fail
+	if KindOf(r.Error) != KindCanceled {
+		span.RecordError(r.Error)
+	}
+	span.End()
+}
+
+// tableNameOf gives back the TableName of a DynamoDB input, empty for inputs without one such as BatchGetItem
+func tableNameOf(params interface{}) string {
+	v := reflect.ValueOf(params)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	field := v.FieldByName("TableName")
+	if !field.IsValid() {
+		return ""
+	}
+	if tableName, ok := field.Interface().(*string); ok && tableName != nil {
+		return *tableName
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package dbclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/tracing"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingExporter keeps the exported spans
+type recordingExporter struct {
+	spans []tracing.SpanData
+}
+
+func (e *recordingExporter) Export(ctx context.Context, spans []tracing.SpanData) error {
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func TestDynamoDBSpans(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		w.Header().Set("X-Amzn-Requestid", "fake-request-id")
+		if r.Header.Get("X-Amz-Target") == "DynamoDB_20120810.DescribeTable" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type":"com.amazonaws.dynamodb.v20120810#ResourceNotFoundException","message":"Requested resource not found"}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+	os.Setenv("AWS_ACCESS_KEY_ID", "test")
+	os.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	defer os.Unsetenv("AWS_ACCESS_KEY_ID")
+	defer os.Unsetenv("AWS_SECRET_ACCESS_KEY")
+	exporter := &recordingExporter{}
+	tracing.SetTracer(tracing.NewTracer(exporter, nil))
+	defer tracing.SetTracer(nil)
+
+	connection, err := NewConnection(ConnectionConfig{DynamoDBARN: "arn:aws:dynamodb:us-east-2:674346455231:table/coverage", Endpoint: ts.URL})
+	assert.NoError(t, err)
+	ctx, parent := tracing.Start(context.Background(), "GET /v1/coveragecheck", tracing.KindServer)
+
+	_, err = connection.DynamoDB.GetItemWithContext(ctx, &dynamodb.GetItemInput{
+		TableName: connection.TableName,
+		Key:       map[string]*dynamodb.AttributeValue{"zipcode": {S: aws.String("94105")}, "carriertype": {S: aws.String("sprint")}},
+	})
+	assert.NoError(t, err)
+	_, err = connection.DynamoDB.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{TableName: connection.TableName})
+	assert.Error(t, err)
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = connection.DynamoDB.GetItemWithContext(canceled, &dynamodb.GetItemInput{
+		TableName: connection.TableName,
+		Key:       map[string]*dynamodb.AttributeValue{"zipcode": {S: aws.String("94105")}, "carriertype": {S: aws.String("sprint")}},
+	})
+	assert.Equal(t, KindCanceled, KindOf(err))
+	assert.NoError(t, tracing.Flush(context.Background()))
+
+	assert.Len(t, exporter.spans, 3)
+	getItem, describeTable := exporter.spans[0], exporter.spans[1]
+	assert.Equal(t, "DynamoDB.GetItem", getItem.Name)
+	assert.Equal(t, tracing.KindClient, getItem.Kind)
+	assert.Equal(t, parent.SpanContext().SpanID, getItem.Parent)
+	assert.Equal(t, map[string]string{
+		"db.system":                "dynamodb",
+		"db.operation":             "GetItem",
+		"aws.dynamodb.table_names": "coverage",
+		"aws.request_id":           "fake-request-id",
+		"aws.retry_count":          "0",
+		"http.status_code":         "200",
+	}, getItem.Attributes)
+	assert.Empty(t, getItem.Error)
+	assert.Equal(t, "DynamoDB.DescribeTable", describeTable.Name)
+	assert.Equal(t, "400", describeTable.Attributes["http.status_code"])
+	assert.Contains(t, describeTable.Error, "ResourceNotFoundException")
+	assert.Equal(t, "DynamoDB.GetItem", exporter.spans[2].Name)
+	assert.Empty(t, exporter.spans[2].Error)
+}
+
+func TestTableNameOf(t *testing.T) {
+	assert.Equal(t, "coverage", tableNameOf(&dynamodb.QueryInput{TableName: aws.String("coverage")}))
+	assert.Equal(t, "", tableNameOf(&dynamodb.GetItemInput{}))
+	assert.Equal(t, "", tableNameOf(&dynamodb.BatchGetItemInput{}))
+	assert.Equal(t, "", tableNameOf(nil))
+}
@@ -2,20 +2,16 @@ package dbclient
 
 import (
 	"context"
-	"strconv"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/service/dynamodb"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
-	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
-	"github.com/aws/aws-sdk-go/service/dynamodb/expression"
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
 	"github.com/rs/zerolog"
 )
 
 type verizonDbClient struct {
-	logger     *zerolog.Logger
-	tableName  *string
-	connection dynamodbiface.DynamoDBAPI
+	logger *zerolog.Logger
+	store  Store
+	rule   rules.Rule
 }
 
 type verizonCoverageData struct {
@@ -40,72 +36,107 @@ type verizonCoverageData struct {
 	BtaName         string `json:"btaname"`
 	MsaRsaCode      string `json:"msarsacode"`
 	MsaRsaName      string `json:"msarsaname"`
+	ZipCenterLon    string `json:"zip_center_lon"`
+	ZipCenterLat    string `json:"zip_center_lat"`
+	Geohash         string `json:"geohash"`
+}
+
+// verizonTechnologies are the technologies reported in Verizon's coverage data
+var verizonTechnologies = []technology{
+	{name: "LTE", percentageAttribute: "vzelte", indicatorAttribute: "vze_lte_ind", weight: 0.6},
+	{name: "EVDO", percentageAttribute: "vzwevdo", indicatorAttribute: "vzw_evdo_ind", weight: 0.2},
+	{name: "Voice/1X", percentageAttribute: "vzwvoiceor1x", indicatorAttribute: "vzw_voice_or_1x_ind", weight: 0.2},
+}
+
+func init() {
+	register(Carrier{
+		ID:      entity.Verizon,
+		Name:    "Verizon",
+		SortKey: "verizon",
+		newData: func() interface{} { return &verizonCoverageData{} },
+		newClient: func(store Store, rule rules.Rule) CoverageCheckClient {
+			return NewVerizonClient(store, rule)
+		},
+		technologies: verizonTechnologies,
+		marketAreas: []marketAreaType{
+			{name: "MTA", codeAttribute: "mtacode", nameAttribute: "mtaname"},
+			{name: "BTA", codeAttribute: "btacode", nameAttribute: "btaname"},
+			{name: "MSA/RSA", codeAttribute: "msarsacode", nameAttribute: "msarsaname"},
+		},
+		centroid: &centroid{latitudeAttribute: "zip_center_lat", longitudeAttribute: "zip_center_lon"},
+		region:   &region{stateAttribute: "state", countyAttribute: "county"},
+		aliases: map[string]string{
+			"zip":             "zipcode",
+			"vzw_lte":         "vzelte",
+			"vzw_lte_ind":     "vze_lte_ind",
+			"vzw_evdo":        "vzwevdo",
+			"vzw_voice_or_1x": "vzwvoiceor1x",
+			"all_lte":         "alltle",
+			"all_lte_ind":     "all_tle_ind",
+		},
+		numericAttributes: []string{"vzwvoiceor1x", "vzwevdo", "vzelte", "alltle", "zip_center_lon", "zip_center_lat"},
+	})
 }
 
 //NewVerizonClient construts and returns Verizon's db client
-func NewVerizonClient(tableName *string, connection dynamodbiface.DynamoDBAPI) verizonDbClient {
-	return verizonDbClient{tableName: tableName, connection: connection}
+func NewVerizonClient(store Store, rule rules.Rule) verizonDbClient {
+	return verizonDbClient{store: store, rule: rule}
 }
 
-func (v verizonDbClient) VerifyCoverage(ctx context.Context, zipCode string) (bool, error) {
+func (v verizonDbClient) VerifyCoverage(ctx context.Context, zipCode string) (Coverage, error) {
 	zerolog.Ctx(ctx).Info().Msg("*** IN VERIZON DB CLIENT ***")
 
-	proj := expression.NamesList(expression.Name("zipcode"), expression.Name("carriertype"), expression.Name("vzelte"), expression.Name("vze_lte_ind"), expression.Name("state"))
-	expr, err := expression.NewBuilder().WithProjection(proj).Build()
+	item, err := v.store.Get(ctx, zipCode, "verizon", v.attributes())
 	if err != nil {
-		zerolog.Ctx(ctx).Error().Err(err).Msg("failed to build projection expression to query dynamodb table for Verizon coverage")
-		return false, err
+		return Coverage{}, err
 	}
-
-	input := &dynamodb.GetItemInput{
-		TableName: v.tableName,
-		Key: map[string]*dynamodb.AttributeValue{
-			"zipcode": {
-				S: aws.String(zipCode),
-			},
-			"carriertype": {
-				S: aws.String("verizon"),
-			},
-		},
-		ExpressionAttributeNames: expr.Names(),
-		ProjectionExpression:     expr.Projection(),
+	if item == nil {
+		zerolog.Ctx(ctx).Debug().Msgf("Could not find coverage for zipcode: %s", zipCode)
+		return unknownCoverage(ctx, v.store, "verizon"), nil
 	}
 
-	result, err := v.connection.GetItemWithContext(ctx, input)
-	if err != nil {
-		zerolog.Ctx(ctx).Error().Err(err).Msg("failed to query dynamodb")
-		return false, err
+	data := verizonCoverageData{}
+	if err := decodeItem(item, &data); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("failed to decode Verizon coverage data")
+		return Coverage{}, err
 	}
 
-	item := verizonCoverageData{}
-	err = dynamodbattribute.UnmarshalMap(result.Item, &item)
+	return v.coverageOf(ctx, zipCode, data), nil
+}
+
+// BatchVerifyCoverage checks Verizon coverage for many zipcodes in batches. Zipcodes without coverage data are reported as not found.
+func (v verizonDbClient) BatchVerifyCoverage(ctx context.Context, zipCodes []string) (map[string]Coverage, error) {
+	zerolog.Ctx(ctx).Info().Msgf("*** IN VERIZON DB CLIENT BatchVerifyCoverage() for %d zipcodes ***", len(zipCodes))
+
+	items, err := v.store.BatchGet(ctx, "verizon", zipCodes, v.attributes())
 	if err != nil {
-		zerolog.Ctx(ctx).Error().Err(err).Msg("failed to UnmarshalMap Verizon coverage data from dynamodb")
-		return false, err
+		return nil, err
 	}
 
-	if item.ZipCode == "" {
-		zerolog.Ctx(ctx).Debug().Msgf("Could not find coverage for zipcode: %s", zipCode)
-		return false, nil
+	unknown := Coverage{}
+	if len(items) < len(zipCodes) {
+		unknown = unknownCoverage(ctx, v.store, "verizon")
 	}
-
-	covered := v.isZipCovered(ctx, zipCode, item)
-	return covered, nil
+	coverage := make(map[string]Coverage, len(zipCodes))
+	for _, zipCode := range zipCodes {
+		coverage[zipCode] = unknown
+	}
+	for _, item := range items {
+		data := verizonCoverageData{}
+		if err := decodeItem(item, &data); err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Msg("failed to decode Verizon coverage data")
+			return nil, err
+		}
+		coverage[data.ZipCode] = v.coverageOf(ctx, data.ZipCode, data)
+	}
+	return coverage, nil
 }
 
-func (v verizonDbClient) isZipCovered(ctx context.Context, zipCode string, data verizonCoverageData) bool {
-	if len(data.VzwLte) == 0 || len(data.VzwLteInd) == 0 || len(data.State) == 0 {
-		zerolog.Ctx(ctx).Debug().Msgf("zipcode: %s not covered as either vzwlte, vzw_lte_ind or state fields are empty", zipCode)
-		return false
-	}
+// attributes are the zipcode and the attributes read by the coverage rule and technologies
+func (v verizonDbClient) attributes() []string {
+	return coverageAttributes(v.rule, verizonTechnologies, "zipcode", "carriertype")
+}
 
-	vzwlte, err := strconv.ParseFloat(data.VzwLte, 64)
-	if err != nil {
-		zerolog.Ctx(ctx).Error().Err(err).Msg("Illegal value in vzwlte")
-		return false
-	}
-	if vzwlte > 50 && data.VzwLteInd == "Y" {
-		return true
-	}
-	return false
+func (v verizonDbClient) coverageOf(ctx context.Context, zipCode string, data verizonCoverageData) Coverage {
+	return evaluateCoverage(ctx, v.rule, verizonTechnologies, zipCode, data)
 }
@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"testing"
 
+	"bitbucket.org/credomobile/coverage/rules"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
@@ -133,14 +134,14 @@ func TestVerizonVerifyCoverage(t *testing.T) {
 			}
 		}
 
-		sprintdbClient := NewVerizonClient(tableName, fakeDb)
+		sprintdbClient := NewVerizonClient(NewDynamoStore(tableName, fakeDb), rules.Default().Carriers["verizon"])
 		result, err := sprintdbClient.VerifyCoverage(context.Background(), tC.zipCode)
 
 		if tC.causeDynamoDbError {
 			assert.NotNil(t, err)
 		} else {
 			assert.NoError(t, err)
-			assert.Equal(t, tC.expectZipCodeCovered, result)
+			assert.Equal(t, tC.expectZipCodeCovered, result.IsCovered)
 		}
 
 		assert.Equal(t, tC.zipCode, fakeDb.Keys["zipcode"])
@@ -159,6 +160,9 @@ type fakeVerizonDynamoDB struct {
 
 func (fd *fakeVerizonDynamoDB) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
 	assert.Equal(fd.t, *fd.tableName, *input.TableName, "incorrect table name")
+	if *input.Key["zipcode"].S == loadMetaZipCode {
+		return &dynamodb.GetItemOutput{}, nil
+	}
 
 	expectedAttributes := map[string]*string{
 		"#0": aws.String("zipcode"),
@@ -166,6 +170,11 @@ func (fd *fakeVerizonDynamoDB) GetItemWithContext(ctx aws.Context, input *dynamo
 		"#2": aws.String("vzelte"),
 		"#3": aws.String("vze_lte_ind"),
 		"#4": aws.String("state"),
+		"#5": aws.String("vzwevdo"),
+		"#6": aws.String("vzw_evdo_ind"),
+		"#7": aws.String("vzwvoiceor1x"),
+		"#8": aws.String("vzw_voice_or_1x_ind"),
+		"#9": aws.String("load_date"),
 	}
 
 	actual := input.ExpressionAttributeNames
@@ -0,0 +1,123 @@
+package dbclient
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPointerCheckInterval is how often a versioned store re-reads the live dataset pointers by default
+const DefaultPointerCheckInterval = 10 * time.Second
+
+type versionedStore struct {
+	store         Store
+	checkInterval time.Duration
+	now           func() time.Time
+
+	mu       sync.Mutex
+	pointers map[string]checkedPointer
+}
+
+// checkedPointer is a live dataset pointer as it was read at checkedAt. found is false for a carrier read unversioned.
+type checkedPointer struct {
+	version   string
+	found     bool
+	checkedAt time.Time
+}
+
+// NewVersionedStore constructs and gives back a store reading each carrier's live dataset version of the store,
+// re-reading the live dataset pointers at most once every checkInterval. Items are given back with the carriertype of
+// the carrier, so readers don't see the version. A carrier that was never promoted is read unversioned.
+func NewVersionedStore(store Store, checkInterval time.Duration) Store {
+	return &versionedStore{store: store, checkInterval: checkInterval, now: time.Now, pointers: map[string]checkedPointer{}}
+}
+
+func (v *versionedStore) Get(ctx context.Context, zipCode string, sortKey string, attributes []string) (Item, error) {
+	liveSortKey, err := v.liveSortKey(ctx, sortKey)
+	if err != nil {
+		return nil, err
+	}
+	item, err := v.store.Get(ctx, zipCode, liveSortKey, attributes)
+	if err != nil || item == nil {
+		return item, err
+	}
+	return unversioned(item, sortKey), nil
+}
+
+func (v *versionedStore) BatchGet(ctx context.Context, sortKey string, zipCodes []string, attributes []string) ([]Item, error) {
+	liveSortKey, err := v.liveSortKey(ctx, sortKey)
+	if err != nil {
+		return nil, err
+	}
+	items, err := v.store.BatchGet(ctx, liveSortKey, zipCodes, attributes)
+	if err != nil {
+		return nil, err
+	}
+	for i, item := range items {
+		items[i] = unversioned(item, sortKey)
+	}
+	return items, nil
+}
+
+// Query reads a page of a secondary index, which holds the items of every dataset version, and leaves out the items
+// that aren't of a live version. A page may hold fewer items than its limit, or none, and still have a NextToken.
+func (v *versionedStore) Query(ctx context.Context, query IndexQuery) (Page, error) {
+	if len(query.Attributes) > 0 {
+		query.Attributes = append([]string{"carriertype"}, query.Attributes...)
+	}
+	page, err := v.store.Query(ctx, query)
+	if err != nil {
+		return Page{}, err
+	}
+
+	items := make([]Item, 0, len(page.Items))
+	for _, item := range page.Items {
+		sortKey := strings.SplitN(item["carriertype"], versionSeparator, 2)[0]
+		liveSortKey, err := v.liveSortKey(ctx, sortKey)
+		if err != nil {
+			return Page{}, err
+		}
+		if item["carriertype"] == liveSortKey {
+			items = append(items, unversioned(item, sortKey))
+		}
+	}
+	page.Items = items
+	return page, nil
+}
+
+func (v *versionedStore) Check(ctx context.Context) error {
+	return v.store.Check(ctx)
+}
+
+// liveSortKey gives back the sort key of the items of a carrier's live dataset version, or the carrier's own sort key
+// when it was never promoted
+func (v *versionedStore) liveSortKey(ctx context.Context, sortKey string) (string, error) {
+	v.mu.Lock()
+	checked, ok := v.pointers[sortKey]
+	v.mu.Unlock()
+
+	if !ok || v.now().Sub(checked.checkedAt) >= v.checkInterval {
+		pointer, found, err := GetDatasetPointer(ctx, v.store, sortKey)
+		if err != nil {
+			return "", err
+		}
+		checked = checkedPointer{version: pointer.Version, found: found, checkedAt: v.now()}
+		v.mu.Lock()
+		v.pointers[sortKey] = checked
+		v.mu.Unlock()
+	}
+
+	if !checked.found {
+		return sortKey, nil
+	}
+	return versionedSortKey(sortKey, checked.version), nil
+}
+
+// unversioned gives back the item with the carriertype of the carrier in place of its version's
+func unversioned(item Item, sortKey string) Item {
+	if _, ok := item["carriertype"]; ok {
+		item["carriertype"] = sortKey
+	}
+	return item
+}
@@ -0,0 +1,74 @@
+package dbclient
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionedStoreReadsLiveVersion(t *testing.T) {
+	store := NewMemoryStore()
+	store.Put(
+		Item{"zipcode": "94105", "carriertype": "sprint#2018-10-01", "cur_pct_cov": "10", "csa_leaf": "SFRSFR415"},
+		Item{"zipcode": "94105", "carriertype": "sprint#2018-11-01", "cur_pct_cov": "90", "csa_leaf": "SFRSFR415"},
+		Item{"zipcode": "94106", "carriertype": "sprint#2018-11-01", "cur_pct_cov": "80"},
+		Item{"zipcode": "94105", "carriertype": "verizon", "vzelte": "100", "csa_leaf": "SFRSFR415"},
+		Item{"zipcode": livePointerZipCode, "carriertype": "sprint", "version": "2018-11-01", "previous": "2018-10-01"},
+	)
+	versioned := NewVersionedStore(store, time.Minute)
+
+	item, err := versioned.Get(context.Background(), "94105", "sprint", []string{"carriertype", "cur_pct_cov"})
+	assert.NoError(t, err)
+	assert.Equal(t, Item{"carriertype": "sprint", "cur_pct_cov": "90"}, item)
+
+	items, err := versioned.BatchGet(context.Background(), "sprint", []string{"94105", "94106", "94107"}, []string{"zipcode", "cur_pct_cov"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Item{{"zipcode": "94105", "cur_pct_cov": "90"}, {"zipcode": "94106", "cur_pct_cov": "80"}}, items)
+
+	// verizon was never promoted and is read unversioned
+	item, err = versioned.Get(context.Background(), "94105", "verizon", []string{"vzelte"})
+	assert.NoError(t, err)
+	assert.Equal(t, Item{"vzelte": "100"}, item)
+
+	page, err := versioned.Query(context.Background(), IndexQuery{Index: "csa_leaf-index", HashKey: "csa_leaf", Value: "SFRSFR415", Attributes: []string{"zipcode"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []Item{
+		{"zipcode": "94105", "carriertype": "sprint"},
+		{"zipcode": "94105", "carriertype": "verizon"},
+	}, page.Items)
+}
+
+func TestVersionedStoreChecksPointer(t *testing.T) {
+	store := NewMemoryStore()
+	loadVersions(t, store, "2018-10-01")
+	_, err := store.Load(context.Background(), entity.Sprint, "2018-11-01", []map[string]string{
+		{"ZIP": "94105", "Cur_Pct_Cov": "50"},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, store.Datasets().Promote(context.Background(), entity.Sprint, "2018-10-01"))
+
+	clock := &fakeClock{now: time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC)}
+	versioned := &versionedStore{store: store, checkInterval: 10 * time.Second, now: clock.Now, pointers: map[string]checkedPointer{}}
+
+	readCoverage := func() string {
+		item, err := versioned.Get(context.Background(), "94105", "sprint", []string{"cur_pct_cov"})
+		assert.NoError(t, err)
+		return item["cur_pct_cov"]
+	}
+	assert.Equal(t, "100", readCoverage())
+
+	assert.NoError(t, store.Datasets().Promote(context.Background(), entity.Sprint, "2018-11-01"))
+	clock.now = clock.now.Add(5 * time.Second)
+	assert.Equal(t, "100", readCoverage())
+
+	clock.now = clock.now.Add(5 * time.Second)
+	assert.Equal(t, "50", readCoverage())
+
+	_, err = store.Datasets().Rollback(context.Background(), entity.Sprint)
+	assert.NoError(t, err)
+	clock.now = clock.now.Add(10 * time.Second)
+	assert.Equal(t, "100", readCoverage())
+}
@@ -5,4 +5,16 @@ type CarrierType string
 const (
 	Sprint  CarrierType = "1"
 	Verizon CarrierType = "2"
+	TMobile CarrierType = "3"
+	ATT     CarrierType = "4"
+
+	// AllCarriers asks for the coverage of every supported carrier
+	AllCarriers CarrierType = "all"
+)
+
+// Health statuses reported by the health check
+const (
+	HealthOK          = "ok"
+	HealthDegraded    = "degraded"
+	HealthUnavailable = "unavailable"
 )
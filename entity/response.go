@@ -1,22 +1,341 @@
 package entity
 
+import "time"
+
 // Response is the container for what should be returned from all public facing endpoints
 type Response struct {
 	Result interface{} `json:"Result,omitempty"`
 	Errors []Error     `json:"Errors,omitempty"`
 }
 
-// Error is used to report errors to the caller
+// Error is used to report errors to the caller. Code is one of the error codes below, for callers to act on
+// without parsing the message.
 type Error struct {
 	Message string `json:"message"`
 	Path    string `json:"path,omitempty"`
+	Code    string `json:"code,omitempty"`
 }
 
+// Error codes of the errors reported to the caller
+const (
+	CodeInvalidRequest = "invalid_request"
+	CodeUnauthorized   = "unauthorized"
+	CodeForbidden      = "forbidden"
+	CodeNotFound       = "not_found"
+	CodeConflict       = "conflict"
+	CodeRateLimited    = "rate_limited"
+	CodeQuotaExceeded  = "quota_exceeded"
+	CodeThrottled      = "throttled"
+	CodeUnavailable    = "unavailable"
+	CodeCanceled       = "canceled"
+	CodeBadData        = "bad_data"
+	CodeInternal       = "internal"
+)
+
+// CoverageStatus tells a zipcode a carrier covers from one it doesn't, and both from one missing from the
+// carrier's coverage data, which IsCovered reports as not covered
+type CoverageStatus string
+
+const (
+	CoverageCovered    CoverageStatus = "covered"
+	CoverageNotCovered CoverageStatus = "not_covered"
+	CoverageUnknown    CoverageStatus = "unknown"
+)
+
+// CoverageCheckResponse is the coverage of a zipcode for a carrier. LoadDate is the load date of the dataset the
+// answer came from, RuleVersion is the version of the coverage rules that produced it and Detail is only set when
+// asked for.
 type CoverageCheckResponse struct {
-	IsCovered bool
+	IsCovered   bool
+	Status      CoverageStatus    `json:",omitempty"`
+	LoadDate    string            `json:",omitempty"`
+	RuleVersion string            `json:",omitempty"`
+	Detail      *CoverageDetail   `json:",omitempty"`
+	Location    *ResolvedLocation `json:",omitempty"`
+}
+
+// ResolvedLocation is the zipcode a latitude and longitude was resolved to: the one whose centroid is nearest,
+// DistanceKm away
+type ResolvedLocation struct {
+	ZipCode    string
+	DistanceKm float64
+}
+
+// CoverageDetail breaks a carrier's coverage of a zipcode down per technology. Score is a normalized
+// 0-100 coverage score that can be compared across carriers.
+type CoverageDetail struct {
+	Score        float64
+	Technologies []TechnologyCoverage
+}
+
+// TechnologyCoverage is the percentage of a zipcode covered by a technology such as LTE, along with the
+// carrier's Y/N indicator for it when the carrier reports one
+type TechnologyCoverage struct {
+	Technology string
+	Percentage *float64 `json:",omitempty"`
+	Indicator  string   `json:",omitempty"`
+}
+
+// CarrierCoverageResult is the coverage of one carrier in a multi-carrier comparison.
+// Coverage is left out when the carrier's lookup failed, in which case Errors is set.
+type CarrierCoverageResult struct {
+	CarrierID string
+	*CoverageCheckResponse
+	Errors []Error `json:",omitempty"`
+}
+
+// MultiCarrierCoverageResponse compares the coverage of every supported carrier for a zipcode.
+// BestCarrierID is the covering carrier with the highest coverage score and is empty when none covers it.
+type MultiCarrierCoverageResponse struct {
+	Carriers      []CarrierCoverageResult
+	BestCarrierID string            `json:",omitempty"`
+	Location      *ResolvedLocation `json:",omitempty"`
+}
+
+// MarketArea is a market a carrier places a zipcode in, such as Sprint's CSA or Verizon's MTA, BTA and MSA/RSA.
+// Some carriers only name their markets, in which case Code is empty.
+type MarketArea struct {
+	Type string
+	Code string `json:",omitempty"`
+	Name string `json:",omitempty"`
+}
+
+// CarrierMarketAreas are the market areas of a zipcode for a carrier. Found is false when the carrier's
+// coverage data doesn't place the zipcode in any market area. Status is the carrier's coverage of the zipcode and
+// LoadDate the load date of the dataset the answer came from.
+type CarrierMarketAreas struct {
+	CarrierID   string
+	Name        string
+	Found       bool
+	Status      CoverageStatus `json:",omitempty"`
+	LoadDate    string         `json:",omitempty"`
+	MarketAreas []MarketArea
+}
+
+// MarketAreaResponse lists the market areas of a zipcode for the carriers asked for
+type MarketAreaResponse struct {
+	ZipCode  string
+	Carriers []CarrierMarketAreas
+}
+
+// CoverageCheckItem is a single zipcode and carrier pair of a batch coverage check
+type CoverageCheckItem struct {
+	ZipCode   string `json:"zipcode"`
+	CarrierID string `json:"carrierid"`
+}
+
+// BatchCoverageCheckRequest is the body of a batch coverage check. Detail asks for the per technology
+// coverage of every item.
+type BatchCoverageCheckRequest struct {
+	Items  []CoverageCheckItem `json:"items"`
+	Detail bool                `json:"detail"`
+}
+
+// BatchCoverageCheckResult is the outcome of a single item of a batch coverage check.
+// Coverage is left out for items that failed validation, in which case Errors is set.
+type BatchCoverageCheckResult struct {
+	ZipCode   string
+	CarrierID string
+	*CoverageCheckResponse
+	Errors []Error `json:",omitempty"`
+}
+
+type BatchCoverageCheckResponse struct {
+	Results []BatchCoverageCheckResult
+}
+
+// CarrierMetadata describes a supported carrier and the technologies its coverage detail reports
+type CarrierMetadata struct {
+	CarrierID    string
+	Name         string
+	Technologies []string
+}
+
+// CarriersResponse lists the supported carriers and the version of the coverage rules applied to them
+type CarriersResponse struct {
+	Carriers    []CarrierMetadata
+	RuleVersion string `json:",omitempty"`
+}
+
+// CacheStatsResponse reports how many coverage and market area lookups the cache answered since the instance started
+type CacheStatsResponse struct {
+	Enabled   bool
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Size      int
+}
+
+// HealthResponse reports the build and how long the instance has been up. The deep check also reports whether the
+// coverage table can be read and which carriers have coverage data loaded.
+type HealthResponse struct {
+	Status        string
+	Version       string
+	Uptime        string
+	UptimeSeconds int64
+	Table         *TableHealth    `json:",omitempty"`
+	Carriers      []CarrierHealth `json:",omitempty"`
+}
+
+// TableHealth is whether the coverage table can be read, Error says why it can't
+type TableHealth struct {
+	Status string
+	Error  string `json:",omitempty"`
+}
+
+// CarrierHealth is whether a carrier has coverage data loaded and from which load
+type CarrierHealth struct {
+	CarrierID string
+	Name      string
+	Loaded    bool
+	LoadDate  string `json:",omitempty"`
+	Rows      int    `json:",omitempty"`
+	Error     string `json:",omitempty"`
+}
+
+// CsaZipCode is a zipcode of a Sprint CSA with its coverage per technology
+type CsaZipCode struct {
+	ZipCode      string
+	State        string `json:",omitempty"`
+	City         string `json:",omitempty"`
+	Score        float64
+	Technologies []TechnologyCoverage
+}
+
+// CsaZipCodesResponse is a page of the zipcodes of a Sprint CSA. NextToken asks for the next page and is left
+// out on the last one.
+type CsaZipCodesResponse struct {
+	Csa       string
+	ZipCodes  []CsaZipCode
+	NextToken string `json:",omitempty"`
+}
+
+// RadiusCoverageResponse is the coverage of every zipcode whose centroid is within RadiusMiles of the center,
+// nearest first, along with a summary of each carrier's coverage of the area
+type RadiusCoverageResponse struct {
+	Center      RadiusCenter
+	RadiusMiles float64
+	ZipCodes    []RadiusZipCode
+	Carriers    []CarrierRadiusSummary
+}
+
+// RadiusCenter is the center of a radius search. ZipCode is set when the search was centered on a zipcode's centroid.
+type RadiusCenter struct {
+	Lat     float64
+	Lon     float64
+	ZipCode string `json:",omitempty"`
+}
+
+// RadiusZipCode is a zipcode in a radius search, DistanceMiles from the center, with each carrier's coverage of it
+type RadiusZipCode struct {
+	ZipCode       string
+	DistanceMiles float64
+	Carriers      []CarrierCoverageResult
+}
+
+// CarrierRadiusSummary is how many of the ZipCodes in a radius search a carrier covers, and how many of them are
+// Unknown, missing from its coverage data. CoveredPercent is the share of the zipcodes it has data for that it covers,
+// from 0 to 100. Errors is set when the carrier's lookup failed.
+type CarrierRadiusSummary struct {
+	CarrierID      string
+	Name           string
+	ZipCodes       int
+	Covered        int
+	Unknown        int
+	CoveredPercent float64
+	Errors         []Error `json:",omitempty"`
+}
+
+// CoverageSummaryResponse summarizes each carrier's coverage of the zipcodes of a state, or of a county of the state
+type CoverageSummaryResponse struct {
+	State    string
+	County   string `json:",omitempty"`
+	Carriers []CarrierCoverageSummary
+}
+
+// CarrierCoverageSummary is how many zipcodes of an area a carrier covers and doesn't, with the average coverage
+// score and percentage of each technology over them. Found is false when the carrier has no coverage data for the
+// area. LoadDate and RuleVersion are the load and coverage rules the summary was computed from.
+type CarrierCoverageSummary struct {
+	CarrierID    string
+	Name         string
+	Found        bool
+	ZipCodes     int
+	Covered      int
+	Uncovered    int
+	AverageScore float64
+	Technologies []TechnologyCoverage `json:",omitempty"`
+	LoadDate     string               `json:",omitempty"`
+	RuleVersion  string               `json:",omitempty"`
+}
+
+// CoverageDiffResponse compares two dataset versions of a carrier's coverage data with the carrier's coverage rule.
+// Gained and Lost are the zipcodes of both versions whose coverage flipped, Swings the technology percentages that
+// moved by at least SwingThreshold points, and Added and Removed the zipcodes found in only one of the versions.
+type CoverageDiffResponse struct {
+	CarrierID      string
+	Name           string
+	From           string
+	To             string
+	RuleVersion    string
+	SwingThreshold float64
+	ZipCodes       int
+	Gained         []CoverageChange
+	Lost           []CoverageChange
+	Swings         []CoverageSwing
+	Added          []string
+	Removed        []string
+}
+
+// CoverageChange is a zipcode whose coverage flipped between two dataset versions, with its score in each
+type CoverageChange struct {
+	ZipCode   string
+	FromScore float64
+	ToScore   float64
+}
+
+// CoverageSwing is a technology percentage of a zipcode that moved between two dataset versions. A percentage
+// missing from a version counts as 0.
+type CoverageSwing struct {
+	ZipCode    string
+	Technology string
+	From       float64
+	To         float64
+	Change     float64
+}
+
+// APIClientRequest issues an api key to a new client. RateLimit is in requests per second and DailyQuota in requests
+// per UTC day, 0 leaves either unlimited.
+type APIClientRequest struct {
+	ClientID   string  `json:"clientid"`
+	Name       string  `json:"name"`
+	RateLimit  float64 `json:"ratelimit"`
+	DailyQuota int     `json:"dailyquota"`
+}
+
+// APIKeyResponse is an api client and its limits. APIKey is only given back when a key is issued or rotated, it
+// can't be read back later. KeyPrefix is the start of the client's current key, PreviousKeyExpiresAt when the key
+// replaced by a rotation stops working and RevokedKeys how many keys a revocation removed.
+type APIKeyResponse struct {
+	ClientID             string
+	Name                 string
+	RateLimit            float64
+	DailyQuota           int
+	APIKey               string     `json:",omitempty"`
+	KeyPrefix            string     `json:",omitempty"`
+	PreviousKeyExpiresAt *time.Time `json:",omitempty"`
+	RevokedKeys          int        `json:",omitempty"`
+}
+
+// APIUsageResponse is how many requests an api client made on each of the last days, the most recent first
+type APIUsageResponse struct {
+	ClientID   string
+	DailyQuota int
+	Days       []APIUsageDay
 }
 
-type CsaResponse struct {
-	CsaFound bool
-	Csa      string
+// APIUsageDay is how many requests an api client made on a UTC day
+type APIUsageDay struct {
+	Date     string
+	Requests int
 }
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"bitbucket.org/credomobile/coverage/auth"
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/services"
+	"bitbucket.org/credomobile/coverage/validators"
+	"github.com/go-chi/chi"
+	"github.com/rs/zerolog/log"
+)
+
+// apiKeyHeader is the header the callers send their api key in
+const apiKeyHeader = "x-api-key"
+
+// RequireAPIKey is middleware letting through requests with a valid api key within its client's rate limit and
+// daily quota. The client's id is put into the request context, and onto its logger, for the handlers. Requests
+// without a valid key get a 401, requests over a limit a 429 with a Retry-After.
+func RequireAPIKey(apiKeys services.APIKeys) func(next http.Handler) http.Handler {
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			key := r.Header.Get(apiKeyHeader)
+			if key == "" {
+				apiKeyRejected(w, http.StatusUnauthorized, entity.CodeUnauthorized, "Missing api key")
+				return
+			}
+
+			clientID, retryAfter, err := apiKeys.Authorize(ctx, key)
+			switch err {
+			case nil:
+			case services.ErrInvalidAPIKey:
+				log.Ctx(ctx).Info().Msg("Rejected api key")
+				apiKeyRejected(w, http.StatusUnauthorized, entity.CodeUnauthorized, "Invalid api key")
+				return
+			case services.ErrRateLimited, services.ErrQuotaExceeded:
+				log.Ctx(ctx).Info().Err(err).Msgf("Throttled api client %s", clientID)
+				setRetryAfter(w, retryAfter)
+				if err == services.ErrQuotaExceeded {
+					apiKeyRejected(w, http.StatusTooManyRequests, entity.CodeQuotaExceeded, "Daily quota exceeded")
+				} else {
+					apiKeyRejected(w, http.StatusTooManyRequests, entity.CodeRateLimited, "Rate limit exceeded")
+				}
+				return
+			default:
+				serviceFailed(w, r, err, "Error occurred authorizing api key")
+				return
+			}
+
+			logger := log.Ctx(ctx).With().Str("client", clientID).Logger()
+			ctx = auth.WithClientID(logger.WithContext(ctx), clientID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// apiKeyRejected writes the status with the error code and message in the error envelope
+func apiKeyRejected(w http.ResponseWriter, status int, code string, message string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(entity.Response{Errors: []entity.Error{{Message: message, Path: apiKeyHeader, Code: code}}})
+}
+
+// IssueAPIKey creates an api client with a new key, given back once in the 201 response
+func IssueAPIKey(validator validators.APIKeysValidator, apiKeysService services.APIKeys) func(w http.ResponseWriter, r *http.Request) {
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var request entity.APIClientRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("unable to decode api client request")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(entity.Response{Errors: []entity.Error{{Message: "Malformed request body", Code: entity.CodeInvalidRequest}}})
+			return
+		}
+
+		validationErrors := traceValidation(ctx, func(ctx context.Context) []entity.Error {
+			return validator.Validate(ctx, request)
+		})
+		if len(validationErrors) > 0 {
+			validationFailed(w, r, validationErrors)
+			return
+		}
+
+		response, err := apiKeysService.Issue(ctx, request)
+		if err == dbclient.ErrClientExists {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(entity.Response{Errors: []entity.Error{{Message: "Client already exists", Path: "clientid", Code: entity.CodeConflict}}})
+			return
+		}
+		if err != nil {
+			serviceFailed(w, r, err, "Error occurred issuing api key for clientID: %s", request.ClientID)
+			return
+		}
+
+		result, _ := json.Marshal(entity.Response{Result: response})
+		w.WriteHeader(http.StatusCreated)
+		w.Write(result)
+	}
+}
+
+// RotateAPIKey gives the client in the path a new key, its previous keys keep working for a grace period
+func RotateAPIKey(validator validators.APIKeysValidator, apiKeysService services.APIKeys) func(w http.ResponseWriter, r *http.Request) {
+	return manageAPIKeys(validator, "rotating api key", apiKeysService.Rotate)
+}
+
+// RevokeAPIKeys removes every key of the client in the path
+func RevokeAPIKeys(validator validators.APIKeysValidator, apiKeysService services.APIKeys) func(w http.ResponseWriter, r *http.Request) {
+	return manageAPIKeys(validator, "revoking api keys", apiKeysService.Revoke)
+}
+
+// manageAPIKeys serves a change to the keys of the client in the path
+func manageAPIKeys(validator validators.APIKeysValidator, action string, change func(ctx context.Context, clientID string) (entity.APIKeyResponse, bool, error)) func(w http.ResponseWriter, r *http.Request) {
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		validationErrors := validateRequest(r, validator.ValidateClient)
+		if len(validationErrors) > 0 {
+			validationFailed(w, r, validationErrors)
+			return
+		}
+
+		ctx := r.Context()
+		clientID := chi.URLParam(r, "clientid")
+		response, found, err := change(ctx, clientID)
+		if err == dbclient.ErrClientChanged {
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(entity.Response{Errors: []entity.Error{{Message: "Client keys changed concurrently, try again", Path: "clientid", Code: entity.CodeConflict}}})
+			return
+		}
+		if err != nil {
+			serviceFailed(w, r, err, "Error occurred %s for clientID: %s", action, clientID)
+			return
+		}
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(entity.Response{Errors: []entity.Error{{Message: "No client found", Path: "clientid", Code: entity.CodeNotFound}}})
+			return
+		}
+
+		result, _ := json.Marshal(entity.Response{Result: response})
+		w.WriteHeader(http.StatusOK)
+		w.Write(result)
+	}
+}
+
+// GetAPIKeyUsage serves how many requests the client in the path made on each of the last days
+func GetAPIKeyUsage(validator validators.APIKeysValidator, apiKeysService services.APIKeys) func(w http.ResponseWriter, r *http.Request) {
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		validationErrors := validateRequest(r, validator.ValidateUsage)
+		if len(validationErrors) > 0 {
+			validationFailed(w, r, validationErrors)
+			return
+		}
+
+		ctx := r.Context()
+		clientID := chi.URLParam(r, "clientid")
+		days := validators.DefaultAPIUsageDays
+		if value := r.URL.Query().Get("days"); value != "" {
+			days, _ = strconv.Atoi(value)
+		}
+		response, found, err := apiKeysService.Usage(ctx, clientID, days)
+		if err != nil {
+			serviceFailed(w, r, err, "Error occurred reading api usage for clientID: %s", clientID)
+			return
+		}
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(entity.Response{Errors: []entity.Error{{Message: "No client found", Path: "clientid", Code: entity.CodeNotFound}}})
+			return
+		}
+
+		result, _ := json.Marshal(entity.Response{Result: response})
+		w.WriteHeader(http.StatusOK)
+		w.Write(result)
+	}
+}
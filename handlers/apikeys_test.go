@@ -0,0 +1,413 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"bitbucket.org/credomobile/coverage/auth"
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/services"
+	"bitbucket.org/credomobile/coverage/validators"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRequireAPIKey(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		key              string
+		clientID         string
+		retryAfter       time.Duration
+		err              error
+		statusCode       int
+		expectedRetry    string
+		expectedResponse string
+	}{
+		{
+			desc:             "Valid key",
+			key:              "ck_good",
+			clientID:         "partner",
+			statusCode:       http.StatusOK,
+			expectedResponse: "hello partner",
+		},
+		{
+			desc:             "Missing key",
+			statusCode:       http.StatusUnauthorized,
+			expectedResponse: `{"Errors":[{"message":"Missing api key","path":"x-api-key","code":"unauthorized"}]}` + "\n",
+		},
+		{
+			desc:             "Invalid key",
+			key:              "ck_bad",
+			err:              services.ErrInvalidAPIKey,
+			statusCode:       http.StatusUnauthorized,
+			expectedResponse: `{"Errors":[{"message":"Invalid api key","path":"x-api-key","code":"unauthorized"}]}` + "\n",
+		},
+		{
+			desc:             "Rate limited",
+			key:              "ck_good",
+			clientID:         "partner",
+			retryAfter:       200 * time.Millisecond,
+			err:              services.ErrRateLimited,
+			statusCode:       http.StatusTooManyRequests,
+			expectedRetry:    "1",
+			expectedResponse: `{"Errors":[{"message":"Rate limit exceeded","path":"x-api-key","code":"rate_limited"}]}` + "\n",
+		},
+		{
+			desc:             "Quota exceeded",
+			key:              "ck_good",
+			clientID:         "partner",
+			retryAfter:       90 * time.Minute,
+			err:              services.ErrQuotaExceeded,
+			statusCode:       http.StatusTooManyRequests,
+			expectedRetry:    "5400",
+			expectedResponse: `{"Errors":[{"message":"Daily quota exceeded","path":"x-api-key","code":"quota_exceeded"}]}` + "\n",
+		},
+		{
+			desc:             "Db error",
+			key:              "ck_good",
+			err:              errors.New("Fake error"),
+			statusCode:       http.StatusInternalServerError,
+			expectedResponse: `{"Errors":[{"message":"There is a problem on the server. Please try again later","code":"internal"}]}` + "\n",
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			apiKeysService := MockAPIKeys{}
+			if tC.key != "" {
+				apiKeysService.On("Authorize", mock.Anything, tC.key).Return(tC.clientID, tC.retryAfter, tC.err)
+			}
+
+			r := chi.NewRouter()
+			r.Use(RequireAPIKey(&apiKeysService))
+			r.Get("/v1/carriers", func(w http.ResponseWriter, r *http.Request) {
+				clientID, _ := auth.ClientIDFrom(r.Context())
+				w.Write([]byte("hello " + clientID))
+			})
+			ts := httptest.NewServer(r)
+			defer ts.Close()
+
+			req, _ := http.NewRequest("GET", ts.URL+"/v1/carriers", nil)
+			if tC.key != "" {
+				req.Header.Set("x-api-key", tC.key)
+			}
+			res, err := ts.Client().Do(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tC.statusCode, res.StatusCode)
+			assert.Equal(t, tC.expectedRetry, res.Header.Get("Retry-After"))
+			body, _ := ioutil.ReadAll(res.Body)
+			assert.Equal(t, tC.expectedResponse, string(body))
+			apiKeysService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		identity   *auth.Identity
+		statusCode int
+	}{
+		{
+			desc:       "Granted scope",
+			identity:   &auth.Identity{Subject: "ops", Scopes: []string{"coverage:read", "coverage:admin"}},
+			statusCode: http.StatusOK,
+		},
+		{
+			desc:       "Other scopes",
+			identity:   &auth.Identity{Subject: "postman", Scopes: []string{"coverage:read"}},
+			statusCode: http.StatusForbidden,
+		},
+		{
+			desc:       "Unauthenticated",
+			statusCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			handler := RequireScope(auth.AdminScope)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			req := httptest.NewRequest("GET", "/v1/admin/apikeys/partner/usage", nil)
+			if tC.identity != nil {
+				req = req.WithContext(auth.WithIdentity(req.Context(), *tC.identity))
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tC.statusCode, w.Code)
+			if tC.statusCode == http.StatusForbidden {
+				assert.Equal(t, `{"Errors":[{"message":"Missing required scope","path":"scope","code":"forbidden"}]}`+"\n", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestIssueAPIKey(t *testing.T) {
+	request := entity.APIClientRequest{ClientID: "partner", Name: "Partner", RateLimit: 5, DailyQuota: 1000}
+	testCases := []struct {
+		desc             string
+		body             string
+		issue            bool
+		response         entity.APIKeyResponse
+		err              error
+		statusCode       int
+		expectedResponse string
+	}{
+		{
+			desc:             "Issued",
+			body:             `{"clientid":"partner","name":"Partner","ratelimit":5,"dailyquota":1000}`,
+			issue:            true,
+			response:         entity.APIKeyResponse{ClientID: "partner", Name: "Partner", RateLimit: 5, DailyQuota: 1000, APIKey: "ck_secret", KeyPrefix: "ck_secre"},
+			statusCode:       http.StatusCreated,
+			expectedResponse: `{"Result":{"ClientID":"partner","Name":"Partner","RateLimit":5,"DailyQuota":1000,"APIKey":"ck_secret","KeyPrefix":"ck_secre"}}`,
+		},
+		{
+			desc:             "Existing client",
+			body:             `{"clientid":"partner","name":"Partner","ratelimit":5,"dailyquota":1000}`,
+			issue:            true,
+			err:              dbclient.ErrClientExists,
+			statusCode:       http.StatusConflict,
+			expectedResponse: `{"Errors":[{"message":"Client already exists","path":"clientid","code":"conflict"}]}` + "\n",
+		},
+		{
+			desc:             "Db error",
+			body:             `{"clientid":"partner","name":"Partner","ratelimit":5,"dailyquota":1000}`,
+			issue:            true,
+			err:              errors.New("Fake error"),
+			statusCode:       http.StatusInternalServerError,
+			expectedResponse: `{"Errors":[{"message":"There is a problem on the server. Please try again later","code":"internal"}]}` + "\n",
+		},
+		{
+			desc:             "Invalid request",
+			body:             `{"clientid":"partner"}`,
+			statusCode:       http.StatusBadRequest,
+			expectedResponse: `{"Errors":[{"message":"Missing required property","path":"name","code":"invalid_request"}]}` + "\n",
+		},
+		{
+			desc:             "Malformed body",
+			body:             `{"clientid":`,
+			statusCode:       http.StatusBadRequest,
+			expectedResponse: `{"Errors":[{"message":"Malformed request body","code":"invalid_request"}]}` + "\n",
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			apiKeysService := MockAPIKeys{}
+			if tC.issue {
+				apiKeysService.On("Issue", mock.Anything, request).Return(tC.response, tC.err)
+			}
+
+			r := chi.NewRouter()
+			r.Post("/v1/admin/apikeys", IssueAPIKey(validators.NewAPIKeysValidator(), &apiKeysService))
+			ts := httptest.NewServer(r)
+			defer ts.Close()
+
+			res, err := ts.Client().Post(ts.URL+"/v1/admin/apikeys", "application/json", strings.NewReader(tC.body))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tC.statusCode, res.StatusCode)
+			body, _ := ioutil.ReadAll(res.Body)
+			assert.Equal(t, tC.expectedResponse, string(body))
+			apiKeysService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRotateAndRevokeAPIKeys(t *testing.T) {
+	expiresAt := time.Date(2018, 11, 2, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		desc             string
+		method           string
+		path             string
+		call             string
+		response         entity.APIKeyResponse
+		found            bool
+		err              error
+		statusCode       int
+		expectedResponse string
+	}{
+		{
+			desc:             "Rotated",
+			method:           "POST",
+			path:             "/v1/admin/apikeys/partner/rotate",
+			call:             "Rotate",
+			response:         entity.APIKeyResponse{ClientID: "partner", Name: "Partner", APIKey: "ck_new", KeyPrefix: "ck_new", PreviousKeyExpiresAt: &expiresAt},
+			found:            true,
+			statusCode:       http.StatusOK,
+			expectedResponse: `{"Result":{"ClientID":"partner","Name":"Partner","RateLimit":0,"DailyQuota":0,"APIKey":"ck_new","KeyPrefix":"ck_new","PreviousKeyExpiresAt":"2018-11-02T12:00:00Z"}}`,
+		},
+		{
+			desc:             "Revoked",
+			method:           "DELETE",
+			path:             "/v1/admin/apikeys/partner",
+			call:             "Revoke",
+			response:         entity.APIKeyResponse{ClientID: "partner", Name: "Partner", RevokedKeys: 2},
+			found:            true,
+			statusCode:       http.StatusOK,
+			expectedResponse: `{"Result":{"ClientID":"partner","Name":"Partner","RateLimit":0,"DailyQuota":0,"RevokedKeys":2}}`,
+		},
+		{
+			desc:             "Unknown client",
+			method:           "DELETE",
+			path:             "/v1/admin/apikeys/partner",
+			call:             "Revoke",
+			statusCode:       http.StatusNotFound,
+			expectedResponse: `{"Errors":[{"message":"No client found","path":"clientid","code":"not_found"}]}` + "\n",
+		},
+		{
+			desc:             "Concurrent change",
+			method:           "DELETE",
+			path:             "/v1/admin/apikeys/partner",
+			call:             "Revoke",
+			err:              dbclient.ErrClientChanged,
+			statusCode:       http.StatusConflict,
+			expectedResponse: `{"Errors":[{"message":"Client keys changed concurrently, try again","path":"clientid","code":"conflict"}]}` + "\n",
+		},
+		{
+			desc:             "Db error",
+			method:           "POST",
+			path:             "/v1/admin/apikeys/partner/rotate",
+			call:             "Rotate",
+			err:              errors.New("Fake error"),
+			statusCode:       http.StatusInternalServerError,
+			expectedResponse: `{"Errors":[{"message":"There is a problem on the server. Please try again later","code":"internal"}]}` + "\n",
+		},
+		{
+			desc:             "Invalid client",
+			method:           "POST",
+			path:             "/v1/admin/apikeys/part.ner/rotate",
+			statusCode:       http.StatusBadRequest,
+			expectedResponse: `{"Errors":[{"message":"Illegal value for property","path":"clientid","code":"invalid_request"}]}` + "\n",
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			apiKeysService := MockAPIKeys{}
+			if tC.call != "" {
+				apiKeysService.On(tC.call, mock.Anything, "partner").Return(tC.response, tC.found, tC.err)
+			}
+
+			r := chi.NewRouter()
+			r.Post("/v1/admin/apikeys/{clientid}/rotate", RotateAPIKey(validators.NewAPIKeysValidator(), &apiKeysService))
+			r.Delete("/v1/admin/apikeys/{clientid}", RevokeAPIKeys(validators.NewAPIKeysValidator(), &apiKeysService))
+			ts := httptest.NewServer(r)
+			defer ts.Close()
+
+			req, _ := http.NewRequest(tC.method, ts.URL+tC.path, nil)
+			res, err := ts.Client().Do(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tC.statusCode, res.StatusCode)
+			body, _ := ioutil.ReadAll(res.Body)
+			assert.Equal(t, tC.expectedResponse, string(body))
+			apiKeysService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetAPIKeyUsage(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		path             string
+		days             int
+		response         entity.APIUsageResponse
+		found            bool
+		err              error
+		statusCode       int
+		expectedResponse string
+	}{
+		{
+			desc:             "Default days",
+			path:             "/v1/admin/apikeys/partner/usage",
+			days:             validators.DefaultAPIUsageDays,
+			response:         entity.APIUsageResponse{ClientID: "partner", DailyQuota: 1000, Days: []entity.APIUsageDay{{Date: "2018-11-01", Requests: 12}}},
+			found:            true,
+			statusCode:       http.StatusOK,
+			expectedResponse: `{"Result":{"ClientID":"partner","DailyQuota":1000,"Days":[{"Date":"2018-11-01","Requests":12}]}}`,
+		},
+		{
+			desc:             "Unknown client",
+			path:             "/v1/admin/apikeys/partner/usage?days=30",
+			days:             30,
+			statusCode:       http.StatusNotFound,
+			expectedResponse: `{"Errors":[{"message":"No client found","path":"clientid","code":"not_found"}]}` + "\n",
+		},
+		{
+			desc:             "Db error",
+			path:             "/v1/admin/apikeys/partner/usage?days=1",
+			days:             1,
+			err:              errors.New("Fake error"),
+			statusCode:       http.StatusInternalServerError,
+			expectedResponse: `{"Errors":[{"message":"There is a problem on the server. Please try again later","code":"internal"}]}` + "\n",
+		},
+		{
+			desc:             "Invalid days",
+			path:             "/v1/admin/apikeys/partner/usage?days=0",
+			statusCode:       http.StatusBadRequest,
+			expectedResponse: `{"Errors":[{"message":"Illegal value for property","path":"days","code":"invalid_request"}]}` + "\n",
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			apiKeysService := MockAPIKeys{}
+			if tC.days > 0 {
+				apiKeysService.On("Usage", mock.Anything, "partner", tC.days).Return(tC.response, tC.found, tC.err)
+			}
+
+			r := chi.NewRouter()
+			r.Get("/v1/admin/apikeys/{clientid}/usage", GetAPIKeyUsage(validators.NewAPIKeysValidator(), &apiKeysService))
+			ts := httptest.NewServer(r)
+			defer ts.Close()
+
+			res, err := ts.Client().Get(ts.URL + tC.path)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tC.statusCode, res.StatusCode)
+			body, _ := ioutil.ReadAll(res.Body)
+			assert.Equal(t, tC.expectedResponse, string(body))
+			apiKeysService.AssertExpectations(t)
+		})
+	}
+}
+
+type MockAPIKeys struct {
+	mock.Mock
+}
+
+func (a *MockAPIKeys) Authorize(ctx context.Context, key string) (string, time.Duration, error) {
+	args := a.Called(ctx, key)
+	return args.String(0), args.Get(1).(time.Duration), errOrNil(args.Get(2))
+}
+
+func (a *MockAPIKeys) Issue(ctx context.Context, request entity.APIClientRequest) (entity.APIKeyResponse, error) {
+	args := a.Called(ctx, request)
+	return args.Get(0).(entity.APIKeyResponse), errOrNil(args.Get(1))
+}
+
+func (a *MockAPIKeys) Rotate(ctx context.Context, clientID string) (entity.APIKeyResponse, bool, error) {
+	args := a.Called(ctx, clientID)
+	return args.Get(0).(entity.APIKeyResponse), args.Bool(1), errOrNil(args.Get(2))
+}
+
+func (a *MockAPIKeys) Revoke(ctx context.Context, clientID string) (entity.APIKeyResponse, bool, error) {
+	args := a.Called(ctx, clientID)
+	return args.Get(0).(entity.APIKeyResponse), args.Bool(1), errOrNil(args.Get(2))
+}
+
+func (a *MockAPIKeys) Usage(ctx context.Context, clientID string, days int) (entity.APIUsageResponse, bool, error) {
+	args := a.Called(ctx, clientID, days)
+	return args.Get(0).(entity.APIUsageResponse), args.Bool(1), errOrNil(args.Get(2))
+}
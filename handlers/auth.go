@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"bitbucket.org/credomobile/coverage/auth"
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/rs/zerolog/log"
+)
+
+// Authenticate is middleware letting through requests with a valid bearer token. The caller's identity is put into
+// the request context, and onto its logger, for the handlers. Other requests get a 401.
+func Authenticate(verifier auth.Verifier) func(next http.Handler) http.Handler {
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			header := r.Header.Get("Authorization")
+			if len(header) < len("Bearer ") || !strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+				unauthorized(w, "Missing bearer token")
+				return
+			}
+
+			identity, err := verifier.Verify(ctx, strings.TrimSpace(header[len("Bearer "):]))
+			if err != nil {
+				log.Ctx(ctx).Info().Err(err).Msg("Rejected bearer token")
+				unauthorized(w, "Invalid bearer token")
+				return
+			}
+
+			logger := log.Ctx(ctx).With().Str("caller", identity.Subject).Logger()
+			ctx = auth.WithIdentity(logger.WithContext(ctx), identity)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// unauthorized writes a 401 with the message in the error envelope
+func unauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="coverage"`)
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(entity.Response{Errors: []entity.Error{{Message: message, Path: "Authorization", Code: entity.CodeUnauthorized}}})
+}
+
+// RequireScope is middleware letting through requests whose bearer token was granted the scope, it goes after
+// Authenticate. Other requests get a 403.
+func RequireScope(scope string) func(next http.Handler) http.Handler {
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			identity, _ := auth.IdentityFrom(r.Context())
+			if !identity.HasScope(scope) {
+				log.Ctx(r.Context()).Info().Msgf("Rejected caller without scope %s", scope)
+				w.WriteHeader(http.StatusForbidden)
+				json.NewEncoder(w).Encode(entity.Response{Errors: []entity.Error{{Message: "Missing required scope", Path: "scope", Code: entity.CodeForbidden}}})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/auth"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuthenticate(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		authorization    string
+		token            string
+		identity         auth.Identity
+		err              error
+		statusCode       int
+		expectedResponse string
+	}{
+		{
+			desc:             "Valid token",
+			authorization:    "Bearer good-token",
+			token:            "good-token",
+			identity:         auth.Identity{Subject: "postman"},
+			statusCode:       http.StatusOK,
+			expectedResponse: "hello postman",
+		},
+		{
+			desc:             "Lower cased scheme",
+			authorization:    "bearer good-token",
+			token:            "good-token",
+			identity:         auth.Identity{Subject: "postman"},
+			statusCode:       http.StatusOK,
+			expectedResponse: "hello postman",
+		},
+		{
+			desc:             "Invalid token",
+			authorization:    "Bearer bad-token",
+			token:            "bad-token",
+			err:              auth.ErrExpiredToken,
+			statusCode:       http.StatusUnauthorized,
+			expectedResponse: `{"Errors":[{"message":"Invalid bearer token","path":"Authorization","code":"unauthorized"}]}` + "\n",
+		},
+		{
+			desc:             "Missing token",
+			statusCode:       http.StatusUnauthorized,
+			expectedResponse: `{"Errors":[{"message":"Missing bearer token","path":"Authorization","code":"unauthorized"}]}` + "\n",
+		},
+		{
+			desc:             "Other scheme",
+			authorization:    "Basic dXNlcjpwYXNz",
+			statusCode:       http.StatusUnauthorized,
+			expectedResponse: `{"Errors":[{"message":"Missing bearer token","path":"Authorization","code":"unauthorized"}]}` + "\n",
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			verifier := MockVerifier{}
+			if tC.token != "" {
+				verifier.On("Verify", mock.Anything, tC.token).Return(tC.identity, tC.err)
+			}
+
+			r := chi.NewRouter()
+			r.Use(Authenticate(&verifier))
+			r.Get("/v1/carriers", func(w http.ResponseWriter, r *http.Request) {
+				identity, _ := auth.IdentityFrom(r.Context())
+				w.Write([]byte("hello " + identity.Subject))
+			})
+			ts := httptest.NewServer(r)
+			defer ts.Close()
+
+			req, _ := http.NewRequest("GET", ts.URL+"/v1/carriers", nil)
+			if tC.authorization != "" {
+				req.Header.Set("Authorization", tC.authorization)
+			}
+			res, err := ts.Client().Do(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tC.statusCode, res.StatusCode)
+			if tC.statusCode == http.StatusUnauthorized {
+				assert.Equal(t, `Bearer realm="coverage"`, res.Header.Get("WWW-Authenticate"))
+			}
+			body, _ := ioutil.ReadAll(res.Body)
+			assert.Equal(t, tC.expectedResponse, string(body))
+			verifier.AssertExpectations(t)
+		})
+	}
+}
+
+type MockVerifier struct {
+	mock.Mock
+}
+
+func (v *MockVerifier) Verify(ctx context.Context, token string) (auth.Identity, error) {
+	args := v.Called(ctx, token)
+	return args.Get(0).(auth.Identity), errOrNil(args.Get(1))
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/services"
+	"bitbucket.org/credomobile/coverage/validators"
+	"github.com/rs/zerolog/log"
+)
+
+func CheckCoverageBatch(validator validators.BatchCoverageCheckValidator, coverageCheckService services.CoverageCheck) func(w http.ResponseWriter, r *http.Request) {
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		var request entity.BatchCoverageCheckRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			log.Ctx(ctx).Debug().Err(err).Msg("unable to decode batch coverage check request")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(entity.Response{Errors: []entity.Error{{Message: "Malformed request body", Code: entity.CodeInvalidRequest}}})
+			return
+		}
+
+		validationErrors := traceValidation(ctx, func(ctx context.Context) []entity.Error {
+			return validator.Validate(ctx, request)
+		})
+		if len(validationErrors) > 0 {
+			validationFailed(w, r, validationErrors)
+			return
+		}
+
+		// Invalid items are reported in place and only the valid ones are looked up
+		results := make([]entity.BatchCoverageCheckResult, len(request.Items))
+		var validItems []entity.CoverageCheckItem
+		var validIndexes []int
+		for i, item := range request.Items {
+			results[i] = entity.BatchCoverageCheckResult{ZipCode: item.ZipCode, CarrierID: item.CarrierID}
+			if itemErrors := validator.ValidateItem(ctx, item); len(itemErrors) > 0 {
+				countValidationFailures(r, itemErrors)
+				results[i].Errors = invalidRequest(itemErrors)
+				continue
+			}
+			validItems = append(validItems, item)
+			validIndexes = append(validIndexes, i)
+		}
+
+		if len(validItems) > 0 {
+			responses, err := coverageCheckService.VerifyBatch(ctx, validItems, request.Detail)
+			if err != nil {
+				serviceFailed(w, r, err, "Error occurred checking coverage for a batch of %d items", len(validItems))
+				return
+			}
+			for i, response := range responses {
+				response := response
+				results[validIndexes[i]].CoverageCheckResponse = &response
+			}
+		}
+
+		result, err := json.Marshal(entity.Response{Result: entity.BatchCoverageCheckResponse{Results: results}})
+		if err != nil {
+			serviceFailed(w, r, err, "Error occurred encoding the coverage of a batch of %d items", len(request.Items))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(result)
+	}
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/validators"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCoverageCheckBatchHappyPath(t *testing.T) {
+	coverageCheckValidator := validators.NewBatchCoverageCheckValidator()
+	coveragecheckService := MockCoverageCheck{}
+
+	coveragecheckService.On("VerifyBatch", mock.Anything, []entity.CoverageCheckItem{
+		{ZipCode: "94105", CarrierID: "1"},
+		{ZipCode: "94106", CarrierID: "2"},
+	}, false).Return([]entity.CoverageCheckResponse{{IsCovered: true}, {IsCovered: false}}, nil)
+
+	r := chi.NewRouter()
+	r.Post("/v1/coveragecheck/batch", CheckCoverageBatch(coverageCheckValidator, &coveragecheckService))
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	payload := `{"items":[{"zipcode":"94105","carrierid":"1"},{"zipcode":"941","carrierid":"1"},{"zipcode":"94106","carrierid":"2"}]}`
+	req, _ := http.NewRequest("POST", ts.URL+"/v1/coveragecheck/batch", strings.NewReader(payload))
+	res, err := ts.Client().Do(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Contains(t, string(body), `{"Result":{"Results":[`+
+		`{"ZipCode":"94105","CarrierID":"1","IsCovered":true},`+
+		`{"ZipCode":"941","CarrierID":"1","Errors":[{"message":"Illegal value for property","path":"zipcode","code":"invalid_request"}]},`+
+		`{"ZipCode":"94106","CarrierID":"2","IsCovered":false}]}}`)
+	coveragecheckService.AssertExpectations(t)
+}
+
+func TestCoverageCheckBatchSadPathValidationErrors(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		payload          string
+		expectedResponse string
+	}{
+		{
+			desc:             "Malformed body",
+			payload:          `{"items":`,
+			expectedResponse: `{"Errors":[{"message":"Malformed request body","code":"invalid_request"}]}`,
+		},
+		{
+			desc:             "Missing items",
+			payload:          `{}`,
+			expectedResponse: `{"Errors":[{"message":"Missing required property","path":"items","code":"invalid_request"}]}`,
+		},
+		{
+			desc:             "Too many items",
+			payload:          `{"items":[` + strings.Repeat(`{"zipcode":"94105","carrierid":"1"},`, 500) + `{"zipcode":"94105","carrierid":"1"}]}`,
+			expectedResponse: `{"Errors":[{"message":"Too many items","path":"items","code":"invalid_request"}]}`,
+		},
+	}
+
+	for _, tC := range testCases {
+		coverageCheckValidator := validators.NewBatchCoverageCheckValidator()
+		coveragecheckService := MockCoverageCheck{}
+
+		t.Run(tC.desc, func(t *testing.T) {
+
+			r := chi.NewRouter()
+			r.Post("/v1/coveragecheck/batch", CheckCoverageBatch(coverageCheckValidator, &coveragecheckService))
+			ts := httptest.NewServer(r)
+			defer ts.Close()
+
+			req, _ := http.NewRequest("POST", ts.URL+"/v1/coveragecheck/batch", strings.NewReader(tC.payload))
+			res, err := ts.Client().Do(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+			body, _ := ioutil.ReadAll(res.Body)
+			assert.Contains(t, string(body), tC.expectedResponse)
+			coveragecheckService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCoverageCheckBatchSadPathInternalServerError(t *testing.T) {
+	coverageCheckValidator := validators.NewBatchCoverageCheckValidator()
+	coveragecheckService := MockCoverageCheck{}
+
+	coveragecheckService.On("VerifyBatch", mock.Anything, mock.Anything, false).Return([]entity.CoverageCheckResponse(nil), errors.New("Fake error"))
+
+	r := chi.NewRouter()
+	r.Post("/v1/coveragecheck/batch", CheckCoverageBatch(coverageCheckValidator, &coveragecheckService))
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	req, _ := http.NewRequest("POST", ts.URL+"/v1/coveragecheck/batch", strings.NewReader(`{"items":[{"zipcode":"94105","carrierid":"1"}]}`))
+	res, err := ts.Client().Do(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Contains(t, string(body), `{"Errors":[{"message":"There is a problem on the server. Please try again later","code":"internal"}]}`)
+	coveragecheckService.AssertExpectations(t)
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetCacheStats(t *testing.T) {
+	cacheStatsService := MockCacheStats{}
+	cacheStatsService.On("Stats", mock.Anything).Return(entity.CacheStatsResponse{Enabled: true, Hits: 3, Misses: 1, Size: 1})
+
+	r := chi.NewRouter()
+	r.Get("/v1/cache/stats", GetCacheStats(&cacheStatsService))
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	res, err := ts.Client().Get(ts.URL + "/v1/cache/stats")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, `{"Result":{"Enabled":true,"Hits":3,"Misses":1,"Evictions":0,"Size":1}}`, string(body))
+	cacheStatsService.AssertExpectations(t)
+}
+
+type MockCacheStats struct {
+	mock.Mock
+}
+
+func (c *MockCacheStats) Stats(ctx context.Context) entity.CacheStatsResponse {
+	args := c.Called(ctx)
+	return args.Get(0).(entity.CacheStatsResponse)
+}
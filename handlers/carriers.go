@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/services"
+)
+
+func GetCarriers(carriersService services.Carriers) func(w http.ResponseWriter, r *http.Request) {
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		response := carriersService.List(r.Context())
+
+		result, _ := json.Marshal(entity.Response{Result: response})
+		w.WriteHeader(http.StatusOK)
+		w.Write(result)
+	}
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetCarriers(t *testing.T) {
+	carriersService := MockCarriers{}
+	carriersService.On("List", mock.Anything).Return(entity.CarriersResponse{
+		Carriers:    []entity.CarrierMetadata{{CarrierID: "1", Name: "Sprint", Technologies: []string{"LTE"}}},
+		RuleVersion: "default-1",
+	})
+
+	r := chi.NewRouter()
+	r.Get("/v1/carriers", GetCarriers(&carriersService))
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	res, err := ts.Client().Get(ts.URL + "/v1/carriers")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, `{"Result":{"Carriers":[{"CarrierID":"1","Name":"Sprint","Technologies":["LTE"]}],"RuleVersion":"default-1"}}`, string(body))
+	carriersService.AssertExpectations(t)
+}
+
+type MockCarriers struct {
+	mock.Mock
+}
+
+func (c *MockCarriers) List(ctx context.Context) entity.CarriersResponse {
+	args := c.Called(ctx)
+	return args.Get(0).(entity.CarriersResponse)
+}
@@ -3,37 +3,71 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
 	"bitbucket.org/credomobile/coverage/entity"
 	"bitbucket.org/credomobile/coverage/services"
 	"bitbucket.org/credomobile/coverage/validators"
-	"github.com/rs/zerolog/log"
 )
 
-func CheckCoverage(validator validators.CoverageCheckValidator, coverageCheckService services.CoverageCheck) func(w http.ResponseWriter, r *http.Request) {
+// CheckCoverage serves the coverage of a zipcode, or of the zipcode nearest a location given as lat and lon
+func CheckCoverage(validator validators.CoverageCheckValidator, coverageCheckService services.CoverageCheck, locatorService services.Locator) func(w http.ResponseWriter, r *http.Request) {
 
 	return func(w http.ResponseWriter, r *http.Request) {
 		var validationErrors []entity.Error
 
-		validationErrors = validator.Validate(r.Context(), r)
+		validationErrors = validateRequest(r, validator.Validate)
 		if len(validationErrors) > 0 {
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(entity.Response{Errors: validationErrors})
+			validationFailed(w, r, validationErrors)
 			return
 		}
 
 		ctx := r.Context()
 		zipCode := r.URL.Query().Get("zipcode")
 		carrierID := r.URL.Query().Get("carrierid")
-		response, err := coverageCheckService.Verify(ctx, zipCode, carrierID)
+		detail := r.URL.Query().Get("detail") == "true"
+
+		var location *entity.ResolvedLocation
+		if zipCode == "" {
+			lat, _ := strconv.ParseFloat(r.URL.Query().Get("lat"), 64)
+			lon, _ := strconv.ParseFloat(r.URL.Query().Get("lon"), 64)
+			resolved, found, err := locatorService.Nearest(ctx, lat, lon)
+			if err != nil {
+				serviceFailed(w, r, err, "Error occurred resolving the zipcode nearest lat: %f and lon: %f", lat, lon)
+				return
+			}
+			if !found {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(entity.Response{Errors: []entity.Error{{Message: "No zipcode found near the location", Path: "lat", Code: entity.CodeNotFound}}})
+				return
+			}
+			location = &resolved
+			zipCode = resolved.ZipCode
+		}
+
+		var response interface{}
+		var err error
+		if entity.CarrierType(carrierID) == entity.AllCarriers {
+			var allResponse entity.MultiCarrierCoverageResponse
+			allResponse, err = coverageCheckService.VerifyAllCarriers(ctx, zipCode, detail)
+			allResponse.Location = location
+			response = allResponse
+		} else {
+			var carrierResponse entity.CoverageCheckResponse
+			carrierResponse, err = coverageCheckService.Verify(ctx, zipCode, carrierID, detail)
+			carrierResponse.Location = location
+			response = carrierResponse
+		}
 		if err != nil {
-			log.Ctx(ctx).Error().Err(err).Msgf("Error occurred checking coverage for zipcode: %s and carrierID: %s", zipCode, carrierID)
-			w.WriteHeader(http.StatusInternalServerError)
-			json.NewEncoder(w).Encode(entity.Error{Message: "There is a problem on the server. Please try again later"})
+			serviceFailed(w, r, err, "Error occurred checking coverage for zipcode: %s and carrierID: %s", zipCode, carrierID)
 			return
 		}
 
-		result, _ := json.Marshal(entity.Response{Result: response})
+		result, err := json.Marshal(entity.Response{Result: response})
+		if err != nil {
+			serviceFailed(w, r, err, "Error occurred encoding the coverage of zipcode: %s and carrierID: %s", zipCode, carrierID)
+			return
+		}
 		w.WriteHeader(http.StatusOK)
 		w.Write(result)
 	}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -51,15 +52,15 @@ func TestCoverageCheckHappyPath(t *testing.T) {
 		coveragecheckService := MockCoverageCheck{}
 
 		if tC.respondCovered {
-			coveragecheckService.On("Verify", mock.Anything, tC.zipCode, tC.carrierID).Return(entity.CoverageCheckResponse{IsCovered: true}, nil)
+			coveragecheckService.On("Verify", mock.Anything, tC.zipCode, tC.carrierID, false).Return(entity.CoverageCheckResponse{IsCovered: true}, nil)
 		} else {
-			coveragecheckService.On("Verify", mock.Anything, tC.zipCode, tC.carrierID).Return(entity.CoverageCheckResponse{IsCovered: false}, nil)
+			coveragecheckService.On("Verify", mock.Anything, tC.zipCode, tC.carrierID, false).Return(entity.CoverageCheckResponse{IsCovered: false}, nil)
 		}
 
 		t.Run(tC.desc, func(t *testing.T) {
 
 			r := chi.NewRouter()
-			r.Get("/v1/coveragecheck", CheckCoverage(coverageCheckValidator, &coveragecheckService))
+			r.Get("/v1/coveragecheck", CheckCoverage(coverageCheckValidator, &coveragecheckService, &MockLocator{}))
 			ts := httptest.NewServer(r)
 			defer ts.Close()
 
@@ -80,6 +81,94 @@ func TestCoverageCheckHappyPath(t *testing.T) {
 	}
 }
 
+func TestCoverageCheckAllCarriers(t *testing.T) {
+	coverageCheckValidator := validators.NewCoverageCheckValidator()
+	coveragecheckService := MockCoverageCheck{}
+
+	coveragecheckService.On("VerifyAllCarriers", mock.Anything, "94105", false).Return(entity.MultiCarrierCoverageResponse{
+		Carriers: []entity.CarrierCoverageResult{
+			{CarrierID: "1", Errors: []entity.Error{{Message: "Unable to check coverage for carrier", Path: "carrierid"}}},
+			{CarrierID: "2", CoverageCheckResponse: &entity.CoverageCheckResponse{IsCovered: true}},
+		},
+		BestCarrierID: "2",
+	}, nil)
+
+	r := chi.NewRouter()
+	r.Get("/v1/coveragecheck", CheckCoverage(coverageCheckValidator, &coveragecheckService, &MockLocator{}))
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("%s/v1/coveragecheck?zipcode=94105&carrierid=all", ts.URL), nil)
+	res, err := ts.Client().Do(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Contains(t, string(body), `{"Result":{"Carriers":[`+
+		`{"CarrierID":"1","Errors":[{"message":"Unable to check coverage for carrier","path":"carrierid"}]},`+
+		`{"CarrierID":"2","IsCovered":true}],"BestCarrierID":"2"}}`)
+	coveragecheckService.AssertExpectations(t)
+}
+
+func TestCoverageCheckWithDetail(t *testing.T) {
+	coverageCheckValidator := validators.NewCoverageCheckValidator()
+	coveragecheckService := MockCoverageCheck{}
+
+	lte := 78.0
+	evdo := 92.0
+	coveragecheckService.On("Verify", mock.Anything, "94105", "2", true).Return(entity.CoverageCheckResponse{
+		IsCovered: true,
+		Detail: &entity.CoverageDetail{
+			Score: 81.2,
+			Technologies: []entity.TechnologyCoverage{
+				{Technology: "LTE", Percentage: &lte, Indicator: "Y"},
+				{Technology: "EVDO", Percentage: &evdo, Indicator: "Y"},
+				{Technology: "Voice/1X"},
+			},
+		},
+	}, nil)
+
+	r := chi.NewRouter()
+	r.Get("/v1/coveragecheck", CheckCoverage(coverageCheckValidator, &coveragecheckService, &MockLocator{}))
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("%s/v1/coveragecheck?zipcode=94105&carrierid=2&detail=true", ts.URL), nil)
+	res, err := ts.Client().Do(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Contains(t, string(body), `{"Result":{"IsCovered":true,"Detail":{"Score":81.2,"Technologies":[`+
+		`{"Technology":"LTE","Percentage":78,"Indicator":"Y"},`+
+		`{"Technology":"EVDO","Percentage":92,"Indicator":"Y"},`+
+		`{"Technology":"Voice/1X"}]}}}`)
+	coveragecheckService.AssertExpectations(t)
+}
+
+func TestCoverageCheckUnencodableDetail(t *testing.T) {
+	coveragecheckService := MockCoverageCheck{}
+	coveragecheckService.On("Verify", mock.Anything, "94105", "2", true).Return(entity.CoverageCheckResponse{
+		IsCovered: true,
+		Detail:    &entity.CoverageDetail{Score: math.NaN()},
+	}, nil)
+
+	r := chi.NewRouter()
+	r.Get("/v1/coveragecheck", CheckCoverage(validators.NewCoverageCheckValidator(), &coveragecheckService, &MockLocator{}))
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	res, err := ts.Client().Get(fmt.Sprintf("%s/v1/coveragecheck?zipcode=94105&carrierid=2&detail=true", ts.URL))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, `{"Errors":[{"message":"There is a problem on the server. Please try again later","code":"internal"}]}`+"\n", string(body))
+	coveragecheckService.AssertExpectations(t)
+}
+
 func TestCoverageCheckSadPathValidationErrors(t *testing.T) {
 	testCases := []struct {
 		desc             string
@@ -92,56 +181,63 @@ func TestCoverageCheckSadPathValidationErrors(t *testing.T) {
 			desc:             "Missing zipcode and carriedID",
 			zipCode:          "",
 			carrierID:        "",
-			expectedResponse: `{"Errors":[{"message":"Missing required property","path":"zipcode"},{"message":"Missing required property","path":"carrierid"}]}`,
+			expectedResponse: `{"Errors":[{"message":"Missing required property","path":"zipcode","code":"invalid_request"},{"message":"Missing required property","path":"carrierid","code":"invalid_request"}]}`,
 			statusCode:       http.StatusBadRequest,
 		},
 		{
 			desc:             "Missing zipcode and a valid carriedID",
 			zipCode:          "",
 			carrierID:        "1",
-			expectedResponse: `{"Errors":[{"message":"Missing required property","path":"zipcode"}]}`,
+			expectedResponse: `{"Errors":[{"message":"Missing required property","path":"zipcode","code":"invalid_request"}]}`,
 			statusCode:       http.StatusBadRequest,
 		},
 		{
 			desc:             "Invalid zipcode with exceeded length and a valid carriedID",
 			zipCode:          "941055",
 			carrierID:        "1",
-			expectedResponse: `{"Errors":[{"message":"Illegal value for property","path":"zipcode"}]}`,
+			expectedResponse: `{"Errors":[{"message":"Illegal value for property","path":"zipcode","code":"invalid_request"}]}`,
 			statusCode:       http.StatusBadRequest,
 		},
 		{
 			desc:             "Invalid zipcode with shortened length and a valid carriedID",
 			zipCode:          "9410",
 			carrierID:        "1",
-			expectedResponse: `{"Errors":[{"message":"Illegal value for property","path":"zipcode"}]}`,
+			expectedResponse: `{"Errors":[{"message":"Illegal value for property","path":"zipcode","code":"invalid_request"}]}`,
 			statusCode:       http.StatusBadRequest,
 		},
 		{
 			desc:             "Invalid zipcode and an invalid carriedID",
 			zipCode:          "abc",
-			carrierID:        "3",
-			expectedResponse: `{"Errors":[{"message":"Illegal value for property","path":"zipcode"},{"message":"Illegal value for property","path":"carrierid"}]}`,
+			carrierID:        "9",
+			expectedResponse: `{"Errors":[{"message":"Illegal value for property","path":"zipcode","code":"invalid_request"},{"message":"Illegal value for property","path":"carrierid","code":"invalid_request"}]}`,
 			statusCode:       http.StatusBadRequest,
 		},
 		{
 			desc:             "Invalid zipcode and an invalid with non number carriedID",
 			zipCode:          "abc",
 			carrierID:        "a",
-			expectedResponse: `{"Errors":[{"message":"Illegal value for property","path":"zipcode"},{"message":"Illegal value for property","path":"carrierid"}]}`,
+			expectedResponse: `{"Errors":[{"message":"Illegal value for property","path":"zipcode","code":"invalid_request"},{"message":"Illegal value for property","path":"carrierid","code":"invalid_request"}]}`,
 			statusCode:       http.StatusBadRequest,
 		},
 		{
 			desc:             "Valid zipcode and an invalid carriedID",
 			zipCode:          "94105",
-			carrierID:        "3",
-			expectedResponse: `{"Errors":[{"message":"Illegal value for property","path":"carrierid"}]}`,
+			carrierID:        "9",
+			expectedResponse: `{"Errors":[{"message":"Illegal value for property","path":"carrierid","code":"invalid_request"}]}`,
+			statusCode:       http.StatusBadRequest,
+		},
+		{
+			desc:             "Valid zipcode and carriedID with an invalid detail flag",
+			zipCode:          "94105",
+			carrierID:        "1&detail=yes",
+			expectedResponse: `{"Errors":[{"message":"Illegal value for property","path":"detail","code":"invalid_request"}]}`,
 			statusCode:       http.StatusBadRequest,
 		},
 		{
 			desc:             "Valid zipcode and an missing carriedID",
 			zipCode:          "94105",
 			carrierID:        "",
-			expectedResponse: `{"Errors":[{"message":"Missing required property","path":"carrierid"}]}`,
+			expectedResponse: `{"Errors":[{"message":"Missing required property","path":"carrierid","code":"invalid_request"}]}`,
 			statusCode:       http.StatusBadRequest,
 		},
 	}
@@ -153,7 +249,7 @@ func TestCoverageCheckSadPathValidationErrors(t *testing.T) {
 		t.Run(tC.desc, func(t *testing.T) {
 
 			r := chi.NewRouter()
-			r.Get("/v1/coveragecheck", CheckCoverage(coverageCheckValidator, &coveragecheckService))
+			r.Get("/v1/coveragecheck", CheckCoverage(coverageCheckValidator, &coveragecheckService, &MockLocator{}))
 			ts := httptest.NewServer(r)
 			defer ts.Close()
 
@@ -176,10 +272,10 @@ func TestCoverageCheckSadPathInternalServerError(t *testing.T) {
 	zipCode := "94105"
 	carrierID := "1"
 
-	coveragecheckService.On("Verify", mock.Anything, zipCode, carrierID).Return(entity.CoverageCheckResponse{}, errors.New("Fake error"))
+	coveragecheckService.On("Verify", mock.Anything, zipCode, carrierID, false).Return(entity.CoverageCheckResponse{}, errors.New("Fake error"))
 
 	r := chi.NewRouter()
-	r.Get("/v1/coveragecheck", CheckCoverage(coverageCheckValidator, &coveragecheckService))
+	r.Get("/v1/coveragecheck", CheckCoverage(coverageCheckValidator, &coveragecheckService, &MockLocator{}))
 	ts := httptest.NewServer(r)
 	defer ts.Close()
 
@@ -190,7 +286,7 @@ func TestCoverageCheckSadPathInternalServerError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
 
 	body, _ := ioutil.ReadAll(res.Body)
-	assert.Contains(t, string(body), `{"message":"There is a problem on the server. Please try again later"}`)
+	assert.Contains(t, string(body), `{"Errors":[{"message":"There is a problem on the server. Please try again later","code":"internal"}]}`)
 	coveragecheckService.AssertExpectations(t)
 }
 
@@ -198,14 +294,98 @@ type MockCoverageCheck struct {
 	mock.Mock
 }
 
-func (c *MockCoverageCheck) Verify(ctx context.Context, zipCode string, carrierID string) (entity.CoverageCheckResponse, error) {
-	args := c.Called(ctx, zipCode, carrierID)
+func (c *MockCoverageCheck) Verify(ctx context.Context, zipCode string, carrierID string, detail bool) (entity.CoverageCheckResponse, error) {
+	args := c.Called(ctx, zipCode, carrierID, detail)
 	return args.Get(0).(entity.CoverageCheckResponse), errOrNil(args.Get(1))
 }
 
+func (c *MockCoverageCheck) VerifyBatch(ctx context.Context, items []entity.CoverageCheckItem, detail bool) ([]entity.CoverageCheckResponse, error) {
+	args := c.Called(ctx, items, detail)
+	return args.Get(0).([]entity.CoverageCheckResponse), errOrNil(args.Get(1))
+}
+
+func (c *MockCoverageCheck) VerifyAllCarriers(ctx context.Context, zipCode string, detail bool) (entity.MultiCarrierCoverageResponse, error) {
+	args := c.Called(ctx, zipCode, detail)
+	return args.Get(0).(entity.MultiCarrierCoverageResponse), errOrNil(args.Get(1))
+}
+
 func errOrNil(o interface{}) error {
 	if o == nil {
 		return nil
 	}
 	return o.(error)
 }
+
+func TestCoverageCheckWithLocation(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		query            string
+		location         entity.ResolvedLocation
+		found            bool
+		locatorErr       error
+		statusCode       int
+		expectedResponse string
+	}{
+		{
+			desc:             "Resolves the nearest zipcode for a carrier",
+			query:            "lat=37.7879&lon=-122.4075&carrierid=1",
+			location:         entity.ResolvedLocation{ZipCode: "94105", DistanceKm: 1.23},
+			found:            true,
+			statusCode:       http.StatusOK,
+			expectedResponse: `{"Result":{"IsCovered":true,"Location":{"ZipCode":"94105","DistanceKm":1.23}}}`,
+		},
+		{
+			desc:             "Resolves the nearest zipcode for all carriers",
+			query:            "lat=37.7879&lon=-122.4075&carrierid=all",
+			location:         entity.ResolvedLocation{ZipCode: "94105", DistanceKm: 1.23},
+			found:            true,
+			statusCode:       http.StatusOK,
+			expectedResponse: `{"Result":{"Carriers":null,"BestCarrierID":"1","Location":{"ZipCode":"94105","DistanceKm":1.23}}}`,
+		},
+		{
+			desc:             "No zipcode near the location",
+			query:            "lat=37.7879&lon=-122.4075&carrierid=1",
+			statusCode:       http.StatusNotFound,
+			expectedResponse: `{"Errors":[{"message":"No zipcode found near the location","path":"lat","code":"not_found"}]}` + "\n",
+		},
+		{
+			desc:             "Locator error",
+			query:            "lat=37.7879&lon=-122.4075&carrierid=1",
+			locatorErr:       errors.New("Fake error"),
+			statusCode:       http.StatusInternalServerError,
+			expectedResponse: `{"Errors":[{"message":"There is a problem on the server. Please try again later","code":"internal"}]}` + "\n",
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			locatorService := MockLocator{}
+			locatorService.On("Nearest", mock.Anything, 37.7879, -122.4075).Return(tC.location, tC.found, tC.locatorErr)
+			coveragecheckService := MockCoverageCheck{}
+			coveragecheckService.On("Verify", mock.Anything, "94105", "1", false).Return(entity.CoverageCheckResponse{IsCovered: true}, nil)
+			coveragecheckService.On("VerifyAllCarriers", mock.Anything, "94105", false).Return(entity.MultiCarrierCoverageResponse{BestCarrierID: "1"}, nil)
+
+			r := chi.NewRouter()
+			r.Get("/v1/coveragecheck", CheckCoverage(validators.NewCoverageCheckValidator(), &coveragecheckService, &locatorService))
+			ts := httptest.NewServer(r)
+			defer ts.Close()
+
+			res, err := ts.Client().Get(ts.URL + "/v1/coveragecheck?" + tC.query)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tC.statusCode, res.StatusCode)
+			body, _ := ioutil.ReadAll(res.Body)
+			assert.Equal(t, tC.expectedResponse, string(body))
+			locatorService.AssertExpectations(t)
+		})
+	}
+}
+
+type MockLocator struct {
+	mock.Mock
+}
+
+func (l *MockLocator) Nearest(ctx context.Context, lat float64, lon float64) (entity.ResolvedLocation, bool, error) {
+	args := l.Called(ctx, lat, lon)
+	return args.Get(0).(entity.ResolvedLocation), args.Bool(1), errOrNil(args.Get(2))
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/services"
+	"bitbucket.org/credomobile/coverage/validators"
+)
+
+// GetCoverageDiff serves the zipcodes whose coverage flipped or swung between two dataset versions of the carrierid,
+// as JSON or, with format=csv, as CSV
+func GetCoverageDiff(validator validators.CoverageDiffValidator, coverageDiffService services.CoverageDiff) func(w http.ResponseWriter, r *http.Request) {
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var validationErrors []entity.Error
+
+		validationErrors = validateRequest(r, validator.Validate)
+		if len(validationErrors) > 0 {
+			validationFailed(w, r, validationErrors)
+			return
+		}
+
+		ctx := r.Context()
+		carrierID := r.URL.Query().Get("carrierid")
+		from := r.URL.Query().Get("from")
+		to := r.URL.Query().Get("to")
+		swing := dbclient.DefaultSwingThreshold
+		if value := r.URL.Query().Get("swing"); value != "" {
+			swing, _ = strconv.ParseFloat(value, 64)
+		}
+
+		response, found, err := coverageDiffService.Diff(ctx, carrierID, from, to, swing)
+		if err != nil {
+			serviceFailed(w, r, err, "Error occurred comparing dataset versions from: %s and to: %s for carrierID: %s", from, to, carrierID)
+			return
+		}
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(entity.Response{Errors: []entity.Error{{Message: "No dataset version found", Path: "version", Code: entity.CodeNotFound}}})
+			return
+		}
+
+		if r.URL.Query().Get("format") == "csv" {
+			w.Header().Set("Content-Type", "text/csv")
+			w.WriteHeader(http.StatusOK)
+			services.WriteCoverageDiffCSV(w, response)
+			return
+		}
+
+		result, _ := json.Marshal(entity.Response{Result: response})
+		w.WriteHeader(http.StatusOK)
+		w.Write(result)
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/validators"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetCoverageDiff(t *testing.T) {
+	diff := entity.CoverageDiffResponse{
+		CarrierID:      "2",
+		Name:           "Verizon",
+		From:           "2018-11-01",
+		To:             "2018-12-01",
+		RuleVersion:    "default-1",
+		SwingThreshold: 20,
+		ZipCodes:       1,
+		Gained:         []entity.CoverageChange{},
+		Lost:           []entity.CoverageChange{{ZipCode: "94105", FromScore: 100, ToScore: 30}},
+		Swings:         []entity.CoverageSwing{},
+		Added:          []string{},
+		Removed:        []string{},
+	}
+	testCases := []struct {
+		desc             string
+		query            string
+		swing            float64
+		response         entity.CoverageDiffResponse
+		found            bool
+		err              error
+		statusCode       int
+		contentType      string
+		expectedResponse string
+	}{
+		{
+			desc:       "Diff as json",
+			query:      "carrierid=2&from=2018-11-01&to=2018-12-01",
+			swing:      20,
+			response:   diff,
+			found:      true,
+			statusCode: http.StatusOK,
+			expectedResponse: `{"Result":{"CarrierID":"2","Name":"Verizon","From":"2018-11-01","To":"2018-12-01","RuleVersion":"default-1",` +
+				`"SwingThreshold":20,"ZipCodes":1,"Gained":[],"Lost":[{"ZipCode":"94105","FromScore":100,"ToScore":30}],` +
+				`"Swings":[],"Added":[],"Removed":[]}}`,
+		},
+		{
+			desc:             "Diff as csv",
+			query:            "carrierid=2&from=2018-11-01&to=2018-12-01&swing=10&format=csv",
+			swing:            10,
+			response:         diff,
+			found:            true,
+			statusCode:       http.StatusOK,
+			contentType:      "text/csv",
+			expectedResponse: "zipcode,change,technology,from,to\n94105,lost,,100,30\n",
+		},
+		{
+			desc:             "Unknown version",
+			query:            "carrierid=2&from=2018-11-01&to=2018-12-01",
+			swing:            20,
+			statusCode:       http.StatusNotFound,
+			expectedResponse: `{"Errors":[{"message":"No dataset version found","path":"version","code":"not_found"}]}` + "\n",
+		},
+		{
+			desc:             "Db error",
+			query:            "carrierid=2&from=2018-11-01&to=2018-12-01",
+			swing:            20,
+			err:              errors.New("Fake error"),
+			statusCode:       http.StatusInternalServerError,
+			expectedResponse: `{"Errors":[{"message":"There is a problem on the server. Please try again later","code":"internal"}]}` + "\n",
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			coverageDiffService := MockCoverageDiff{}
+			coverageDiffService.On("Diff", mock.Anything, "2", "2018-11-01", "2018-12-01", tC.swing).Return(tC.response, tC.found, tC.err)
+
+			r := chi.NewRouter()
+			r.Get("/v1/datasets/diff", GetCoverageDiff(validators.NewCoverageDiffValidator(), &coverageDiffService))
+			ts := httptest.NewServer(r)
+			defer ts.Close()
+
+			res, err := ts.Client().Get(ts.URL + "/v1/datasets/diff?" + tC.query)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tC.statusCode, res.StatusCode)
+			if tC.contentType != "" {
+				assert.Equal(t, tC.contentType, res.Header.Get("Content-Type"))
+			}
+			body, _ := ioutil.ReadAll(res.Body)
+			assert.Equal(t, tC.expectedResponse, string(body))
+			coverageDiffService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetCoverageDiffWithValidationErrors(t *testing.T) {
+	coverageDiffService := MockCoverageDiff{}
+
+	r := chi.NewRouter()
+	r.Get("/v1/datasets/diff", GetCoverageDiff(validators.NewCoverageDiffValidator(), &coverageDiffService))
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	res, err := ts.Client().Get(ts.URL + "/v1/datasets/diff?carrierid=2")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, `{"Errors":[{"message":"Missing required property","path":"to","code":"invalid_request"}]}`+"\n", string(body))
+	coverageDiffService.AssertNotCalled(t, "Diff")
+}
+
+type MockCoverageDiff struct {
+	mock.Mock
+}
+
+func (c *MockCoverageDiff) Diff(ctx context.Context, carrierID string, from string, to string, swingThreshold float64) (entity.CoverageDiffResponse, bool, error) {
+	args := c.Called(ctx, carrierID, from, to, swingThreshold)
+	return args.Get(0).(entity.CoverageDiffResponse), args.Bool(1), errOrNil(args.Get(2))
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/services"
+	"bitbucket.org/credomobile/coverage/validators"
+)
+
+// GetCoverageSummary serves the covered and uncovered zipcode counts and average coverage of a state, or of a county
+// of the state, for the carrierid
+func GetCoverageSummary(validator validators.CoverageSummaryValidator, coverageSummaryService services.CoverageSummary) func(w http.ResponseWriter, r *http.Request) {
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var validationErrors []entity.Error
+
+		validationErrors = validateRequest(r, validator.Validate)
+		if len(validationErrors) > 0 {
+			validationFailed(w, r, validationErrors)
+			return
+		}
+
+		ctx := r.Context()
+		state := strings.ToUpper(r.URL.Query().Get("state"))
+		county := r.URL.Query().Get("county")
+		carrierID := r.URL.Query().Get("carrierid")
+		response, err := coverageSummaryService.GetSummary(ctx, state, county, carrierID)
+		if err != nil {
+			serviceFailed(w, r, err, "Error occurred getting coverage summary for state: %s, county: %s and carrierID: %s", state, county, carrierID)
+			return
+		}
+
+		result, _ := json.Marshal(entity.Response{Result: response})
+		w.WriteHeader(http.StatusOK)
+		w.Write(result)
+	}
+}
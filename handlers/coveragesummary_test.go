@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/validators"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetCoverageSummary(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		query            string
+		response         entity.CoverageSummaryResponse
+		err              error
+		statusCode       int
+		expectedResponse string
+	}{
+		{
+			desc:  "County summary",
+			query: "state=ca&county=Alameda&carrierid=2",
+			response: entity.CoverageSummaryResponse{State: "CA", County: "Alameda", Carriers: []entity.CarrierCoverageSummary{
+				{CarrierID: "2", Name: "Verizon", Found: true, ZipCodes: 10, Covered: 8, Uncovered: 2, AverageScore: 70.5, LoadDate: "2018-11-01", RuleVersion: "default-1"},
+			}},
+			statusCode: http.StatusOK,
+			expectedResponse: `{"Result":{"State":"CA","County":"Alameda","Carriers":[{"CarrierID":"2","Name":"Verizon","Found":true,` +
+				`"ZipCodes":10,"Covered":8,"Uncovered":2,"AverageScore":70.5,"LoadDate":"2018-11-01","RuleVersion":"default-1"}]}}`,
+		},
+		{
+			desc:             "Db error",
+			query:            "state=CA&county=Alameda&carrierid=2",
+			err:              errors.New("Fake error"),
+			statusCode:       http.StatusInternalServerError,
+			expectedResponse: `{"Errors":[{"message":"There is a problem on the server. Please try again later","code":"internal"}]}` + "\n",
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			coverageSummaryService := MockCoverageSummary{}
+			coverageSummaryService.On("GetSummary", mock.Anything, "CA", "Alameda", "2").Return(tC.response, tC.err)
+
+			r := chi.NewRouter()
+			r.Get("/v1/coverage/summary", GetCoverageSummary(validators.NewCoverageSummaryValidator(), &coverageSummaryService))
+			ts := httptest.NewServer(r)
+			defer ts.Close()
+
+			res, err := ts.Client().Get(ts.URL + "/v1/coverage/summary?" + tC.query)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tC.statusCode, res.StatusCode)
+			body, _ := ioutil.ReadAll(res.Body)
+			assert.Equal(t, tC.expectedResponse, string(body))
+			coverageSummaryService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetCoverageSummaryWithValidationErrors(t *testing.T) {
+	coverageSummaryService := MockCoverageSummary{}
+
+	r := chi.NewRouter()
+	r.Get("/v1/coverage/summary", GetCoverageSummary(validators.NewCoverageSummaryValidator(), &coverageSummaryService))
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	res, err := ts.Client().Get(ts.URL + "/v1/coverage/summary?carrierid=1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, `{"Errors":[{"message":"Missing required property","path":"state","code":"invalid_request"}]}`+"\n", string(body))
+	coverageSummaryService.AssertNotCalled(t, "GetSummary")
+}
+
+type MockCoverageSummary struct {
+	mock.Mock
+}
+
+func (c *MockCoverageSummary) GetSummary(ctx context.Context, state string, county string, carrierID string) (entity.CoverageSummaryResponse, error) {
+	args := c.Called(ctx, state, county, carrierID)
+	return args.Get(0).(entity.CoverageSummaryResponse), errOrNil(args.Get(1))
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/services"
+	"bitbucket.org/credomobile/coverage/validators"
+	"github.com/go-chi/chi"
+)
+
+// GetCsaZipCodes serves a page of the zipcodes of the CSA in the path. The token query parameter is the NextToken
+// of the previous page.
+func GetCsaZipCodes(validator validators.CsaZipCodesValidator, csaZipCodesService services.CsaZipCodes) func(w http.ResponseWriter, r *http.Request) {
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var validationErrors []entity.Error
+
+		validationErrors = validateRequest(r, validator.Validate)
+		if len(validationErrors) > 0 {
+			validationFailed(w, r, validationErrors)
+			return
+		}
+
+		ctx := r.Context()
+		csa := chi.URLParam(r, "csa")
+		limit := validators.DefaultCsaZipCodesLimit
+		if value := r.URL.Query().Get("limit"); value != "" {
+			limit, _ = strconv.Atoi(value)
+		}
+		response, err := csaZipCodesService.List(ctx, csa, limit, r.URL.Query().Get("token"))
+		if err == dbclient.ErrInvalidToken {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(entity.Response{Errors: []entity.Error{{Message: "Illegal value for property", Path: "token", Code: entity.CodeInvalidRequest}}})
+			return
+		}
+		if err != nil {
+			serviceFailed(w, r, err, "Error occurred listing zipcodes of csa: %s", csa)
+			return
+		}
+
+		result, _ := json.Marshal(entity.Response{Result: response})
+		w.WriteHeader(http.StatusOK)
+		w.Write(result)
+	}
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/validators"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetCsaZipCodes(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		path             string
+		limit            int
+		token            string
+		response         entity.CsaZipCodesResponse
+		err              error
+		statusCode       int
+		expectedResponse string
+	}{
+		{
+			desc:             "First page with the default limit",
+			path:             "/v1/csa/PHXTUC520/zipcodes",
+			limit:            validators.DefaultCsaZipCodesLimit,
+			response:         entity.CsaZipCodesResponse{Csa: "PHXTUC520", ZipCodes: []entity.CsaZipCode{{ZipCode: "85001", State: "AZ", City: "Phoenix", Score: 60}}, NextToken: "fakeNextToken"},
+			statusCode:       http.StatusOK,
+			expectedResponse: `{"Result":{"Csa":"PHXTUC520","ZipCodes":[{"ZipCode":"85001","State":"AZ","City":"Phoenix","Score":60,"Technologies":null}],"NextToken":"fakeNextToken"}}`,
+		},
+		{
+			desc:             "Last page",
+			path:             "/v1/csa/PHXTUC520/zipcodes?limit=10&token=fakeToken",
+			limit:            10,
+			token:            "fakeToken",
+			response:         entity.CsaZipCodesResponse{Csa: "PHXTUC520", ZipCodes: []entity.CsaZipCode{}},
+			statusCode:       http.StatusOK,
+			expectedResponse: `{"Result":{"Csa":"PHXTUC520","ZipCodes":[]}}`,
+		},
+		{
+			desc:             "Invalid token",
+			path:             "/v1/csa/PHXTUC520/zipcodes?token=fakeToken",
+			limit:            validators.DefaultCsaZipCodesLimit,
+			token:            "fakeToken",
+			err:              dbclient.ErrInvalidToken,
+			statusCode:       http.StatusBadRequest,
+			expectedResponse: `{"Errors":[{"message":"Illegal value for property","path":"token","code":"invalid_request"}]}` + "\n",
+		},
+		{
+			desc:             "Db error",
+			path:             "/v1/csa/PHXTUC520/zipcodes",
+			limit:            validators.DefaultCsaZipCodesLimit,
+			err:              errors.New("Fake error"),
+			statusCode:       http.StatusInternalServerError,
+			expectedResponse: `{"Errors":[{"message":"There is a problem on the server. Please try again later","code":"internal"}]}` + "\n",
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			csaZipCodesService := MockCsaZipCodes{}
+			csaZipCodesService.On("List", mock.Anything, "PHXTUC520", tC.limit, tC.token).Return(tC.response, tC.err)
+
+			r := chi.NewRouter()
+			r.Get("/v1/csa/{csa}/zipcodes", GetCsaZipCodes(validators.NewCsaZipCodesValidator(), &csaZipCodesService))
+			ts := httptest.NewServer(r)
+			defer ts.Close()
+
+			res, err := ts.Client().Get(ts.URL + tC.path)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tC.statusCode, res.StatusCode)
+			body, _ := ioutil.ReadAll(res.Body)
+			assert.Equal(t, tC.expectedResponse, string(body))
+			csaZipCodesService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetCsaZipCodesSadPathValidationErrors(t *testing.T) {
+	csaZipCodesService := MockCsaZipCodes{}
+
+	r := chi.NewRouter()
+	r.Get("/v1/csa/{csa}/zipcodes", GetCsaZipCodes(validators.NewCsaZipCodesValidator(), &csaZipCodesService))
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	res, err := ts.Client().Get(ts.URL + "/v1/csa/PHXTUC520/zipcodes?limit=0")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, `{"Errors":[{"message":"Illegal value for property","path":"limit","code":"invalid_request"}]}`+"\n", string(body))
+	csaZipCodesService.AssertNotCalled(t, "List", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+type MockCsaZipCodes struct {
+	mock.Mock
+}
+
+func (c *MockCsaZipCodes) List(ctx context.Context, csa string, limit int, token string) (entity.CsaZipCodesResponse, error) {
+	args := c.Called(ctx, csa, limit, token)
+	return args.Get(0).(entity.CsaZipCodesResponse), errOrNil(args.Get(1))
+}
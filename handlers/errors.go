@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/services"
+	"github.com/rs/zerolog/log"
+)
+
+// statusClientClosedRequest is the non-standard status of a request the caller canceled before it was answered
+const statusClientClosedRequest = 499
+
+// failures are the status and message of the error codes of failed service calls
+var failures = map[string]struct {
+	status  int
+	message string
+}{
+	entity.CodeNotFound:    {http.StatusNotFound, "The requested resource was not found"},
+	entity.CodeThrottled:   {http.StatusTooManyRequests, "Too many requests. Please try again later"},
+	entity.CodeUnavailable: {http.StatusServiceUnavailable, "The service is temporarily unavailable. Please try again later"},
+	entity.CodeCanceled:    {statusClientClosedRequest, "The request was canceled"},
+	entity.CodeBadData:     {http.StatusInternalServerError, "There is a problem on the server. Please try again later"},
+	entity.CodeInternal:    {http.StatusInternalServerError, "There is a problem on the server. Please try again later"},
+}
+
+// serviceFailed logs the failure of a service call and writes its status and error code in the error envelope:
+// a 404 for something not found, a 429 for a throttled table, a 503 for an unavailable one, a 499 for a request the
+// caller canceled and a 500 otherwise. Throttled and unavailable failures come with a Retry-After. Neither a 404 nor a
+// 499 is a failure of the service, they are logged at info.
+func serviceFailed(w http.ResponseWriter, r *http.Request, err error, format string, args ...interface{}) {
+	code := services.ErrorCode(err)
+	failure := failures[code]
+	if failure.status == http.StatusNotFound || failure.status == statusClientClosedRequest {
+		log.Ctx(r.Context()).Info().Err(err).Msgf(format, args...)
+	} else {
+		log.Ctx(r.Context()).Error().Err(err).Str("code", code).Msgf(format, args...)
+	}
+
+	if retryAfter := dbclient.RetryAfter(err); retryAfter > 0 {
+		setRetryAfter(w, retryAfter)
+	}
+	w.WriteHeader(failure.status)
+	json.NewEncoder(w).Encode(entity.Response{Errors: []entity.Error{{Message: failure.message, Code: code}}})
+}
+
+// setRetryAfter sets the Retry-After header in whole seconds, at least one
+func setRetryAfter(w http.ResponseWriter, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Max(1, math.Ceil(retryAfter.Seconds())))))
+}
+
+// invalidRequest gives back the validation errors with the invalid request error code
+func invalidRequest(validationErrors []entity.Error) []entity.Error {
+	for i := range validationErrors {
+		if validationErrors[i].Code == "" {
+			validationErrors[i].Code = entity.CodeInvalidRequest
+		}
+	}
+	return validationErrors
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/validators"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCoverageCheckServiceFailures(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		err              error
+		statusCode       int
+		retryAfter       string
+		expectedResponse string
+	}{
+		{
+			desc:             "Unknown carrier",
+			err:              dbclient.ErrUnknownCarrier,
+			statusCode:       http.StatusNotFound,
+			expectedResponse: `{"Errors":[{"message":"The requested resource was not found","code":"not_found"}]}`,
+		},
+		{
+			desc:             "Throttled table",
+			err:              awserr.New(dynamodb.ErrCodeProvisionedThroughputExceededException, "Rate of requests exceeds the allowed throughput", nil),
+			statusCode:       http.StatusTooManyRequests,
+			retryAfter:       "1",
+			expectedResponse: `{"Errors":[{"message":"Too many requests. Please try again later","code":"throttled"}]}`,
+		},
+		{
+			desc:             "Timed out",
+			err:              awserr.New("RequestCanceled", "request context canceled", context.DeadlineExceeded),
+			statusCode:       http.StatusServiceUnavailable,
+			retryAfter:       "5",
+			expectedResponse: `{"Errors":[{"message":"The service is temporarily unavailable. Please try again later","code":"unavailable"}]}`,
+		},
+		{
+			desc:             "Canceled",
+			err:              awserr.New("RequestCanceled", "request context canceled", context.Canceled),
+			statusCode:       499,
+			expectedResponse: `{"Errors":[{"message":"The request was canceled","code":"canceled"}]}`,
+		},
+		{
+			desc:             "Corrupt item",
+			err:              &dbclient.Error{Kind: dbclient.KindBadData, Err: errors.New("malformed coverage item")},
+			statusCode:       http.StatusInternalServerError,
+			expectedResponse: `{"Errors":[{"message":"There is a problem on the server. Please try again later","code":"bad_data"}]}`,
+		},
+		{
+			desc:             "Anything else",
+			err:              errors.New("Fake error"),
+			statusCode:       http.StatusInternalServerError,
+			expectedResponse: `{"Errors":[{"message":"There is a problem on the server. Please try again later","code":"internal"}]}`,
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			coveragecheckService := MockCoverageCheck{}
+			coveragecheckService.On("Verify", mock.Anything, "94105", "1", false).Return(entity.CoverageCheckResponse{}, tC.err)
+
+			r := chi.NewRouter()
+			r.Get("/v1/coveragecheck", CheckCoverage(validators.NewCoverageCheckValidator(), &coveragecheckService, &MockLocator{}))
+			ts := httptest.NewServer(r)
+			defer ts.Close()
+
+			res, err := ts.Client().Get(fmt.Sprintf("%s/v1/coveragecheck?zipcode=94105&carrierid=1", ts.URL))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tC.statusCode, res.StatusCode)
+			assert.Equal(t, tC.retryAfter, res.Header.Get("Retry-After"))
+			body, _ := ioutil.ReadAll(res.Body)
+			assert.Equal(t, tC.expectedResponse+"\n", string(body))
+			coveragecheckService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/services"
+)
+
+// GetHealth serves the shallow health check, or the deep one when asked for with mode=deep. An unavailable
+// service answers 503 so monitors don't need to read the body.
+func GetHealth(healthService services.Health) func(w http.ResponseWriter, r *http.Request) {
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var deep bool
+		switch r.URL.Query().Get("mode") {
+		case "", "shallow":
+		case "deep":
+			deep = true
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(entity.Response{Errors: []entity.Error{{Message: "mode must be shallow or deep", Path: "mode", Code: entity.CodeInvalidRequest}}})
+			return
+		}
+
+		response := healthService.Check(r.Context(), deep)
+
+		result, _ := json.Marshal(entity.Response{Result: response})
+		if response.Status == entity.HealthUnavailable {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		w.Write(result)
+	}
+}
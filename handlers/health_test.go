@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetHealth(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		deep       bool
+		response   entity.HealthResponse
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "shallow",
+			response:   entity.HealthResponse{Status: entity.HealthOK, Version: "fakeVersion", Uptime: "1m30s", UptimeSeconds: 90},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"Result":{"Status":"ok","Version":"fakeVersion","Uptime":"1m30s","UptimeSeconds":90}}`,
+		},
+		{
+			name:  "deep",
+			query: "?mode=deep",
+			deep:  true,
+			response: entity.HealthResponse{
+				Status:   entity.HealthDegraded,
+				Version:  "fakeVersion",
+				Uptime:   "1m30s",
+				Table:    &entity.TableHealth{Status: entity.HealthOK},
+				Carriers: []entity.CarrierHealth{{CarrierID: "1", Name: "Sprint"}},
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   `{"Result":{"Status":"degraded","Version":"fakeVersion","Uptime":"1m30s","UptimeSeconds":0,"Table":{"Status":"ok"},"Carriers":[{"CarrierID":"1","Name":"Sprint","Loaded":false}]}}`,
+		},
+		{
+			name:       "unavailable",
+			query:      "?mode=deep",
+			deep:       true,
+			response:   entity.HealthResponse{Status: entity.HealthUnavailable, Table: &entity.TableHealth{Status: entity.HealthUnavailable, Error: "fake error"}},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"Result":{"Status":"unavailable","Version":"","Uptime":"","UptimeSeconds":0,"Table":{"Status":"unavailable","Error":"fake error"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			healthService := MockHealth{}
+			healthService.On("Check", mock.Anything, tt.deep).Return(tt.response)
+
+			r := chi.NewRouter()
+			r.Get("/v1/health", GetHealth(&healthService))
+			ts := httptest.NewServer(r)
+			defer ts.Close()
+
+			res, err := ts.Client().Get(ts.URL + "/v1/health" + tt.query)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, res.StatusCode)
+			body, _ := ioutil.ReadAll(res.Body)
+			assert.Equal(t, tt.wantBody, string(body))
+			healthService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetHealthWithInvalidMode(t *testing.T) {
+	healthService := MockHealth{}
+
+	r := chi.NewRouter()
+	r.Get("/v1/health", GetHealth(&healthService))
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	res, err := ts.Client().Get(ts.URL + "/v1/health?mode=deeper")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, `{"Errors":[{"message":"mode must be shallow or deep","path":"mode","code":"invalid_request"}]}`+"\n", string(body))
+	healthService.AssertNotCalled(t, "Check", mock.Anything, mock.Anything)
+}
+
+type MockHealth struct {
+	mock.Mock
+}
+
+func (h *MockHealth) Check(ctx context.Context, deep bool) entity.HealthResponse {
+	args := h.Called(ctx, deep)
+	return args.Get(0).(entity.HealthResponse)
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/services"
+	"bitbucket.org/credomobile/coverage/validators"
+)
+
+// GetMarketAreas serves the market areas of a zipcode for the carrierid, Sprint when it's left out
+func GetMarketAreas(validator validators.MarketAreaValidator, marketAreaService services.MarketArea) func(w http.ResponseWriter, r *http.Request) {
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var validationErrors []entity.Error
+
+		validationErrors = validateRequest(r, validator.Validate)
+		if len(validationErrors) > 0 {
+			validationFailed(w, r, validationErrors)
+			return
+		}
+
+		ctx := r.Context()
+		zipCode := r.URL.Query().Get("zipcode")
+		carrierID := r.URL.Query().Get("carrierid")
+		if carrierID == "" {
+			carrierID = string(entity.Sprint)
+		}
+		response, err := marketAreaService.GetMarketAreas(ctx, zipCode, carrierID)
+		if err != nil {
+			serviceFailed(w, r, err, "Error occurred getting market areas for zipcode: %s and carrierID: %s", zipCode, carrierID)
+			return
+		}
+
+		result, _ := json.Marshal(entity.Response{Result: response})
+		w.WriteHeader(http.StatusOK)
+		w.Write(result)
+	}
+}
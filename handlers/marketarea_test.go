@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/validators"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetMarketAreasHappyPath(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		query            string
+		zipCode          string
+		carrierID        string
+		response         entity.MarketAreaResponse
+		expectedResponse string
+	}{
+		{
+			desc:      "Happy path with a csa found, carrierid defaults to Sprint",
+			query:     "zipcode=94105",
+			zipCode:   "94105",
+			carrierID: "1",
+			response: entity.MarketAreaResponse{ZipCode: "94105", Carriers: []entity.CarrierMarketAreas{
+				{CarrierID: "1", Name: "Sprint", Found: true, MarketAreas: []entity.MarketArea{{Type: "CSA", Code: "abc"}}},
+			}},
+			expectedResponse: `{"Result":{"ZipCode":"94105","Carriers":[{"CarrierID":"1","Name":"Sprint","Found":true,"MarketAreas":[{"Type":"CSA","Code":"abc"}]}]}}`,
+		},
+		{
+			desc:      "Happy path with Verizon market areas",
+			query:     "zipcode=94105&carrierid=2",
+			zipCode:   "94105",
+			carrierID: "2",
+			response: entity.MarketAreaResponse{ZipCode: "94105", Carriers: []entity.CarrierMarketAreas{
+				{CarrierID: "2", Name: "Verizon", Found: true, MarketAreas: []entity.MarketArea{{Type: "MTA", Code: "M024", Name: "San Francisco"}}},
+			}},
+			expectedResponse: `{"Result":{"ZipCode":"94105","Carriers":[{"CarrierID":"2","Name":"Verizon","Found":true,"MarketAreas":[{"Type":"MTA","Code":"M024","Name":"San Francisco"}]}]}}`,
+		},
+		{
+			desc:      "Happy path with no market areas",
+			query:     "zipcode=94106&carrierid=1",
+			zipCode:   "94106",
+			carrierID: "1",
+			response: entity.MarketAreaResponse{ZipCode: "94106", Carriers: []entity.CarrierMarketAreas{
+				{CarrierID: "1", Name: "Sprint", Found: false, MarketAreas: []entity.MarketArea{}},
+			}},
+			expectedResponse: `{"Result":{"ZipCode":"94106","Carriers":[{"CarrierID":"1","Name":"Sprint","Found":false,"MarketAreas":[]}]}}`,
+		},
+	}
+
+	for _, tC := range testCases {
+		marketAreaValidator := validators.NewMarketAreaValidator()
+		marketAreaService := MockMarketArea{}
+		marketAreaService.On("GetMarketAreas", mock.Anything, tC.zipCode, tC.carrierID).Return(tC.response, nil)
+
+		t.Run(tC.desc, func(t *testing.T) {
+
+			r := chi.NewRouter()
+			r.Get("/v1/marketarea", GetMarketAreas(marketAreaValidator, &marketAreaService))
+			ts := httptest.NewServer(r)
+			defer ts.Close()
+
+			req, _ := http.NewRequest("GET", fmt.Sprintf("%s/v1/marketarea?%s", ts.URL, tC.query), nil)
+			res, err := ts.Client().Do(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+
+			body, _ := ioutil.ReadAll(res.Body)
+			assert.Equal(t, tC.expectedResponse, string(body))
+			marketAreaService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetMarketAreasSadPathValidationErrors(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		zipCode          string
+		expectedResponse string
+		statusCode       int
+	}{
+		{
+			desc:             "Missing zipcode",
+			zipCode:          "",
+			expectedResponse: `{"Errors":[{"message":"Missing required property","path":"zipcode","code":"invalid_request"}]}`,
+			statusCode:       http.StatusBadRequest,
+		},
+		{
+			desc:             "Invalid zipcode with exceeded length",
+			zipCode:          "94105678907",
+			expectedResponse: `{"Errors":[{"message":"Illegal value for property","path":"zipcode","code":"invalid_request"}]}`,
+			statusCode:       http.StatusBadRequest,
+		},
+		{
+			desc:             "Invalid zipcode with shortened length",
+			zipCode:          "9410",
+			expectedResponse: `{"Errors":[{"message":"Illegal value for property","path":"zipcode","code":"invalid_request"}]}`,
+			statusCode:       http.StatusBadRequest,
+		},
+		{
+			desc:             "Invalid carrierid",
+			zipCode:          "94105&carrierid=9",
+			expectedResponse: `{"Errors":[{"message":"Illegal value for property","path":"carrierid","code":"invalid_request"}]}`,
+			statusCode:       http.StatusBadRequest,
+		},
+		{
+			desc:             "Invalid zipcode with alphanumeric characters",
+			zipCode:          "abc",
+			expectedResponse: `{"Errors":[{"message":"Illegal value for property","path":"zipcode","code":"invalid_request"}]}`,
+			statusCode:       http.StatusBadRequest,
+		},
+	}
+
+	for _, tC := range testCases {
+		marketAreaValidator := validators.NewMarketAreaValidator()
+		marketAreaService := MockMarketArea{}
+
+		t.Run(tC.desc, func(t *testing.T) {
+
+			r := chi.NewRouter()
+			r.Get("/v1/csa", GetMarketAreas(marketAreaValidator, &marketAreaService))
+			ts := httptest.NewServer(r)
+			defer ts.Close()
+
+			req, _ := http.NewRequest("GET", fmt.Sprintf("%s/v1/csa?zipcode=%s", ts.URL, tC.zipCode), nil)
+			res, err := ts.Client().Do(req)
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+
+			body, _ := ioutil.ReadAll(res.Body)
+			assert.Contains(t, string(body), tC.expectedResponse)
+			marketAreaService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetMarketAreasSadPathInternalServerError(t *testing.T) {
+	marketAreaValidator := validators.NewMarketAreaValidator()
+	marketAreaService := MockMarketArea{}
+	zipCode := "94105"
+
+	marketAreaService.On("GetMarketAreas", mock.Anything, zipCode, "1").Return(entity.MarketAreaResponse{}, errors.New("Fake error"))
+
+	r := chi.NewRouter()
+	r.Get("/v1/csa", GetMarketAreas(marketAreaValidator, &marketAreaService))
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	req, _ := http.NewRequest("GET", fmt.Sprintf("%s/v1/csa?zipcode=%s", ts.URL, zipCode), nil)
+	res, err := ts.Client().Do(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Contains(t, string(body), `{"Errors":[{"message":"There is a problem on the server. Please try again later","code":"internal"}]}`)
+	marketAreaService.AssertExpectations(t)
+}
+
+type MockMarketArea struct {
+	mock.Mock
+}
+
+func (m *MockMarketArea) GetMarketAreas(ctx context.Context, zipCode string, carrierID string) (entity.MarketAreaResponse, error) {
+	args := m.Called(ctx, zipCode, carrierID)
+	return args.Get(0).(entity.MarketAreaResponse), errOrNil(args.Get(1))
+}
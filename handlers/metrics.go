@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/metrics"
+	"github.com/go-chi/chi"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	requestsTotal = metrics.Default.NewCounter("coverage_http_requests_total",
+		"Requests served by route, method, status and carrierid", "route", "method", "status", "carrier")
+	requestDuration = metrics.Default.NewHistogram("coverage_http_request_duration_seconds",
+		"Time to serve a request by route and method", metrics.DefaultBuckets, "route", "method")
+	validationFailures = metrics.Default.NewCounter("coverage_validation_failures_total",
+		"Request properties failing validation by route and property", "route", "property")
+)
+
+// Instrument is middleware counting the requests by route, status and carrier and timing them. The route is the
+// pattern the request matched, so the metrics don't grow with the zipcodes and ids in the paths.
+func Instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+
+		route := routePattern(r)
+		requestsTotal.Inc(route, r.Method, strconv.Itoa(recorder.status), carrierLabel(r.URL.Query().Get("carrierid")))
+		requestDuration.Observe(time.Since(started).Seconds(), route, r.Method)
+	})
+}
+
+// statusRecorder keeps the status a handler wrote
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// routePattern gives back the route pattern the request matched, or unmatched
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || len(rctx.RoutePatterns) == 0 {
+		return "unmatched"
+	}
+	return strings.Replace(strings.Join(rctx.RoutePatterns, ""), "/*/", "/", -1)
+}
+
+// carrierLabel gives back the sort key of a registered carrierid, all, none when there is none or invalid
+func carrierLabel(carrierID string) string {
+	switch {
+	case carrierID == "":
+		return "none"
+	case entity.CarrierType(carrierID) == entity.AllCarriers:
+		return "all"
+	}
+	if carrier, ok := dbclient.LookupCarrier(entity.CarrierType(carrierID)); ok {
+		return carrier.SortKey
+	}
+	return "invalid"
+}
+
+// validationFailed counts the validation errors of the request and writes them in a 400
+func validationFailed(w http.ResponseWriter, r *http.Request, validationErrors []entity.Error) {
+	countValidationFailures(r, validationErrors)
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(entity.Response{Errors: invalidRequest(validationErrors)})
+}
+
+// countValidationFailures counts the validation errors by route and the property they are about
+func countValidationFailures(r *http.Request, validationErrors []entity.Error) {
+	route := routePattern(r)
+	for _, validationError := range validationErrors {
+		property := validationError.Path
+		if property == "" {
+			property = "body"
+		}
+		validationFailures.Inc(route, property)
+	}
+	log.Ctx(r.Context()).Debug().Int("validationErrors", len(validationErrors)).Msg("request failed validation")
+}
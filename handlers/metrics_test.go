@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/validators"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstrument(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(Instrument)
+	r.Get("/v1/csa/{csa}/zipcodes", GetCsaZipCodes(validators.NewCsaZipCodesValidator(), &MockCsaZipCodes{}))
+	r.Get("/v1/coveragecheck", func(w http.ResponseWriter, r *http.Request) {})
+
+	testCases := []struct {
+		desc   string
+		path   string
+		labels []string
+	}{
+		{
+			desc:   "Route pattern rather than path",
+			path:   "/v1/csa/PHX-TUC/zipcodes",
+			labels: []string{"/v1/csa/{csa}/zipcodes", "GET", "400", "none"},
+		},
+		{
+			desc:   "Registered carrier",
+			path:   "/v1/coveragecheck?zipcode=94105&carrierid=1",
+			labels: []string{"/v1/coveragecheck", "GET", "200", "sprint"},
+		},
+		{
+			desc:   "All carriers",
+			path:   "/v1/coveragecheck?zipcode=94105&carrierid=all",
+			labels: []string{"/v1/coveragecheck", "GET", "200", "all"},
+		},
+		{
+			desc:   "Unknown carrier",
+			path:   "/v1/coveragecheck?zipcode=94105&carrierid=99",
+			labels: []string{"/v1/coveragecheck", "GET", "200", "invalid"},
+		},
+		{
+			desc:   "Unmatched route",
+			path:   "/v1/nowhere",
+			labels: []string{"unmatched", "GET", "404", "none"},
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			before := requestsTotal.Value(tC.labels...)
+
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", tC.path, nil))
+
+			assert.Equal(t, before+1, requestsTotal.Value(tC.labels...))
+		})
+	}
+}
+
+func TestValidationFailures(t *testing.T) {
+	r := chi.NewRouter()
+	r.Get("/v1/csa/{csa}/zipcodes", GetCsaZipCodes(validators.NewCsaZipCodesValidator(), &MockCsaZipCodes{}))
+	csa := validationFailures.Value("/v1/csa/{csa}/zipcodes", "csa")
+	limit := validationFailures.Value("/v1/csa/{csa}/zipcodes", "limit")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/v1/csa/PHX-TUC/zipcodes?limit=0", nil))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, csa+1, validationFailures.Value("/v1/csa/{csa}/zipcodes", "csa"))
+	assert.Equal(t, limit+1, validationFailures.Value("/v1/csa/{csa}/zipcodes", "limit"))
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/services"
+	"bitbucket.org/credomobile/coverage/validators"
+)
+
+// CheckRadiusCoverage serves the coverage of every zipcode within a radius in miles of a zipcode or of a location
+// given as lat and lon, along with a summary of each carrier's coverage of the area
+func CheckRadiusCoverage(validator validators.RadiusCoverageValidator, radiusCoverageService services.RadiusCoverage) func(w http.ResponseWriter, r *http.Request) {
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		var validationErrors []entity.Error
+
+		validationErrors = validateRequest(r, validator.Validate)
+		if len(validationErrors) > 0 {
+			validationFailed(w, r, validationErrors)
+			return
+		}
+
+		ctx := r.Context()
+		center := entity.RadiusCenter{ZipCode: r.URL.Query().Get("zipcode")}
+		if center.ZipCode == "" {
+			center.Lat, _ = strconv.ParseFloat(r.URL.Query().Get("lat"), 64)
+			center.Lon, _ = strconv.ParseFloat(r.URL.Query().Get("lon"), 64)
+		}
+		radius, _ := strconv.ParseFloat(r.URL.Query().Get("radius"), 64)
+		carrierID := r.URL.Query().Get("carrierid")
+		detail := r.URL.Query().Get("detail") == "true"
+
+		response, found, err := radiusCoverageService.Verify(ctx, center, radius, carrierID, detail)
+		if err != nil {
+			serviceFailed(w, r, err, "Error occurred checking coverage within %.2f miles for carrierID: %s", radius, carrierID)
+			return
+		}
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(entity.Response{Errors: []entity.Error{{Message: "No location found for the zipcode", Path: "zipcode", Code: entity.CodeNotFound}}})
+			return
+		}
+
+		result, err := json.Marshal(entity.Response{Result: response})
+		if err != nil {
+			serviceFailed(w, r, err, "Error occurred encoding the coverage within %.2f miles for carrierID: %s", radius, carrierID)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(result)
+	}
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/validators"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCheckRadiusCoverage(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		query            string
+		center           entity.RadiusCenter
+		response         entity.RadiusCoverageResponse
+		found            bool
+		err              error
+		statusCode       int
+		expectedResponse string
+	}{
+		{
+			desc:   "Around a location",
+			query:  "lat=37.78&lon=-122.4&radius=10&carrierid=2",
+			center: entity.RadiusCenter{Lat: 37.78, Lon: -122.4},
+			response: entity.RadiusCoverageResponse{
+				Center:      entity.RadiusCenter{Lat: 37.78, Lon: -122.4},
+				RadiusMiles: 10,
+				ZipCodes: []entity.RadiusZipCode{{ZipCode: "94105", DistanceMiles: 0.75, Carriers: []entity.CarrierCoverageResult{
+					{CarrierID: "2", CoverageCheckResponse: &entity.CoverageCheckResponse{IsCovered: true}},
+				}}},
+				Carriers: []entity.CarrierRadiusSummary{{CarrierID: "2", Name: "Verizon", ZipCodes: 1, Covered: 1, CoveredPercent: 100}},
+			},
+			found:      true,
+			statusCode: http.StatusOK,
+			expectedResponse: `{"Result":{"Center":{"Lat":37.78,"Lon":-122.4},"RadiusMiles":10,` +
+				`"ZipCodes":[{"ZipCode":"94105","DistanceMiles":0.75,"Carriers":[{"CarrierID":"2","IsCovered":true}]}],` +
+				`"Carriers":[{"CarrierID":"2","Name":"Verizon","ZipCodes":1,"Covered":1,"Unknown":0,"CoveredPercent":100}]}}`,
+		},
+		{
+			desc:             "Zipcode without a location",
+			query:            "zipcode=94105&radius=10&carrierid=all",
+			center:           entity.RadiusCenter{ZipCode: "94105"},
+			statusCode:       http.StatusNotFound,
+			expectedResponse: `{"Errors":[{"message":"No location found for the zipcode","path":"zipcode","code":"not_found"}]}` + "\n",
+		},
+		{
+			desc:             "Db error",
+			query:            "zipcode=94105&radius=10&carrierid=all",
+			center:           entity.RadiusCenter{ZipCode: "94105"},
+			err:              errors.New("Fake error"),
+			statusCode:       http.StatusInternalServerError,
+			expectedResponse: `{"Errors":[{"message":"There is a problem on the server. Please try again later","code":"internal"}]}` + "\n",
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			radiusCoverageService := MockRadiusCoverage{}
+			radiusCoverageService.On("Verify", mock.Anything, tC.center, 10.0, mock.Anything, false).Return(tC.response, tC.found, tC.err)
+
+			r := chi.NewRouter()
+			r.Get("/v1/coveragecheck/radius", CheckRadiusCoverage(validators.NewRadiusCoverageValidator(), &radiusCoverageService))
+			ts := httptest.NewServer(r)
+			defer ts.Close()
+
+			res, err := ts.Client().Get(ts.URL + "/v1/coveragecheck/radius?" + tC.query)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tC.statusCode, res.StatusCode)
+			body, _ := ioutil.ReadAll(res.Body)
+			assert.Equal(t, tC.expectedResponse, string(body))
+			radiusCoverageService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCheckRadiusCoverageWithValidationErrors(t *testing.T) {
+	radiusCoverageService := MockRadiusCoverage{}
+
+	r := chi.NewRouter()
+	r.Get("/v1/coveragecheck/radius", CheckRadiusCoverage(validators.NewRadiusCoverageValidator(), &radiusCoverageService))
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	res, err := ts.Client().Get(ts.URL + "/v1/coveragecheck/radius?zipcode=94105&carrierid=1&radius=100")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, `{"Errors":[{"message":"Illegal value for property","path":"radius","code":"invalid_request"}]}`+"\n", string(body))
+	radiusCoverageService.AssertNotCalled(t, "Verify")
+}
+
+type MockRadiusCoverage struct {
+	mock.Mock
+}
+
+func (c *MockRadiusCoverage) Verify(ctx context.Context, center entity.RadiusCenter, radiusMiles float64, carrierID string, detail bool) (entity.RadiusCoverageResponse, bool, error) {
+	args := c.Called(ctx, center, radiusMiles, carrierID, detail)
+	return args.Get(0).(entity.RadiusCoverageResponse), args.Bool(1), errOrNil(args.Get(2))
+}
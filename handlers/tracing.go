@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/tracing"
+	"github.com/rs/zerolog/log"
+)
+
+// trackingIDHeader is the header the callers identify their requests with
+const trackingIDHeader = "x-trackingid"
+
+// Trace is middleware serving each request in a server span. The span continues the trace of a W3C traceparent
+// header, or else takes the callers' x-trackingid as its trace id when it is a UUID, so the trace of a request can
+// be found by either. The x-trackingid is echoed back, made up of the trace id when the caller didn't send one.
+func Trace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		trackingID := r.Header.Get(trackingIDHeader)
+		if parent, ok := tracing.ParseTraceParent(r.Header.Get("traceparent")); ok {
+			ctx = tracing.ContextWithRemoteParent(ctx, parent)
+		} else if traceID, ok := tracing.TraceIDFromTrackingID(trackingID); ok {
+			ctx = tracing.ContextWithRemoteParent(ctx, tracing.SpanContext{TraceID: traceID})
+		}
+
+		ctx, span := tracing.Start(ctx, r.Method, tracing.KindServer)
+		defer span.End()
+		if span != nil {
+			traceID := span.SpanContext().TraceID
+			if trackingID == "" {
+				trackingID = uuidOf(traceID)
+			}
+			logger := log.Ctx(ctx).With().Str("traceid", traceID.String()).Logger()
+			ctx = logger.WithContext(ctx)
+		}
+		if trackingID != "" {
+			w.Header().Set(trackingIDHeader, trackingID)
+			span.SetAttribute("tracking.id", trackingID)
+		}
+		span.SetAttribute("http.method", r.Method)
+		span.SetAttribute("http.target", r.URL.Path)
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		route := routePattern(r)
+		span.SetName(r.Method + " " + route)
+		span.SetAttribute("http.route", route)
+		span.SetAttribute("http.status_code", strconv.Itoa(recorder.status))
+		if recorder.status >= http.StatusInternalServerError {
+			span.RecordError(errors.New(http.StatusText(recorder.status)))
+		}
+	})
+}
+
+// uuidOf formats a trace id as a UUID, the form of the callers' tracking ids
+func uuidOf(traceID tracing.TraceID) string {
+	id := traceID.String()
+	return id[:8] + "-" + id[8:12] + "-" + id[12:16] + "-" + id[16:20] + "-" + id[20:]
+}
+
+// validateRequest runs the validator of a request in a validation span
+func validateRequest(r *http.Request, validate func(ctx context.Context, r *http.Request) []entity.Error) []entity.Error {
+	return traceValidation(r.Context(), func(ctx context.Context) []entity.Error {
+		return validate(ctx, r)
+	})
+}
+
+// traceValidation runs a validation in a span recording how many errors it found
+func traceValidation(ctx context.Context, validate func(ctx context.Context) []entity.Error) []entity.Error {
+	ctx, span := tracing.Start(ctx, "validate", tracing.KindInternal)
+	defer span.End()
+	validationErrors := validate(ctx)
+	span.SetAttribute("validation.errors", strconv.Itoa(len(validationErrors)))
+	return validationErrors
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/tracing"
+	"bitbucket.org/credomobile/coverage/validators"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingExporter keeps the exported spans
+type recordingExporter struct {
+	spans []tracing.SpanData
+}
+
+func (e *recordingExporter) Export(ctx context.Context, spans []tracing.SpanData) error {
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func TestTrace(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(Trace)
+	r.Get("/v1/csa/{csa}/zipcodes", GetCsaZipCodes(validators.NewCsaZipCodesValidator(), &MockCsaZipCodes{}))
+	r.Get("/v1/carriers", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	testCases := []struct {
+		desc        string
+		path        string
+		headers     map[string]string
+		name        string
+		statusCode  string
+		spans       int
+		traceID     string
+		parent      string
+		trackingID  string
+		errorStatus string
+	}{
+		{
+			desc:       "Validation span under the request span",
+			path:       "/v1/csa/PHX-TUC/zipcodes",
+			name:       "GET /v1/csa/{csa}/zipcodes",
+			statusCode: "400",
+			spans:      2,
+		},
+		{
+			desc:        "Server error",
+			path:        "/v1/carriers",
+			name:        "GET /v1/carriers",
+			statusCode:  "500",
+			spans:       1,
+			errorStatus: "Internal Server Error",
+		},
+		{
+			desc:       "Tracking id as the trace id",
+			path:       "/v1/carriers",
+			headers:    map[string]string{"x-trackingid": "565242ac-a5d7-4377-a88f-adc0d8cdd3ea"},
+			name:       "GET /v1/carriers",
+			statusCode: "500",
+			spans:      1,
+			traceID:    "565242aca5d74377a88fadc0d8cdd3ea",
+			trackingID: "565242ac-a5d7-4377-a88f-adc0d8cdd3ea",
+		},
+		{
+			desc: "Traceparent over the tracking id",
+			path: "/v1/carriers",
+			headers: map[string]string{
+				"x-trackingid": "order-1234",
+				"traceparent":  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			},
+			name:       "GET /v1/carriers",
+			statusCode: "500",
+			spans:      1,
+			traceID:    "4bf92f3577b34da6a3ce929d0e0e4736",
+			parent:     "00f067aa0ba902b7",
+			trackingID: "order-1234",
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			exporter := &recordingExporter{}
+			tracing.SetTracer(tracing.NewTracer(exporter, nil))
+			defer tracing.SetTracer(nil)
+
+			req := httptest.NewRequest("GET", tC.path, nil)
+			for key, value := range tC.headers {
+				req.Header.Set(key, value)
+			}
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+			assert.NoError(t, tracing.Flush(context.Background()))
+
+			assert.Len(t, exporter.spans, tC.spans)
+			server := exporter.spans[len(exporter.spans)-1]
+			assert.Equal(t, tC.name, server.Name)
+			assert.Equal(t, tracing.KindServer, server.Kind)
+			assert.Equal(t, tC.statusCode, server.Attributes["http.status_code"])
+			if tC.errorStatus != "" {
+				assert.Equal(t, tC.errorStatus, server.Error)
+			}
+			if tC.traceID != "" {
+				assert.Equal(t, tC.traceID, server.TraceID.String())
+			}
+			if tC.parent != "" {
+				assert.Equal(t, tC.parent, server.Parent.String())
+			} else {
+				assert.False(t, server.Parent.IsValid())
+			}
+			// a tracking id is made up of the trace id when the caller didn't send one
+			if tC.trackingID == "" {
+				tC.trackingID = uuidOf(server.TraceID)
+			}
+			assert.Equal(t, tC.trackingID, rr.Header().Get("x-trackingid"))
+			for _, span := range exporter.spans[:len(exporter.spans)-1] {
+				assert.Equal(t, "validate", span.Name)
+				assert.Equal(t, server.SpanID, span.Parent)
+				assert.NotEqual(t, "0", span.Attributes["validation.errors"])
+			}
+		})
+	}
+}
+
+func TestTraceOff(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(Trace)
+	r.Get("/v1/carriers", func(w http.ResponseWriter, r *http.Request) {})
+
+	req := httptest.NewRequest("GET", "/v1/carriers", nil)
+	req.Header.Set("x-trackingid", "order-1234")
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "order-1234", rr.Header().Get("x-trackingid"))
+}
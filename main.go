@@ -1,23 +1,73 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"time"
 
+	"bitbucket.org/credomobile/coverage/auth"
 	"bitbucket.org/credomobile/coverage/dbclient"
 	"bitbucket.org/credomobile/coverage/handlers"
+	"bitbucket.org/credomobile/coverage/metrics"
+	"bitbucket.org/credomobile/coverage/rules"
 	"bitbucket.org/credomobile/coverage/services"
+	"bitbucket.org/credomobile/coverage/tracing"
 	"bitbucket.org/credomobile/coverage/validators"
 	"bitbucket.org/credomobile/frink"
 	"bitbucket.org/credomobile/frink/flambda"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/go-chi/chi"
+	"github.com/rs/zerolog"
 )
 
 type Config struct {
 	frink.BaseConfig
-	DynamoDBArn string `env:"DYNAMODB_ARN"`
+	DynamoDBArn            string        `env:"DYNAMODB_ARN"`
+	DynamoDBRegion         string        `env:"DYNAMODB_REGION"`
+	DynamoDBEndpoint       string        `env:"DYNAMODB_ENDPOINT"`
+	DynamoDBConnectTimeout time.Duration `env:"DYNAMODB_CONNECT_TIMEOUT"`
+	DynamoDBRequestTimeout time.Duration `env:"DYNAMODB_REQUEST_TIMEOUT"`
+	CoverageRules          string        `env:"COVERAGE_RULES"`
+	CoverageRulesFile      string        `env:"COVERAGE_RULES_FILE"`
+	CoverageStore          string        `env:"COVERAGE_STORE"`
+	CoverageFixtures       string        `env:"COVERAGE_FIXTURES"`
+	CacheDisabled          bool          `env:"COVERAGE_CACHE_DISABLED"`
+	CacheSize              int           `env:"COVERAGE_CACHE_SIZE"`
+	CacheTTL               time.Duration `env:"COVERAGE_CACHE_TTL"`
+	CacheNegativeTTL       time.Duration `env:"COVERAGE_CACHE_NEGATIVE_TTL"`
+	PointerCheckInterval   time.Duration `env:"COVERAGE_POINTER_CHECK_INTERVAL"`
+	JWKSFile               string        `env:"COVERAGE_JWKS_FILE"`
+	JWKSURL                string        `env:"COVERAGE_JWKS_URL"`
+	JWTAudience            string        `env:"COVERAGE_JWT_AUDIENCE"`
+	AuthDisabled           bool          `env:"COVERAGE_AUTH_DISABLED"`
+	APIKeysStore           string        `env:"COVERAGE_API_KEYS_STORE"`
+	APIKeysArn             string        `env:"COVERAGE_API_KEYS_ARN"`
+	TracesExporter         string        `env:"OTEL_TRACES_EXPORTER"`
+	OTLPEndpoint           string        `env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	ServiceName            string        `env:"OTEL_SERVICE_NAME"`
 }
 
+// metricsNamespace is the CloudWatch namespace of the metrics flushed in Lambda mode
+const metricsNamespace = "coverage"
+
+// defaultOTLPEndpoint is the OpenTelemetry collector's OTLP/HTTP receiver, the collector of the Lambda layer included
+const defaultOTLPEndpoint = "http://localhost:4318"
+
+// traceFlushInterval is how often the http mode exports the finished spans
+const traceFlushInterval = 5 * time.Second
+
+// version is the git commit the Makefile builds, injected through ldflags
+var version = "dev"
+
+// started is the cold start of the instance, the health check reports the uptime since
+var started = time.Now()
+
 var initialized = false
 var frinkLambda *flambda.FrinkAdapter
 
@@ -25,40 +75,323 @@ func Handler(req events.APIGatewayProxyRequest) (events.APIGatewayProxyResponse,
 	if !initialized {
 		log.Println("Lambda COLD START")
 
-		config := &Config{}
-		opts, err := frink.NewDefaultLambdaOptions()
-		if err != nil {
-			log.Fatal("unable to configure options: ", err)
-		}
-		app, err := frink.New("coverage", opts, config)
+		frinkLambda = flambda.New(newApp())
+		initialized = true
+	}
+	response, err := frinkLambda.Proxy(req)
+	// Lambda has no scraper, the metrics go to CloudWatch as embedded metric format log lines instead
+	if flushErr := metrics.Default.WriteEMF(os.Stdout, metricsNamespace, time.Now()); flushErr != nil {
+		log.Println("unable to flush metrics: ", flushErr)
+	}
+	// the instance may be frozen once the response is returned, so the spans are exported before
+	if flushErr := tracing.Flush(context.Background()); flushErr != nil {
+		log.Println("unable to flush traces: ", flushErr)
+	}
+	return response, err
+}
+
+// newApp loads the config and wires the services and routes, shared by the Lambda and http modes
+func newApp() *frink.App {
+	config := &Config{}
+	opts, err := frink.NewDefaultLambdaOptions()
+	if err != nil {
+		log.Fatal("unable to configure options: ", err)
+	}
+	app, err := frink.New("coverage", opts, config)
+	if err != nil {
+		app.Logger.Fatal().Err(err).Msg("unable to configure application")
+	}
+
+	tracer, err := newTracer(config, app.Logger)
+	if err != nil {
+		app.Logger.Fatal().Err(err).Msg("unable to configure tracing")
+	}
+	tracing.SetTracer(tracer)
+
+	ruleSet, err := rules.Load(config.CoverageRules, config.CoverageRulesFile)
+	if err != nil {
+		app.Logger.Fatal().Err(err).Msg("unable to load coverage rules")
+	}
+	app.Logger.Info().Msgf("loaded coverage rules version %s", ruleSet.Version)
+
+	store, datasets, err := newStore(config, ruleSet, app.Logger)
+	if err != nil {
+		app.Logger.Fatal().Err(err).Msg("unable to configure coverage store")
+	}
+	// every reader goes through the live dataset pointers, so a promotion or rollback switches them all
+	pointerCheckInterval := config.PointerCheckInterval
+	if pointerCheckInterval <= 0 {
+		pointerCheckInterval = dbclient.DefaultPointerCheckInterval
+	}
+	store = dbclient.NewVersionedStore(store, pointerCheckInterval)
+
+	dbclientFactory, err := dbclient.NewDbClientFactory(store, ruleSet, app.Logger)
+	if err != nil {
+		app.Logger.Fatal().Err(err).Msg("unable to configure Db Client")
+	}
+
+	marketAreaDbClient := dbclient.NewMarketAreaClient(store, ruleSet)
+
+	// the cache is a package level value of the lambda, so it survives warm invocations
+	var cache *dbclient.Cache
+	if !config.CacheDisabled {
+		cache = dbclient.NewCache(store, dbclient.CacheConfig{
+			Size:        config.CacheSize,
+			TTL:         config.CacheTTL,
+			NegativeTTL: config.CacheNegativeTTL,
+		})
+		dbclientFactory = dbclient.NewCachingDbClientFactory(dbclientFactory, cache)
+		marketAreaDbClient = cache.MarketAreaClient(marketAreaDbClient)
+	}
+
+	coverageCheckService := services.NewCoverageCheck(dbclientFactory)
+	carriersService := services.NewCarriers(dbclientFactory)
+	cacheStatsService := services.NewCacheStats(cache)
+	// the health check reads the store directly, the cache would hide a broken table
+	healthService := services.NewHealth(store, version, started)
+
+	marketAreaService, err := services.NewMarketArea(marketAreaDbClient, app.Logger)
+	if err != nil {
+		app.Logger.Fatal().Err(err).Msg("unable to configure market area service")
+	}
+
+	coverageCheckValidator := validators.NewCoverageCheckValidator()
+	batchCoverageCheckValidator := validators.NewBatchCoverageCheckValidator()
+	csaZipCodesService, err := services.NewCsaZipCodes(dbclient.NewCsaZipCodeClient(store), app.Logger)
+	if err != nil {
+		app.Logger.Fatal().Err(err).Msg("unable to configure csa zipcodes service")
+	}
+
+	locatorService, err := services.NewLocator(dbclient.NewZipCodeLocator(store))
+	if err != nil {
+		app.Logger.Fatal().Err(err).Msg("unable to configure locator service")
+	}
+
+	radiusCoverageService, err := services.NewRadiusCoverage(dbclientFactory, dbclient.NewZipCodeLocator(store))
+	if err != nil {
+		app.Logger.Fatal().Err(err).Msg("unable to configure radius coverage service")
+	}
+
+	coverageSummaryService, err := services.NewCoverageSummary(dbclient.NewCoverageSummaryClient(store), app.Logger)
+	if err != nil {
+		app.Logger.Fatal().Err(err).Msg("unable to configure coverage summary service")
+	}
+
+	// the diff reads the dataset versions themselves, the live one is only one of them
+	coverageDiffService, err := services.NewCoverageDiff(dbclient.NewCoverageDiffer(datasets, ruleSet), app.Logger)
+	if err != nil {
+		app.Logger.Fatal().Err(err).Msg("unable to configure coverage diff service")
+	}
+
+	marketAreaValidator := validators.NewMarketAreaValidator()
+	coverageSummaryValidator := validators.NewCoverageSummaryValidator()
+	radiusCoverageValidator := validators.NewRadiusCoverageValidator()
+	csaZipCodesValidator := validators.NewCsaZipCodesValidator()
+	coverageDiffValidator := validators.NewCoverageDiffValidator()
+
+	verifier, err := newVerifier(config)
+	if err != nil {
+		app.Logger.Fatal().Err(err).Msg("unable to configure jwt authentication")
+	}
+
+	var apiKeysService services.APIKeys
+	apiKeyStore, err := newAPIKeyStore(config)
+	if err != nil {
+		app.Logger.Fatal().Err(err).Msg("unable to configure api key store")
+	}
+	if apiKeyStore != nil {
+		apiKeysService, err = services.NewAPIKeys(apiKeyStore, app.Logger)
 		if err != nil {
-			app.Logger.Fatal().Err(err).Msg("unable to configure application")
+			app.Logger.Fatal().Err(err).Msg("unable to configure api keys service")
 		}
+	}
+	apiKeysValidator := validators.NewAPIKeysValidator()
 
-		dbclientFactory, err := dbclient.NewDbClientFactory(config.DynamoDBArn, app.Logger)
+	// every route is traced, counted and timed, the health check included
+	app.Router.Group(func(r chi.Router) {
+		r.Use(handlers.Trace, handlers.Instrument)
+		// the health check stays open for the load balancer and deploy checks
+		r.Get("/v1/health", handlers.GetHealth(healthService))
+		r.Group(func(r chi.Router) {
+			if verifier != nil {
+				r.Use(handlers.Authenticate(verifier))
+			} else {
+				app.Logger.Warn().Msg("jwt authentication is disabled, the coverage routes are open")
+			}
+			// the api key comes after the token, so unauthenticated requests don't count against a client's quota
+			if apiKeysService != nil {
+				r.Use(handlers.RequireAPIKey(apiKeysService))
+			}
+			r.Get("/v1/coveragecheck", handlers.CheckCoverage(coverageCheckValidator, coverageCheckService, locatorService))
+			r.Get("/v1/coveragecheck/radius", handlers.CheckRadiusCoverage(radiusCoverageValidator, radiusCoverageService))
+			r.Post("/v1/coveragecheck/batch", handlers.CheckCoverageBatch(batchCoverageCheckValidator, coverageCheckService))
+			r.Get("/v1/marketarea", handlers.GetMarketAreas(marketAreaValidator, marketAreaService))
+			// the csa route predates the other carriers' market areas and is kept for its callers
+			r.Get("/v1/csa", handlers.GetMarketAreas(marketAreaValidator, marketAreaService))
+			r.Get("/v1/csa/{csa}/zipcodes", handlers.GetCsaZipCodes(csaZipCodesValidator, csaZipCodesService))
+			r.Get("/v1/coverage/summary", handlers.GetCoverageSummary(coverageSummaryValidator, coverageSummaryService))
+			r.Get("/v1/carriers", handlers.GetCarriers(carriersService))
+			r.Get("/v1/cache/stats", handlers.GetCacheStats(cacheStatsService))
+		})
+		mountAdminRoutes(r, verifier, app.Logger, func(r chi.Router) {
+			// the diff scans two versions of the table, too heavy for every caller to run at will
+			r.Get("/v1/datasets/diff", handlers.GetCoverageDiff(coverageDiffValidator, coverageDiffService))
+			if apiKeysService != nil {
+				r.Post("/v1/admin/apikeys", handlers.IssueAPIKey(apiKeysValidator, apiKeysService))
+				r.Post("/v1/admin/apikeys/{clientid}/rotate", handlers.RotateAPIKey(apiKeysValidator, apiKeysService))
+				r.Delete("/v1/admin/apikeys/{clientid}", handlers.RevokeAPIKeys(apiKeysValidator, apiKeysService))
+				r.Get("/v1/admin/apikeys/{clientid}/usage", handlers.GetAPIKeyUsage(apiKeysValidator, apiKeysService))
+			}
+		})
+	})
+
+	return app
+}
+
+// mountAdminRoutes mounts the admin routes behind a bearer token granted the admin scope. They aren't mounted when
+// jwt authentication is disabled, anyone could issue themselves an api key otherwise.
+func mountAdminRoutes(r chi.Router, verifier auth.Verifier, logger *zerolog.Logger, routes func(r chi.Router)) {
+	if verifier == nil {
+		logger.Warn().Msg("jwt authentication is disabled, the admin routes are not mounted")
+		return
+	}
+	r.Group(func(r chi.Router) {
+		r.Use(handlers.Authenticate(verifier), handlers.RequireScope(auth.AdminScope))
+		routes(r)
+	})
+}
+
+// newStore gives back the coverage store selected by COVERAGE_STORE and its dataset versions: the DynamoDB coverage
+// table by default, or an in-memory store seeded with COVERAGE_FIXTURES and summarized with the rule set
+func newStore(config *Config, ruleSet rules.RuleSet, logger *zerolog.Logger) (dbclient.Store, dbclient.Datasets, error) {
+	switch config.CoverageStore {
+	case "", "dynamodb":
+		connection, err := dbclient.NewConnection(dbclient.ConnectionConfig{
+			DynamoDBARN:    config.DynamoDBArn,
+			Region:         config.DynamoDBRegion,
+			Endpoint:       config.DynamoDBEndpoint,
+			ConnectTimeout: config.DynamoDBConnectTimeout,
+			RequestTimeout: config.DynamoDBRequestTimeout,
+		})
 		if err != nil {
-			app.Logger.Fatal().Err(err).Msg("unable to configure Db Client")
+			return nil, nil, err
 		}
+		return dbclient.NewDynamoStore(connection.TableName, connection.DynamoDB), dbclient.NewDatasets(connection.TableName, connection.DynamoDB), nil
+	case "memory":
+		store := dbclient.NewMemoryStore()
+		store.SetRuleSet(ruleSet)
+		err := dbclient.LoadFixtures(logger.WithContext(context.Background()), store, store.Datasets(), config.CoverageFixtures, time.Now().Format("2006-01-02"))
+		return store, store.Datasets(), err
+	default:
+		return nil, nil, fmt.Errorf("unknown coverage store %q, expected dynamodb or memory", config.CoverageStore)
+	}
+}
 
-		coverageCheckService := services.NewCoverageCheck(dbclientFactory)
+// newVerifier gives back the verifier of the bearer tokens, with the JWKS read from COVERAGE_JWKS_FILE or fetched
+// from COVERAGE_JWKS_URL. It is nil when COVERAGE_AUTH_DISABLED is set, for local development.
+func newVerifier(config *Config) (auth.Verifier, error) {
+	if config.AuthDisabled {
+		return nil, nil
+	}
+	audience := config.JWTAudience
+	if audience == "" {
+		audience = auth.DefaultAudience
+	}
 
-		csaService, err := services.NewCsa(config.DynamoDBArn, app.Logger)
+	switch {
+	case config.JWKSFile != "":
+		keys, err := auth.LoadKeySetFile(config.JWKSFile)
 		if err != nil {
-			app.Logger.Fatal().Err(err).Msg("unable to configure Csa service")
+			return nil, err
 		}
+		return auth.NewVerifier(keys, audience), nil
+	case config.JWKSURL != "":
+		keys := auth.NewRemoteKeySet(config.JWKSURL, &http.Client{Timeout: 5 * time.Second}, auth.DefaultKeySetRefreshInterval)
+		return auth.NewVerifier(keys, audience), nil
+	default:
+		return nil, errors.New("no jwks configured, set COVERAGE_JWKS_FILE or COVERAGE_JWKS_URL, or COVERAGE_AUTH_DISABLED")
+	}
+}
 
-		coverageCheckValidator := validators.NewCoverageCheckValidator()
-		csaValidator := validators.NewCsaValidator()
+// newAPIKeyStore gives back the api key store selected by COVERAGE_API_KEYS_STORE: the DynamoDB table of
+// COVERAGE_API_KEYS_ARN, or an empty in-memory store. It is nil when api keys aren't configured.
+func newAPIKeyStore(config *Config) (dbclient.APIKeyStore, error) {
+	switch config.APIKeysStore {
+	case "":
+		return nil, nil
+	case "dynamodb":
+		connection, err := dbclient.NewConnection(dbclient.ConnectionConfig{
+			DynamoDBARN:    config.APIKeysArn,
+			Region:         config.DynamoDBRegion,
+			Endpoint:       config.DynamoDBEndpoint,
+			ConnectTimeout: config.DynamoDBConnectTimeout,
+			RequestTimeout: config.DynamoDBRequestTimeout,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return dbclient.NewAPIKeyStore(connection.TableName, connection.DynamoDB), nil
+	case "memory":
+		return dbclient.NewMemoryAPIKeyStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown api key store %q, expected dynamodb or memory", config.APIKeysStore)
+	}
+}
 
-		app.Router.Get("/v1/coveragecheck", handlers.CheckCoverage(coverageCheckValidator, coverageCheckService))
-		app.Router.Get("/v1/csa", handlers.GetCsa(csaValidator, csaService))
+// newTracer gives back the tracer of the exporter selected by OTEL_TRACES_EXPORTER: otlp to the collector at
+// OTEL_EXPORTER_OTLP_ENDPOINT, or console to stdout. It is nil when tracing is off, the default.
+func newTracer(config *Config, logger *zerolog.Logger) (*tracing.Tracer, error) {
+	serviceName := config.ServiceName
+	if serviceName == "" {
+		serviceName = "coverage"
+	}
+	onError := func(err error) {
+		logger.Error().Err(err).Msg("unable to export spans")
+	}
 
-		frinkLambda = flambda.New(app)
-		initialized = true
+	switch config.TracesExporter {
+	case "", "none":
+		return nil, nil
+	case "console":
+		return tracing.NewTracer(tracing.NewConsoleExporter(os.Stdout), onError), nil
+	case "otlp":
+		endpoint := config.OTLPEndpoint
+		if endpoint == "" {
+			endpoint = defaultOTLPEndpoint
+		}
+		exporter := tracing.NewOTLPExporter(endpoint, &http.Client{Timeout: 5 * time.Second}, serviceName)
+		return tracing.NewTracer(exporter, onError), nil
+	default:
+		return nil, fmt.Errorf("unknown traces exporter %q, expected otlp, console or none", config.TracesExporter)
 	}
-	return frinkLambda.Proxy(req)
 }
 
 func main() {
-	lambda.Start(Handler)
+	mode := flag.String("mode", "lambda", "how to run the service: lambda or http")
+	addr := flag.String("addr", ":8080", "address the http mode listens on")
+	flag.Parse()
+
+	switch *mode {
+	case "lambda":
+		lambda.Start(Handler)
+	case "http":
+		app := newApp()
+		app.Router.Get("/metrics", metrics.Handler(metrics.Default).ServeHTTP)
+		go flushTraces(app.Logger, traceFlushInterval)
+		serveHTTP(app, *addr)
+		if err := tracing.Flush(context.Background()); err != nil {
+			app.Logger.Error().Err(err).Msg("unable to flush traces")
+		}
+	default:
+		log.Fatalf("unknown mode %q, expected lambda or http", *mode)
+	}
+}
+
+// flushTraces exports the finished spans of the http mode every interval, a full batch is exported right away
+func flushTraces(logger *zerolog.Logger, interval time.Duration) {
+	for range time.Tick(interval) {
+		if err := tracing.Flush(context.Background()); err != nil {
+			logger.Error().Err(err).Msg("unable to flush traces")
+		}
+	}
 }
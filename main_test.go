@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/auth"
+	"github.com/go-chi/chi"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewVerifier(t *testing.T) {
+	verifier, err := newVerifier(&Config{AuthDisabled: true})
+	assert.NoError(t, err)
+	assert.Nil(t, verifier)
+
+	_, err = newVerifier(&Config{})
+	assert.Error(t, err)
+
+	_, err = newVerifier(&Config{JWKSFile: "does-not-exist.json"})
+	assert.Error(t, err)
+
+	verifier, err = newVerifier(&Config{JWKSURL: "http://localhost:0/.well-known/jwks.json"})
+	assert.NoError(t, err)
+	assert.NotNil(t, verifier)
+}
+
+func TestNewAPIKeyStore(t *testing.T) {
+	store, err := newAPIKeyStore(&Config{})
+	assert.NoError(t, err)
+	assert.Nil(t, store)
+
+	store, err = newAPIKeyStore(&Config{APIKeysStore: "memory"})
+	assert.NoError(t, err)
+	assert.NotNil(t, store)
+
+	_, err = newAPIKeyStore(&Config{APIKeysStore: "dynamodb"})
+	assert.Error(t, err)
+
+	store, err = newAPIKeyStore(&Config{APIKeysStore: "dynamodb", APIKeysArn: "arn:aws:dynamodb:us-east-2:674346455231:table/coverage-apikeys"})
+	assert.NoError(t, err)
+	assert.NotNil(t, store)
+
+	_, err = newAPIKeyStore(&Config{APIKeysStore: "redis"})
+	assert.Error(t, err)
+}
+
+// tokenVerifier grants the admin scope to the "admin" token and no scope to the "caller" one, rejecting any other
+type tokenVerifier struct{}
+
+func (tokenVerifier) Verify(ctx context.Context, token string) (auth.Identity, error) {
+	switch token {
+	case "admin":
+		return auth.Identity{Subject: "admin", Scopes: []string{auth.AdminScope}}, nil
+	case "caller":
+		return auth.Identity{Subject: "caller"}, nil
+	default:
+		return auth.Identity{}, errors.New("invalid token")
+	}
+}
+
+func TestMountAdminRoutes(t *testing.T) {
+	logger := zerolog.Nop()
+	paths := []string{"/v1/datasets/diff", "/v1/admin/apikeys/partner-acme/usage"}
+
+	tests := []struct {
+		name     string
+		verifier auth.Verifier
+		token    string
+		status   int
+	}{
+		{name: "auth disabled", verifier: nil, status: http.StatusNotFound},
+		{name: "missing token", verifier: tokenVerifier{}, status: http.StatusUnauthorized},
+		{name: "invalid token", verifier: tokenVerifier{}, token: "forged", status: http.StatusUnauthorized},
+		{name: "missing admin scope", verifier: tokenVerifier{}, token: "caller", status: http.StatusForbidden},
+		{name: "admin", verifier: tokenVerifier{}, token: "admin", status: http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router := chi.NewRouter()
+			mountAdminRoutes(router, test.verifier, &logger, func(r chi.Router) {
+				for _, path := range paths {
+					r.Get(path, func(w http.ResponseWriter, r *http.Request) {})
+				}
+			})
+
+			for _, path := range paths {
+				req := httptest.NewRequest(http.MethodGet, path, nil)
+				if test.token != "" {
+					req.Header.Set("Authorization", "Bearer "+test.token)
+				}
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				assert.Equal(t, test.status, w.Code, path)
+			}
+		})
+	}
+}
+
+func TestNewTracer(t *testing.T) {
+	logger := zerolog.Nop()
+
+	tracer, err := newTracer(&Config{}, &logger)
+	assert.NoError(t, err)
+	assert.Nil(t, tracer)
+
+	tracer, err = newTracer(&Config{TracesExporter: "none"}, &logger)
+	assert.NoError(t, err)
+	assert.Nil(t, tracer)
+
+	tracer, err = newTracer(&Config{TracesExporter: "console"}, &logger)
+	assert.NoError(t, err)
+	assert.NotNil(t, tracer)
+
+	tracer, err = newTracer(&Config{TracesExporter: "otlp", OTLPEndpoint: "http://collector:4318"}, &logger)
+	assert.NoError(t, err)
+	assert.NotNil(t, tracer)
+
+	_, err = newTracer(&Config{TracesExporter: "jaeger"}, &logger)
+	assert.Error(t, err)
+}
@@ -0,0 +1,343 @@
+package metrics
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the upper bounds in seconds of the latency histograms, from 5ms to 10s
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// maxEMFValues is how many observations of a histogram an embedded metric format line carries, CloudWatch's limit
+const maxEMFValues = 100
+
+// Default is the registry the handlers, services and db clients record into
+var Default = NewRegistry()
+
+// metric is a counter or histogram of a registry
+type metric interface {
+	writePrometheus(w io.Writer)
+	writeEMF(w io.Writer, namespace string, timestamp int64) error
+}
+
+// Registry holds counters and histograms by name. They are written out cumulatively in the Prometheus text format
+// for scraping, or as what changed since the last flush in CloudWatch's embedded metric format, for Lambda.
+type Registry struct {
+	mu      sync.Mutex
+	names   map[string]bool
+	metrics []metric
+}
+
+// NewRegistry constructs and gives back an empty registry
+func NewRegistry() *Registry {
+	return &Registry{names: map[string]bool{}}
+}
+
+// add registers a metric, panicking when its name is taken as it is a programming error
+func (r *Registry) add(name string, m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[name] {
+		panic(fmt.Sprintf("metrics: %s registered twice", name))
+	}
+	r.names[name] = true
+	r.metrics = append(r.metrics, m)
+}
+
+func (r *Registry) all() []metric {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]metric(nil), r.metrics...)
+}
+
+// WritePrometheus writes every metric in the Prometheus text exposition format
+func (r *Registry) WritePrometheus(w io.Writer) {
+	for _, m := range r.all() {
+		m.writePrometheus(w)
+	}
+}
+
+// WriteEMF writes a CloudWatch embedded metric format line per series that changed since the last flush: the
+// increase of a counter, or the observations of a histogram. Lambda ships the lines to CloudWatch as metrics.
+func (r *Registry) WriteEMF(w io.Writer, namespace string, timestamp time.Time) error {
+	milliseconds := timestamp.UnixNano() / int64(time.Millisecond)
+	for _, m := range r.all() {
+		if err := m.writeEMF(w, namespace, milliseconds); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler serves the registry in the Prometheus text exposition format
+func Handler(r *Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WritePrometheus(w)
+	})
+}
+
+// series are the values of a metric by their label values
+type series struct {
+	name       string
+	help       string
+	labelNames []string
+}
+
+// key gives back the key of the label values, panicking when they don't match the label names
+func (s series) key(labelValues []string) string {
+	if len(labelValues) != len(s.labelNames) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values, got %d", s.name, len(s.labelNames), len(labelValues)))
+	}
+	return strings.Join(labelValues, "\xff")
+}
+
+// labels formats the label pairs of a series in the Prometheus text format, with extra pairs appended
+func (s series) labels(labelValues []string, extra ...string) string {
+	pairs := make([]string, 0, len(labelValues)+len(extra)/2)
+	for i, name := range s.labelNames {
+		pairs = append(pairs, name+`="`+escapeLabel(labelValues[i])+`"`)
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, extra[i]+`="`+extra[i+1]+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func (s series) header(w io.Writer, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", s.name, s.help, s.name, kind)
+}
+
+// emf writes an embedded metric format line of the metric's value for the label values
+func (s series) emf(w io.Writer, namespace string, timestamp int64, unit string, labelValues []string, value interface{}) error {
+	line := map[string]interface{}{
+		"_aws": map[string]interface{}{
+			"Timestamp": timestamp,
+			"CloudWatchMetrics": []interface{}{map[string]interface{}{
+				"Namespace":  namespace,
+				"Dimensions": [][]string{s.labelNames},
+				"Metrics":    []interface{}{map[string]string{"Name": s.name, "Unit": unit}},
+			}},
+		},
+		s.name: value,
+	}
+	for i, name := range s.labelNames {
+		line[name] = labelValues[i]
+	}
+	raw, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(raw, '\n'))
+	return err
+}
+
+// Counter is a count going up, by label values
+type Counter struct {
+	series
+	mu     sync.Mutex
+	values map[string]*counterValue
+}
+
+type counterValue struct {
+	labelValues []string
+	value       float64
+	flushed     float64
+}
+
+// NewCounter registers and gives back a counter with the label names
+func (r *Registry) NewCounter(name string, help string, labelNames ...string) *Counter {
+	c := &Counter{series: series{name: name, help: help, labelNames: labelNames}, values: map[string]*counterValue{}}
+	r.add(name, c)
+	return c
+}
+
+// Inc adds one to the count of the label values
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds to the count of the label values
+func (c *Counter) Add(value float64, labelValues ...string) {
+	key := c.key(labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.values[key]
+	if !ok {
+		v = &counterValue{labelValues: append([]string(nil), labelValues...)}
+		c.values[key] = v
+	}
+	v.value += value
+}
+
+// Value gives back the count of the label values
+func (c *Counter) Value(labelValues ...string) float64 {
+	key := c.key(labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if v, ok := c.values[key]; ok {
+		return v.value
+	}
+	return 0
+}
+
+func (c *Counter) sorted() []*counterValue {
+	values := make([]*counterValue, 0, len(c.values))
+	for _, v := range c.values {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool { return c.key(values[i].labelValues) < c.key(values[j].labelValues) })
+	return values
+}
+
+func (c *Counter) writePrometheus(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.header(w, "counter")
+	for _, v := range c.sorted() {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, c.labels(v.labelValues), formatFloat(v.value))
+	}
+}
+
+func (c *Counter) writeEMF(w io.Writer, namespace string, timestamp int64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, v := range c.sorted() {
+		if v.value == v.flushed {
+			continue
+		}
+		if err := c.emf(w, namespace, timestamp, "Count", v.labelValues, v.value-v.flushed); err != nil {
+			return err
+		}
+		v.flushed = v.value
+	}
+	return nil
+}
+
+// Histogram counts observations into buckets, by label values
+type Histogram struct {
+	series
+	buckets []float64
+	mu      sync.Mutex
+	values  map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labelValues []string
+	counts      []uint64
+	count       uint64
+	sum         float64
+	// pending are the observations since the last embedded metric format flush
+	pending []float64
+}
+
+// NewHistogram registers and gives back a histogram with the bucket upper bounds, in increasing order, and the
+// label names
+func (r *Registry) NewHistogram(name string, help string, buckets []float64, labelNames ...string) *Histogram {
+	h := &Histogram{series: series{name: name, help: help, labelNames: labelNames}, buckets: buckets, values: map[string]*histogramValue{}}
+	r.add(name, h)
+	return h
+}
+
+// Observe records an observation for the label values
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	key := h.key(labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	v, ok := h.values[key]
+	if !ok {
+		v = &histogramValue{labelValues: append([]string(nil), labelValues...), counts: make([]uint64, len(h.buckets))}
+		h.values[key] = v
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			v.counts[i]++
+			break
+		}
+	}
+	v.count++
+	v.sum += value
+	if len(v.pending) < maxEMFValues {
+		v.pending = append(v.pending, value)
+	}
+}
+
+// Count gives back how many observations the label values have
+func (h *Histogram) Count(labelValues ...string) uint64 {
+	key := h.key(labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if v, ok := h.values[key]; ok {
+		return v.count
+	}
+	return 0
+}
+
+func (h *Histogram) sorted() []*histogramValue {
+	values := make([]*histogramValue, 0, len(h.values))
+	for _, v := range h.values {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool { return h.key(values[i].labelValues) < h.key(values[j].labelValues) })
+	return values
+}
+
+func (h *Histogram) writePrometheus(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.header(w, "histogram")
+	for _, v := range h.sorted() {
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += v.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labels(v.labelValues, "le", formatFloat(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.labels(v.labelValues, "le", "+Inf"), v.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.labels(v.labelValues), formatFloat(v.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.labels(v.labelValues), v.count)
+	}
+}
+
+// writeEMF writes the observations themselves, so CloudWatch can give percentiles of them. Past maxEMFValues
+// observations in between flushes only the Prometheus output counts them.
+func (h *Histogram) writeEMF(w io.Writer, namespace string, timestamp int64) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	unit := "None"
+	if strings.HasSuffix(h.name, "_seconds") {
+		unit = "Seconds"
+	}
+	for _, v := range h.sorted() {
+		if len(v.pending) == 0 {
+			continue
+		}
+		if err := h.emf(w, namespace, timestamp, unit, v.labelValues, v.pending); err != nil {
+			return err
+		}
+		v.pending = nil
+	}
+	return nil
+}
+
+func formatFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// escapeLabel escapes a label value for the Prometheus text format
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
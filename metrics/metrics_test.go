@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	registry := NewRegistry()
+	requests := registry.NewCounter("requests_total", "Requests served", "route", "status")
+	latency := registry.NewHistogram("latency_seconds", "Call latency", []float64{.1, 1}, "operation")
+
+	requests.Inc("/v1/carriers", "200")
+	requests.Add(2, "/v1/coveragecheck", "400")
+	requests.Inc("/v1/carriers", "200")
+	latency.Observe(.05, "GetItem")
+	latency.Observe(.5, "GetItem")
+	latency.Observe(3, "GetItem")
+
+	var out bytes.Buffer
+	registry.WritePrometheus(&out)
+
+	assert.Equal(t, `# HELP requests_total Requests served
+# TYPE requests_total counter
+requests_total{route="/v1/carriers",status="200"} 2
+requests_total{route="/v1/coveragecheck",status="400"} 2
+# HELP latency_seconds Call latency
+# TYPE latency_seconds histogram
+latency_seconds_bucket{operation="GetItem",le="0.1"} 1
+latency_seconds_bucket{operation="GetItem",le="1"} 2
+latency_seconds_bucket{operation="GetItem",le="+Inf"} 3
+latency_seconds_sum{operation="GetItem"} 3.55
+latency_seconds_count{operation="GetItem"} 3
+`, out.String())
+	assert.Equal(t, float64(2), requests.Value("/v1/carriers", "200"))
+	assert.Equal(t, float64(0), requests.Value("/v1/carriers", "500"))
+	assert.Equal(t, uint64(3), latency.Count("GetItem"))
+}
+
+func TestWriteEMF(t *testing.T) {
+	registry := NewRegistry()
+	requests := registry.NewCounter("requests_total", "Requests served", "route")
+	latency := registry.NewHistogram("latency_seconds", "Call latency", DefaultBuckets, "operation")
+	timestamp := time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	requests.Add(3, "/v1/carriers")
+	latency.Observe(.25, "GetItem")
+	latency.Observe(.5, "GetItem")
+
+	var out bytes.Buffer
+	assert.NoError(t, registry.WriteEMF(&out, "coverage", timestamp))
+	assert.Equal(t, `{"_aws":{"CloudWatchMetrics":[{"Dimensions":[["route"]],"Metrics":[{"Name":"requests_total","Unit":"Count"}],"Namespace":"coverage"}],"Timestamp":1541030400000},"requests_total":3,"route":"/v1/carriers"}
+{"_aws":{"CloudWatchMetrics":[{"Dimensions":[["operation"]],"Metrics":[{"Name":"latency_seconds","Unit":"Seconds"}],"Namespace":"coverage"}],"Timestamp":1541030400000},"latency_seconds":[0.25,0.5],"operation":"GetItem"}
+`, out.String())
+
+	// a flush only carries what changed since the previous one
+	out.Reset()
+	assert.NoError(t, registry.WriteEMF(&out, "coverage", timestamp))
+	assert.Equal(t, "", out.String())
+
+	requests.Inc("/v1/carriers")
+	assert.NoError(t, registry.WriteEMF(&out, "coverage", timestamp))
+	assert.Contains(t, out.String(), `"requests_total":1,`)
+}
+
+func TestHandler(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounter("requests_total", "Requests served").Inc()
+
+	w := httptest.NewRecorder()
+	Handler(registry).ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "# HELP requests_total Requests served\n# TYPE requests_total counter\nrequests_total 1\n", w.Body.String())
+}
+
+func TestRegistryMisuse(t *testing.T) {
+	registry := NewRegistry()
+	requests := registry.NewCounter("requests_total", "Requests served", "route")
+
+	assert.Panics(t, func() { registry.NewCounter("requests_total", "Requests served") })
+	assert.Panics(t, func() { requests.Inc("/v1/carriers", "200") })
+}
+
+func TestEscapeLabel(t *testing.T) {
+	assert.Equal(t, `a\"b\\c\n`, escapeLabel("a\"b\\c\n"))
+}
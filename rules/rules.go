@@ -0,0 +1,246 @@
+package rules
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+)
+
+// Operators supported by a comparison rule
+const (
+	GreaterThan        = "gt"
+	GreaterThanOrEqual = "gte"
+	LessThan           = "lt"
+	LessThanOrEqual    = "lte"
+	Equal              = "eq"
+	NotEqual           = "ne"
+	Present            = "present"
+)
+
+// RuleSet is a versioned set of coverage rules keyed by the carrier's dynamodb sort key (e.g. "sprint")
+type RuleSet struct {
+	Version  string          `json:"version"`
+	Carriers map[string]Rule `json:"carriers"`
+}
+
+// Rule decides whether a zipcode is covered from the attributes of its coverage item.
+// A rule either combines child rules with And/Or or compares a single Attribute: numeric operators
+// compare against Threshold, eq/ne against Value and present only requires the attribute to be non-empty.
+type Rule struct {
+	And       []Rule   `json:"and,omitempty"`
+	Or        []Rule   `json:"or,omitempty"`
+	Attribute string   `json:"attribute,omitempty"`
+	Operator  string   `json:"operator,omitempty"`
+	Threshold *float64 `json:"threshold,omitempty"`
+	Value     string   `json:"value,omitempty"`
+}
+
+// Default gives back the rules the service shipped with: Sprint needs more than 50% on both cur_pct_cov
+// and lte_4g_pctcov, Verizon needs more than 50% on vzelte with vze_lte_ind set to Y and a state,
+// T-Mobile needs more than 50% on lte_pct_cov and a state and AT&T needs more than 50% on lte_pct_cov
+// with lte_ind set to Y.
+func Default() RuleSet {
+	return RuleSet{
+		Version: "default-1",
+		Carriers: map[string]Rule{
+			"sprint": {And: []Rule{
+				{Attribute: "cur_pct_cov", Operator: GreaterThan, Threshold: threshold(50)},
+				{Attribute: "lte_4g_pctcov", Operator: GreaterThan, Threshold: threshold(50)},
+			}},
+			"verizon": {And: []Rule{
+				{Attribute: "vzelte", Operator: GreaterThan, Threshold: threshold(50)},
+				{Attribute: "vze_lte_ind", Operator: Equal, Value: "Y"},
+				{Attribute: "state", Operator: Present},
+			}},
+			"tmobile": {And: []Rule{
+				{Attribute: "lte_pct_cov", Operator: GreaterThan, Threshold: threshold(50)},
+				{Attribute: "state", Operator: Present},
+			}},
+			"att": {And: []Rule{
+				{Attribute: "lte_pct_cov", Operator: GreaterThan, Threshold: threshold(50)},
+				{Attribute: "lte_ind", Operator: Equal, Value: "Y"},
+			}},
+		},
+	}
+}
+
+// Load reads the rule set from a JSON file, or from the raw JSON when no file is given, falling back
+// to the Default rules when neither is configured. The rule set is validated before it is given back.
+func Load(rawRules string, rulesFile string) (RuleSet, error) {
+	if rulesFile == "" && rawRules == "" {
+		return Default(), nil
+	}
+
+	data := []byte(rawRules)
+	if rulesFile != "" {
+		var err error
+		data, err = ioutil.ReadFile(rulesFile)
+		if err != nil {
+			return RuleSet{}, err
+		}
+	}
+
+	ruleSet := RuleSet{}
+	if err := json.Unmarshal(data, &ruleSet); err != nil {
+		return RuleSet{}, fmt.Errorf("unable to parse coverage rules: %v", err)
+	}
+	if err := ruleSet.Validate(); err != nil {
+		return RuleSet{}, err
+	}
+	return ruleSet, nil
+}
+
+// Validate checks the rule set has a version and that every carrier's rule is well formed
+func (rs RuleSet) Validate() error {
+	if rs.Version == "" {
+		return errors.New("coverage rules are missing a version")
+	}
+	if len(rs.Carriers) == 0 {
+		return errors.New("coverage rules do not define any carrier")
+	}
+	for carrier, rule := range rs.Carriers {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("invalid coverage rule for carrier %s: %v", carrier, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks the rule and all of its children are well formed
+func (r Rule) Validate() error {
+	kinds := 0
+	if len(r.And) > 0 {
+		kinds++
+	}
+	if len(r.Or) > 0 {
+		kinds++
+	}
+	if r.Attribute != "" {
+		kinds++
+	}
+	if kinds != 1 {
+		return errors.New("a rule needs exactly one of and, or, attribute")
+	}
+
+	for _, child := range r.children() {
+		if err := child.Validate(); err != nil {
+			return err
+		}
+	}
+	if r.Attribute == "" {
+		return nil
+	}
+
+	switch r.Operator {
+	case GreaterThan, GreaterThanOrEqual, LessThan, LessThanOrEqual:
+		if r.Threshold == nil {
+			return fmt.Errorf("operator %s on %s needs a threshold", r.Operator, r.Attribute)
+		}
+	case Equal, NotEqual:
+		if r.Value == "" {
+			return fmt.Errorf("operator %s on %s needs a value", r.Operator, r.Attribute)
+		}
+	case Present:
+	default:
+		return fmt.Errorf("unknown operator %q on %s", r.Operator, r.Attribute)
+	}
+	return nil
+}
+
+// Attributes lists the item attributes the rule reads, in the order they first appear
+func (r Rule) Attributes() []string {
+	var attributes []string
+	seen := map[string]bool{}
+	r.collectAttributes(&attributes, seen)
+	return attributes
+}
+
+func (r Rule) collectAttributes(attributes *[]string, seen map[string]bool) {
+	if r.Attribute != "" && !seen[r.Attribute] {
+		seen[r.Attribute] = true
+		*attributes = append(*attributes, r.Attribute)
+	}
+	for _, child := range r.children() {
+		child.collectAttributes(attributes, seen)
+	}
+}
+
+// Evaluate applies the rule to the attributes of a coverage item. A missing or empty attribute fails
+// its comparison, while a non numeric value under a numeric operator is reported as an error.
+func (r Rule) Evaluate(attributes map[string]string) (bool, error) {
+	switch {
+	case len(r.And) > 0:
+		for _, child := range r.And {
+			ok, err := child.Evaluate(attributes)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	case len(r.Or) > 0:
+		var firstErr error
+		for _, child := range r.Or {
+			ok, err := child.Evaluate(attributes)
+			if ok {
+				return true, nil
+			}
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		return false, firstErr
+	case r.Attribute != "":
+		return r.compare(attributes[r.Attribute])
+	default:
+		return false, errors.New("empty coverage rule")
+	}
+}
+
+func (r Rule) compare(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+
+	switch r.Operator {
+	case Present:
+		return true, nil
+	case Equal:
+		return value == r.Value, nil
+	case NotEqual:
+		return value != r.Value, nil
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return false, fmt.Errorf("illegal value %q in %s", value, r.Attribute)
+	}
+	if r.Threshold == nil {
+		return false, fmt.Errorf("operator %s on %s needs a threshold", r.Operator, r.Attribute)
+	}
+
+	switch r.Operator {
+	case GreaterThan:
+		return number > *r.Threshold, nil
+	case GreaterThanOrEqual:
+		return number >= *r.Threshold, nil
+	case LessThan:
+		return number < *r.Threshold, nil
+	case LessThanOrEqual:
+		return number <= *r.Threshold, nil
+	}
+	return false, fmt.Errorf("unknown operator %q on %s", r.Operator, r.Attribute)
+}
+
+// children gives back the combined rules, only one of And and Or is set on a valid rule
+func (r Rule) children() []Rule {
+	if len(r.And) > 0 {
+		return r.And
+	}
+	return r.Or
+}
+
+func threshold(value float64) *float64 {
+	return &value
+}
@@ -0,0 +1,212 @@
+package rules
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultRules(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		carrier       string
+		attributes    map[string]string
+		expectCovered bool
+		expectError   bool
+	}{
+		{
+			desc:          "sprint zipcode with coverage",
+			carrier:       "sprint",
+			attributes:    map[string]string{"cur_pct_cov": "100", "lte_4g_pctcov": "100"},
+			expectCovered: true,
+		},
+		{
+			desc:          "sprint zipcode at the threshold",
+			carrier:       "sprint",
+			attributes:    map[string]string{"cur_pct_cov": "50", "lte_4g_pctcov": "100"},
+			expectCovered: false,
+		},
+		{
+			desc:          "sprint zipcode with missing attributes",
+			carrier:       "sprint",
+			attributes:    map[string]string{"cur_pct_cov": "", "lte_4g_pctcov": ""},
+			expectCovered: false,
+		},
+		{
+			desc:        "sprint zipcode with illegal percentage",
+			carrier:     "sprint",
+			attributes:  map[string]string{"cur_pct_cov": "abc", "lte_4g_pctcov": "100"},
+			expectError: true,
+		},
+		{
+			desc:          "verizon zipcode with coverage",
+			carrier:       "verizon",
+			attributes:    map[string]string{"vzelte": "100", "vze_lte_ind": "Y", "state": "CA"},
+			expectCovered: true,
+		},
+		{
+			desc:          "verizon zipcode with lte indicator off",
+			carrier:       "verizon",
+			attributes:    map[string]string{"vzelte": "100", "vze_lte_ind": "N", "state": "CA"},
+			expectCovered: false,
+		},
+		{
+			desc:          "verizon zipcode without state",
+			carrier:       "verizon",
+			attributes:    map[string]string{"vzelte": "100", "vze_lte_ind": "Y"},
+			expectCovered: false,
+		},
+		{
+			desc:          "tmobile zipcode with coverage",
+			carrier:       "tmobile",
+			attributes:    map[string]string{"lte_pct_cov": "75", "state": "WA"},
+			expectCovered: true,
+		},
+		{
+			desc:          "tmobile zipcode without state",
+			carrier:       "tmobile",
+			attributes:    map[string]string{"lte_pct_cov": "75"},
+			expectCovered: false,
+		},
+		{
+			desc:          "att zipcode with coverage",
+			carrier:       "att",
+			attributes:    map[string]string{"lte_pct_cov": "75", "lte_ind": "Y"},
+			expectCovered: true,
+		},
+		{
+			desc:          "att zipcode with lte indicator off",
+			carrier:       "att",
+			attributes:    map[string]string{"lte_pct_cov": "75", "lte_ind": "N"},
+			expectCovered: false,
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			covered, err := Default().Carriers[tC.carrier].Evaluate(tC.attributes)
+
+			if tC.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tC.expectCovered, covered)
+		})
+	}
+}
+
+func TestOrRule(t *testing.T) {
+	rule := Rule{Or: []Rule{
+		{Attribute: "lte", Operator: GreaterThanOrEqual, Threshold: threshold(80)},
+		{And: []Rule{
+			{Attribute: "evdo", Operator: GreaterThan, Threshold: threshold(90)},
+			{Attribute: "ind", Operator: NotEqual, Value: "N"},
+		}},
+	}}
+
+	covered, err := rule.Evaluate(map[string]string{"lte": "80"})
+	assert.NoError(t, err)
+	assert.True(t, covered)
+
+	covered, err = rule.Evaluate(map[string]string{"lte": "10", "evdo": "95", "ind": "Y"})
+	assert.NoError(t, err)
+	assert.True(t, covered)
+
+	covered, err = rule.Evaluate(map[string]string{"lte": "10", "evdo": "95", "ind": "N"})
+	assert.NoError(t, err)
+	assert.False(t, covered)
+
+	assert.Equal(t, []string{"lte", "evdo", "ind"}, rule.Attributes())
+}
+
+func TestRuleValidate(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		rule        Rule
+		expectError bool
+	}{
+		{
+			desc: "valid comparison",
+			rule: Rule{Attribute: "lte", Operator: LessThan, Threshold: threshold(10)},
+		},
+		{
+			desc:        "empty rule",
+			rule:        Rule{},
+			expectError: true,
+		},
+		{
+			desc:        "rule with both and and attribute",
+			rule:        Rule{Attribute: "lte", Operator: Present, And: []Rule{{Attribute: "evdo", Operator: Present}}},
+			expectError: true,
+		},
+		{
+			desc:        "numeric operator without threshold",
+			rule:        Rule{Attribute: "lte", Operator: GreaterThan},
+			expectError: true,
+		},
+		{
+			desc:        "equality without value",
+			rule:        Rule{Attribute: "ind", Operator: Equal},
+			expectError: true,
+		},
+		{
+			desc:        "unknown operator",
+			rule:        Rule{Attribute: "lte", Operator: "between", Threshold: threshold(10)},
+			expectError: true,
+		},
+		{
+			desc:        "invalid child",
+			rule:        Rule{Or: []Rule{{Attribute: "lte", Operator: Present}, {Attribute: "evdo"}}},
+			expectError: true,
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			err := tC.rule.Validate()
+
+			if tC.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	rawRules := `{"version":"2018-11-01","carriers":{"sprint":{"attribute":"lte_4g_pctcov","operator":"gte","threshold":70}}}`
+
+	ruleSet, err := Load("", "")
+	assert.NoError(t, err)
+	assert.Equal(t, Default(), ruleSet)
+
+	ruleSet, err = Load(rawRules, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "2018-11-01", ruleSet.Version)
+	assert.Equal(t, []string{"lte_4g_pctcov"}, ruleSet.Carriers["sprint"].Attributes())
+
+	file, _ := ioutil.TempFile("", "rules")
+	defer os.Remove(file.Name())
+	file.WriteString(rawRules)
+	file.Close()
+
+	ruleSet, err = Load("", file.Name())
+	assert.NoError(t, err)
+	assert.Equal(t, "2018-11-01", ruleSet.Version)
+
+	_, err = Load(`{"carriers":{"sprint":{"attribute":"lte_4g_pctcov","operator":"gte","threshold":70}}}`, "")
+	assert.Error(t, err)
+
+	_, err = Load(`{"version":"1","carriers":{"sprint":{"attribute":"lte_4g_pctcov","operator":"gte"}}}`, "")
+	assert.Error(t, err)
+
+	_, err = Load(`not json`, "")
+	assert.Error(t, err)
+
+	_, err = Load("", "/does/not/exist.json")
+	assert.Error(t, err)
+}
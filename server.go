@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"bitbucket.org/credomobile/frink"
+)
+
+// shutdownTimeout is how long in flight requests get to finish once the server is asked to stop
+const shutdownTimeout = 10 * time.Second
+
+// serveHTTP serves the app's routes on a plain http server until SIGINT or SIGTERM, for local development
+// and integration tests
+func serveHTTP(app *frink.App, addr string) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		app.Logger.Fatal().Err(err).Msgf("unable to listen on %s", addr)
+	}
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	server := &http.Server{
+		Handler:      app.Router,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 30 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+	app.Logger.Info().Msgf("listening on %s", listener.Addr())
+	if err := serve(server, listener, stop, shutdownTimeout); err != nil {
+		app.Logger.Fatal().Err(err).Msg("http server failed")
+	}
+	app.Logger.Info().Msg("http server stopped")
+}
+
+// serve serves on the listener until a signal arrives on stop, then shuts the server down gracefully
+func serve(server *http.Server, listener net.Listener, stop <-chan os.Signal, timeout time.Duration) error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-errs:
+		return err
+	case <-stop:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return server.Shutdown(ctx)
+}
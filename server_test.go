@@ -0,0 +1,49 @@
+package main
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeShutsDownGracefully(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+
+	started := make(chan bool)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("done"))
+	})
+
+	stop := make(chan os.Signal, 1)
+	served := make(chan error)
+	go func() {
+		served <- serve(&http.Server{Handler: mux}, listener, stop, time.Second)
+	}()
+
+	responses := make(chan string)
+	go func() {
+		res, err := http.Get("http://" + listener.Addr().String() + "/slow")
+		if err != nil {
+			responses <- err.Error()
+			return
+		}
+		body, _ := ioutil.ReadAll(res.Body)
+		responses <- string(body)
+	}()
+
+	<-started
+	stop <- syscall.SIGTERM
+
+	assert.Equal(t, "done", <-responses)
+	assert.NoError(t, <-served)
+}
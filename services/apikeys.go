@@ -0,0 +1,336 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// apiKeyPrefix starts every issued key, so a leaked one is easy to recognize
+	apiKeyPrefix = "ck_"
+	// keyPrefixLength is how much of a key is kept in the clear to tell keys apart
+	keyPrefixLength = 8
+	// RotationGracePeriod is how long the key replaced by a rotation keeps working, for the client to switch over
+	RotationGracePeriod = 24 * time.Hour
+	// apiKeyCacheTTL is how long a resolved key is trusted without reading the table again, and so how long a
+	// revoked key may keep working on other instances
+	apiKeyCacheTTL = time.Minute
+	usageDayFormat = "2006-01-02"
+)
+
+var (
+	// ErrInvalidAPIKey is given back for a key that is unknown, revoked or past the grace period of a rotation
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	// ErrRateLimited is given back for a request over the client's rate limit
+	ErrRateLimited = errors.New("rate limit exceeded")
+	// ErrQuotaExceeded is given back for a request over the client's daily quota
+	ErrQuotaExceeded = errors.New("daily quota exceeded")
+)
+
+// APIKeys resolves the api keys of the requests to their clients, applying the clients' limits, and manages the
+// clients' keys. Authorize gives back, along with ErrRateLimited and ErrQuotaExceeded, how long to wait before
+// trying again.
+type APIKeys interface {
+	Authorize(ctx context.Context, key string) (string, time.Duration, error)
+	Issue(ctx context.Context, request entity.APIClientRequest) (entity.APIKeyResponse, error)
+	Rotate(ctx context.Context, clientID string) (entity.APIKeyResponse, bool, error)
+	Revoke(ctx context.Context, clientID string) (entity.APIKeyResponse, bool, error)
+	Usage(ctx context.Context, clientID string, days int) (entity.APIUsageResponse, bool, error)
+}
+
+// resolvedKey is a key resolved to its client, trusted until expires
+type resolvedKey struct {
+	client  dbclient.APIClient
+	expires time.Time
+}
+
+// tokenBucket is the rate limit state of a client, holding up to a second worth of requests
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type apiKeys struct {
+	dbClient dbclient.APIKeyStore
+	now      func() time.Time
+	random   io.Reader
+
+	mu      sync.Mutex
+	keys    map[string]resolvedKey
+	buckets map[string]*tokenBucket
+}
+
+//NewAPIKeys constructs and gives back api keys service
+func NewAPIKeys(dbClient dbclient.APIKeyStore, logger *zerolog.Logger) (*apiKeys, error) {
+	if dbClient == nil {
+		return nil, errors.New("Invalid api key store")
+	}
+
+	return &apiKeys{
+		dbClient: dbClient,
+		now:      time.Now,
+		random:   rand.Reader,
+		keys:     map[string]resolvedKey{},
+		buckets:  map[string]*tokenBucket{},
+	}, nil
+}
+
+// Authorize gives back the id of the client the key belongs to. The client's rate limit is applied by each instance
+// on its own, while its usage is counted in the table and so its daily quota holds across instances. Requests over
+// the quota are counted too, requests over the rate limit aren't.
+func (a *apiKeys) Authorize(ctx context.Context, key string) (string, time.Duration, error) {
+	client, err := a.resolve(ctx, key)
+	if err != nil {
+		return "", 0, err
+	}
+
+	now := a.now()
+	if retryAfter, ok := a.take(client, now); !ok {
+		return client.ID, retryAfter, ErrRateLimited
+	}
+
+	day := now.UTC().Format(usageDayFormat)
+	requests, err := a.dbClient.AddUsage(ctx, client.ID, day, 1)
+	if err != nil {
+		return "", 0, err
+	}
+	if client.DailyQuota > 0 && requests > client.DailyQuota {
+		midnight := now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+		return client.ID, midnight.Sub(now), ErrQuotaExceeded
+	}
+	return client.ID, 0, nil
+}
+
+// resolve gives back the client of the key, from the cache when it was resolved within apiKeyCacheTTL. Unknown keys
+// aren't cached, so a key issued through another instance works right away.
+func (a *apiKeys) resolve(ctx context.Context, key string) (dbclient.APIClient, error) {
+	hash := hashKey(key)
+	now := a.now()
+
+	a.mu.Lock()
+	resolved, ok := a.keys[hash]
+	a.mu.Unlock()
+	if !ok || !now.Before(resolved.expires) {
+		client, found, err := a.dbClient.GetClientByKey(ctx, hash)
+		if err != nil {
+			return dbclient.APIClient{}, err
+		}
+		if !found {
+			return dbclient.APIClient{}, ErrInvalidAPIKey
+		}
+		resolved = resolvedKey{client: client, expires: now.Add(apiKeyCacheTTL)}
+		a.mu.Lock()
+		a.keys[hash] = resolved
+		a.mu.Unlock()
+	}
+
+	// the client item is the source of truth, a key lookup it doesn't list is left over from a revocation
+	for _, clientKey := range resolved.client.Keys {
+		if clientKey.Hash == hash && !clientKey.Expired(now) {
+			return resolved.client, nil
+		}
+	}
+	return dbclient.APIClient{}, ErrInvalidAPIKey
+}
+
+// take takes a request off the client's token bucket, or gives back how long until there is one
+func (a *apiKeys) take(client dbclient.APIClient, now time.Time) (time.Duration, bool) {
+	if client.RateLimit <= 0 {
+		return 0, true
+	}
+	burst := math.Max(client.RateLimit, 1)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	bucket, ok := a.buckets[client.ID]
+	if !ok {
+		bucket = &tokenBucket{tokens: burst, last: now}
+		a.buckets[client.ID] = bucket
+	}
+	bucket.tokens = math.Min(burst, bucket.tokens+now.Sub(bucket.last).Seconds()*client.RateLimit)
+	bucket.last = now
+	if bucket.tokens < 1 {
+		return time.Duration((1 - bucket.tokens) / client.RateLimit * float64(time.Second)), false
+	}
+	bucket.tokens--
+	return 0, true
+}
+
+// Issue creates the client with a new key, failing with dbclient.ErrClientExists when the client id is taken
+func (a *apiKeys) Issue(ctx context.Context, request entity.APIClientRequest) (entity.APIKeyResponse, error) {
+	zerolog.Ctx(ctx).Info().Msgf("Issuing api key for clientID: %s", request.ClientID)
+
+	key, apiKey, err := a.newKey()
+	if err != nil {
+		return entity.APIKeyResponse{}, err
+	}
+	client := dbclient.APIClient{
+		ID:         request.ClientID,
+		Name:       request.Name,
+		RateLimit:  request.RateLimit,
+		DailyQuota: request.DailyQuota,
+		Keys:       []dbclient.APIKey{apiKey},
+	}
+	if err := a.dbClient.PutClient(ctx, client, true); err != nil {
+		return entity.APIKeyResponse{}, err
+	}
+
+	response := apiKeyResponse(client)
+	response.APIKey = key
+	response.KeyPrefix = apiKey.Prefix
+	return response, nil
+}
+
+// Rotate gives the client a new key. Its current keys keep working for RotationGracePeriod, keys already past their
+// grace period are removed. found is false for an unknown client. It fails with dbclient.ErrClientChanged when the
+// client's keys were changed concurrently, leaving that change in place.
+func (a *apiKeys) Rotate(ctx context.Context, clientID string) (entity.APIKeyResponse, bool, error) {
+	zerolog.Ctx(ctx).Info().Msgf("Rotating api key for clientID: %s", clientID)
+
+	client, found, err := a.dbClient.GetClient(ctx, clientID)
+	if err != nil || !found {
+		return entity.APIKeyResponse{}, false, err
+	}
+	key, apiKey, err := a.newKey()
+	if err != nil {
+		return entity.APIKeyResponse{}, false, err
+	}
+
+	now := a.now()
+	expiresAt := now.Add(RotationGracePeriod).UTC().Truncate(time.Second)
+	var keys []dbclient.APIKey
+	var expired []string
+	for _, clientKey := range client.Keys {
+		if clientKey.Expired(now) {
+			expired = append(expired, clientKey.Hash)
+			continue
+		}
+		if clientKey.ExpiresAt.IsZero() || clientKey.ExpiresAt.After(expiresAt) {
+			clientKey.ExpiresAt = expiresAt
+		}
+		keys = append(keys, clientKey)
+	}
+	client.Keys = append(keys, apiKey)
+	if err := a.dbClient.PutClient(ctx, client, false); err != nil {
+		return entity.APIKeyResponse{}, false, err
+	}
+	if err := a.dbClient.DeleteKeys(ctx, expired); err != nil {
+		return entity.APIKeyResponse{}, false, err
+	}
+	a.forget(client.Keys)
+
+	response := apiKeyResponse(client)
+	response.APIKey = key
+	response.KeyPrefix = apiKey.Prefix
+	if len(keys) > 0 {
+		response.PreviousKeyExpiresAt = &expiresAt
+	}
+	return response, true, nil
+}
+
+// Revoke removes every key of the client at once. Other instances may accept them for up to apiKeyCacheTTL.
+// found is false for an unknown client. It fails with dbclient.ErrClientChanged when the client's keys were changed
+// concurrently, such as by a rotation issuing a key the revocation didn't see.
+func (a *apiKeys) Revoke(ctx context.Context, clientID string) (entity.APIKeyResponse, bool, error) {
+	zerolog.Ctx(ctx).Info().Msgf("Revoking api keys for clientID: %s", clientID)
+
+	client, found, err := a.dbClient.GetClient(ctx, clientID)
+	if err != nil || !found {
+		return entity.APIKeyResponse{}, false, err
+	}
+
+	revoked := client.Keys
+	hashes := make([]string, 0, len(revoked))
+	for _, clientKey := range revoked {
+		hashes = append(hashes, clientKey.Hash)
+	}
+	// the client item stops listing the keys first, which is enough to reject them
+	client.Keys = nil
+	if err := a.dbClient.PutClient(ctx, client, false); err != nil {
+		return entity.APIKeyResponse{}, false, err
+	}
+	if err := a.dbClient.DeleteKeys(ctx, hashes); err != nil {
+		return entity.APIKeyResponse{}, false, err
+	}
+	a.forget(revoked)
+
+	response := apiKeyResponse(client)
+	response.RevokedKeys = len(revoked)
+	return response, true, nil
+}
+
+// Usage gives back how many requests the client made on each of the last days, today included. found is false for
+// an unknown client.
+func (a *apiKeys) Usage(ctx context.Context, clientID string, days int) (entity.APIUsageResponse, bool, error) {
+	client, found, err := a.dbClient.GetClient(ctx, clientID)
+	if err != nil || !found {
+		return entity.APIUsageResponse{}, false, err
+	}
+
+	today := a.now().UTC()
+	dates := make([]string, days)
+	for i := range dates {
+		dates[i] = today.AddDate(0, 0, -i).Format(usageDayFormat)
+	}
+	usage, err := a.dbClient.GetUsage(ctx, clientID, dates)
+	if err != nil {
+		return entity.APIUsageResponse{}, false, err
+	}
+
+	response := entity.APIUsageResponse{ClientID: client.ID, DailyQuota: client.DailyQuota, Days: make([]entity.APIUsageDay, 0, days)}
+	for _, date := range dates {
+		response.Days = append(response.Days, entity.APIUsageDay{Date: date, Requests: usage[date]})
+	}
+	return response, true, nil
+}
+
+// newKey gives back a new random key along with what is stored of it
+func (a *apiKeys) newKey() (string, dbclient.APIKey, error) {
+	raw := make([]byte, 32)
+	if _, err := io.ReadFull(a.random, raw); err != nil {
+		return "", dbclient.APIKey{}, err
+	}
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	return key, dbclient.APIKey{Hash: hashKey(key), Prefix: key[:keyPrefixLength]}, nil
+}
+
+// forget drops the keys from the cache, so this instance sees their change right away
+func (a *apiKeys) forget(keys []dbclient.APIKey) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, clientKey := range keys {
+		delete(a.keys, clientKey.Hash)
+	}
+}
+
+// hashKey gives back the hex encoded sha256 of a key, the form it is stored and looked up in
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// apiKeyResponse gives back the client and its limits, with the prefix of its newest key
+func apiKeyResponse(client dbclient.APIClient) entity.APIKeyResponse {
+	response := entity.APIKeyResponse{
+		ClientID:   client.ID,
+		Name:       client.Name,
+		RateLimit:  client.RateLimit,
+		DailyQuota: client.DailyQuota,
+	}
+	if len(client.Keys) > 0 {
+		response.KeyPrefix = client.Keys[len(client.Keys)-1].Prefix
+	}
+	return response
+}
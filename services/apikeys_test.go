@@ -0,0 +1,240 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestAPIKeys gives back the api keys service over an in-memory store, with a clock the test moves
+func newTestAPIKeys(t *testing.T, now *time.Time) (*apiKeys, *dbclient.MemoryAPIKeyStore) {
+	store := dbclient.NewMemoryAPIKeyStore()
+	service, err := NewAPIKeys(store, nil)
+	assert.NoError(t, err)
+	service.now = func() time.Time { return *now }
+	return service, store
+}
+
+func TestNewAPIKeys(t *testing.T) {
+	_, err := NewAPIKeys(nil, nil)
+	assert.EqualError(t, err, "Invalid api key store")
+}
+
+func TestAPIKeysIssueAndAuthorize(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2018, 11, 1, 12, 0, 0, 0, time.UTC)
+	service, _ := newTestAPIKeys(t, &now)
+	service.random = bytes.NewReader(bytes.Repeat([]byte{0xfb}, 64))
+
+	response, err := service.Issue(ctx, entity.APIClientRequest{ClientID: "partner", Name: "Partner", DailyQuota: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, "ck_-_v7-_v7-_v7-_v7-_v7-_v7-_v7-_v7-_v7-_v7-_s", response.APIKey)
+	assert.Equal(t, "ck_-_v7-", response.KeyPrefix)
+	assert.Equal(t, entity.APIKeyResponse{ClientID: "partner", Name: "Partner", DailyQuota: 2, APIKey: response.APIKey, KeyPrefix: "ck_-_v7-"}, response)
+
+	_, err = service.Issue(ctx, entity.APIClientRequest{ClientID: "partner", Name: "Other"})
+	assert.Equal(t, dbclient.ErrClientExists, err)
+
+	clientID, _, err := service.Authorize(ctx, response.APIKey)
+	assert.NoError(t, err)
+	assert.Equal(t, "partner", clientID)
+	_, _, err = service.Authorize(ctx, response.APIKey)
+	assert.NoError(t, err)
+
+	// the third request of the day is over the quota, until midnight UTC
+	clientID, retryAfter, err := service.Authorize(ctx, response.APIKey)
+	assert.Equal(t, ErrQuotaExceeded, err)
+	assert.Equal(t, "partner", clientID)
+	assert.Equal(t, 12*time.Hour, retryAfter)
+
+	now = now.Add(12 * time.Hour)
+	_, _, err = service.Authorize(ctx, response.APIKey)
+	assert.NoError(t, err)
+
+	_, _, err = service.Authorize(ctx, "ck_unknown")
+	assert.Equal(t, ErrInvalidAPIKey, err)
+}
+
+func TestAPIKeysRateLimit(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2018, 11, 1, 12, 0, 0, 0, time.UTC)
+	service, _ := newTestAPIKeys(t, &now)
+
+	response, err := service.Issue(ctx, entity.APIClientRequest{ClientID: "partner", Name: "Partner", RateLimit: 2})
+	assert.NoError(t, err)
+
+	// a second worth of requests goes through at once
+	for i := 0; i < 2; i++ {
+		_, _, err = service.Authorize(ctx, response.APIKey)
+		assert.NoError(t, err)
+	}
+	_, retryAfter, err := service.Authorize(ctx, response.APIKey)
+	assert.Equal(t, ErrRateLimited, err)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	now = now.Add(500 * time.Millisecond)
+	_, _, err = service.Authorize(ctx, response.APIKey)
+	assert.NoError(t, err)
+
+	// requests over the rate limit aren't counted
+	usage, found, err := service.Usage(ctx, "partner", 1)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []entity.APIUsageDay{{Date: "2018-11-01", Requests: 3}}, usage.Days)
+}
+
+func TestAPIKeysRotate(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2018, 11, 1, 12, 0, 0, 0, time.UTC)
+	service, store := newTestAPIKeys(t, &now)
+
+	issued, err := service.Issue(ctx, entity.APIClientRequest{ClientID: "partner", Name: "Partner"})
+	assert.NoError(t, err)
+	_, _, err = service.Authorize(ctx, issued.APIKey)
+	assert.NoError(t, err)
+
+	rotated, found, err := service.Rotate(ctx, "partner")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.NotEqual(t, issued.APIKey, rotated.APIKey)
+	assert.Equal(t, now.Add(RotationGracePeriod), *rotated.PreviousKeyExpiresAt)
+
+	// both keys work during the grace period, only the new one after it
+	_, _, err = service.Authorize(ctx, issued.APIKey)
+	assert.NoError(t, err)
+	_, _, err = service.Authorize(ctx, rotated.APIKey)
+	assert.NoError(t, err)
+
+	now = now.Add(RotationGracePeriod)
+	_, _, err = service.Authorize(ctx, issued.APIKey)
+	assert.Equal(t, ErrInvalidAPIKey, err)
+	_, _, err = service.Authorize(ctx, rotated.APIKey)
+	assert.NoError(t, err)
+
+	// the expired key is removed by the next rotation
+	_, _, err = service.Rotate(ctx, "partner")
+	assert.NoError(t, err)
+	client, _, err := store.GetClient(ctx, "partner")
+	assert.NoError(t, err)
+	assert.Len(t, client.Keys, 2)
+	_, found, err = store.GetClientByKey(ctx, hashKey(issued.APIKey))
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	_, found, err = service.Rotate(ctx, "unknown")
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestAPIKeysRevoke(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2018, 11, 1, 12, 0, 0, 0, time.UTC)
+	service, _ := newTestAPIKeys(t, &now)
+
+	issued, err := service.Issue(ctx, entity.APIClientRequest{ClientID: "partner", Name: "Partner"})
+	assert.NoError(t, err)
+	rotated, _, err := service.Rotate(ctx, "partner")
+	assert.NoError(t, err)
+	_, _, err = service.Authorize(ctx, rotated.APIKey)
+	assert.NoError(t, err)
+
+	revoked, found, err := service.Revoke(ctx, "partner")
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, entity.APIKeyResponse{ClientID: "partner", Name: "Partner", RevokedKeys: 2}, revoked)
+
+	// the revocation is seen right away by the instance making it, despite the cache
+	_, _, err = service.Authorize(ctx, issued.APIKey)
+	assert.Equal(t, ErrInvalidAPIKey, err)
+	_, _, err = service.Authorize(ctx, rotated.APIKey)
+	assert.Equal(t, ErrInvalidAPIKey, err)
+
+	_, found, err = service.Revoke(ctx, "unknown")
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+// interleavingStore runs interleave once, after the first client read, as a concurrent change would
+type interleavingStore struct {
+	dbclient.APIKeyStore
+	interleave func()
+}
+
+func (s *interleavingStore) GetClient(ctx context.Context, clientID string) (dbclient.APIClient, bool, error) {
+	client, found, err := s.APIKeyStore.GetClient(ctx, clientID)
+	if interleave := s.interleave; interleave != nil {
+		s.interleave = nil
+		interleave()
+	}
+	return client, found, err
+}
+
+func TestAPIKeysConcurrentChanges(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2018, 11, 1, 12, 0, 0, 0, time.UTC)
+	store := &interleavingStore{APIKeyStore: dbclient.NewMemoryAPIKeyStore()}
+	service, err := NewAPIKeys(store, nil)
+	assert.NoError(t, err)
+	service.now = func() time.Time { return now }
+	issued, err := service.Issue(ctx, entity.APIClientRequest{ClientID: "partner", Name: "Partner"})
+	assert.NoError(t, err)
+
+	// a rotation racing another loses rather than dropping the other's key
+	var first entity.APIKeyResponse
+	store.interleave = func() {
+		first, _, err = service.Rotate(ctx, "partner")
+		assert.NoError(t, err)
+	}
+	_, _, err = service.Rotate(ctx, "partner")
+	assert.Equal(t, dbclient.ErrClientChanged, err)
+	_, _, err = service.Authorize(ctx, first.APIKey)
+	assert.NoError(t, err)
+
+	// a rotation racing a revocation doesn't bring the revoked keys back
+	store.interleave = func() {
+		_, _, err = service.Revoke(ctx, "partner")
+		assert.NoError(t, err)
+	}
+	_, _, err = service.Rotate(ctx, "partner")
+	assert.Equal(t, dbclient.ErrClientChanged, err)
+	for _, key := range []string{issued.APIKey, first.APIKey} {
+		_, _, err = service.Authorize(ctx, key)
+		assert.Equal(t, ErrInvalidAPIKey, err)
+	}
+	client, _, err := store.GetClient(ctx, "partner")
+	assert.NoError(t, err)
+	assert.Empty(t, client.Keys)
+}
+
+func TestAPIKeysUsage(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2018, 11, 1, 12, 0, 0, 0, time.UTC)
+	service, store := newTestAPIKeys(t, &now)
+
+	_, err := service.Issue(ctx, entity.APIClientRequest{ClientID: "partner", Name: "Partner", DailyQuota: 100})
+	assert.NoError(t, err)
+	store.AddUsage(ctx, "partner", "2018-11-01", 4)
+	store.AddUsage(ctx, "partner", "2018-10-30", 7)
+
+	usage, found, err := service.Usage(ctx, "partner", 3)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, entity.APIUsageResponse{
+		ClientID:   "partner",
+		DailyQuota: 100,
+		Days: []entity.APIUsageDay{
+			{Date: "2018-11-01", Requests: 4},
+			{Date: "2018-10-31", Requests: 0},
+			{Date: "2018-10-30", Requests: 7},
+		},
+	}, usage)
+
+	_, found, err = service.Usage(ctx, "unknown", 3)
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
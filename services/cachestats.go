@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+)
+
+type CacheStats interface {
+	Stats(ctx context.Context) entity.CacheStatsResponse
+}
+
+type cacheStats struct {
+	cache *dbclient.Cache
+}
+
+// NewCacheStats constructs and gives back a service reporting the cache's hit and miss counts.
+// The cache is nil when caching is disabled.
+func NewCacheStats(cache *dbclient.Cache) CacheStats {
+	return cacheStats{
+		cache: cache,
+	}
+}
+
+func (c cacheStats) Stats(ctx context.Context) entity.CacheStatsResponse {
+	if c.cache == nil {
+		return entity.CacheStatsResponse{}
+	}
+
+	stats := c.cache.Stats()
+	return entity.CacheStatsResponse{
+		Enabled:   true,
+		Hits:      stats.Hits,
+		Misses:    stats.Misses,
+		Evictions: stats.Evictions,
+		Size:      stats.Size,
+	}
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheStats(t *testing.T) {
+	store := dbclient.NewMemoryStore()
+	cache := dbclient.NewCache(store, dbclient.CacheConfig{})
+	marketAreaClient := cache.MarketAreaClient(dbclient.NewMarketAreaClient(store, rules.Default()))
+	marketAreaClient.GetMarketAreas(context.Background(), entity.Sprint, "94105")
+	marketAreaClient.GetMarketAreas(context.Background(), entity.Sprint, "94105")
+
+	response := NewCacheStats(cache).Stats(context.Background())
+
+	assert.Equal(t, entity.CacheStatsResponse{Enabled: true, Hits: 1, Misses: 1, Size: 1}, response)
+}
+
+func TestCacheStatsDisabled(t *testing.T) {
+	response := NewCacheStats(nil).Stats(context.Background())
+
+	assert.Equal(t, entity.CacheStatsResponse{}, response)
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/rs/zerolog"
+)
+
+type Carriers interface {
+	List(ctx context.Context) entity.CarriersResponse
+}
+
+type carriers struct {
+	dbclientFactory dbclient.ClientFactory
+}
+
+// NewCarriers constructs and gives back a service describing the carriers the db client factory supports
+func NewCarriers(dbclientFactory dbclient.ClientFactory) Carriers {
+	return carriers{
+		dbclientFactory: dbclientFactory,
+	}
+}
+
+// List describes every carrier coverage can be checked for, in carrier id order
+func (c carriers) List(ctx context.Context) entity.CarriersResponse {
+	zerolog.Ctx(ctx).Info().Msg("Listing carriers")
+
+	response := entity.CarriersResponse{Carriers: []entity.CarrierMetadata{}, RuleVersion: c.dbclientFactory.RuleVersion()}
+	for _, id := range c.dbclientFactory.Carriers() {
+		carrier, ok := dbclient.LookupCarrier(id)
+		if !ok {
+			continue
+		}
+		response.Carriers = append(response.Carriers, entity.CarrierMetadata{
+			CarrierID:    string(carrier.ID),
+			Name:         carrier.Name,
+			Technologies: carrier.Technologies(),
+		})
+	}
+	return response
+}
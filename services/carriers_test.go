@@ -0,0 +1,34 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCarriersList(t *testing.T) {
+	dbClientFactory := mockClientFactory{}
+	dbClientFactory.On("Carriers").Return([]entity.CarrierType{entity.Sprint, entity.Verizon})
+
+	response := NewCarriers(dbClientFactory).List(context.Background())
+
+	assert.Equal(t, entity.CarriersResponse{
+		Carriers: []entity.CarrierMetadata{
+			{CarrierID: "1", Name: "Sprint", Technologies: []string{"LTE", "LTE 2500", "EVDO", "CDMA"}},
+			{CarrierID: "2", Name: "Verizon", Technologies: []string{"LTE", "EVDO", "Voice/1X"}},
+		},
+		RuleVersion: "fakeRuleVersion",
+	}, response)
+	dbClientFactory.AssertExpectations(t)
+}
+
+func TestCarriersListSkipsUnregisteredCarriers(t *testing.T) {
+	dbClientFactory := mockClientFactory{}
+	dbClientFactory.On("Carriers").Return([]entity.CarrierType{"99"})
+
+	response := NewCarriers(dbClientFactory).List(context.Background())
+
+	assert.Empty(t, response.Carriers)
+}
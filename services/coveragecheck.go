@@ -2,14 +2,22 @@ package services
 
 import (
 	"context"
+	"sync"
 
 	"bitbucket.org/credomobile/coverage/dbclient"
 	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/metrics"
+	"bitbucket.org/credomobile/coverage/tracing"
 	"github.com/rs/zerolog"
 )
 
+var lookupsTotal = metrics.Default.NewCounter("coverage_lookups_total",
+	"Coverage lookups by carrier and result, covered, uncovered or notfound", "carrier", "result")
+
 type CoverageCheck interface {
-	Verify(ctx context.Context, zipCode string, carrierID string) (entity.CoverageCheckResponse, error)
+	Verify(ctx context.Context, zipCode string, carrierID string, detail bool) (entity.CoverageCheckResponse, error)
+	VerifyBatch(ctx context.Context, items []entity.CoverageCheckItem, detail bool) ([]entity.CoverageCheckResponse, error)
+	VerifyAllCarriers(ctx context.Context, zipCode string, detail bool) (entity.MultiCarrierCoverageResponse, error)
 }
 
 type coverageCheck struct {
@@ -17,24 +25,175 @@ type coverageCheck struct {
 }
 
 func NewCoverageCheck(dbclientFactory dbclient.ClientFactory) CoverageCheck {
-	//xray.AWS(dynamo.Client)
 	return coverageCheck{
 		dbclientFactory: dbclientFactory,
 	}
 }
 
-func (c coverageCheck) Verify(ctx context.Context, zipCode string, carrierID string) (entity.CoverageCheckResponse, error) {
-	zerolog.Ctx(ctx).Info().Msgf("Verifying coverage for zipcode: %s and carrierID: %s", zipCode, carrierID)
-
-	dbclient, err := c.dbclientFactory.GetDbClient(entity.CarrierType(carrierID))
+// Verify checks a carrier's coverage of a zipcode. detail adds the per technology coverage and score to the response.
+func (c coverageCheck) Verify(ctx context.Context, zipCode string, carrierID string, detail bool) (entity.CoverageCheckResponse, error) {
+	coverage, err := c.verify(ctx, zipCode, carrierID)
 	if err != nil {
 		return entity.CoverageCheckResponse{}, err
 	}
 
-	isCovered, err := dbclient.VerifyCoverage(ctx, zipCode)
+	return c.response(coverage, detail), nil
+}
+
+func (c coverageCheck) verify(ctx context.Context, zipCode string, carrierID string) (dbclient.Coverage, error) {
+	zerolog.Ctx(ctx).Info().Msgf("Verifying coverage for zipcode: %s and carrierID: %s", zipCode, carrierID)
+
+	dbClient, err := resolveCarrier(ctx, c.dbclientFactory, entity.CarrierType(carrierID))
 	if err != nil {
-		return entity.CoverageCheckResponse{}, err
+		return dbclient.Coverage{}, err
+	}
+
+	coverage, err := dbClient.VerifyCoverage(ctx, zipCode)
+	if err == nil {
+		countLookup(entity.CarrierType(carrierID), coverage)
 	}
+	return coverage, err
+}
+
+// VerifyBatch checks coverage for many zipcode and carrier pairs, making one batch lookup per carrier.
+// The responses are in the same order as the items.
+func (c coverageCheck) VerifyBatch(ctx context.Context, items []entity.CoverageCheckItem, detail bool) ([]entity.CoverageCheckResponse, error) {
+	zerolog.Ctx(ctx).Info().Msgf("Verifying coverage for a batch of %d items", len(items))
+
+	zipCodesByCarrier := make(map[entity.CarrierType][]string)
+	seen := make(map[entity.CoverageCheckItem]bool)
+	for _, item := range items {
+		if seen[item] {
+			continue
+		}
+		seen[item] = true
+		carrier := entity.CarrierType(item.CarrierID)
+		zipCodesByCarrier[carrier] = append(zipCodesByCarrier[carrier], item.ZipCode)
+	}
+
+	coverageByCarrier := make(map[entity.CarrierType]map[string]dbclient.Coverage)
+	for carrier, zipCodes := range zipCodesByCarrier {
+		dbclient, err := resolveCarrier(ctx, c.dbclientFactory, carrier)
+		if err != nil {
+			return nil, err
+		}
+
+		coverage, err := dbclient.BatchVerifyCoverage(ctx, zipCodes)
+		if err != nil {
+			return nil, err
+		}
+		for _, zipCode := range zipCodes {
+			countLookup(carrier, coverage[zipCode])
+		}
+		coverageByCarrier[carrier] = coverage
+	}
+
+	responses := make([]entity.CoverageCheckResponse, len(items))
+	for i, item := range items {
+		responses[i] = c.response(coverageByCarrier[entity.CarrierType(item.CarrierID)][item.ZipCode], detail)
+	}
+	return responses, nil
+}
 
-	return entity.CoverageCheckResponse{IsCovered: isCovered}, nil
+// VerifyAllCarriers checks the coverage of every carrier known to the db client factory in parallel.
+// A failed lookup is reported on that carrier's result; an error is only returned when every lookup failed.
+// The best carrier is the covering carrier with the highest coverage score, whether or not detail is asked for.
+func (c coverageCheck) VerifyAllCarriers(ctx context.Context, zipCode string, detail bool) (entity.MultiCarrierCoverageResponse, error) {
+	carriers := c.dbclientFactory.Carriers()
+	zerolog.Ctx(ctx).Info().Msgf("Verifying coverage for zipcode: %s across %d carriers", zipCode, len(carriers))
+
+	results := make([]entity.CarrierCoverageResult, len(carriers))
+	scores := make([]float64, len(carriers))
+	errs := make([]error, len(carriers))
+	var wg sync.WaitGroup
+	for i, carrier := range carriers {
+		wg.Add(1)
+		go func(i int, carrier entity.CarrierType) {
+			defer wg.Done()
+
+			results[i].CarrierID = string(carrier)
+			coverage, err := c.verify(ctx, zipCode, string(carrier))
+			if err != nil {
+				zerolog.Ctx(ctx).Error().Err(err).Msgf("failed to verify coverage for zipcode: %s and carrierID: %s", zipCode, carrier)
+				results[i].Errors = carrierFailed(err)
+				errs[i] = err
+				return
+			}
+			response := c.response(coverage, detail)
+			results[i].CoverageCheckResponse = &response
+			scores[i] = coverage.Detail.Score
+		}(i, carrier)
+	}
+	wg.Wait()
+
+	response := entity.MultiCarrierCoverageResponse{Carriers: results}
+	var failures []error
+	bestScore := -1.0
+	for i, result := range results {
+		if result.CoverageCheckResponse == nil {
+			failures = append(failures, errs[i])
+			continue
+		}
+		if result.IsCovered && scores[i] > bestScore {
+			response.BestCarrierID = result.CarrierID
+			bestScore = scores[i]
+		}
+	}
+	if len(failures) > 0 && len(failures) == len(results) {
+		return entity.MultiCarrierCoverageResponse{}, allCarriersFailed(failures)
+	}
+	return response, nil
+}
+
+// statusOf gives back the status of a carrier's coverage of a zipcode, unknown when its coverage data doesn't have it
+func statusOf(coverage dbclient.Coverage) entity.CoverageStatus {
+	switch {
+	case !coverage.Found:
+		return entity.CoverageUnknown
+	case coverage.IsCovered:
+		return entity.CoverageCovered
+	default:
+		return entity.CoverageNotCovered
+	}
+}
+
+// resolveCarrier gives back the db client of the carrier in a carrier resolution span
+func resolveCarrier(ctx context.Context, dbclientFactory dbclient.ClientFactory, carrierID entity.CarrierType) (dbclient.CoverageCheckClient, error) {
+	_, span := tracing.Start(ctx, "resolve carrier", tracing.KindInternal)
+	defer span.End()
+	span.SetAttribute("carrier.id", string(carrierID))
+	dbClient, err := dbclientFactory.GetDbClient(carrierID)
+	span.RecordError(err)
+	return dbClient, err
+}
+
+// countLookup counts a lookup of the carrier's coverage by whether the zipcode is covered, or not in its data
+func countLookup(carrierID entity.CarrierType, coverage dbclient.Coverage) {
+	carrier := string(carrierID)
+	if registered, ok := dbclient.LookupCarrier(carrierID); ok {
+		carrier = registered.SortKey
+	}
+	result := "uncovered"
+	switch {
+	case !coverage.Found:
+		result = "notfound"
+	case coverage.IsCovered:
+		result = "covered"
+	}
+	lookupsTotal.Inc(carrier, result)
+}
+
+// response builds the coverage check response, attaching the per technology detail when asked for
+func (c coverageCheck) response(coverage dbclient.Coverage, detail bool) entity.CoverageCheckResponse {
+	response := entity.CoverageCheckResponse{
+		IsCovered:   coverage.IsCovered,
+		Status:      statusOf(coverage),
+		LoadDate:    coverage.LoadDate,
+		RuleVersion: c.dbclientFactory.RuleVersion(),
+	}
+	if detail {
+		coverageDetail := coverage.Detail
+		response.Detail = &coverageDetail
+	}
+	return response
 }
@@ -7,6 +7,7 @@ import (
 
 	"bitbucket.org/credomobile/coverage/dbclient"
 	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/tracing"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -15,15 +16,18 @@ func TestCoverageCheckHappyPathForSprint(t *testing.T) {
 	dbClientFactory := mockClientFactory{}
 
 	mockSprintClient := mockSprintClient{}
-	mockSprintClient.On("VerifyCoverage", mock.Anything, mock.Anything).Return(true, nil)
+	mockSprintClient.On("VerifyCoverage", mock.Anything, mock.Anything).Return(dbclient.Coverage{IsCovered: true, Found: true, LoadDate: "2018-11-01"}, nil)
 	dbClientFactory.On("GetDbClient", mock.Anything).Return(mockSprintClient, nil)
 
 	service := NewCoverageCheck(dbClientFactory)
-	response, err := service.Verify(context.Background(), "94105", "1")
+	response, err := service.Verify(context.Background(), "94105", "1", false)
 
 	assert.NotNil(t, response)
 	assert.NoError(t, err)
 	assert.Equal(t, true, response.IsCovered)
+	assert.Equal(t, entity.CoverageCovered, response.Status)
+	assert.Equal(t, "2018-11-01", response.LoadDate)
+	assert.Equal(t, "fakeRuleVersion", response.RuleVersion)
 	mockSprintClient.AssertExpectations(t)
 	dbClientFactory.AssertExpectations(t)
 }
@@ -32,11 +36,11 @@ func TestCoverageCheckHappyPathForVerizon(t *testing.T) {
 	dbClientFactory := mockClientFactory{}
 	mockVerizonClient := mockVerizonClient{}
 
-	mockVerizonClient.On("VerifyCoverage", mock.Anything, mock.Anything).Return(true, nil)
+	mockVerizonClient.On("VerifyCoverage", mock.Anything, mock.Anything).Return(dbclient.Coverage{IsCovered: true}, nil)
 	dbClientFactory.On("GetDbClient", mock.Anything).Return(mockVerizonClient, nil)
 
 	service := NewCoverageCheck(dbClientFactory)
-	response, err := service.Verify(context.Background(), "94105", "2")
+	response, err := service.Verify(context.Background(), "94105", "2", false)
 
 	assert.NotNil(t, response)
 	assert.NoError(t, err)
@@ -48,12 +52,12 @@ func TestCoverageCheckHappyPathForVerizon(t *testing.T) {
 func TestCoverageCheckWithDbClientFactoryError(t *testing.T) {
 	dbClientFactory := mockClientFactory{}
 	mockVerizonClient := mockVerizonClient{}
-	mockVerizonClient.On("VerifyCoverage", mock.Anything, mock.Anything).Return(true, nil)
+	mockVerizonClient.On("VerifyCoverage", mock.Anything, mock.Anything).Return(dbclient.Coverage{IsCovered: true}, nil)
 
 	dbClientFactory.On("GetDbClient", mock.Anything).Return(mockVerizonClient, errors.New("Fake db Client Factory error"))
 
 	service := NewCoverageCheck(dbClientFactory)
-	_, err := service.Verify(context.Background(), "94105", "2")
+	_, err := service.Verify(context.Background(), "94105", "2", false)
 
 	assert.Error(t, err)
 	dbClientFactory.AssertExpectations(t)
@@ -63,17 +67,226 @@ func TestCoverageCheckWithDbClientError(t *testing.T) {
 	dbClientFactory := mockClientFactory{}
 
 	mockVerizonClient := mockVerizonClient{}
-	mockVerizonClient.On("VerifyCoverage", mock.Anything, mock.Anything).Return(false, errors.New("Fake db Client error"))
+	mockVerizonClient.On("VerifyCoverage", mock.Anything, mock.Anything).Return(dbclient.Coverage{}, errors.New("Fake db Client error"))
 	dbClientFactory.On("GetDbClient", mock.Anything).Return(mockVerizonClient, nil)
 
 	service := NewCoverageCheck(dbClientFactory)
-	_, err := service.Verify(context.Background(), "94105", "2")
+	_, err := service.Verify(context.Background(), "94105", "2", false)
 
 	assert.Error(t, err)
 	mockVerizonClient.AssertExpectations(t)
 	dbClientFactory.AssertExpectations(t)
 }
 
+func TestCoverageCheckVerifyBatch(t *testing.T) {
+	dbClientFactory := mockClientFactory{}
+
+	mockSprintClient := mockSprintClient{}
+	mockSprintClient.On("BatchVerifyCoverage", mock.Anything, []string{"94105", "94106"}).Return(map[string]dbclient.Coverage{"94105": {IsCovered: true, Found: true, LoadDate: "2018-11-01"}, "94106": {LoadDate: "2018-11-01"}}, nil)
+	mockVerizonClient := mockVerizonClient{}
+	mockVerizonClient.On("BatchVerifyCoverage", mock.Anything, []string{"94105"}).Return(map[string]dbclient.Coverage{"94105": {Found: true, LoadDate: "2018-11-02"}}, nil)
+	dbClientFactory.On("GetDbClient", entity.Sprint).Return(mockSprintClient, nil)
+	dbClientFactory.On("GetDbClient", entity.Verizon).Return(mockVerizonClient, nil)
+
+	service := NewCoverageCheck(dbClientFactory)
+	responses, err := service.VerifyBatch(context.Background(), []entity.CoverageCheckItem{
+		{ZipCode: "94105", CarrierID: "1"},
+		{ZipCode: "94105", CarrierID: "2"},
+		{ZipCode: "94106", CarrierID: "1"},
+		{ZipCode: "94105", CarrierID: "1"},
+	}, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.CoverageCheckResponse{
+		{IsCovered: true, Status: entity.CoverageCovered, LoadDate: "2018-11-01", RuleVersion: "fakeRuleVersion"},
+		{IsCovered: false, Status: entity.CoverageNotCovered, LoadDate: "2018-11-02", RuleVersion: "fakeRuleVersion"},
+		{IsCovered: false, Status: entity.CoverageUnknown, LoadDate: "2018-11-01", RuleVersion: "fakeRuleVersion"},
+		{IsCovered: true, Status: entity.CoverageCovered, LoadDate: "2018-11-01", RuleVersion: "fakeRuleVersion"},
+	}, responses)
+	mockSprintClient.AssertExpectations(t)
+	mockVerizonClient.AssertExpectations(t)
+	dbClientFactory.AssertExpectations(t)
+}
+
+func TestCoverageCheckCountsLookups(t *testing.T) {
+	dbClientFactory := mockClientFactory{}
+
+	mockSprintClient := mockSprintClient{}
+	mockSprintClient.On("VerifyCoverage", mock.Anything, "94105").Return(dbclient.Coverage{IsCovered: true, Found: true}, nil)
+	mockSprintClient.On("BatchVerifyCoverage", mock.Anything, []string{"94106", "00000"}).Return(map[string]dbclient.Coverage{"94106": {Found: true}}, nil)
+	dbClientFactory.On("GetDbClient", entity.Sprint).Return(mockSprintClient, nil)
+	covered := lookupsTotal.Value("sprint", "covered")
+	uncovered := lookupsTotal.Value("sprint", "uncovered")
+	notFound := lookupsTotal.Value("sprint", "notfound")
+
+	service := NewCoverageCheck(dbClientFactory)
+	_, err := service.Verify(context.Background(), "94105", "1", false)
+	assert.NoError(t, err)
+	_, err = service.VerifyBatch(context.Background(), []entity.CoverageCheckItem{{ZipCode: "94106", CarrierID: "1"}, {ZipCode: "00000", CarrierID: "1"}}, false)
+	assert.NoError(t, err)
+
+	assert.Equal(t, covered+1, lookupsTotal.Value("sprint", "covered"))
+	assert.Equal(t, uncovered+1, lookupsTotal.Value("sprint", "uncovered"))
+	assert.Equal(t, notFound+1, lookupsTotal.Value("sprint", "notfound"))
+}
+
+// recordingExporter keeps the exported spans
+type recordingExporter struct {
+	spans []tracing.SpanData
+}
+
+func (e *recordingExporter) Export(ctx context.Context, spans []tracing.SpanData) error {
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func TestCoverageCheckTracesCarrierResolution(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracing.SetTracer(tracing.NewTracer(exporter, nil))
+	defer tracing.SetTracer(nil)
+
+	dbClientFactory := mockClientFactory{}
+	mockSprintClient := mockSprintClient{}
+	mockSprintClient.On("VerifyCoverage", mock.Anything, "94105").Return(dbclient.Coverage{IsCovered: true, Found: true}, nil)
+	dbClientFactory.On("GetDbClient", entity.Sprint).Return(mockSprintClient, nil)
+	dbClientFactory.On("GetDbClient", entity.CarrierType("99")).Return(mockSprintClient, errors.New("Fake db Client Factory error"))
+
+	ctx, parent := tracing.Start(context.Background(), "GET /v1/coveragecheck", tracing.KindServer)
+	service := NewCoverageCheck(dbClientFactory)
+	_, err := service.Verify(ctx, "94105", "1", false)
+	assert.NoError(t, err)
+	_, err = service.Verify(ctx, "94105", "99", false)
+	assert.Error(t, err)
+	assert.NoError(t, tracing.Flush(context.Background()))
+
+	assert.Len(t, exporter.spans, 2)
+	for _, span := range exporter.spans {
+		assert.Equal(t, "resolve carrier", span.Name)
+		assert.Equal(t, parent.SpanContext().SpanID, span.Parent)
+	}
+	assert.Equal(t, string(entity.Sprint), exporter.spans[0].Attributes["carrier.id"])
+	assert.Empty(t, exporter.spans[0].Error)
+	assert.Equal(t, "99", exporter.spans[1].Attributes["carrier.id"])
+	assert.Equal(t, "Fake db Client Factory error", exporter.spans[1].Error)
+}
+
+func TestCoverageCheckVerifyBatchWithDbClientError(t *testing.T) {
+	dbClientFactory := mockClientFactory{}
+
+	mockSprintClient := mockSprintClient{}
+	mockSprintClient.On("BatchVerifyCoverage", mock.Anything, mock.Anything).Return(map[string]dbclient.Coverage(nil), errors.New("Fake db Client error"))
+	dbClientFactory.On("GetDbClient", mock.Anything).Return(mockSprintClient, nil)
+
+	service := NewCoverageCheck(dbClientFactory)
+	_, err := service.VerifyBatch(context.Background(), []entity.CoverageCheckItem{{ZipCode: "94105", CarrierID: "1"}}, false)
+
+	assert.Error(t, err)
+	mockSprintClient.AssertExpectations(t)
+	dbClientFactory.AssertExpectations(t)
+}
+
+func TestCoverageCheckVerifyAllCarriers(t *testing.T) {
+	dbClientFactory := mockClientFactory{}
+
+	mockSprintClient := mockSprintClient{}
+	mockSprintClient.On("VerifyCoverage", mock.Anything, "94105").Return(dbclient.Coverage{IsCovered: false, Found: true}, nil)
+	mockVerizonClient := mockVerizonClient{}
+	mockVerizonClient.On("VerifyCoverage", mock.Anything, "94105").Return(dbclient.Coverage{IsCovered: true, Found: true}, nil)
+	dbClientFactory.On("Carriers").Return([]entity.CarrierType{entity.Sprint, entity.Verizon})
+	dbClientFactory.On("GetDbClient", entity.Sprint).Return(mockSprintClient, nil)
+	dbClientFactory.On("GetDbClient", entity.Verizon).Return(mockVerizonClient, nil)
+
+	service := NewCoverageCheck(dbClientFactory)
+	response, err := service.VerifyAllCarriers(context.Background(), "94105", false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2", response.BestCarrierID)
+	assert.Equal(t, []entity.CarrierCoverageResult{
+		{CarrierID: "1", CoverageCheckResponse: &entity.CoverageCheckResponse{IsCovered: false, Status: entity.CoverageNotCovered, RuleVersion: "fakeRuleVersion"}},
+		{CarrierID: "2", CoverageCheckResponse: &entity.CoverageCheckResponse{IsCovered: true, Status: entity.CoverageCovered, RuleVersion: "fakeRuleVersion"}},
+	}, response.Carriers)
+	mockSprintClient.AssertExpectations(t)
+	mockVerizonClient.AssertExpectations(t)
+	dbClientFactory.AssertExpectations(t)
+}
+
+func TestCoverageCheckVerifyAllCarriersWithOneCarrierFailing(t *testing.T) {
+	dbClientFactory := mockClientFactory{}
+
+	mockSprintClient := mockSprintClient{}
+	mockSprintClient.On("VerifyCoverage", mock.Anything, "94105").Return(dbclient.Coverage{}, errors.New("Fake db Client error"))
+	mockVerizonClient := mockVerizonClient{}
+	mockVerizonClient.On("VerifyCoverage", mock.Anything, "94105").Return(dbclient.Coverage{IsCovered: false}, nil)
+	dbClientFactory.On("Carriers").Return([]entity.CarrierType{entity.Sprint, entity.Verizon})
+	dbClientFactory.On("GetDbClient", entity.Sprint).Return(mockSprintClient, nil)
+	dbClientFactory.On("GetDbClient", entity.Verizon).Return(mockVerizonClient, nil)
+
+	service := NewCoverageCheck(dbClientFactory)
+	response, err := service.VerifyAllCarriers(context.Background(), "94105", false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "", response.BestCarrierID)
+	assert.Nil(t, response.Carriers[0].CoverageCheckResponse)
+	assert.Equal(t, []entity.Error{{Message: "Unable to check coverage for carrier", Path: "carrierid", Code: entity.CodeInternal}}, response.Carriers[0].Errors)
+	assert.Equal(t, &entity.CoverageCheckResponse{IsCovered: false, Status: entity.CoverageUnknown, RuleVersion: "fakeRuleVersion"}, response.Carriers[1].CoverageCheckResponse)
+	mockSprintClient.AssertExpectations(t)
+	mockVerizonClient.AssertExpectations(t)
+}
+
+func TestCoverageCheckVerifyAllCarriersWithEveryCarrierFailing(t *testing.T) {
+	dbClientFactory := mockClientFactory{}
+
+	mockSprintClient := mockSprintClient{}
+	mockSprintClient.On("VerifyCoverage", mock.Anything, "94105").Return(dbclient.Coverage{}, errors.New("Fake db Client error"))
+	dbClientFactory.On("Carriers").Return([]entity.CarrierType{entity.Sprint})
+	dbClientFactory.On("GetDbClient", entity.Sprint).Return(mockSprintClient, nil)
+
+	service := NewCoverageCheck(dbClientFactory)
+	_, err := service.VerifyAllCarriers(context.Background(), "94105", false)
+
+	assert.Error(t, err)
+	mockSprintClient.AssertExpectations(t)
+}
+
+func TestCoverageCheckWithDetail(t *testing.T) {
+	dbClientFactory := mockClientFactory{}
+
+	percentage := 78.0
+	detail := entity.CoverageDetail{Score: 78, Technologies: []entity.TechnologyCoverage{{Technology: "LTE", Percentage: &percentage, Indicator: "Y"}}}
+	mockVerizonClient := mockVerizonClient{}
+	mockVerizonClient.On("VerifyCoverage", mock.Anything, "94105").Return(dbclient.Coverage{IsCovered: true, Detail: detail}, nil)
+	dbClientFactory.On("GetDbClient", entity.Verizon).Return(mockVerizonClient, nil)
+
+	service := NewCoverageCheck(dbClientFactory)
+
+	response, err := service.Verify(context.Background(), "94105", "2", true)
+	assert.NoError(t, err)
+	assert.Equal(t, &detail, response.Detail)
+
+	response, err = service.Verify(context.Background(), "94105", "2", false)
+	assert.NoError(t, err)
+	assert.Nil(t, response.Detail)
+}
+
+func TestCoverageCheckVerifyAllCarriersPicksHighestScore(t *testing.T) {
+	dbClientFactory := mockClientFactory{}
+
+	mockSprintClient := mockSprintClient{}
+	mockSprintClient.On("VerifyCoverage", mock.Anything, "94105").Return(dbclient.Coverage{IsCovered: true, Detail: entity.CoverageDetail{Score: 62.5}}, nil)
+	mockVerizonClient := mockVerizonClient{}
+	mockVerizonClient.On("VerifyCoverage", mock.Anything, "94105").Return(dbclient.Coverage{IsCovered: true, Detail: entity.CoverageDetail{Score: 91}}, nil)
+	dbClientFactory.On("Carriers").Return([]entity.CarrierType{entity.Sprint, entity.Verizon})
+	dbClientFactory.On("GetDbClient", entity.Sprint).Return(mockSprintClient, nil)
+	dbClientFactory.On("GetDbClient", entity.Verizon).Return(mockVerizonClient, nil)
+
+	service := NewCoverageCheck(dbClientFactory)
+	response, err := service.VerifyAllCarriers(context.Background(), "94105", false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2", response.BestCarrierID)
+	assert.Nil(t, response.Carriers[1].Detail)
+}
+
 type mockClientFactory struct {
 	mock.Mock
 }
@@ -83,6 +296,15 @@ func (m mockClientFactory) GetDbClient(t entity.CarrierType) (dbclient.CoverageC
 	return args.Get(0).(dbclient.CoverageCheckClient), errOrNil(args.Get(1))
 }
 
+func (m mockClientFactory) RuleVersion() string {
+	return "fakeRuleVersion"
+}
+
+func (m mockClientFactory) Carriers() []entity.CarrierType {
+	args := m.Called()
+	return args.Get(0).([]entity.CarrierType)
+}
+
 type mockSprintClient struct {
 	mock.Mock
 }
@@ -91,14 +313,24 @@ type mockVerizonClient struct {
 	mock.Mock
 }
 
-func (m mockSprintClient) VerifyCoverage(ctx context.Context, zipCode string) (bool, error) {
+func (m mockSprintClient) VerifyCoverage(ctx context.Context, zipCode string) (dbclient.Coverage, error) {
 	args := m.Called(ctx, zipCode)
-	return args.Get(0).(bool), errOrNil(args.Get(1))
+	return args.Get(0).(dbclient.Coverage), errOrNil(args.Get(1))
 }
 
-func (m mockVerizonClient) VerifyCoverage(ctx context.Context, zipCode string) (bool, error) {
+func (m mockVerizonClient) VerifyCoverage(ctx context.Context, zipCode string) (dbclient.Coverage, error) {
 	args := m.Called(ctx, zipCode)
-	return args.Get(0).(bool), errOrNil(args.Get(1))
+	return args.Get(0).(dbclient.Coverage), errOrNil(args.Get(1))
+}
+
+func (m mockSprintClient) BatchVerifyCoverage(ctx context.Context, zipCodes []string) (map[string]dbclient.Coverage, error) {
+	args := m.Called(ctx, zipCodes)
+	return args.Get(0).(map[string]dbclient.Coverage), errOrNil(args.Get(1))
+}
+
+func (m mockVerizonClient) BatchVerifyCoverage(ctx context.Context, zipCodes []string) (map[string]dbclient.Coverage, error) {
+	args := m.Called(ctx, zipCodes)
+	return args.Get(0).(map[string]dbclient.Coverage), errOrNil(args.Get(1))
 }
 
 func errOrNil(o interface{}) error {
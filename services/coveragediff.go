@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/rs/zerolog"
+)
+
+type CoverageDiff interface {
+	Diff(ctx context.Context, carrierID string, from string, to string, swingThreshold float64) (entity.CoverageDiffResponse, bool, error)
+}
+
+type coverageDiff struct {
+	dbClient dbclient.CoverageDiffer
+}
+
+//NewCoverageDiff constructs and gives back coverage diff service
+func NewCoverageDiff(dbClient dbclient.CoverageDiffer, logger *zerolog.Logger) (coverageDiff, error) {
+	if dbClient == nil {
+		return coverageDiff{}, errors.New("Invalid coverage differ")
+	}
+
+	return coverageDiff{
+		dbClient: dbClient,
+	}, nil
+}
+
+// Diff reports the zipcodes whose coverage flipped or swung between two dataset versions of a carrier, comparing the
+// live version when from is empty. found is false when either version wasn't completely loaded.
+func (c coverageDiff) Diff(ctx context.Context, carrierID string, from string, to string, swingThreshold float64) (entity.CoverageDiffResponse, bool, error) {
+	zerolog.Ctx(ctx).Info().Msgf("Comparing dataset versions from: %s and to: %s for carrierID: %s", from, to, carrierID)
+
+	carrier, ok := dbclient.LookupCarrier(entity.CarrierType(carrierID))
+	if !ok {
+		return entity.CoverageDiffResponse{}, false, dbclient.ErrUnknownCarrier
+	}
+
+	diff, err := c.dbClient.Diff(ctx, carrier.ID, from, to, swingThreshold)
+	if err == dbclient.ErrUnknownVersion {
+		return entity.CoverageDiffResponse{}, false, nil
+	}
+	if err != nil {
+		return entity.CoverageDiffResponse{}, false, err
+	}
+
+	return entity.CoverageDiffResponse{
+		CarrierID:      string(carrier.ID),
+		Name:           carrier.Name,
+		From:           diff.From,
+		To:             diff.To,
+		RuleVersion:    diff.RuleVersion,
+		SwingThreshold: swingThreshold,
+		ZipCodes:       diff.ZipCodes,
+		Gained:         orEmptyChanges(diff.Gained),
+		Lost:           orEmptyChanges(diff.Lost),
+		Swings:         orEmptySwings(diff.Swings),
+		Added:          orEmptyZipCodes(diff.Added),
+		Removed:        orEmptyZipCodes(diff.Removed),
+	}, true, nil
+}
+
+// WriteCoverageDiffCSV writes a coverage diff as CSV, a row per flipped, swung, added or removed zipcode
+func WriteCoverageDiffCSV(w io.Writer, diff entity.CoverageDiffResponse) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"zipcode", "change", "technology", "from", "to"})
+	for _, change := range diff.Gained {
+		writer.Write([]string{change.ZipCode, "gained", "", formatNumber(change.FromScore), formatNumber(change.ToScore)})
+	}
+	for _, change := range diff.Lost {
+		writer.Write([]string{change.ZipCode, "lost", "", formatNumber(change.FromScore), formatNumber(change.ToScore)})
+	}
+	for _, swing := range diff.Swings {
+		writer.Write([]string{swing.ZipCode, "swing", swing.Technology, formatNumber(swing.From), formatNumber(swing.To)})
+	}
+	for _, zipCode := range diff.Added {
+		writer.Write([]string{zipCode, "added", "", "", ""})
+	}
+	for _, zipCode := range diff.Removed {
+		writer.Write([]string{zipCode, "removed", "", "", ""})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// the orEmpty helpers keep an empty list in the JSON report rather than null
+func orEmptyChanges(changes []entity.CoverageChange) []entity.CoverageChange {
+	if changes == nil {
+		return []entity.CoverageChange{}
+	}
+	return changes
+}
+
+func orEmptySwings(swings []entity.CoverageSwing) []entity.CoverageSwing {
+	if swings == nil {
+		return []entity.CoverageSwing{}
+	}
+	return swings
+}
+
+func orEmptyZipCodes(zipCodes []string) []string {
+	if zipCodes == nil {
+		return []string{}
+	}
+	return zipCodes
+}
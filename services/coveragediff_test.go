@@ -0,0 +1,112 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNewCoverageDiff(t *testing.T) {
+	logger := zerolog.New(os.Stdout).With().Logger()
+	store := dbclient.NewMemoryStore()
+	coverageDiffService, err := NewCoverageDiff(dbclient.NewCoverageDiffer(store.Datasets(), rules.Default()), &logger)
+	assert.NoError(t, err)
+	assert.IsType(t, coverageDiff{}, coverageDiffService)
+
+	_, err = NewCoverageDiff(nil, &logger)
+	assert.Error(t, err)
+}
+
+func TestCoverageDiffDiff(t *testing.T) {
+	mockCoverageDiffer := mockCoverageDiffer{}
+	mockCoverageDiffer.On("Diff", mock.Anything, entity.Verizon, "", "2018-12-01", 20.0).Return(dbclient.CoverageDiff{
+		From:        "2018-11-01",
+		To:          "2018-12-01",
+		RuleVersion: "default-1",
+		ZipCodes:    2,
+		Lost:        []entity.CoverageChange{{ZipCode: "94105", FromScore: 100, ToScore: 30}},
+		Swings:      []entity.CoverageSwing{{ZipCode: "94105", Technology: "LTE", From: 100, To: 30, Change: -70}},
+		Added:       []string{"94109"},
+	}, nil)
+
+	coverageDiffService := coverageDiff{dbClient: &mockCoverageDiffer}
+	response, found, err := coverageDiffService.Diff(context.Background(), "2", "", "2018-12-01", 20)
+
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, entity.CoverageDiffResponse{
+		CarrierID:      "2",
+		Name:           "Verizon",
+		From:           "2018-11-01",
+		To:             "2018-12-01",
+		RuleVersion:    "default-1",
+		SwingThreshold: 20,
+		ZipCodes:       2,
+		Gained:         []entity.CoverageChange{},
+		Lost:           []entity.CoverageChange{{ZipCode: "94105", FromScore: 100, ToScore: 30}},
+		Swings:         []entity.CoverageSwing{{ZipCode: "94105", Technology: "LTE", From: 100, To: 30, Change: -70}},
+		Added:          []string{"94109"},
+		Removed:        []string{},
+	}, response)
+	mockCoverageDiffer.AssertExpectations(t)
+}
+
+func TestCoverageDiffWithUnknownVersion(t *testing.T) {
+	mockCoverageDiffer := mockCoverageDiffer{}
+	mockCoverageDiffer.On("Diff", mock.Anything, entity.Sprint, "2018-11-01", "2018-12-01", 20.0).Return(dbclient.CoverageDiff{}, dbclient.ErrUnknownVersion)
+
+	coverageDiffService := coverageDiff{dbClient: &mockCoverageDiffer}
+	_, found, err := coverageDiffService.Diff(context.Background(), "1", "2018-11-01", "2018-12-01", 20)
+
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestCoverageDiffWithError(t *testing.T) {
+	mockCoverageDiffer := mockCoverageDiffer{}
+	mockCoverageDiffer.On("Diff", mock.Anything, entity.Sprint, "", "2018-12-01", 20.0).Return(dbclient.CoverageDiff{}, errors.New("Fake error"))
+
+	coverageDiffService := coverageDiff{dbClient: &mockCoverageDiffer}
+	_, _, err := coverageDiffService.Diff(context.Background(), "1", "", "2018-12-01", 20)
+	assert.Error(t, err)
+
+	_, _, err = coverageDiffService.Diff(context.Background(), "9", "", "2018-12-01", 20)
+	assert.EqualError(t, err, "Invalid Carrier Type")
+}
+
+func TestWriteCoverageDiffCSV(t *testing.T) {
+	var out bytes.Buffer
+	err := WriteCoverageDiffCSV(&out, entity.CoverageDiffResponse{
+		Gained:  []entity.CoverageChange{{ZipCode: "94106", FromScore: 40, ToScore: 60.5}},
+		Lost:    []entity.CoverageChange{{ZipCode: "94105", FromScore: 100, ToScore: 30}},
+		Swings:  []entity.CoverageSwing{{ZipCode: "94105", Technology: "LTE", From: 100, To: 30, Change: -70}},
+		Added:   []string{"94109"},
+		Removed: []string{"94108"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "zipcode,change,technology,from,to\n"+
+		"94106,gained,,40,60.5\n"+
+		"94105,lost,,100,30\n"+
+		"94105,swing,LTE,100,30\n"+
+		"94109,added,,,\n"+
+		"94108,removed,,,\n", out.String())
+}
+
+type mockCoverageDiffer struct {
+	mock.Mock
+}
+
+func (m *mockCoverageDiffer) Diff(ctx context.Context, carrier entity.CarrierType, from string, to string, swingThreshold float64) (dbclient.CoverageDiff, error) {
+	args := m.Called(ctx, carrier, from, to, swingThreshold)
+	return args.Get(0).(dbclient.CoverageDiff), errOrNil(args.Get(1))
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/rs/zerolog"
+)
+
+type CoverageSummary interface {
+	GetSummary(ctx context.Context, state string, county string, carrierID string) (entity.CoverageSummaryResponse, error)
+}
+
+type coverageSummary struct {
+	dbClient dbclient.CoverageSummaryClient
+}
+
+//NewCoverageSummary constructs and gives back coverage summary service
+func NewCoverageSummary(dbClient dbclient.CoverageSummaryClient, logger *zerolog.Logger) (coverageSummary, error) {
+	if dbClient == nil {
+		return coverageSummary{}, errors.New("Invalid coverage summary db client")
+	}
+
+	return coverageSummary{
+		dbClient: dbClient,
+	}, nil
+}
+
+// GetSummary reads a carrier's summary of the coverage of a state, or of a county of the state when county isn't
+// empty, or every registered carrier's when carrierID is all
+func (c coverageSummary) GetSummary(ctx context.Context, state string, county string, carrierID string) (entity.CoverageSummaryResponse, error) {
+	zerolog.Ctx(ctx).Info().Msgf("Getting coverage summary for state: %s, county: %s and carrierID: %s", state, county, carrierID)
+
+	var carriers []dbclient.Carrier
+	if entity.CarrierType(carrierID) == entity.AllCarriers {
+		carriers = dbclient.RegisteredCarriers()
+	} else {
+		carrier, ok := dbclient.LookupCarrier(entity.CarrierType(carrierID))
+		if !ok {
+			return entity.CoverageSummaryResponse{}, dbclient.ErrUnknownCarrier
+		}
+		carriers = []dbclient.Carrier{carrier}
+	}
+
+	response := entity.CoverageSummaryResponse{State: state, County: county, Carriers: []entity.CarrierCoverageSummary{}}
+	for _, carrier := range carriers {
+		summary, found, err := c.dbClient.GetSummary(ctx, carrier.ID, state, county)
+		if err != nil {
+			return entity.CoverageSummaryResponse{}, err
+		}
+
+		response.Carriers = append(response.Carriers, entity.CarrierCoverageSummary{
+			CarrierID:    string(carrier.ID),
+			Name:         carrier.Name,
+			Found:        found,
+			ZipCodes:     summary.ZipCodes,
+			Covered:      summary.Covered,
+			Uncovered:    summary.ZipCodes - summary.Covered,
+			AverageScore: summary.Score,
+			Technologies: summary.Technologies,
+			LoadDate:     summary.LoadDate,
+			RuleVersion:  summary.RuleVersion,
+		})
+	}
+	return response, nil
+}
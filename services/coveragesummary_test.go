@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNewCoverageSummary(t *testing.T) {
+	logger := zerolog.New(os.Stdout).With().Logger()
+	coverageSummaryService, err := NewCoverageSummary(dbclient.NewCoverageSummaryClient(dbclient.NewMemoryStore()), &logger)
+	assert.NoError(t, err)
+	assert.IsType(t, coverageSummary{}, coverageSummaryService)
+
+	_, err = NewCoverageSummary(nil, &logger)
+	assert.Error(t, err)
+}
+
+func TestCoverageSummaryGetSummary(t *testing.T) {
+	percentage := 76.7
+	mockCoverageSummaryDbClient := mockCoverageSummaryDbClient{}
+	mockCoverageSummaryDbClient.On("GetSummary", mock.Anything, entity.Verizon, "CA", "Alameda").Return(dbclient.CoverageSummary{
+		State:        "CA",
+		County:       "Alameda",
+		ZipCodes:     10,
+		Covered:      8,
+		Score:        70.5,
+		Technologies: []entity.TechnologyCoverage{{Technology: "LTE", Percentage: &percentage}},
+		LoadDate:     "2018-11-01",
+		RuleVersion:  "default-1",
+	}, true, nil)
+
+	coverageSummaryService := coverageSummary{dbClient: &mockCoverageSummaryDbClient}
+	response, err := coverageSummaryService.GetSummary(context.Background(), "CA", "Alameda", "2")
+
+	assert.NoError(t, err)
+	assert.Equal(t, entity.CoverageSummaryResponse{
+		State:  "CA",
+		County: "Alameda",
+		Carriers: []entity.CarrierCoverageSummary{{
+			CarrierID:    "2",
+			Name:         "Verizon",
+			Found:        true,
+			ZipCodes:     10,
+			Covered:      8,
+			Uncovered:    2,
+			AverageScore: 70.5,
+			Technologies: []entity.TechnologyCoverage{{Technology: "LTE", Percentage: &percentage}},
+			LoadDate:     "2018-11-01",
+			RuleVersion:  "default-1",
+		}},
+	}, response)
+	mockCoverageSummaryDbClient.AssertExpectations(t)
+}
+
+func TestCoverageSummaryGetSummaryForAllCarriers(t *testing.T) {
+	mockCoverageSummaryDbClient := mockCoverageSummaryDbClient{}
+	mockCoverageSummaryDbClient.On("GetSummary", mock.Anything, mock.Anything, "NY", "").Return(dbclient.CoverageSummary{}, false, nil)
+
+	coverageSummaryService := coverageSummary{dbClient: &mockCoverageSummaryDbClient}
+	response, err := coverageSummaryService.GetSummary(context.Background(), "NY", "", "all")
+
+	assert.NoError(t, err)
+	assert.Len(t, response.Carriers, len(dbclient.RegisteredCarriers()))
+	assert.Equal(t, entity.CarrierCoverageSummary{CarrierID: "1", Name: "Sprint"}, response.Carriers[0])
+	mockCoverageSummaryDbClient.AssertNumberOfCalls(t, "GetSummary", len(dbclient.RegisteredCarriers()))
+}
+
+func TestCoverageSummaryGetSummaryWithDbClientError(t *testing.T) {
+	mockCoverageSummaryDbClient := mockCoverageSummaryDbClient{}
+	mockCoverageSummaryDbClient.On("GetSummary", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(dbclient.CoverageSummary{}, false, errors.New("Fake error"))
+
+	coverageSummaryService := coverageSummary{dbClient: &mockCoverageSummaryDbClient}
+	_, err := coverageSummaryService.GetSummary(context.Background(), "CA", "", "1")
+	assert.Error(t, err)
+
+	_, err = coverageSummaryService.GetSummary(context.Background(), "CA", "", "9")
+	assert.EqualError(t, err, "Invalid Carrier Type")
+}
+
+type mockCoverageSummaryDbClient struct {
+	mock.Mock
+}
+
+func (m *mockCoverageSummaryDbClient) GetSummary(ctx context.Context, carrier entity.CarrierType, state string, county string) (dbclient.CoverageSummary, bool, error) {
+	args := m.Called(ctx, carrier, state, county)
+	return args.Get(0).(dbclient.CoverageSummary), args.Bool(1), errOrNil(args.Get(2))
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/rs/zerolog"
+)
+
+type CsaZipCodes interface {
+	List(ctx context.Context, csa string, limit int, token string) (entity.CsaZipCodesResponse, error)
+}
+
+type csaZipCodes struct {
+	dbClient dbclient.CsaZipCodeClient
+}
+
+//NewCsaZipCodes constructs and gives back a service listing the zipcodes of a CSA
+func NewCsaZipCodes(dbClient dbclient.CsaZipCodeClient, logger *zerolog.Logger) (csaZipCodes, error) {
+	if dbClient == nil {
+		return csaZipCodes{}, errors.New("Invalid csa zipcode db client")
+	}
+
+	return csaZipCodes{
+		dbClient: dbClient,
+	}, nil
+}
+
+// List gives back a page of the zipcodes of the CSA. token is the NextToken of the previous page.
+func (c csaZipCodes) List(ctx context.Context, csa string, limit int, token string) (entity.CsaZipCodesResponse, error) {
+	zerolog.Ctx(ctx).Info().Msgf("Listing zipcodes of csa: %s", csa)
+
+	page, err := c.dbClient.ListZipCodes(ctx, csa, limit, token)
+	if err != nil {
+		return entity.CsaZipCodesResponse{}, err
+	}
+
+	return entity.CsaZipCodesResponse{Csa: csa, ZipCodes: page.ZipCodes, NextToken: page.NextToken}, nil
+}
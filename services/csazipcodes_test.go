@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNewCsaZipCodes(t *testing.T) {
+	logger := zerolog.New(os.Stdout).With().Logger()
+	csaZipCodesService, err := NewCsaZipCodes(dbclient.NewCsaZipCodeClient(dbclient.NewMemoryStore()), &logger)
+	assert.NoError(t, err)
+	assert.IsType(t, csaZipCodes{}, csaZipCodesService)
+
+	_, err = NewCsaZipCodes(nil, &logger)
+	assert.Error(t, err)
+}
+
+func TestCsaZipCodesList(t *testing.T) {
+	mockCsaZipCodeDbClient := mockCsaZipCodeDbClient{}
+	mockCsaZipCodeDbClient.On("ListZipCodes", mock.Anything, "PHXTUC520", 2, "fakeToken").Return(dbclient.CsaZipCodes{
+		ZipCodes:  []entity.CsaZipCode{{ZipCode: "85001", State: "AZ"}},
+		NextToken: "fakeNextToken",
+	}, nil)
+
+	csaZipCodesService := csaZipCodes{
+		dbClient: &mockCsaZipCodeDbClient,
+	}
+
+	response, err := csaZipCodesService.List(context.Background(), "PHXTUC520", 2, "fakeToken")
+
+	assert.NoError(t, err)
+	assert.Equal(t, entity.CsaZipCodesResponse{
+		Csa:       "PHXTUC520",
+		ZipCodes:  []entity.CsaZipCode{{ZipCode: "85001", State: "AZ"}},
+		NextToken: "fakeNextToken",
+	}, response)
+	mockCsaZipCodeDbClient.AssertExpectations(t)
+}
+
+func TestCsaZipCodesListWithDbClientError(t *testing.T) {
+	mockCsaZipCodeDbClient := mockCsaZipCodeDbClient{}
+	mockCsaZipCodeDbClient.On("ListZipCodes", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(dbclient.CsaZipCodes{}, dbclient.ErrInvalidToken)
+
+	csaZipCodesService := csaZipCodes{
+		dbClient: &mockCsaZipCodeDbClient,
+	}
+
+	_, err := csaZipCodesService.List(context.Background(), "PHXTUC520", 2, "fakeToken")
+
+	assert.Equal(t, dbclient.ErrInvalidToken, err)
+	mockCsaZipCodeDbClient.AssertExpectations(t)
+}
+
+type mockCsaZipCodeDbClient struct {
+	mock.Mock
+}
+
+func (m *mockCsaZipCodeDbClient) ListZipCodes(ctx context.Context, csa string, limit int, token string) (dbclient.CsaZipCodes, error) {
+	args := m.Called(ctx, csa, limit, token)
+	return args.Get(0).(dbclient.CsaZipCodes), errOrNil(args.Get(1))
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"errors"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+)
+
+// ErrorCode gives back the error code reported to the caller for a failure, by its kind
+func ErrorCode(err error) string {
+	switch dbclient.KindOf(err) {
+	case dbclient.KindNotFound:
+		return entity.CodeNotFound
+	case dbclient.KindThrottled:
+		return entity.CodeThrottled
+	case dbclient.KindUnavailable:
+		return entity.CodeUnavailable
+	case dbclient.KindBadData:
+		return entity.CodeBadData
+	case dbclient.KindCanceled:
+		return entity.CodeCanceled
+	default:
+		return entity.CodeInternal
+	}
+}
+
+// carrierFailed gives back the error reported on the result of a carrier whose lookup failed
+func carrierFailed(err error) []entity.Error {
+	return []entity.Error{{Message: "Unable to check coverage for carrier", Path: "carrierid", Code: ErrorCode(err)}}
+}
+
+// allCarriersFailed gives back the error of a lookup that failed for every carrier. It is of the kind of the
+// carriers' errors when they agree, a throttled table throttles every carrier, and internal otherwise.
+func allCarriersFailed(errs []error) error {
+	kind := dbclient.KindOf(errs[0])
+	for _, err := range errs[1:] {
+		if dbclient.KindOf(err) != kind {
+			kind = dbclient.KindInternal
+			break
+		}
+	}
+	return &dbclient.Error{Kind: kind, Err: errors.New("unable to check coverage for any carrier")}
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorCode(t *testing.T) {
+	assert.Equal(t, entity.CodeNotFound, ErrorCode(dbclient.ErrUnknownCarrier))
+	assert.Equal(t, entity.CodeThrottled, ErrorCode(awserr.New("ProvisionedThroughputExceededException", "", nil)))
+	assert.Equal(t, entity.CodeUnavailable, ErrorCode(awserr.New("RequestCanceled", "", context.DeadlineExceeded)))
+	assert.Equal(t, entity.CodeCanceled, ErrorCode(awserr.New("RequestCanceled", "", context.Canceled)))
+	assert.Equal(t, entity.CodeBadData, ErrorCode(&dbclient.Error{Kind: dbclient.KindBadData, Err: errors.New("malformed coverage item")}))
+	assert.Equal(t, entity.CodeInternal, ErrorCode(errors.New("Fake error")))
+}
+
+func TestAllCarriersFailed(t *testing.T) {
+	throttled := awserr.New("ProvisionedThroughputExceededException", "", nil)
+
+	err := allCarriersFailed([]error{throttled, throttled})
+	assert.EqualError(t, err, "unable to check coverage for any carrier")
+	assert.Equal(t, dbclient.KindThrottled, dbclient.KindOf(err))
+
+	err = allCarriersFailed([]error{throttled, errors.New("Fake error")})
+	assert.Equal(t, dbclient.KindInternal, dbclient.KindOf(err))
+}
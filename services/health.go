@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/rs/zerolog"
+)
+
+type Health interface {
+	Check(ctx context.Context, deep bool) entity.HealthResponse
+}
+
+type health struct {
+	store   dbclient.Store
+	version string
+	started time.Time
+	now     func() time.Time
+}
+
+// NewHealth constructs and gives back a health service for the build version, started at cold start
+func NewHealth(store dbclient.Store, version string, started time.Time) Health {
+	return health{
+		store:   store,
+		version: version,
+		started: started,
+		now:     time.Now,
+	}
+}
+
+// Check reports the build and uptime. The deep check also reads the coverage table and the load of every
+// registered carrier: the service is unavailable when the table can't be read and degraded when a carrier
+// has no coverage data loaded.
+func (h health) Check(ctx context.Context, deep bool) entity.HealthResponse {
+	uptime := h.now().Sub(h.started)
+	response := entity.HealthResponse{
+		Status:        entity.HealthOK,
+		Version:       h.version,
+		Uptime:        uptime.Round(time.Second).String(),
+		UptimeSeconds: int64(uptime.Seconds()),
+	}
+	if !deep {
+		return response
+	}
+
+	response.Table = &entity.TableHealth{Status: entity.HealthOK}
+	if err := h.store.Check(ctx); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("coverage table health check failed")
+		response.Status = entity.HealthUnavailable
+		response.Table = &entity.TableHealth{Status: entity.HealthUnavailable, Error: err.Error()}
+		return response
+	}
+
+	for _, carrier := range dbclient.RegisteredCarriers() {
+		carrierHealth := entity.CarrierHealth{CarrierID: string(carrier.ID), Name: carrier.Name}
+		meta, found, err := dbclient.GetLoadMeta(ctx, h.store, carrier.SortKey)
+		switch {
+		case err != nil:
+			zerolog.Ctx(ctx).Error().Err(err).Msgf("failed to read the load of %s", carrier.SortKey)
+			carrierHealth.Error = err.Error()
+			response.Status = entity.HealthUnavailable
+		case !found || meta.Rows == 0:
+			if response.Status == entity.HealthOK {
+				response.Status = entity.HealthDegraded
+			}
+		default:
+			carrierHealth.Loaded = true
+		}
+		carrierHealth.LoadDate = meta.LoadDate
+		carrierHealth.Rows = meta.Rows
+		response.Carriers = append(response.Carriers, carrierHealth)
+	}
+	return response
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+// brokenStore is a store whose table can't be read
+type brokenStore struct {
+	*dbclient.MemoryStore
+}
+
+func (b brokenStore) Check(ctx context.Context) error {
+	return errors.New("ResourceNotFoundException")
+}
+
+func newTestHealth(store dbclient.Store) health {
+	started := time.Date(2018, 11, 1, 0, 0, 0, 0, time.UTC)
+	h := NewHealth(store, "fakeVersion", started).(health)
+	h.now = func() time.Time { return started.Add(90*time.Second + 400*time.Millisecond) }
+	return h
+}
+
+func TestHealthShallow(t *testing.T) {
+	response := newTestHealth(brokenStore{dbclient.NewMemoryStore()}).Check(context.Background(), false)
+
+	assert.Equal(t, entity.HealthResponse{Status: entity.HealthOK, Version: "fakeVersion", Uptime: "1m30s", UptimeSeconds: 90}, response)
+}
+
+func TestHealthDeep(t *testing.T) {
+	store := dbclient.NewMemoryStore()
+	for _, carrier := range []entity.CarrierType{entity.Sprint, entity.Verizon, entity.TMobile, entity.ATT} {
+		_, err := store.Load(context.Background(), carrier, "2018-11-01", []map[string]string{{"zipcode": "94105"}})
+		assert.NoError(t, err)
+		assert.NoError(t, store.Datasets().Promote(context.Background(), carrier, "2018-11-01"))
+	}
+
+	response := newTestHealth(dbclient.NewVersionedStore(store, 0)).Check(context.Background(), true)
+
+	assert.Equal(t, entity.HealthOK, response.Status)
+	assert.Equal(t, &entity.TableHealth{Status: entity.HealthOK}, response.Table)
+	assert.Len(t, response.Carriers, 4)
+	assert.Equal(t, entity.CarrierHealth{CarrierID: "1", Name: "Sprint", Loaded: true, LoadDate: "2018-11-01", Rows: 1}, response.Carriers[0])
+}
+
+func TestHealthDeepWithCarrierNotLoaded(t *testing.T) {
+	store := dbclient.NewMemoryStore()
+	_, err := store.Load(context.Background(), entity.Sprint, "2018-11-01", []map[string]string{{"zipcode": "94105"}})
+	assert.NoError(t, err)
+	assert.NoError(t, store.Datasets().Promote(context.Background(), entity.Sprint, "2018-11-01"))
+
+	response := newTestHealth(dbclient.NewVersionedStore(store, 0)).Check(context.Background(), true)
+
+	assert.Equal(t, entity.HealthDegraded, response.Status)
+	assert.True(t, response.Carriers[0].Loaded)
+	assert.Equal(t, entity.CarrierHealth{CarrierID: "2", Name: "Verizon"}, response.Carriers[1])
+}
+
+func TestHealthDeepWithBrokenTable(t *testing.T) {
+	response := newTestHealth(brokenStore{dbclient.NewMemoryStore()}).Check(context.Background(), true)
+
+	assert.Equal(t, entity.HealthUnavailable, response.Status)
+	assert.Equal(t, &entity.TableHealth{Status: entity.HealthUnavailable, Error: "ResourceNotFoundException"}, response.Table)
+	assert.Empty(t, response.Carriers)
+}
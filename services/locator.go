@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/rs/zerolog"
+)
+
+type Locator interface {
+	Nearest(ctx context.Context, lat float64, lon float64) (entity.ResolvedLocation, bool, error)
+}
+
+type locator struct {
+	dbClient dbclient.ZipCodeLocator
+}
+
+//NewLocator constructs and gives back a service resolving locations to zipcodes
+func NewLocator(dbClient dbclient.ZipCodeLocator) (locator, error) {
+	if dbClient == nil {
+		return locator{}, errors.New("Invalid zipcode locator")
+	}
+
+	return locator{
+		dbClient: dbClient,
+	}, nil
+}
+
+// Nearest resolves a location to the zipcode whose centroid is nearest. found is false when no zipcode is close enough.
+func (l locator) Nearest(ctx context.Context, lat float64, lon float64) (entity.ResolvedLocation, bool, error) {
+	zerolog.Ctx(ctx).Info().Msgf("Resolving zipcode nearest lat: %f and lon: %f", lat, lon)
+
+	nearby, found, err := l.dbClient.Nearest(ctx, lat, lon)
+	if err != nil || !found {
+		return entity.ResolvedLocation{}, false, err
+	}
+
+	return entity.ResolvedLocation{ZipCode: nearby.ZipCode, DistanceKm: math.Round(nearby.DistanceKm*100) / 100}, true, nil
+}
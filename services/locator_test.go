@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNewLocator(t *testing.T) {
+	locatorService, err := NewLocator(dbclient.NewZipCodeLocator(dbclient.NewMemoryStore()))
+	assert.NoError(t, err)
+	assert.IsType(t, locator{}, locatorService)
+
+	_, err = NewLocator(nil)
+	assert.Error(t, err)
+}
+
+func TestLocatorNearest(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		nearby        dbclient.NearbyZipCode
+		found         bool
+		err           error
+		expected      entity.ResolvedLocation
+		expectedFound bool
+	}{
+		{
+			desc:          "Found rounds the distance",
+			nearby:        dbclient.NearbyZipCode{ZipCode: "94105", DistanceKm: 1.23456},
+			found:         true,
+			expected:      entity.ResolvedLocation{ZipCode: "94105", DistanceKm: 1.23},
+			expectedFound: true,
+		},
+		{
+			desc: "Not found",
+		},
+		{
+			desc: "Db error",
+			err:  errors.New("Fake error"),
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			mockZipCodeLocator := mockZipCodeLocator{}
+			mockZipCodeLocator.On("Nearest", mock.Anything, 37.78, -122.4).Return(tC.nearby, tC.found, tC.err)
+
+			location, found, err := locator{dbClient: &mockZipCodeLocator}.Nearest(context.Background(), 37.78, -122.4)
+
+			assert.Equal(t, tC.err, err)
+			assert.Equal(t, tC.expectedFound, found)
+			assert.Equal(t, tC.expected, location)
+			mockZipCodeLocator.AssertExpectations(t)
+		})
+	}
+}
+
+type mockZipCodeLocator struct {
+	mock.Mock
+}
+
+func (m *mockZipCodeLocator) Nearest(ctx context.Context, lat float64, lon float64) (dbclient.NearbyZipCode, bool, error) {
+	args := m.Called(ctx, lat, lon)
+	return args.Get(0).(dbclient.NearbyZipCode), args.Bool(1), errOrNil(args.Get(2))
+}
+
+func (m *mockZipCodeLocator) Within(ctx context.Context, lat float64, lon float64, radiusKm float64) ([]dbclient.NearbyZipCode, error) {
+	args := m.Called(ctx, lat, lon, radiusKm)
+	return args.Get(0).([]dbclient.NearbyZipCode), errOrNil(args.Get(1))
+}
+
+func (m *mockZipCodeLocator) Centroid(ctx context.Context, zipCode string) (float64, float64, bool, error) {
+	args := m.Called(ctx, zipCode)
+	return args.Get(0).(float64), args.Get(1).(float64), args.Bool(2), errOrNil(args.Get(3))
+}
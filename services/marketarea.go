@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/rs/zerolog"
+)
+
+type MarketArea interface {
+	GetMarketAreas(ctx context.Context, zipCode string, carrierID string) (entity.MarketAreaResponse, error)
+}
+
+type marketArea struct {
+	dbClient dbclient.MarketAreaClient
+}
+
+//NewMarketArea constructs and gives back market area service
+func NewMarketArea(dbClient dbclient.MarketAreaClient, logger *zerolog.Logger) (marketArea, error) {
+	if dbClient == nil {
+		return marketArea{}, errors.New("Invalid market area db client")
+	}
+
+	return marketArea{
+		dbClient: dbClient,
+	}, nil
+}
+
+// GetMarketAreas looks up the market areas of a zipcode for a carrier, or for every registered carrier when
+// carrierID is all
+func (m marketArea) GetMarketAreas(ctx context.Context, zipCode string, carrierID string) (entity.MarketAreaResponse, error) {
+	zerolog.Ctx(ctx).Info().Msgf("Getting market areas for zipcode: %s and carrierID: %s", zipCode, carrierID)
+
+	var carriers []dbclient.Carrier
+	if entity.CarrierType(carrierID) == entity.AllCarriers {
+		carriers = dbclient.RegisteredCarriers()
+	} else {
+		carrier, ok := dbclient.LookupCarrier(entity.CarrierType(carrierID))
+		if !ok {
+			return entity.MarketAreaResponse{}, dbclient.ErrUnknownCarrier
+		}
+		carriers = []dbclient.Carrier{carrier}
+	}
+
+	response := entity.MarketAreaResponse{ZipCode: zipCode, Carriers: []entity.CarrierMarketAreas{}}
+	for _, carrier := range carriers {
+		marketAreas, err := m.dbClient.GetMarketAreas(ctx, carrier.ID, zipCode)
+		if err != nil {
+			return entity.MarketAreaResponse{}, err
+		}
+
+		areas := marketAreas.Areas
+		if areas == nil {
+			areas = []entity.MarketArea{}
+		}
+		response.Carriers = append(response.Carriers, entity.CarrierMarketAreas{
+			CarrierID:   string(carrier.ID),
+			Name:        carrier.Name,
+			Found:       marketAreas.Found,
+			Status:      statusOf(marketAreas.Coverage),
+			LoadDate:    marketAreas.Coverage.LoadDate,
+			MarketAreas: areas,
+		})
+	}
+	return response, nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"bitbucket.org/credomobile/coverage/rules"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNewMarketArea(t *testing.T) {
+	logger := zerolog.New(os.Stdout).With().Logger()
+	marketAreaService, err := NewMarketArea(dbclient.NewMarketAreaClient(dbclient.NewMemoryStore(), rules.Default()), &logger)
+
+	assert.NoError(t, err)
+	assert.IsType(t, marketArea{}, marketAreaService)
+	assert.Implements(t, (*dbclient.MarketAreaClient)(nil), marketAreaService.dbClient)
+}
+
+func TestNewMarketAreaWithoutDbClient(t *testing.T) {
+	logger := zerolog.New(os.Stdout).With().Logger()
+	_, err := NewMarketArea(nil, &logger)
+
+	assert.Error(t, err)
+}
+
+func TestGetMarketAreasHappyPathWithCsaFound(t *testing.T) {
+	mockMarketAreaDbClient := mockMarketAreaDbClient{}
+	mockMarketAreaDbClient.On("GetMarketAreas", mock.Anything, entity.Sprint, "94105").Return(dbclient.MarketAreas{
+		Found:    true,
+		Areas:    []entity.MarketArea{{Type: "CSA", Code: "fakeCsa"}},
+		Coverage: dbclient.Coverage{IsCovered: true, Found: true, LoadDate: "2018-11-01"},
+	}, nil)
+
+	marketAreaService := marketArea{
+		dbClient: &mockMarketAreaDbClient,
+	}
+
+	response, err := marketAreaService.GetMarketAreas(context.Background(), "94105", "1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, entity.MarketAreaResponse{
+		ZipCode: "94105",
+		Carriers: []entity.CarrierMarketAreas{
+			{CarrierID: "1", Name: "Sprint", Found: true, Status: entity.CoverageCovered, LoadDate: "2018-11-01", MarketAreas: []entity.MarketArea{{Type: "CSA", Code: "fakeCsa"}}},
+		},
+	}, response)
+	mockMarketAreaDbClient.AssertExpectations(t)
+}
+
+func TestGetMarketAreasHappyPathWithNoMarketAreas(t *testing.T) {
+	mockMarketAreaDbClient := mockMarketAreaDbClient{}
+	mockMarketAreaDbClient.On("GetMarketAreas", mock.Anything, entity.Verizon, "94105").Return(dbclient.MarketAreas{}, nil)
+
+	marketAreaService := marketArea{
+		dbClient: &mockMarketAreaDbClient,
+	}
+
+	response, err := marketAreaService.GetMarketAreas(context.Background(), "94105", "2")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []entity.CarrierMarketAreas{{CarrierID: "2", Name: "Verizon", Found: false, Status: entity.CoverageUnknown, MarketAreas: []entity.MarketArea{}}}, response.Carriers)
+	mockMarketAreaDbClient.AssertExpectations(t)
+}
+
+func TestGetMarketAreasForAllCarriers(t *testing.T) {
+	mockMarketAreaDbClient := mockMarketAreaDbClient{}
+	mockMarketAreaDbClient.On("GetMarketAreas", mock.Anything, mock.Anything, "94105").Return(dbclient.MarketAreas{}, nil)
+
+	marketAreaService := marketArea{
+		dbClient: &mockMarketAreaDbClient,
+	}
+
+	response, err := marketAreaService.GetMarketAreas(context.Background(), "94105", "all")
+
+	assert.NoError(t, err)
+	assert.Len(t, response.Carriers, 4)
+	assert.Equal(t, "1", response.Carriers[0].CarrierID)
+	assert.Equal(t, "4", response.Carriers[3].CarrierID)
+	mockMarketAreaDbClient.AssertNumberOfCalls(t, "GetMarketAreas", 4)
+}
+
+func TestGetMarketAreasSadPath(t *testing.T) {
+	mockMarketAreaDbClient := mockMarketAreaDbClient{}
+	mockMarketAreaDbClient.On("GetMarketAreas", mock.Anything, mock.Anything, mock.Anything).Return(dbclient.MarketAreas{}, errors.New("Fake Db error"))
+
+	marketAreaService := marketArea{
+		dbClient: &mockMarketAreaDbClient,
+	}
+
+	_, err := marketAreaService.GetMarketAreas(context.Background(), "94105", "1")
+	assert.Error(t, err)
+
+	_, err = marketAreaService.GetMarketAreas(context.Background(), "94105", "9")
+	assert.EqualError(t, err, "Invalid Carrier Type")
+}
+
+type mockMarketAreaDbClient struct {
+	mock.Mock
+}
+
+func (m *mockMarketAreaDbClient) GetMarketAreas(ctx context.Context, carrier entity.CarrierType, zipCode string) (dbclient.MarketAreas, error) {
+	args := m.Called(ctx, carrier, zipCode)
+	return args.Get(0).(dbclient.MarketAreas), errOrNil(args.Get(1))
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/rs/zerolog"
+)
+
+// kmPerMile converts the miles of a radius search into the kilometers of the zipcode locator
+const kmPerMile = 1.609344
+
+type RadiusCoverage interface {
+	Verify(ctx context.Context, center entity.RadiusCenter, radiusMiles float64, carrierID string, detail bool) (entity.RadiusCoverageResponse, bool, error)
+}
+
+type radiusCoverage struct {
+	coverageCheck
+	locator dbclient.ZipCodeLocator
+}
+
+//NewRadiusCoverage constructs and gives back a service checking the coverage of the zipcodes around a point
+func NewRadiusCoverage(dbclientFactory dbclient.ClientFactory, locator dbclient.ZipCodeLocator) (radiusCoverage, error) {
+	if dbclientFactory == nil || locator == nil {
+		return radiusCoverage{}, errors.New("Invalid db client factory or zipcode locator")
+	}
+
+	return radiusCoverage{
+		coverageCheck: coverageCheck{dbclientFactory: dbclientFactory},
+		locator:       locator,
+	}, nil
+}
+
+// Verify checks a carrier's coverage, or every carrier's for carrierid all, of each zipcode within the radius of the
+// center. A center with a zipcode is moved onto the zipcode's centroid; found is false when it has none.
+// A failed carrier lookup is reported on that carrier's results; an error is only returned when every lookup failed.
+func (c radiusCoverage) Verify(ctx context.Context, center entity.RadiusCenter, radiusMiles float64, carrierID string, detail bool) (entity.RadiusCoverageResponse, bool, error) {
+	if center.ZipCode != "" {
+		lat, lon, found, err := c.locator.Centroid(ctx, center.ZipCode)
+		if err != nil || !found {
+			return entity.RadiusCoverageResponse{}, false, err
+		}
+		center.Lat, center.Lon = lat, lon
+	}
+	zerolog.Ctx(ctx).Info().Msgf("Verifying coverage within %.2f miles of lat: %f and lon: %f", radiusMiles, center.Lat, center.Lon)
+
+	nearby, err := c.locator.Within(ctx, center.Lat, center.Lon, radiusMiles*kmPerMile)
+	if err != nil {
+		return entity.RadiusCoverageResponse{}, false, err
+	}
+	zipCodes := make([]string, len(nearby))
+	for i, zipCode := range nearby {
+		zipCodes[i] = zipCode.ZipCode
+	}
+
+	carriers := []entity.CarrierType{entity.CarrierType(carrierID)}
+	if carriers[0] == entity.AllCarriers {
+		carriers = c.dbclientFactory.Carriers()
+	}
+
+	coverageByCarrier := make([]map[string]dbclient.Coverage, len(carriers))
+	errs := make([]error, len(carriers))
+	if len(zipCodes) > 0 {
+		var wg sync.WaitGroup
+		for i, carrier := range carriers {
+			wg.Add(1)
+			go func(i int, carrier entity.CarrierType) {
+				defer wg.Done()
+
+				coverageByCarrier[i], errs[i] = c.verifyAll(ctx, carrier, zipCodes)
+				if errs[i] != nil {
+					zerolog.Ctx(ctx).Error().Err(errs[i]).Msgf("failed to verify coverage of %d zipcodes for carrierID: %s", len(zipCodes), carrier)
+				}
+			}(i, carrier)
+		}
+		wg.Wait()
+	}
+
+	response := entity.RadiusCoverageResponse{
+		Center:      center,
+		RadiusMiles: radiusMiles,
+		ZipCodes:    make([]entity.RadiusZipCode, len(nearby)),
+		Carriers:    make([]entity.CarrierRadiusSummary, len(carriers)),
+	}
+	for i, zipCode := range nearby {
+		response.ZipCodes[i] = entity.RadiusZipCode{
+			ZipCode:       zipCode.ZipCode,
+			DistanceMiles: math.Round(zipCode.DistanceKm/kmPerMile*100) / 100,
+			Carriers:      make([]entity.CarrierCoverageResult, len(carriers)),
+		}
+	}
+
+	var failures []error
+	for j, carrier := range carriers {
+		summary := entity.CarrierRadiusSummary{CarrierID: string(carrier), ZipCodes: len(nearby)}
+		if schema, ok := dbclient.LookupCarrier(carrier); ok {
+			summary.Name = schema.Name
+		}
+		if errs[j] != nil {
+			failures = append(failures, errs[j])
+			summary.Errors = carrierFailed(errs[j])
+		}
+
+		for i := range response.ZipCodes {
+			result := &response.ZipCodes[i].Carriers[j]
+			result.CarrierID = string(carrier)
+			if errs[j] != nil {
+				result.Errors = summary.Errors
+				continue
+			}
+			coverage := c.response(coverageByCarrier[j][response.ZipCodes[i].ZipCode], detail)
+			result.CoverageCheckResponse = &coverage
+			switch {
+			case coverage.IsCovered:
+				summary.Covered++
+			case coverage.Status == entity.CoverageUnknown:
+				summary.Unknown++
+			}
+		}
+		// a zipcode missing from the carrier's data is no evidence it isn't covered, so it is left out of the share
+		if known := summary.ZipCodes - summary.Unknown; known > 0 && errs[j] == nil {
+			summary.CoveredPercent = math.Round(float64(summary.Covered)/float64(known)*10000) / 100
+		}
+		response.Carriers[j] = summary
+	}
+	if len(failures) > 0 && len(failures) == len(carriers) {
+		return entity.RadiusCoverageResponse{}, false, allCarriersFailed(failures)
+	}
+	return response, true, nil
+}
+
+// verifyAll checks a carrier's coverage of the zipcodes in one batch lookup
+func (c radiusCoverage) verifyAll(ctx context.Context, carrier entity.CarrierType, zipCodes []string) (map[string]dbclient.Coverage, error) {
+	dbClient, err := resolveCarrier(ctx, c.dbclientFactory, carrier)
+	if err != nil {
+		return nil, err
+	}
+
+	return dbClient.BatchVerifyCoverage(ctx, zipCodes)
+}
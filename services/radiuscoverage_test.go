@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/dbclient"
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNewRadiusCoverage(t *testing.T) {
+	service, err := NewRadiusCoverage(mockClientFactory{}, &mockZipCodeLocator{})
+	assert.NoError(t, err)
+	assert.IsType(t, radiusCoverage{}, service)
+
+	_, err = NewRadiusCoverage(mockClientFactory{}, nil)
+	assert.Error(t, err)
+}
+
+func TestRadiusCoverageVerifyAllCarriers(t *testing.T) {
+	dbClientFactory := mockClientFactory{}
+	mockZipCodeLocator := mockZipCodeLocator{}
+	mockZipCodeLocator.On("Within", mock.Anything, 37.78, -122.4, 10*kmPerMile).Return([]dbclient.NearbyZipCode{
+		{ZipCode: "94105", DistanceKm: 1.2},
+		{ZipCode: "94110", DistanceKm: 5.5},
+	}, nil)
+
+	mockSprintClient := mockSprintClient{}
+	mockSprintClient.On("BatchVerifyCoverage", mock.Anything, []string{"94105", "94110"}).Return(map[string]dbclient.Coverage{"94105": {IsCovered: true, Found: true}}, nil)
+	mockVerizonClient := mockVerizonClient{}
+	mockVerizonClient.On("BatchVerifyCoverage", mock.Anything, []string{"94105", "94110"}).Return(map[string]dbclient.Coverage{"94105": {IsCovered: true, Found: true}, "94110": {IsCovered: true, Found: true}}, nil)
+	dbClientFactory.On("Carriers").Return([]entity.CarrierType{entity.Sprint, entity.Verizon})
+	dbClientFactory.On("GetDbClient", entity.Sprint).Return(mockSprintClient, nil)
+	dbClientFactory.On("GetDbClient", entity.Verizon).Return(mockVerizonClient, nil)
+
+	service, _ := NewRadiusCoverage(dbClientFactory, &mockZipCodeLocator)
+	response, found, err := service.Verify(context.Background(), entity.RadiusCenter{Lat: 37.78, Lon: -122.4}, 10, "all", false)
+
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, entity.RadiusCenter{Lat: 37.78, Lon: -122.4}, response.Center)
+	assert.Equal(t, []entity.RadiusZipCode{
+		{ZipCode: "94105", DistanceMiles: 0.75, Carriers: []entity.CarrierCoverageResult{
+			{CarrierID: "1", CoverageCheckResponse: &entity.CoverageCheckResponse{IsCovered: true, Status: entity.CoverageCovered, RuleVersion: "fakeRuleVersion"}},
+			{CarrierID: "2", CoverageCheckResponse: &entity.CoverageCheckResponse{IsCovered: true, Status: entity.CoverageCovered, RuleVersion: "fakeRuleVersion"}},
+		}},
+		{ZipCode: "94110", DistanceMiles: 3.42, Carriers: []entity.CarrierCoverageResult{
+			{CarrierID: "1", CoverageCheckResponse: &entity.CoverageCheckResponse{IsCovered: false, Status: entity.CoverageUnknown, RuleVersion: "fakeRuleVersion"}},
+			{CarrierID: "2", CoverageCheckResponse: &entity.CoverageCheckResponse{IsCovered: true, Status: entity.CoverageCovered, RuleVersion: "fakeRuleVersion"}},
+		}},
+	}, response.ZipCodes)
+	assert.Equal(t, []entity.CarrierRadiusSummary{
+		{CarrierID: "1", Name: "Sprint", ZipCodes: 2, Covered: 1, Unknown: 1, CoveredPercent: 100},
+		{CarrierID: "2", Name: "Verizon", ZipCodes: 2, Covered: 2, CoveredPercent: 100},
+	}, response.Carriers)
+	mockZipCodeLocator.AssertExpectations(t)
+	mockSprintClient.AssertExpectations(t)
+	mockVerizonClient.AssertExpectations(t)
+}
+
+func TestRadiusCoverageVerifyAroundZipCode(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		found         bool
+		err           error
+		expectedFound bool
+	}{
+		{desc: "Centroid found", found: true, expectedFound: true},
+		{desc: "No centroid"},
+		{desc: "Db error", err: errors.New("Fake error")},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			dbClientFactory := mockClientFactory{}
+			mockZipCodeLocator := mockZipCodeLocator{}
+			mockZipCodeLocator.On("Centroid", mock.Anything, "94105").Return(37.7898, -122.3942, tC.found, tC.err)
+			if tC.expectedFound {
+				mockZipCodeLocator.On("Within", mock.Anything, 37.7898, -122.3942, 5*kmPerMile).Return([]dbclient.NearbyZipCode(nil), nil)
+			}
+
+			service, _ := NewRadiusCoverage(dbClientFactory, &mockZipCodeLocator)
+			response, found, err := service.Verify(context.Background(), entity.RadiusCenter{ZipCode: "94105"}, 5, "1", false)
+
+			assert.Equal(t, tC.err, err)
+			assert.Equal(t, tC.expectedFound, found)
+			if tC.expectedFound {
+				assert.Equal(t, entity.RadiusCenter{Lat: 37.7898, Lon: -122.3942, ZipCode: "94105"}, response.Center)
+				assert.Empty(t, response.ZipCodes)
+				assert.Equal(t, []entity.CarrierRadiusSummary{{CarrierID: "1", Name: "Sprint"}}, response.Carriers)
+			}
+			mockZipCodeLocator.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRadiusCoverageVerifyWithCarrierFailing(t *testing.T) {
+	nearby := []dbclient.NearbyZipCode{{ZipCode: "94105", DistanceKm: 1.2}}
+
+	t.Run("One carrier failing", func(t *testing.T) {
+		dbClientFactory := mockClientFactory{}
+		mockZipCodeLocator := mockZipCodeLocator{}
+		mockZipCodeLocator.On("Within", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nearby, nil)
+		mockSprintClient := mockSprintClient{}
+		mockSprintClient.On("BatchVerifyCoverage", mock.Anything, mock.Anything).Return(map[string]dbclient.Coverage(nil), errors.New("Fake db Client error"))
+		mockVerizonClient := mockVerizonClient{}
+		mockVerizonClient.On("BatchVerifyCoverage", mock.Anything, mock.Anything).Return(map[string]dbclient.Coverage{"94105": {IsCovered: true}}, nil)
+		dbClientFactory.On("Carriers").Return([]entity.CarrierType{entity.Sprint, entity.Verizon})
+		dbClientFactory.On("GetDbClient", entity.Sprint).Return(mockSprintClient, nil)
+		dbClientFactory.On("GetDbClient", entity.Verizon).Return(mockVerizonClient, nil)
+
+		service, _ := NewRadiusCoverage(dbClientFactory, &mockZipCodeLocator)
+		response, found, err := service.Verify(context.Background(), entity.RadiusCenter{Lat: 37.78, Lon: -122.4}, 10, "all", false)
+
+		assert.NoError(t, err)
+		assert.True(t, found)
+		failed := []entity.Error{{Message: "Unable to check coverage for carrier", Path: "carrierid", Code: entity.CodeInternal}}
+		assert.Equal(t, entity.CarrierRadiusSummary{CarrierID: "1", Name: "Sprint", ZipCodes: 1, Errors: failed}, response.Carriers[0])
+		assert.Equal(t, entity.CarrierCoverageResult{CarrierID: "1", Errors: failed}, response.ZipCodes[0].Carriers[0])
+		assert.Equal(t, 100.0, response.Carriers[1].CoveredPercent)
+	})
+
+	t.Run("Every carrier failing", func(t *testing.T) {
+		dbClientFactory := mockClientFactory{}
+		mockZipCodeLocator := mockZipCodeLocator{}
+		mockZipCodeLocator.On("Within", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nearby, nil)
+		dbClientFactory.On("GetDbClient", entity.Sprint).Return(mockSprintClient{}, errors.New("Fake db Client Factory error"))
+
+		service, _ := NewRadiusCoverage(dbClientFactory, &mockZipCodeLocator)
+		_, _, err := service.Verify(context.Background(), entity.RadiusCenter{Lat: 37.78, Lon: -122.4}, 10, "1", false)
+
+		assert.Error(t, err)
+	})
+}
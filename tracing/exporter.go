@@ -0,0 +1,185 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// consoleExporter writes the spans as JSON lines, for local debugging
+type consoleExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewConsoleExporter constructs and gives back an exporter writing a JSON line per span
+func NewConsoleExporter(w io.Writer) Exporter {
+	return &consoleExporter{w: w}
+}
+
+type consoleSpan struct {
+	TraceID    string            `json:"traceId"`
+	SpanID     string            `json:"spanId"`
+	ParentID   string            `json:"parentSpanId,omitempty"`
+	Name       string            `json:"name"`
+	Kind       Kind              `json:"kind"`
+	Start      string            `json:"start"`
+	DurationMs float64           `json:"durationMs"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+func (e *consoleExporter) Export(ctx context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	encoder := json.NewEncoder(e.w)
+	for _, span := range spans {
+		line := consoleSpan{
+			TraceID:    span.TraceID.String(),
+			SpanID:     span.SpanID.String(),
+			Name:       span.Name,
+			Kind:       span.Kind,
+			Start:      span.Start.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+			DurationMs: float64(span.End.Sub(span.Start).Nanoseconds()) / 1e6,
+			Attributes: span.Attributes,
+			Error:      span.Error,
+		}
+		if span.Parent.IsValid() {
+			line.ParentID = span.Parent.String()
+		}
+		if err := encoder.Encode(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// otlpExporter posts the spans to an OTLP/HTTP collector in the JSON encoding
+type otlpExporter struct {
+	url         string
+	client      *http.Client
+	serviceName string
+}
+
+// NewOTLPExporter constructs and gives back an exporter posting to the traces path of an OTLP/HTTP endpoint such
+// as http://localhost:4318, with the service name as the service.name resource attribute
+func NewOTLPExporter(endpoint string, client *http.Client, serviceName string) Exporter {
+	return otlpExporter{url: strings.TrimSuffix(endpoint, "/") + "/v1/traces", client: client, serviceName: serviceName}
+}
+
+// the otlp types are the parts of the OTLP trace export request the exporter fills in
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              Kind            `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpAttribute struct {
+	Key   string          `json:"key"`
+	Value otlpStringValue `json:"value"`
+}
+
+type otlpStringValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+// otlpStatus is unset for a span that succeeded and an error, code 2, for one that failed
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+func (e otlpExporter) Export(ctx context.Context, spans []SpanData) error {
+	request := otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: []otlpAttribute{{Key: "service.name", Value: otlpStringValue{e.serviceName}}}},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: e.serviceName}, Spans: make([]otlpSpan, 0, len(spans))}},
+	}}}
+	for _, span := range spans {
+		s := otlpSpan{
+			TraceID:           span.TraceID.String(),
+			SpanID:            span.SpanID.String(),
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.Attributes),
+		}
+		if span.Parent.IsValid() {
+			s.ParentSpanID = span.Parent.String()
+		}
+		if span.Error != "" {
+			s.Status = otlpStatus{Code: 2, Message: span.Error}
+		}
+		request.ResourceSpans[0].ScopeSpans[0].Spans = append(request.ResourceSpans[0].ScopeSpans[0].Spans, s)
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := e.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	io.Copy(ioutil.Discard, res.Body)
+	if res.StatusCode/100 != 2 {
+		return fmt.Errorf("unable to export spans: %s", res.Status)
+	}
+	return nil
+}
+
+// otlpAttributes gives back the attributes sorted by key, so the requests are stable
+func otlpAttributes(attributes map[string]string) []otlpAttribute {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	values := make([]otlpAttribute, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, otlpAttribute{Key: key, Value: otlpStringValue{attributes[key]}})
+	}
+	return values
+}
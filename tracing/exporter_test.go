@@ -0,0 +1,84 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testSpans() []SpanData {
+	start := time.Date(2018, 10, 9, 0, 0, 0, 0, time.UTC)
+	root := SpanData{
+		SpanContext: SpanContext{TraceID: TraceID{0x4b, 0xf9}, SpanID: SpanID{0x01}},
+		Name:        "GET /v1/coveragecheck",
+		Kind:        KindServer,
+		Start:       start,
+		End:         start.Add(25 * time.Millisecond),
+		Attributes:  map[string]string{"http.status_code": "200", "http.method": "GET"},
+	}
+	child := SpanData{
+		SpanContext: SpanContext{TraceID: TraceID{0x4b, 0xf9}, SpanID: SpanID{0x02}},
+		Parent:      SpanID{0x01},
+		Name:        "DynamoDB.GetItem",
+		Kind:        KindClient,
+		Start:       start,
+		End:         start.Add(10 * time.Millisecond),
+		Attributes:  map[string]string{},
+		Error:       "ResourceNotFoundException",
+	}
+	return []SpanData{child, root}
+}
+
+func TestConsoleExporter(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, NewConsoleExporter(&buf).Export(context.Background(), testSpans()))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 2)
+	assert.JSONEq(t, `{"traceId":"4bf90000000000000000000000000000","spanId":"0200000000000000","parentSpanId":"0100000000000000","name":"DynamoDB.GetItem","kind":3,"start":"2018-10-09T00:00:00.000000Z","durationMs":10,"error":"ResourceNotFoundException"}`, string(lines[0]))
+	assert.JSONEq(t, `{"traceId":"4bf90000000000000000000000000000","spanId":"0100000000000000","name":"GET /v1/coveragecheck","kind":2,"start":"2018-10-09T00:00:00.000000Z","durationMs":25,"attributes":{"http.method":"GET","http.status_code":"200"}}`, string(lines[1]))
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/traces", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer ts.Close()
+
+	assert.NoError(t, NewOTLPExporter(ts.URL+"/", ts.Client(), "coverage").Export(context.Background(), testSpans()))
+
+	var request otlpRequest
+	assert.NoError(t, json.Unmarshal(body, &request))
+	assert.Equal(t, []otlpAttribute{{Key: "service.name", Value: otlpStringValue{"coverage"}}}, request.ResourceSpans[0].Resource.Attributes)
+	spans := request.ResourceSpans[0].ScopeSpans[0].Spans
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "0100000000000000", spans[0].ParentSpanID)
+	assert.Equal(t, otlpStatus{Code: 2, Message: "ResourceNotFoundException"}, spans[0].Status)
+	assert.Equal(t, "1539043200000000000", spans[0].StartTimeUnixNano)
+	assert.Empty(t, spans[1].ParentSpanID)
+	assert.Equal(t, otlpStatus{}, spans[1].Status)
+	assert.Equal(t, []otlpAttribute{
+		{Key: "http.method", Value: otlpStringValue{"GET"}},
+		{Key: "http.status_code", Value: otlpStringValue{"200"}},
+	}, spans[1].Attributes)
+}
+
+func TestOTLPExporterError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	err := NewOTLPExporter(ts.URL, ts.Client(), "coverage").Export(context.Background(), testSpans())
+	assert.EqualError(t, err, "unable to export spans: 503 Service Unavailable")
+}
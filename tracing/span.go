@@ -0,0 +1,188 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kind is the role of a span in a trace, with the values of OpenTelemetry's SpanKind
+type Kind int
+
+const (
+	KindInternal Kind = 1
+	KindServer   Kind = 2
+	KindClient   Kind = 3
+)
+
+// TraceID and SpanID identify a trace and a span of it, as in W3C trace context
+type (
+	TraceID [16]byte
+	SpanID  [8]byte
+)
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+func (s SpanID) String() string  { return hex.EncodeToString(s[:]) }
+
+// IsValid tells whether the id is set, an all zero id is invalid in W3C trace context
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+func (s SpanID) IsValid() bool  { return s != SpanID{} }
+
+// SpanContext is what identifies a span across services: its trace and its own id
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+}
+
+// ParseTraceParent gives back the span context of a W3C traceparent header such as
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01. ok is false for a malformed or invalid one.
+func ParseTraceParent(header string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return SpanContext{}, false
+	}
+	var sc SpanContext
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return SpanContext{}, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return SpanContext{}, false
+	}
+	return sc, sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// TraceParent gives back the W3C traceparent header of the span context, sampled
+func (sc SpanContext) TraceParent() string {
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-01"
+}
+
+// TraceIDFromTrackingID gives back the trace id of an x-trackingid that is a UUID, so the callers' tracking ids
+// find their traces. ok is false for any other tracking id.
+func TraceIDFromTrackingID(trackingID string) (TraceID, bool) {
+	var id TraceID
+	raw := strings.Replace(trackingID, "-", "", -1)
+	if len(raw) != 32 {
+		return TraceID{}, false
+	}
+	if _, err := hex.Decode(id[:], []byte(raw)); err != nil {
+		return TraceID{}, false
+	}
+	return id, id.IsValid()
+}
+
+// NewTraceID gives back a random trace id
+func NewTraceID() TraceID {
+	var id TraceID
+	rand.Read(id[:])
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	rand.Read(id[:])
+	return id
+}
+
+// SpanData is a finished span as it is exported
+type SpanData struct {
+	SpanContext
+	Parent     SpanID
+	Name       string
+	Kind       Kind
+	Start      time.Time
+	End        time.Time
+	Attributes map[string]string
+	// Error is the message of the error the span ended with, empty when it succeeded
+	Error string
+}
+
+// Span is an operation being traced. A nil span, given back when tracing is off, ignores every call, so the
+// instrumented code doesn't check whether tracing is on.
+type Span struct {
+	tracer *Tracer
+	mu     sync.Mutex
+	data   SpanData
+	ended  bool
+}
+
+// SpanContext gives back the ids of the span
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.data.SpanContext
+}
+
+// SetName renames the span, such as a request span once its route is known
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Name = name
+}
+
+// SetAttribute sets an attribute of the span
+func (s *Span) SetAttribute(key string, value string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Attributes[key] = value
+}
+
+// RecordError marks the span as failed with the error, a nil error is ignored
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Error = err.Error()
+}
+
+// End finishes the span and hands it to its tracer's exporter. Ending it again does nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = s.tracer.now()
+	data := s.data
+	s.mu.Unlock()
+	s.tracer.finish(data)
+}
+
+type spanKey struct{}
+type remoteKey struct{}
+
+// ContextWithRemoteParent gives back a copy of the context whose next span continues the trace of another service
+func ContextWithRemoteParent(ctx context.Context, parent SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, parent)
+}
+
+// SpanFromContext gives back the span the context carries, nil when there is none
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// Start starts a span with the global tracer, as a child of the span in the context, or of the remote parent put
+// in by ContextWithRemoteParent, or as the root of a new trace. It gives back a nil span when tracing is off.
+func Start(ctx context.Context, name string, kind Kind) (context.Context, *Span) {
+	tracer := globalTracer()
+	if tracer == nil {
+		return ctx, nil
+	}
+	return tracer.Start(ctx, name, kind)
+}
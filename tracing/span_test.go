@@ -0,0 +1,125 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingExporter keeps the exported spans
+type recordingExporter struct {
+	spans []SpanData
+}
+
+func (e *recordingExporter) Export(ctx context.Context, spans []SpanData) error {
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func TestStart(t *testing.T) {
+	exporter := &recordingExporter{}
+	tracer := NewTracer(exporter, nil)
+	tracer.now = func() time.Time { return time.Date(2018, 10, 9, 0, 0, 0, 0, time.UTC) }
+
+	ctx, root := tracer.Start(context.Background(), "GET /v1/coveragecheck", KindServer)
+	_, child := tracer.Start(ctx, "resolve carrier", KindInternal)
+	child.SetAttribute("carrier.id", "1")
+	child.RecordError(errors.New("unknown carrier"))
+	child.End()
+	child.End()
+	root.RecordError(nil)
+	root.End()
+
+	assert.NoError(t, tracer.Flush(context.Background()))
+	assert.Len(t, exporter.spans, 2)
+	assert.Equal(t, "resolve carrier", exporter.spans[0].Name)
+	assert.Equal(t, root.SpanContext().TraceID, exporter.spans[0].TraceID)
+	assert.Equal(t, root.SpanContext().SpanID, exporter.spans[0].Parent)
+	assert.Equal(t, map[string]string{"carrier.id": "1"}, exporter.spans[0].Attributes)
+	assert.Equal(t, "unknown carrier", exporter.spans[0].Error)
+	assert.False(t, exporter.spans[1].Parent.IsValid())
+	assert.Empty(t, exporter.spans[1].Error)
+
+	// nothing is left to export
+	assert.NoError(t, tracer.Flush(context.Background()))
+	assert.Len(t, exporter.spans, 2)
+}
+
+func TestStartRemoteParent(t *testing.T) {
+	tracer := NewTracer(&recordingExporter{}, nil)
+	parent, ok := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.True(t, ok)
+
+	_, span := tracer.Start(ContextWithRemoteParent(context.Background(), parent), "GET", KindServer)
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", span.data.Parent.String())
+	assert.NotEqual(t, parent.SpanID, span.SpanContext().SpanID)
+}
+
+func TestStartOff(t *testing.T) {
+	SetTracer(nil)
+	ctx, span := Start(context.Background(), "validate", KindInternal)
+
+	assert.Nil(t, span)
+	assert.Nil(t, SpanFromContext(ctx))
+	// a nil span ignores every call
+	span.SetName("validate")
+	span.SetAttribute("validation.errors", "0")
+	span.RecordError(errors.New("failed"))
+	span.End()
+	assert.NoError(t, Flush(context.Background()))
+}
+
+func TestParseTraceParent(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		header string
+		ok     bool
+	}{
+		{desc: "Sampled", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", ok: true},
+		{desc: "Not sampled", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", ok: true},
+		{desc: "Empty", header: ""},
+		{desc: "Invalid version", header: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{desc: "Zero trace id", header: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{desc: "Zero span id", header: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"},
+		{desc: "Not hex", header: "00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01"},
+		{desc: "Short", header: "00-4bf92f3577b34da6-00f067aa0ba902b7-01"},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			sc, ok := ParseTraceParent(tC.header)
+			assert.Equal(t, tC.ok, ok)
+			if ok {
+				assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", sc.TraceParent())
+			}
+		})
+	}
+}
+
+func TestTraceIDFromTrackingID(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		trackingID string
+		traceID    string
+		ok         bool
+	}{
+		{desc: "UUID", trackingID: "565242ac-a5d7-4377-a88f-adc0d8cdd3ea", traceID: "565242aca5d74377a88fadc0d8cdd3ea", ok: true},
+		{desc: "Bare hex", trackingID: "565242aca5d74377a88fadc0d8cdd3ea", traceID: "565242aca5d74377a88fadc0d8cdd3ea", ok: true},
+		{desc: "Not a UUID", trackingID: "order-1234"},
+		{desc: "Zero", trackingID: "00000000-0000-0000-0000-000000000000"},
+		{desc: "Empty", trackingID: ""},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			traceID, ok := TraceIDFromTrackingID(tC.trackingID)
+			assert.Equal(t, tC.ok, ok)
+			if ok {
+				assert.Equal(t, tC.traceID, traceID.String())
+			}
+		})
+	}
+}
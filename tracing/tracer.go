@@ -0,0 +1,104 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultBatchSize is how many finished spans a tracer holds before exporting them without waiting for a Flush
+const DefaultBatchSize = 512
+
+// Exporter sends finished spans to a tracing backend
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+}
+
+// Tracer starts spans and batches the finished ones for its exporter. Every trace is kept, there is no sampling.
+type Tracer struct {
+	exporter  Exporter
+	batchSize int
+	now       func() time.Time
+	// flushErrors gets the error of an export started by a full batch, which has no caller to give it back to
+	flushErrors func(error)
+
+	mu      sync.Mutex
+	pending []SpanData
+}
+
+// NewTracer constructs and gives back a tracer exporting with the exporter. The errors of the exports of full
+// batches are handed to onError.
+func NewTracer(exporter Exporter, onError func(error)) *Tracer {
+	return &Tracer{exporter: exporter, batchSize: DefaultBatchSize, now: time.Now, flushErrors: onError}
+}
+
+// Start starts a span, see the package level Start
+func (t *Tracer) Start(ctx context.Context, name string, kind Kind) (context.Context, *Span) {
+	data := SpanData{Name: name, Kind: kind, Start: t.now(), Attributes: map[string]string{}}
+	if parent := SpanFromContext(ctx); parent != nil {
+		data.TraceID = parent.data.TraceID
+		data.Parent = parent.data.SpanID
+	} else if remote, ok := ctx.Value(remoteKey{}).(SpanContext); ok && remote.TraceID.IsValid() {
+		data.TraceID = remote.TraceID
+		data.Parent = remote.SpanID
+	} else {
+		data.TraceID = NewTraceID()
+	}
+	data.SpanID = newSpanID()
+
+	span := &Span{tracer: t, data: data}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// finish queues a finished span, exporting the batch in the background once it is full
+func (t *Tracer) finish(data SpanData) {
+	t.mu.Lock()
+	t.pending = append(t.pending, data)
+	full := len(t.pending) >= t.batchSize
+	t.mu.Unlock()
+	if full {
+		go func() {
+			if err := t.Flush(context.Background()); err != nil && t.flushErrors != nil {
+				t.flushErrors(err)
+			}
+		}()
+	}
+}
+
+// Flush exports the finished spans. Lambda calls it before returning, as the instance may be frozen right after.
+func (t *Tracer) Flush(ctx context.Context) error {
+	t.mu.Lock()
+	spans := t.pending
+	t.pending = nil
+	t.mu.Unlock()
+	if len(spans) == 0 {
+		return nil
+	}
+	return t.exporter.Export(ctx, spans)
+}
+
+var (
+	globalMu sync.RWMutex
+	global   *Tracer
+)
+
+// SetTracer sets the tracer the package level Start uses, nil turns tracing off
+func SetTracer(tracer *Tracer) {
+	globalMu.Lock()
+	defer globalMu.Unlock()
+	global = tracer
+}
+
+func globalTracer() *Tracer {
+	globalMu.RLock()
+	defer globalMu.RUnlock()
+	return global
+}
+
+// Flush flushes the global tracer, if tracing is on
+func Flush(ctx context.Context) error {
+	if tracer := globalTracer(); tracer != nil {
+		return tracer.Flush(ctx)
+	}
+	return nil
+}
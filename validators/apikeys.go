@@ -0,0 +1,77 @@
+package validators
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/go-chi/chi"
+)
+
+// clientIDRegex matches api client ids such as partner-acme. The # separating the parts of the table ids is left out.
+var clientIDRegex = regexp.MustCompile("^[A-Za-z0-9_-]{1,64}$")
+
+// DefaultAPIUsageDays and MaxAPIUsageDays bound the days of an api client's usage report
+const (
+	DefaultAPIUsageDays = 7
+	MaxAPIUsageDays     = 90
+)
+
+type APIKeysValidator interface {
+	Validate(ctx context.Context, request entity.APIClientRequest) []entity.Error
+	ValidateClient(ctx context.Context, r *http.Request) []entity.Error
+	ValidateUsage(ctx context.Context, r *http.Request) []entity.Error
+}
+type apiKeysValidator struct {
+}
+
+func NewAPIKeysValidator() APIKeysValidator {
+	return apiKeysValidator{}
+}
+
+// Validate validates the client id, name and limits of a new api client
+func (v apiKeysValidator) Validate(ctx context.Context, request entity.APIClientRequest) []entity.Error {
+	var validationErrors []entity.Error
+
+	if request.ClientID == "" {
+		validationErrors = append(validationErrors, entity.Error{Message: "Missing required property", Path: "clientid"})
+	} else if !clientIDRegex.MatchString(request.ClientID) {
+		validationErrors = append(validationErrors, entity.Error{Message: "Illegal value for property", Path: "clientid"})
+	}
+	if request.Name == "" {
+		validationErrors = append(validationErrors, entity.Error{Message: "Missing required property", Path: "name"})
+	} else if len(request.Name) > 128 {
+		validationErrors = append(validationErrors, entity.Error{Message: "Illegal value for property", Path: "name"})
+	}
+	if request.RateLimit < 0 {
+		validationErrors = append(validationErrors, entity.Error{Message: "Illegal value for property", Path: "ratelimit"})
+	}
+	if request.DailyQuota < 0 {
+		validationErrors = append(validationErrors, entity.Error{Message: "Illegal value for property", Path: "dailyquota"})
+	}
+	return validationErrors
+}
+
+// ValidateClient validates the clientid path parameter
+func (v apiKeysValidator) ValidateClient(ctx context.Context, r *http.Request) []entity.Error {
+	var validationErrors []entity.Error
+
+	if !clientIDRegex.MatchString(chi.URLParam(r, "clientid")) {
+		validationErrors = append(validationErrors, entity.Error{Message: "Illegal value for property", Path: "clientid"})
+	}
+	return validationErrors
+}
+
+// ValidateUsage validates the clientid path parameter and the optional days of a usage report
+func (v apiKeysValidator) ValidateUsage(ctx context.Context, r *http.Request) []entity.Error {
+	validationErrors := v.ValidateClient(ctx, r)
+
+	if days := r.URL.Query().Get("days"); days != "" {
+		if n, err := strconv.Atoi(days); err != nil || n < 1 || n > MaxAPIUsageDays {
+			validationErrors = append(validationErrors, entity.Error{Message: "Illegal value for property", Path: "days"})
+		}
+	}
+	return validationErrors
+}
@@ -0,0 +1,103 @@
+package validators
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeysValidator(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		request          entity.APIClientRequest
+		expectedResponse []entity.Error
+	}{
+		{
+			desc:    "Validates a valid client",
+			request: entity.APIClientRequest{ClientID: "partner-acme", Name: "Acme", RateLimit: 5, DailyQuota: 10000},
+		},
+		{
+			desc:    "Validates an unlimited client",
+			request: entity.APIClientRequest{ClientID: "team_sales", Name: "Sales"},
+		},
+		{
+			desc:             "Validates a missing clientid and name",
+			request:          entity.APIClientRequest{},
+			expectedResponse: []entity.Error{{Message: "Missing required property", Path: "clientid"}, {Message: "Missing required property", Path: "name"}},
+		},
+		{
+			desc:             "Validates an invalid clientid",
+			request:          entity.APIClientRequest{ClientID: "partner#acme", Name: "Acme"},
+			expectedResponse: []entity.Error{{Message: "Illegal value for property", Path: "clientid"}},
+		},
+		{
+			desc:             "Validates a long name",
+			request:          entity.APIClientRequest{ClientID: "partner", Name: strings.Repeat("a", 129)},
+			expectedResponse: []entity.Error{{Message: "Illegal value for property", Path: "name"}},
+		},
+		{
+			desc:             "Validates negative limits",
+			request:          entity.APIClientRequest{ClientID: "partner", Name: "Acme", RateLimit: -1, DailyQuota: -1},
+			expectedResponse: []entity.Error{{Message: "Illegal value for property", Path: "ratelimit"}, {Message: "Illegal value for property", Path: "dailyquota"}},
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			response := NewAPIKeysValidator().Validate(context.Background(), tC.request)
+			assert.Equal(t, tC.expectedResponse, response)
+		})
+	}
+}
+
+func TestAPIKeysValidatorUsage(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		path             string
+		expectedResponse []entity.Error
+	}{
+		{
+			desc: "Validates a valid client",
+			path: "/v1/admin/apikeys/partner-acme/usage",
+		},
+		{
+			desc: "Validates a valid client and days",
+			path: "/v1/admin/apikeys/partner-acme/usage?days=90",
+		},
+		{
+			desc:             "Validates an invalid client",
+			path:             "/v1/admin/apikeys/partner.acme/usage",
+			expectedResponse: []entity.Error{{Message: "Illegal value for property", Path: "clientid"}},
+		},
+		{
+			desc:             "Validates days out of range",
+			path:             "/v1/admin/apikeys/partner/usage?days=91",
+			expectedResponse: []entity.Error{{Message: "Illegal value for property", Path: "days"}},
+		},
+		{
+			desc:             "Validates non numeric days",
+			path:             "/v1/admin/apikeys/partner/usage?days=week",
+			expectedResponse: []entity.Error{{Message: "Illegal value for property", Path: "days"}},
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			var response []entity.Error
+			r := chi.NewRouter()
+			r.Get("/v1/admin/apikeys/{clientid}/usage", func(w http.ResponseWriter, req *http.Request) {
+				response = NewAPIKeysValidator().ValidateUsage(context.Background(), req)
+			})
+
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", tC.path, nil))
+
+			assert.Equal(t, tC.expectedResponse, response)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package validators
+
+import (
+	"context"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/rs/zerolog/log"
+)
+
+// maxBatchItems caps the number of zipcode and carrier pairs accepted in a single batch coverage check
+const maxBatchItems = 500
+
+type BatchCoverageCheckValidator interface {
+	Validate(ctx context.Context, request entity.BatchCoverageCheckRequest) []entity.Error
+	ValidateItem(ctx context.Context, item entity.CoverageCheckItem) []entity.Error
+}
+type batchCoverageCheckValidator struct {
+}
+
+func NewBatchCoverageCheckValidator() BatchCoverageCheckValidator {
+	return batchCoverageCheckValidator{}
+}
+
+// Validate checks the batch as a whole. Errors of individual items are reported by ValidateItem.
+func (v batchCoverageCheckValidator) Validate(ctx context.Context, request entity.BatchCoverageCheckRequest) []entity.Error {
+	var validationErrors []entity.Error
+
+	if len(request.Items) == 0 {
+		validationErrors = append(validationErrors, entity.Error{Message: "Missing required property", Path: "items"})
+		return validationErrors
+	}
+
+	if len(request.Items) > maxBatchItems {
+		log.Ctx(ctx).Debug().Int("items", len(request.Items)).Int("maxItems", maxBatchItems).Msg("batch too large")
+		validationErrors = append(validationErrors, entity.Error{Message: "Too many items", Path: "items"})
+	}
+	return validationErrors
+}
+
+func (v batchCoverageCheckValidator) ValidateItem(ctx context.Context, item entity.CoverageCheckItem) []entity.Error {
+	return validateCoverageCheck(ctx, item.ZipCode, item.CarrierID, false)
+}
@@ -0,0 +1,98 @@
+package validators
+
+import (
+	"context"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchCoverageCheckValidator(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		itemCount        int
+		expectedResponse []entity.Error
+	}{
+		{
+			desc:             "Validates a batch with items",
+			itemCount:        2,
+			expectedResponse: nil,
+		},
+		{
+			desc:      "Validates a batch without items",
+			itemCount: 0,
+			expectedResponse: []entity.Error{
+				entity.Error{Message: "Missing required property", Path: "items"},
+			},
+		},
+		{
+			desc:      "Validates a batch with too many items",
+			itemCount: maxBatchItems + 1,
+			expectedResponse: []entity.Error{
+				entity.Error{Message: "Too many items", Path: "items"},
+			},
+		},
+	}
+
+	for _, tC := range testCases {
+
+		t.Run(tC.desc, func(t *testing.T) {
+			request := entity.BatchCoverageCheckRequest{}
+			for i := 0; i < tC.itemCount; i++ {
+				request.Items = append(request.Items, entity.CoverageCheckItem{ZipCode: "94105", CarrierID: "1"})
+			}
+
+			validator := NewBatchCoverageCheckValidator()
+			response := validator.Validate(context.Background(), request)
+
+			assert.Equal(t, tC.expectedResponse, response)
+		})
+	}
+}
+
+func TestBatchCoverageCheckItemValidator(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		item             entity.CoverageCheckItem
+		expectedResponse []entity.Error
+	}{
+		{
+			desc:             "Validates a valid item",
+			item:             entity.CoverageCheckItem{ZipCode: "94105", CarrierID: "2"},
+			expectedResponse: nil,
+		},
+		{
+			desc: "Validates an item with missing zipcode",
+			item: entity.CoverageCheckItem{CarrierID: "2"},
+			expectedResponse: []entity.Error{
+				entity.Error{Message: "Missing required property", Path: "zipcode"},
+			},
+		},
+		{
+			desc: "Validates an item with invalid zipcode and carrierid",
+			item: entity.CoverageCheckItem{ZipCode: "941ab", CarrierID: "9"},
+			expectedResponse: []entity.Error{
+				entity.Error{Message: "Illegal value for property", Path: "zipcode"},
+				entity.Error{Message: "Illegal value for property", Path: "carrierid"},
+			},
+		},
+		{
+			desc: "Validates an item asking for all carriers",
+			item: entity.CoverageCheckItem{ZipCode: "94105", CarrierID: "all"},
+			expectedResponse: []entity.Error{
+				entity.Error{Message: "Illegal value for property", Path: "carrierid"},
+			},
+		},
+	}
+
+	for _, tC := range testCases {
+
+		t.Run(tC.desc, func(t *testing.T) {
+			validator := NewBatchCoverageCheckValidator()
+			response := validator.ValidateItem(context.Background(), tC.item)
+
+			assert.Equal(t, tC.expectedResponse, response)
+		})
+	}
+}
@@ -2,9 +2,12 @@ package validators
 
 import (
 	"context"
+	"math"
 	"net/http"
 	"regexp"
+	"strconv"
 
+	"bitbucket.org/credomobile/coverage/dbclient"
 	"bitbucket.org/credomobile/coverage/entity"
 	"github.com/rs/zerolog/log"
 )
@@ -73,15 +76,33 @@ func NewCoverageCheckValidator() CoverageCheckValidator {
 // 	return validationErrors
 // }
 
+// Validate validates a coverage check of a zipcode, or of a location given as lat and lon instead
 func (v coverageCheckValidator) Validate(ctx context.Context, r *http.Request) []entity.Error {
+	query := r.URL.Query()
+	var validationErrors []entity.Error
+	if query.Get("lat") != "" || query.Get("lon") != "" {
+		validationErrors = validateLocationCheck(ctx, query.Get("zipcode"), query.Get("lat"), query.Get("lon"), query.Get("carrierid"))
+	} else {
+		validationErrors = validateCoverageCheck(ctx, query.Get("zipcode"), query.Get("carrierid"), true)
+	}
+
+	switch r.URL.Query().Get("detail") {
+	case "", "true", "false":
+	default:
+		validationErrors = append(validationErrors, entity.Error{Message: "Illegal value for property", Path: "detail"})
+	}
+	return validationErrors
+}
+
+// validateCoverageCheck validates a zipcode and carrierid pair, shared by the single and batch coverage checks.
+// allowAllCarriers accepts carrierid=all to compare every supported carrier.
+func validateCoverageCheck(ctx context.Context, zipCode string, carrierID string, allowAllCarriers bool) []entity.Error {
 	var validationErrors []entity.Error
 
-	zipCode := r.URL.Query().Get("zipcode")
 	if zipCode == "" {
 		validationErrors = append(validationErrors, entity.Error{Message: "Missing required property", Path: "zipcode"})
 	}
 
-	carrierID := r.URL.Query().Get("carrierid")
 	if carrierID == "" {
 		validationErrors = append(validationErrors, entity.Error{Message: "Missing required property", Path: "carrierid"})
 	}
@@ -97,15 +118,49 @@ func (v coverageCheckValidator) Validate(ctx context.Context, r *http.Request) [
 		validationErrors = append(validationErrors, entity.Error{Message: "Illegal value for property", Path: "zipcode"})
 	}
 
+	return append(validationErrors, validateCarrierID(ctx, carrierID, allowAllCarriers)...)
+}
+
+// validateLocationCheck validates a lat and lon pair in place of a zipcode, along with the carrierid
+func validateLocationCheck(ctx context.Context, zipCode string, lat string, lon string, carrierID string) []entity.Error {
+	var validationErrors []entity.Error
+
+	if zipCode != "" {
+		validationErrors = append(validationErrors, entity.Error{Message: "Either zipcode or lat and lon may be given", Path: "zipcode"})
+	}
+	for _, coordinate := range []struct {
+		path  string
+		value string
+		limit float64
+	}{{"lat", lat, 90}, {"lon", lon, 180}} {
+		if coordinate.value == "" {
+			validationErrors = append(validationErrors, entity.Error{Message: "Missing required property", Path: coordinate.path})
+			continue
+		}
+		if value, err := strconv.ParseFloat(coordinate.value, 64); err != nil || math.IsNaN(value) || math.Abs(value) > coordinate.limit {
+			validationErrors = append(validationErrors, entity.Error{Message: "Illegal value for property", Path: coordinate.path})
+		}
+	}
+
+	if carrierID == "" {
+		return append(validationErrors, entity.Error{Message: "Missing required property", Path: "carrierid"})
+	}
+	return append(validationErrors, validateCarrierID(ctx, carrierID, true)...)
+}
+
+// validateCarrierID validates a carrierid is registered, or is all when allowAllCarriers
+func validateCarrierID(ctx context.Context, carrierID string, allowAllCarriers bool) []entity.Error {
+	var validationErrors []entity.Error
+
 	isValidCarrierID := false
-	switch entity.CarrierType(carrierID) {
-	case entity.Sprint:
+	if entity.CarrierType(carrierID) == entity.AllCarriers {
+		isValidCarrierID = allowAllCarriers
+		log.Ctx(ctx).Debug().Bool("carrierIDCheck", isValidCarrierID).Interface("carrierID", carrierID).Interface("ALL", carrierID)
+	} else if carrier, ok := dbclient.LookupCarrier(entity.CarrierType(carrierID)); ok {
 		isValidCarrierID = true
-		log.Ctx(ctx).Debug().Bool("carrierIDCheck", isValidCarrierID).Interface("carrierID", carrierID).Interface("SPRINT", carrierID)
-	case entity.Verizon:
-		isValidCarrierID = true
-		log.Ctx(ctx).Debug().Bool("carrierIDCheck", isValidCarrierID).Interface("carrierID", carrierID).Interface("VERIZON", carrierID)
-	default:
+		log.Ctx(ctx).Debug().Bool("carrierIDCheck", isValidCarrierID).Interface("carrierID", carrierID).Interface(carrier.Name, carrierID)
+	}
+	if !isValidCarrierID {
 		log.Ctx(ctx).Debug().Interface("Invalid Carrier ID", carrierID)
 		validationErrors = append(validationErrors, entity.Error{Message: "Illegal value for property", Path: "carrierid"})
 	}
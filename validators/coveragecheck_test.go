@@ -32,6 +32,27 @@ func TestCoverageCheckValidator(t *testing.T) {
 			expectError:      false,
 			expectedResponse: nil,
 		},
+		{
+			desc:             "Validates a valid zipcode and carrierid for T-Mobile",
+			zipCode:          "94105",
+			carrierID:        "3",
+			expectError:      false,
+			expectedResponse: nil,
+		},
+		{
+			desc:             "Validates a valid zipcode and carrierid for AT&T",
+			zipCode:          "94105",
+			carrierID:        "4",
+			expectError:      false,
+			expectedResponse: nil,
+		},
+		{
+			desc:             "Validates a valid zipcode and carrierid for all carriers",
+			zipCode:          "94105",
+			carrierID:        "all",
+			expectError:      false,
+			expectedResponse: nil,
+		},
 		{
 			desc:        "Validates a missing zipcode and carrierid",
 			zipCode:     "",
@@ -63,7 +84,7 @@ func TestCoverageCheckValidator(t *testing.T) {
 		{
 			desc:        "Validates a invalid zipcode and an invalid carrierid",
 			zipCode:     "941ab",
-			carrierID:   "3",
+			carrierID:   "9",
 			expectError: true,
 			expectedResponse: []entity.Error{
 				entity.Error{Message: "Illegal value for property", Path: "zipcode"},
@@ -91,6 +112,53 @@ func TestCoverageCheckValidator(t *testing.T) {
 	}
 }
 
+func TestCoverageCheckValidatorWithLocation(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		query            string
+		expectedResponse []entity.Error
+	}{
+		{
+			desc:  "Validates a valid location and carrierid",
+			query: "lat=37.7879&lon=-122.4075&carrierid=1",
+		},
+		{
+			desc:  "Validates a valid location for all carriers",
+			query: "lat=-90&lon=180&carrierid=all",
+		},
+		{
+			desc:             "Validates a location with a zipcode",
+			query:            "zipcode=94105&lat=37.7879&lon=-122.4075&carrierid=1",
+			expectedResponse: []entity.Error{{Message: "Either zipcode or lat and lon may be given", Path: "zipcode"}},
+		},
+		{
+			desc:             "Validates a missing lon and carrierid",
+			query:            "lat=37.7879",
+			expectedResponse: []entity.Error{{Message: "Missing required property", Path: "lon"}, {Message: "Missing required property", Path: "carrierid"}},
+		},
+		{
+			desc:             "Validates an out of range lat, a non numeric lon and an invalid carrierid",
+			query:            "lat=90.1&lon=abc&carrierid=9",
+			expectedResponse: []entity.Error{{Message: "Illegal value for property", Path: "lat"}, {Message: "Illegal value for property", Path: "lon"}, {Message: "Illegal value for property", Path: "carrierid"}},
+		},
+		{
+			desc:             "Validates a NaN lon",
+			query:            "lat=1&lon=NaN&carrierid=1",
+			expectedResponse: []entity.Error{{Message: "Illegal value for property", Path: "lon"}},
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "fakeUrlBasePath/v1/coveragecheck?"+tC.query, nil)
+
+			response := NewCoverageCheckValidator().Validate(context.Background(), req)
+
+			assert.Equal(t, tC.expectedResponse, response)
+		})
+	}
+}
+
 func TestInvalidZipCodeRegex(t *testing.T) {
 	testZipCodes := []string{
 		"9410a",
@@ -0,0 +1,58 @@
+package validators
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"bitbucket.org/credomobile/coverage/entity"
+)
+
+// versionRegex matches dataset versions, the load dates of the carrier exports such as 2018-11-01
+var versionRegex = regexp.MustCompile("^[0-9A-Za-z._-]{1,32}$")
+
+type CoverageDiffValidator interface {
+	Validate(ctx context.Context, r *http.Request) []entity.Error
+}
+type coverageDiffValidator struct {
+}
+
+func NewCoverageDiffValidator() CoverageDiffValidator {
+	return coverageDiffValidator{}
+}
+
+// Validate validates the carrierid, the to and optional from dataset versions, the optional swing threshold in
+// percentage points and the format of a coverage diff
+func (v coverageDiffValidator) Validate(ctx context.Context, r *http.Request) []entity.Error {
+	query := r.URL.Query()
+	var validationErrors []entity.Error
+
+	if carrierID := query.Get("carrierid"); carrierID == "" {
+		validationErrors = append(validationErrors, entity.Error{Message: "Missing required property", Path: "carrierid"})
+	} else {
+		validationErrors = append(validationErrors, validateCarrierID(ctx, carrierID, false)...)
+	}
+
+	if from := query.Get("from"); from != "" && !versionRegex.MatchString(from) {
+		validationErrors = append(validationErrors, entity.Error{Message: "Illegal value for property", Path: "from"})
+	}
+	if to := query.Get("to"); to == "" {
+		validationErrors = append(validationErrors, entity.Error{Message: "Missing required property", Path: "to"})
+	} else if !versionRegex.MatchString(to) {
+		validationErrors = append(validationErrors, entity.Error{Message: "Illegal value for property", Path: "to"})
+	}
+
+	if swing := query.Get("swing"); swing != "" {
+		if value, err := strconv.ParseFloat(swing, 64); err != nil || !(value > 0 && value <= 100) {
+			validationErrors = append(validationErrors, entity.Error{Message: "Illegal value for property", Path: "swing"})
+		}
+	}
+
+	switch query.Get("format") {
+	case "", "json", "csv":
+	default:
+		validationErrors = append(validationErrors, entity.Error{Message: "Illegal value for property", Path: "format"})
+	}
+	return validationErrors
+}
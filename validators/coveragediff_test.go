@@ -0,0 +1,57 @@
+package validators
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoverageDiffValidator(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		query            string
+		expectedResponse []entity.Error
+	}{
+		{
+			desc:  "Validates a diff against the live version",
+			query: "carrierid=2&to=2018-12-01",
+		},
+		{
+			desc:  "Validates a diff of two versions as csv",
+			query: "carrierid=1&from=2018-11-01&to=2018-12-01&swing=12.5&format=csv",
+		},
+		{
+			desc:             "Validates a missing carrierid and to",
+			query:            "from=2018-11-01",
+			expectedResponse: []entity.Error{{Message: "Missing required property", Path: "carrierid"}, {Message: "Missing required property", Path: "to"}},
+		},
+		{
+			desc:             "Validates all carriers",
+			query:            "carrierid=all&to=2018-12-01",
+			expectedResponse: []entity.Error{{Message: "Illegal value for property", Path: "carrierid"}},
+		},
+		{
+			desc:             "Validates invalid versions",
+			query:            "carrierid=2&from=2018%2311&to=2018%2F12",
+			expectedResponse: []entity.Error{{Message: "Illegal value for property", Path: "from"}, {Message: "Illegal value for property", Path: "to"}},
+		},
+		{
+			desc:             "Validates an invalid swing and format",
+			query:            "carrierid=2&to=2018-12-01&swing=0&format=xml",
+			expectedResponse: []entity.Error{{Message: "Illegal value for property", Path: "swing"}, {Message: "Illegal value for property", Path: "format"}},
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/v1/datasets/diff?"+tC.query, nil)
+
+			response := NewCoverageDiffValidator().Validate(context.Background(), r)
+
+			assert.Equal(t, tC.expectedResponse, response)
+		})
+	}
+}
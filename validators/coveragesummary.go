@@ -0,0 +1,48 @@
+package validators
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"bitbucket.org/credomobile/coverage/entity"
+)
+
+// stateRegex matches two letter state codes such as CA
+var stateRegex = regexp.MustCompile("^[A-Za-z]{2}$")
+
+// countyRegex matches county names such as Alameda, St. Louis or Prince George's
+var countyRegex = regexp.MustCompile("^[A-Za-z][A-Za-z .'-]{0,63}$")
+
+type CoverageSummaryValidator interface {
+	Validate(ctx context.Context, r *http.Request) []entity.Error
+}
+type coverageSummaryValidator struct {
+}
+
+func NewCoverageSummaryValidator() CoverageSummaryValidator {
+	return coverageSummaryValidator{}
+}
+
+// Validate validates the state, the optional county and the carrierid of a coverage summary
+func (v coverageSummaryValidator) Validate(ctx context.Context, r *http.Request) []entity.Error {
+	query := r.URL.Query()
+	var validationErrors []entity.Error
+
+	if state := query.Get("state"); state == "" {
+		validationErrors = append(validationErrors, entity.Error{Message: "Missing required property", Path: "state"})
+	} else if !stateRegex.MatchString(state) {
+		validationErrors = append(validationErrors, entity.Error{Message: "Illegal value for property", Path: "state"})
+	}
+
+	if county := query.Get("county"); county != "" && !countyRegex.MatchString(county) {
+		validationErrors = append(validationErrors, entity.Error{Message: "Illegal value for property", Path: "county"})
+	}
+
+	if carrierID := query.Get("carrierid"); carrierID == "" {
+		validationErrors = append(validationErrors, entity.Error{Message: "Missing required property", Path: "carrierid"})
+	} else {
+		validationErrors = append(validationErrors, validateCarrierID(ctx, carrierID, true)...)
+	}
+	return validationErrors
+}
@@ -0,0 +1,57 @@
+package validators
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCoverageSummaryValidator(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		query            string
+		expectedResponse []entity.Error
+	}{
+		{
+			desc:  "Validates a state",
+			query: "state=CA&carrierid=all",
+		},
+		{
+			desc:  "Validates a state and county",
+			query: "state=mo&county=St.%20Louis&carrierid=2",
+		},
+		{
+			desc:             "Validates a missing state and carrierid",
+			query:            "county=Alameda",
+			expectedResponse: []entity.Error{{Message: "Missing required property", Path: "state"}, {Message: "Missing required property", Path: "carrierid"}},
+		},
+		{
+			desc:             "Validates an invalid state",
+			query:            "state=California&carrierid=1",
+			expectedResponse: []entity.Error{{Message: "Illegal value for property", Path: "state"}},
+		},
+		{
+			desc:             "Validates an invalid county",
+			query:            "state=CA&county=%23Alameda&carrierid=1",
+			expectedResponse: []entity.Error{{Message: "Illegal value for property", Path: "county"}},
+		},
+		{
+			desc:             "Validates an invalid carrierid",
+			query:            "state=CA&carrierid=9",
+			expectedResponse: []entity.Error{{Message: "Illegal value for property", Path: "carrierid"}},
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/v1/coverage/summary?"+tC.query, nil)
+
+			response := NewCoverageSummaryValidator().Validate(context.Background(), r)
+
+			assert.Equal(t, tC.expectedResponse, response)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package validators
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/go-chi/chi"
+)
+
+// csaRegex matches Sprint csa_leaf values such as PHXTUC520
+var csaRegex = regexp.MustCompile("^[A-Za-z0-9]{1,32}$")
+
+// DefaultCsaZipCodesLimit and MaxCsaZipCodesLimit bound the page size of a CSA zipcode listing
+const (
+	DefaultCsaZipCodesLimit = 100
+	MaxCsaZipCodesLimit     = 500
+)
+
+type CsaZipCodesValidator interface {
+	Validate(ctx context.Context, r *http.Request) []entity.Error
+}
+type csaZipCodesValidator struct {
+}
+
+func NewCsaZipCodesValidator() CsaZipCodesValidator {
+	return csaZipCodesValidator{}
+}
+
+// Validate validates the csa path parameter and the optional limit of a CSA zipcode listing. The token is opaque
+// and checked when it is used.
+func (v csaZipCodesValidator) Validate(ctx context.Context, r *http.Request) []entity.Error {
+	var validationErrors []entity.Error
+
+	if !csaRegex.MatchString(chi.URLParam(r, "csa")) {
+		validationErrors = append(validationErrors, entity.Error{Message: "Illegal value for property", Path: "csa"})
+	}
+
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		if n, err := strconv.Atoi(limit); err != nil || n < 1 || n > MaxCsaZipCodesLimit {
+			validationErrors = append(validationErrors, entity.Error{Message: "Illegal value for property", Path: "limit"})
+		}
+	}
+	return validationErrors
+}
@@ -0,0 +1,58 @@
+package validators
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCsaZipCodesValidator(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		path             string
+		expectedResponse []entity.Error
+	}{
+		{
+			desc: "Validates a valid csa",
+			path: "/v1/csa/PHXTUC520/zipcodes",
+		},
+		{
+			desc: "Validates a valid csa and limit",
+			path: "/v1/csa/PHXTUC520/zipcodes?limit=500&token=abc",
+		},
+		{
+			desc:             "Validates an invalid csa",
+			path:             "/v1/csa/PHX-TUC/zipcodes",
+			expectedResponse: []entity.Error{{Message: "Illegal value for property", Path: "csa"}},
+		},
+		{
+			desc:             "Validates a non numeric limit",
+			path:             "/v1/csa/PHXTUC520/zipcodes?limit=abc",
+			expectedResponse: []entity.Error{{Message: "Illegal value for property", Path: "limit"}},
+		},
+		{
+			desc:             "Validates a limit out of range",
+			path:             "/v1/csa/PHXTUC520/zipcodes?limit=501",
+			expectedResponse: []entity.Error{{Message: "Illegal value for property", Path: "limit"}},
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			var response []entity.Error
+			r := chi.NewRouter()
+			r.Get("/v1/csa/{csa}/zipcodes", func(w http.ResponseWriter, req *http.Request) {
+				response = NewCsaZipCodesValidator().Validate(context.Background(), req)
+			})
+
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", tC.path, nil))
+
+			assert.Equal(t, tC.expectedResponse, response)
+		})
+	}
+}
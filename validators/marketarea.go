@@ -0,0 +1,28 @@
+package validators
+
+import (
+	"context"
+	"net/http"
+
+	"bitbucket.org/credomobile/coverage/entity"
+)
+
+type MarketAreaValidator interface {
+	Validate(ctx context.Context, r *http.Request) []entity.Error
+}
+type marketAreaValidator struct {
+}
+
+func NewMarketAreaValidator() MarketAreaValidator {
+	return marketAreaValidator{}
+}
+
+// Validate validates the zipcode and carrierid of a market area lookup. carrierid may be all, and is
+// Sprint when left out because the csa lookup used to be Sprint only.
+func (v marketAreaValidator) Validate(ctx context.Context, r *http.Request) []entity.Error {
+	carrierID := r.URL.Query().Get("carrierid")
+	if carrierID == "" {
+		carrierID = string(entity.Sprint)
+	}
+	return validateCoverageCheck(ctx, r.URL.Query().Get("zipcode"), carrierID, true)
+}
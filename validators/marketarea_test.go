@@ -10,10 +10,11 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func TestCsaValidator(t *testing.T) {
+func TestMarketAreaValidator(t *testing.T) {
 	testCases := []struct {
 		desc             string
 		zipCode          string
+		carrierID        string
 		expectError      bool
 		expectedResponse []entity.Error
 	}{
@@ -23,6 +24,27 @@ func TestCsaValidator(t *testing.T) {
 			expectError:      false,
 			expectedResponse: nil,
 		},
+		{
+			desc:             "Validates a valid zipcode and carrierid",
+			zipCode:          "94105",
+			carrierID:        "2",
+			expectError:      false,
+			expectedResponse: nil,
+		},
+		{
+			desc:             "Validates all carriers",
+			zipCode:          "94105",
+			carrierID:        "all",
+			expectError:      false,
+			expectedResponse: nil,
+		},
+		{
+			desc:             "Validates an invalid carrierid",
+			zipCode:          "94105",
+			carrierID:        "9",
+			expectError:      true,
+			expectedResponse: []entity.Error{entity.Error{Message: "Illegal value for property", Path: "carrierid"}},
+		},
 		{
 			desc:             "Validates a missing zipcode",
 			zipCode:          "",
@@ -53,9 +75,9 @@ func TestCsaValidator(t *testing.T) {
 
 		t.Run(tC.desc, func(t *testing.T) {
 
-			req, _ := http.NewRequest("GET", fmt.Sprintf("%s/v1/csa?zipcode=%s", "fakeUrlBasePath", tC.zipCode), nil)
+			req, _ := http.NewRequest("GET", fmt.Sprintf("%s/v1/marketarea?zipcode=%s&carrierid=%s", "fakeUrlBasePath", tC.zipCode, tC.carrierID), nil)
 
-			validator := NewCsaValidator()
+			validator := NewMarketAreaValidator()
 			response := validator.Validate(context.Background(), req)
 
 			if !tC.expectError {
@@ -0,0 +1,47 @@
+package validators
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"bitbucket.org/credomobile/coverage/entity"
+)
+
+// MaxRadiusMiles bounds the radius of a radius coverage search
+const MaxRadiusMiles = 25
+
+type RadiusCoverageValidator interface {
+	Validate(ctx context.Context, r *http.Request) []entity.Error
+}
+type radiusCoverageValidator struct {
+}
+
+func NewRadiusCoverageValidator() RadiusCoverageValidator {
+	return radiusCoverageValidator{}
+}
+
+// Validate validates the center of a radius coverage search, a zipcode or a location given as lat and lon, along with
+// the radius in miles, the carrierid and detail
+func (v radiusCoverageValidator) Validate(ctx context.Context, r *http.Request) []entity.Error {
+	query := r.URL.Query()
+	var validationErrors []entity.Error
+	if query.Get("lat") != "" || query.Get("lon") != "" {
+		validationErrors = validateLocationCheck(ctx, query.Get("zipcode"), query.Get("lat"), query.Get("lon"), query.Get("carrierid"))
+	} else {
+		validationErrors = validateCoverageCheck(ctx, query.Get("zipcode"), query.Get("carrierid"), true)
+	}
+
+	if radius := query.Get("radius"); radius == "" {
+		validationErrors = append(validationErrors, entity.Error{Message: "Missing required property", Path: "radius"})
+	} else if value, err := strconv.ParseFloat(radius, 64); err != nil || !(value > 0 && value <= MaxRadiusMiles) {
+		validationErrors = append(validationErrors, entity.Error{Message: "Illegal value for property", Path: "radius"})
+	}
+
+	switch query.Get("detail") {
+	case "", "true", "false":
+	default:
+		validationErrors = append(validationErrors, entity.Error{Message: "Illegal value for property", Path: "detail"})
+	}
+	return validationErrors
+}
@@ -0,0 +1,72 @@
+package validators
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"bitbucket.org/credomobile/coverage/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRadiusCoverageValidator(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		query            string
+		expectedResponse []entity.Error
+	}{
+		{
+			desc:  "Validates a zipcode center",
+			query: "zipcode=94105&radius=10&carrierid=all",
+		},
+		{
+			desc:  "Validates a location center",
+			query: "lat=37.78&lon=-122.4&radius=2.5&carrierid=2&detail=true",
+		},
+		{
+			desc:             "Validates a missing radius",
+			query:            "zipcode=94105&carrierid=1",
+			expectedResponse: []entity.Error{{Message: "Missing required property", Path: "radius"}},
+		},
+		{
+			desc:             "Validates a non numeric radius",
+			query:            "zipcode=94105&radius=ten&carrierid=1",
+			expectedResponse: []entity.Error{{Message: "Illegal value for property", Path: "radius"}},
+		},
+		{
+			desc:             "Validates a radius out of range",
+			query:            "zipcode=94105&radius=26&carrierid=1",
+			expectedResponse: []entity.Error{{Message: "Illegal value for property", Path: "radius"}},
+		},
+		{
+			desc:             "Validates a zero radius",
+			query:            "zipcode=94105&radius=0&carrierid=1",
+			expectedResponse: []entity.Error{{Message: "Illegal value for property", Path: "radius"}},
+		},
+		{
+			desc:             "Validates a missing center",
+			query:            "radius=10&carrierid=1",
+			expectedResponse: []entity.Error{{Message: "Missing required property", Path: "zipcode"}},
+		},
+		{
+			desc:             "Validates both a zipcode and a location",
+			query:            "zipcode=94105&lat=37.78&lon=-122.4&radius=10&carrierid=1",
+			expectedResponse: []entity.Error{{Message: "Either zipcode or lat and lon may be given", Path: "zipcode"}},
+		},
+		{
+			desc:             "Validates an invalid carrierid",
+			query:            "zipcode=94105&radius=10&carrierid=9",
+			expectedResponse: []entity.Error{{Message: "Illegal value for property", Path: "carrierid"}},
+		},
+	}
+
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/v1/coveragecheck/radius?"+tC.query, nil)
+
+			response := NewRadiusCoverageValidator().Validate(context.Background(), r)
+
+			assert.Equal(t, tC.expectedResponse, response)
+		})
+	}
+}